@@ -3,6 +3,7 @@ module github.com/metal3-io/baremetal-operator/apis
 go 1.16
 
 require (
+	github.com/google/gofuzz v1.1.0
 	github.com/stretchr/testify v1.7.0
 	k8s.io/api v0.20.2
 	k8s.io/apimachinery v0.20.2
@@ -43,6 +43,11 @@ const (
 	// unlike in the paused case, the host status may be updated
 	DetachedAnnotation = "baremetalhost.metal3.io/detached"
 
+	// RotateCredentialsAnnotation is the annotation that requests a new
+	// password to be set on the BMC account of the host and stored in
+	// its credentials secret
+	RotateCredentialsAnnotation = "baremetalhost.metal3.io/rotate-credentials"
+
 	// StatusAnnotation is the annotation that keeps a copy of the Status of BMH
 	// This is particularly useful when we pivot BMH. If the status
 	// annotation is present and status is empty, BMO will reconstruct BMH Status
@@ -160,6 +165,46 @@ const (
 	DetachError ErrorType = "detach error"
 )
 
+// Condition types reported in the status of a host
+const (
+	// RegisteredCondition is true when the host is registered with
+	// the provisioner.
+	RegisteredCondition = "Registered"
+
+	// CredentialsValidCondition is true when the current BMC
+	// credentials have been validated by the provisioner.
+	CredentialsValidCondition = "CredentialsValid"
+
+	// InspectedCondition is true when the hardware details of the host
+	// have been collected.
+	InspectedCondition = "Inspected"
+
+	// PreparedCondition is true when the RAID and firmware settings
+	// of the host have been applied.
+	PreparedCondition = "Prepared"
+
+	// ProvisionedCondition is true when an image has been written to
+	// the host, or when the host is externally provisioned.
+	ProvisionedCondition = "Provisioned"
+
+	// PowerSyncedCondition is true when the power state of the host
+	// matches the online field of its spec.
+	PowerSyncedCondition = "PowerSynced"
+
+	// DetachedCondition is true when the host has been detached from
+	// the provisioner.
+	DetachedCondition = "Detached"
+
+	// FirmwareInBaselineCondition is true when the firmware versions
+	// of the host match the FirmwareBaseline of its hardware profile.
+	FirmwareInBaselineCondition = "FirmwareInBaseline"
+
+	// BMCCertificateUnchangedCondition is true when the BMC presents
+	// the certificate whose fingerprint is recorded in the status, and
+	// false when it presents another one.
+	BMCCertificateUnchangedCondition = "BMCCertificateUnchanged"
+)
+
 // ProvisioningState defines the states the provisioner will report
 // the host has having.
 type ProvisioningState string
@@ -222,15 +267,76 @@ type BMCDetails struct {
 	Address string `json:"address"`
 
 	// The name of the secret containing the BMC credentials (requires
-	// keys "username" and "password").
+	// keys "username" and "password"). With a credentials provider
+	// other than Secret, the path of the credentials in the store of
+	// the provider.
 	CredentialsName string `json:"credentialsName"`
 
+	// CredentialsProvider selects the store holding the BMC
+	// credentials. Defaults to a Secret in the namespace of the host.
+	// +optional
+	CredentialsProvider CredentialsProviderType `json:"credentialsProvider,omitempty"`
+
 	// DisableCertificateVerification disables verification of server
 	// certificates when using HTTPS to connect to the BMC. This is
 	// required when the server certificate is self-signed, but is
 	// insecure because it allows a man-in-the-middle to intercept the
 	// connection.
 	DisableCertificateVerification bool `json:"disableCertificateVerification,omitempty"`
+
+	// CABundle references the certificates of the authorities trusted
+	// to sign the server certificate of the BMC, for BMCs using an
+	// internal CA. It is ignored when DisableCertificateVerification
+	// is set.
+	// +optional
+	CABundle *CABundleReference `json:"caBundle,omitempty"`
+}
+
+// CredentialsProviderType is the store holding the credentials of a
+// BMC.
+// +kubebuilder:validation:Enum=Secret;Vault;File
+type CredentialsProviderType string
+
+// Supported credentials providers
+const (
+	// CredentialsProviderSecret reads the credentials from a Secret
+	CredentialsProviderSecret CredentialsProviderType = "Secret"
+	// CredentialsProviderVault reads the credentials from the KV
+	// secrets engine of a Vault compatible server, under the namespace
+	// of the host
+	CredentialsProviderVault CredentialsProviderType = "Vault"
+	// CredentialsProviderFile reads the credentials from files mounted
+	// in the operator, for example by a CSI driver, under a directory
+	// named after the namespace of the host
+	CredentialsProviderFile CredentialsProviderType = "File"
+)
+
+// CABundleKind is the kind of object holding a CA bundle.
+// +kubebuilder:validation:Enum=Secret;ConfigMap
+type CABundleKind string
+
+// Allowed CA bundle kinds
+const (
+	CABundleKindSecret    CABundleKind = "Secret"
+	CABundleKindConfigMap CABundleKind = "ConfigMap"
+)
+
+// DefaultCABundleKey is the key holding the CA bundle when none is
+// given.
+const DefaultCABundleKey = "ca.crt"
+
+// CABundleReference points to a Secret or ConfigMap, in the namespace
+// of the host, holding PEM encoded CA certificates.
+type CABundleReference struct {
+	// The kind of object holding the bundle, Secret or ConfigMap.
+	Kind CABundleKind `json:"kind"`
+
+	// The name of the object holding the bundle.
+	Name string `json:"name"`
+
+	// The key of the bundle in the object. Defaults to "ca.crt".
+	// +optional
+	Key string `json:"key,omitempty"`
 }
 
 // HardwareRAIDVolume defines the desired configuration of volume in hardware RAID
@@ -289,6 +395,67 @@ type RAIDConfig struct {
 	SoftwareRAIDVolumes []SoftwareRAIDVolume `json:"softwareRAIDVolumes,omitempty"`
 }
 
+// BootDevice is a kind of device the host can boot from.
+// +kubebuilder:validation:Enum=Disk;PXE;CD;USB
+type BootDevice string
+
+// Allowed boot devices
+const (
+	BootDeviceDisk BootDevice = "Disk"
+	BootDevicePXE  BootDevice = "PXE"
+	BootDeviceCD   BootDevice = "CD"
+	BootDeviceUSB  BootDevice = "USB"
+)
+
+// PowerProfile is the trade-off between performance and power usage
+// of the host.
+// +kubebuilder:validation:Enum=Performance;Balanced;PowerSaving
+type PowerProfile string
+
+// Allowed power profiles
+const (
+	PowerProfilePerformance PowerProfile = "Performance"
+	PowerProfileBalanced    PowerProfile = "Balanced"
+	PowerProfilePowerSaving PowerProfile = "PowerSaving"
+)
+
+// CStateLimit is the deepest idle state processors can enter.
+// +kubebuilder:validation:Enum=C1;C1E;C6
+type CStateLimit string
+
+// Allowed C-state limits
+const (
+	CStateLimitC1  CStateLimit = "C1"
+	CStateLimitC1E CStateLimit = "C1E"
+	CStateLimitC6  CStateLimit = "C6"
+)
+
+// SecureBootKeys selects the keys used to verify boot loaders when
+// secure boot is enabled.
+// +kubebuilder:validation:Enum=Default;Custom
+type SecureBootKeys string
+
+// Allowed secure boot keys
+const (
+	// SecureBootKeysDefault uses the keys installed by the vendor.
+	SecureBootKeysDefault SecureBootKeys = "Default"
+	// SecureBootKeysCustom uses the keys enrolled by the user.
+	SecureBootKeysCustom SecureBootKeys = "Custom"
+)
+
+// NICPXEConfig enables or disables network boot on a network
+// interface.
+type NICPXEConfig struct {
+	// The index of the network interface in the firmware, starting
+	// from 1.
+	// +kubebuilder:validation:Minimum=1
+	Index int `json:"index"`
+
+	// Whether the host can boot from the network through the
+	// interface.
+	Enabled bool `json:"enabled"`
+}
+
 // FirmwareConfig contains the configuration that you want to configure BIOS settings in Bare metal server
 type FirmwareConfig struct {
 	// Supports the virtualization of platform hardware.
@@ -305,6 +472,110 @@ type FirmwareConfig struct {
 	// This supports following options: true, false.
 	// +kubebuilder:validation:Enum=true;false
 	SriovEnabled *bool `json:"sriovEnabled,omitempty"`
+
+	// The order in which the host tries to boot from its devices.
+	// +optional
+	BootOrder []BootDevice `json:"bootOrder,omitempty"`
+
+	// The trade-off between performance and power usage.
+	// This supports following options: Performance, Balanced, PowerSaving.
+	// +optional
+	PowerProfile PowerProfile `json:"powerProfile,omitempty"`
+
+	// Allows idle processors to enter power saving states.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	CStatesEnabled *bool `json:"cStatesEnabled,omitempty"`
+
+	// The deepest idle state processors can enter, to bound the
+	// latency of waking them up.
+	// This supports following options: C1, C1E, C6.
+	// +optional
+	CStateLimit CStateLimit `json:"cStateLimit,omitempty"`
+
+	// Allows processors to run above their base frequency.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	TurboBoostEnabled *bool `json:"turboBoostEnabled,omitempty"`
+
+	// Exposes the memory of each processor as a separate NUMA node
+	// instead of interleaving it.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	NUMAEnabled *bool `json:"numaEnabled,omitempty"`
+
+	// Interleaves memory accesses across the channels of each memory
+	// controller.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	MemoryInterleavingEnabled *bool `json:"memoryInterleavingEnabled,omitempty"`
+
+	// Enables HyperTransport Assist, which reduces the cache probe
+	// traffic between AMD processors.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	HyperTransportAssistEnabled *bool `json:"hyperTransportAssistEnabled,omitempty"`
+
+	// Enables the Trusted Platform Module.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	TPMEnabled *bool `json:"tpmEnabled,omitempty"`
+
+	// The keys used to verify boot loaders with secure boot.
+	// This supports following options: Default, Custom.
+	// +optional
+	SecureBootKeys SecureBootKeys `json:"secureBootKeys,omitempty"`
+
+	// Enables or disables network boot per network interface.
+	// +optional
+	PXE []NICPXEConfig `json:"pxe,omitempty"`
+}
+
+// FirmwareComponent is a part of the host whose firmware can be
+// updated.
+// +kubebuilder:validation:Enum=bios;bmc;nic
+type FirmwareComponent string
+
+const (
+	// FirmwareComponentBIOS is the BIOS or UEFI firmware of the system
+	FirmwareComponentBIOS FirmwareComponent = "bios"
+
+	// FirmwareComponentBMC is the firmware of the BMC
+	FirmwareComponentBMC FirmwareComponent = "bmc"
+
+	// FirmwareComponentNIC is the firmware of the network adapters
+	FirmwareComponentNIC FirmwareComponent = "nic"
+)
+
+// FirmwareUpdate describes a firmware image to apply to a component of
+// the host while it is prepared.
+type FirmwareUpdate struct {
+	// The component updated by the image.
+	Component FirmwareComponent `json:"component"`
+
+	// URL of the firmware image, reachable by the BMC.
+	URL string `json:"url"`
+
+	// The SHA1 checksum of the image, checked before it is applied.
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+
+	// The version the component reports once the image is applied.
+	Version string `json:"version"`
+}
+
+// FirmwareUpdateStatus reports the versions of a component updated
+// while the host was prepared.
+type FirmwareUpdateStatus struct {
+	FirmwareUpdate `json:",inline"`
+
+	// The version of the component before the update, if known.
+	// +optional
+	PreviousVersion string `json:"previousVersion,omitempty"`
+
+	// The version of the component after the update, if known.
+	// +optional
+	CurrentVersion string `json:"currentVersion,omitempty"`
 }
 
 // BareMetalHostSpec defines the desired state of BareMetalHost
@@ -327,6 +598,11 @@ type BareMetalHostSpec struct {
 	// BIOS configuration for bare metal server
 	Firmware *FirmwareConfig `json:"firmware,omitempty"`
 
+	// Firmware images to apply to the components of the host, in
+	// order, when it is prepared.
+	// +optional
+	FirmwareUpdates []FirmwareUpdate `json:"firmwareUpdates,omitempty"`
+
 	// What is the name of the hardware profile for this host? It
 	// should only be necessary to set this when inspection cannot
 	// automatically determine the profile.
@@ -534,6 +810,9 @@ type Storage struct {
 
 	// The SCSI location of the device
 	HCTL string `json:"hctl,omitempty"`
+
+	// The firmware revision of the device
+	FirmwareVersion string `json:"firmwareVersion,omitempty"`
 }
 
 // VLANID is a 12-bit 802.1Q VLAN identifier
@@ -577,12 +856,34 @@ type NIC struct {
 
 	// Whether the NIC is PXE Bootable
 	PXE bool `json:"pxe,omitempty"`
+
+	// The version of the firmware of the NIC
+	FirmwareVersion string `json:"firmwareVersion,omitempty"`
 }
 
 // Firmware describes the firmware on the host.
 type Firmware struct {
 	// The BIOS for this firmware
 	BIOS BIOS `json:"bios,omitempty"`
+
+	// The version of the firmware of the BMC
+	BMCVersion string `json:"bmcVersion,omitempty"`
+
+	// The RAID controllers of the host
+	RAIDControllers []RAIDController `json:"raidControllers,omitempty"`
+}
+
+// RAIDController describes the firmware of a RAID controller on the
+// host.
+type RAIDController struct {
+	// The name of the controller
+	Name string `json:"name,omitempty"`
+
+	// The model of the controller
+	Model string `json:"model,omitempty"`
+
+	// The version of the firmware of the controller
+	FirmwareVersion string `json:"firmwareVersion,omitempty"`
 }
 
 // BIOS describes the BIOS version on the host.
@@ -714,6 +1015,12 @@ type BareMetalHostStatus struct {
 	// the last credentials we sent to the provisioning backend
 	TriedCredentials CredentialsStatus `json:"triedCredentials,omitempty"`
 
+	// The SHA-256 fingerprint of the certificate presented by the BMC
+	// when access to it was last validated, for trust-on-first-use
+	// pinning. The certificate is checked against it periodically.
+	// +optional
+	BMCCertificateFingerprint string `json:"bmcCertificateFingerprint,omitempty"`
+
 	// the last error message reported by the provisioning subsystem
 	ErrorMessage string `json:"errorMessage"`
 
@@ -727,6 +1034,35 @@ type BareMetalHostStatus struct {
 	// ErrorCount records how many times the host has encoutered an error since the last successful operation
 	// +kubebuilder:default:=0
 	ErrorCount int `json:"errorCount"`
+
+	// CapacityQueue is the position of the host in the queue of the
+	// hosts waiting for a (de)provisioning slot, while it is delayed.
+	// +optional
+	CapacityQueue *CapacityQueueStatus `json:"capacityQueue,omitempty"`
+
+	// Conditions describe the current state of the host.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// CapacityQueueStatus is the position of a delayed host in the queue
+// of the hosts of its capacity pool waiting for a (de)provisioning slot
+type CapacityQueueStatus struct {
+	// Pool is the name of the CapacityPool of the host, empty when the
+	// host belongs to no pool.
+	// +optional
+	Pool string `json:"pool,omitempty"`
+
+	// Position is the position of the host in the queue of its pool,
+	// starting at 1.
+	Position int `json:"position"`
+
+	// Since is when the host started waiting.
+	Since metav1.Time `json:"since"`
 }
 
 // ProvisionStatus holds the state information for a single target.
@@ -753,6 +1089,10 @@ type ProvisionStatus struct {
 	// The Bios set by the user
 	Firmware *FirmwareConfig `json:"firmware,omitempty"`
 
+	// The firmware updates applied when the host was prepared, with
+	// the versions of the components before and after the update
+	FirmwareUpdates []FirmwareUpdateStatus `json:"firmwareUpdates,omitempty"`
+
 	// Custom deploy procedure applied to the host.
 	CustomDeploy *CustomDeploy `json:"customDeploy,omitempty"`
 }
@@ -763,6 +1103,7 @@ type ProvisionStatus struct {
 // +k8s:openapi-gen=true
 // +kubebuilder:resource:shortName=bmh;bmhost
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.operationalStatus",description="Operational status",priority=1
 // +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.provisioning.state",description="Provisioning status"
 // +kubebuilder:printcolumn:name="Consumer",type="string",JSONPath=".spec.consumerRef.name",description="Consumer using this host"
@@ -0,0 +1,116 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// BareMetalHostClaimFinalizer is the name of the finalizer added
+	// to claims so the host is released before they are deleted.
+	BareMetalHostClaimFinalizer string = "baremetalhostclaim.metal3.io"
+)
+
+// ClaimPhase describes the binding of a claim to a host.
+type ClaimPhase string
+
+const (
+	// ClaimPhasePending means no host matching the claim is
+	// available yet.
+	ClaimPhasePending ClaimPhase = "Pending"
+
+	// ClaimPhaseBound means a host has been reserved for the claim.
+	ClaimPhaseBound ClaimPhase = "Bound"
+)
+
+// BareMetalHostClaimSpec defines the requirements a host must meet to
+// be bound to the claim. Only hosts in the namespace of the claim are
+// considered.
+type BareMetalHostClaimSpec struct {
+	// Selector restricts the hosts to the ones with matching labels.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// The minimum amount of RAM of the host, in Mebibytes.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinRAMMebibytes int `json:"minRAMMebibytes,omitempty"`
+
+	// The minimum number of CPUs of the host.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinCPUCount int `json:"minCPUCount,omitempty"`
+
+	// The host must have at least one disk of this type.
+	// +kubebuilder:validation:Enum=HDD;SSD;NVME;
+	// +optional
+	DiskType DiskType `json:"diskType,omitempty"`
+
+	// The host must have at least one network interface with this
+	// speed, in Gbps.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinNICSpeedGbps int `json:"minNICSpeedGbps,omitempty"`
+
+	// The hardware profile of the host.
+	// +optional
+	HardwareProfile string `json:"hardwareProfile,omitempty"`
+}
+
+// BareMetalHostClaimStatus defines the observed state of BareMetalHostClaim
+type BareMetalHostClaimStatus struct {
+	// Phase is Pending until a host is bound to the claim.
+	// +optional
+	Phase ClaimPhase `json:"phase,omitempty"`
+
+	// HostName is the name of the host bound to the claim.
+	// +optional
+	HostName string `json:"hostName,omitempty"`
+
+	// Message explains why the claim is still pending.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=bmhc
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Binding of the claim"
+//+kubebuilder:printcolumn:name="Host",type="string",JSONPath=".status.hostName",description="Host bound to the claim"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// BareMetalHostClaim is the Schema for the baremetalhostclaims API
+type BareMetalHostClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BareMetalHostClaimSpec   `json:"spec,omitempty"`
+	Status BareMetalHostClaimStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BareMetalHostClaimList contains a list of BareMetalHostClaim
+type BareMetalHostClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BareMetalHostClaim `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BareMetalHostClaim{}, &BareMetalHostClaimList{})
+}
@@ -0,0 +1,173 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// BMCDiscoveryLabel is the label holding the name of the
+	// BMCDiscovery that created a host
+	BMCDiscoveryLabel = "metal3.io/bmc-discovery"
+)
+
+// DiscoveryProtocol is a protocol used to find BMCs
+// +kubebuilder:validation:Enum=redfish;ipmi
+type DiscoveryProtocol string
+
+const (
+	// DiscoveryProtocolRedfish finds BMCs serving a Redfish service
+	// root
+	DiscoveryProtocolRedfish DiscoveryProtocol = "redfish"
+
+	// DiscoveryProtocolIPMI finds BMCs answering an RMCP presence
+	// ping
+	DiscoveryProtocolIPMI DiscoveryProtocol = "ipmi"
+)
+
+// BMCDiscoverySpec defines the address ranges to scan for BMCs and how
+// to access the BMCs found
+type BMCDiscoverySpec struct {
+	// CIDRs are the address ranges to scan. The ranges are limited to
+	// 4096 addresses in total.
+	// +kubebuilder:validation:MinItems=1
+	CIDRs []string `json:"cidrs"`
+
+	// CredentialsName is the name of the secret in the namespace of
+	// the discovery holding the credentials of the BMCs. It is used
+	// to read the details of the systems, and each host created gets
+	// its own copy of it.
+	CredentialsName string `json:"credentialsName"`
+
+	// Protocols are the protocols used to find BMCs, all of them by
+	// default. A BMC answering to several protocols is reported once,
+	// with the first protocol of the list that found it.
+	// +optional
+	Protocols []DiscoveryProtocol `json:"protocols,omitempty"`
+
+	// RedfishPort is the HTTPS port of the Redfish service, 443 by
+	// default.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	RedfishPort int `json:"redfishPort,omitempty"`
+
+	// IPMIPort is the UDP port of the IPMI service, 623 by default.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	IPMIPort int `json:"ipmiPort,omitempty"`
+
+	// DisableCertificateVerification disables verification of the
+	// certificates of the BMCs, both during discovery and by the
+	// hosts created.
+	// +optional
+	DisableCertificateVerification bool `json:"disableCertificateVerification,omitempty"`
+
+	// RescanInterval is the time between two scans of the ranges. The
+	// ranges are only scanned again when the spec changes if it is
+	// not set.
+	// +optional
+	RescanInterval *metav1.Duration `json:"rescanInterval,omitempty"`
+}
+
+// DiscoveredBMC describes a BMC found by a scan
+type DiscoveredBMC struct {
+	// IP is the address the BMC answered on
+	IP string `json:"ip"`
+
+	// Protocol is the protocol the BMC was found with
+	Protocol DiscoveryProtocol `json:"protocol"`
+
+	// Address is the BMC address of the host created for the system
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// SerialNumber is the serial number of the system
+	// +optional
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// MACAddresses are the MAC addresses of the network interfaces of
+	// the system
+	// +optional
+	MACAddresses []string `json:"macAddresses,omitempty"`
+
+	// HostName is the name of the host managing the system
+	// +optional
+	HostName string `json:"hostName,omitempty"`
+
+	// ErrorMessage explains why the details of the system could not
+	// be read or why no host was created for it
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// BMCDiscoveryStatus defines the observed state of BMCDiscovery
+type BMCDiscoveryStatus struct {
+	// LastScanTime is the time the last scan completed
+	// +optional
+	LastScanTime *metav1.Time `json:"lastScanTime,omitempty"`
+
+	// ObservedGeneration is the generation of the spec used for the
+	// last scan
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ScannedAddresses is the number of addresses already scanned by
+	// the scan in progress. The ranges are scanned a batch of
+	// addresses at a time.
+	// +optional
+	ScannedAddresses int `json:"scannedAddresses,omitempty"`
+
+	// BMCs are the BMCs found by the last scan, or so far by the scan
+	// in progress
+	// +optional
+	BMCs []DiscoveredBMC `json:"bmcs,omitempty"`
+
+	// ErrorMessage explains why the last scan failed
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=bmcd
+//+kubebuilder:printcolumn:name="Last Scan",type="date",JSONPath=".status.lastScanTime",description="Time of the last scan"
+//+kubebuilder:printcolumn:name="Error",type="string",JSONPath=".status.errorMessage",description="Why the last scan failed"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// BMCDiscovery is the Schema for the bmcdiscoveries API
+type BMCDiscovery struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BMCDiscoverySpec   `json:"spec,omitempty"`
+	Status BMCDiscoveryStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BMCDiscoveryList contains a list of BMCDiscovery
+type BMCDiscoveryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BMCDiscovery `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BMCDiscovery{}, &BMCDiscoveryList{})
+}
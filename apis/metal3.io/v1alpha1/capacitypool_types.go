@@ -0,0 +1,82 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// CapacityPoolSpec defines the hosts of a pool and how many of them
+// can be (de)provisioned simultaneously
+type CapacityPoolSpec struct {
+	// The namespaces of the hosts of the pool.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// A label selector of the hosts of the pool.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// The maximum number of hosts of the pool inspected, provisioned
+	// or deprovisioned simultaneously.
+	// +kubebuilder:validation:Minimum=1
+	Limit int `json:"limit"`
+}
+
+// Matches returns true when the host belongs to the pool, because of
+// its namespace or its labels.
+func (pool *CapacityPool) Matches(host *BareMetalHost) (bool, error) {
+	for _, namespace := range pool.Spec.Namespaces {
+		if namespace == host.Namespace {
+			return true, nil
+		}
+	}
+	if pool.Spec.Selector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(pool.Spec.Selector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(host.Labels)), nil
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Limit",type="integer",JSONPath=".spec.limit",description="Maximum number of busy hosts"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// CapacityPool is the Schema for the capacitypools API
+type CapacityPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CapacityPoolSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CapacityPoolList contains a list of CapacityPool
+type CapacityPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CapacityPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CapacityPool{}, &CapacityPoolList{})
+}
@@ -0,0 +1,111 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FirmwareBaselineComponent is the firmware version expected on the
+// components of a given model.
+type FirmwareBaselineComponent struct {
+	// A string the model of the component must contain, ignoring
+	// case. Components of every model match when it is not set.
+	// +optional
+	Model string `json:"model,omitempty"`
+
+	// The firmware version expected on the matching components.
+	Version string `json:"version"`
+}
+
+// FirmwareBaselineSpec defines the firmware versions expected on the
+// hosts of a hardware profile
+type FirmwareBaselineSpec struct {
+	// HardwareProfile is the name of the hardware profile of the
+	// hosts the baseline applies to, as found in their status.
+	// +kubebuilder:validation:MinLength=1
+	HardwareProfile string `json:"hardwareProfile"`
+
+	// The expected version of the BIOS.
+	// +optional
+	BIOSVersion string `json:"biosVersion,omitempty"`
+
+	// The expected version of the firmware of the BMC.
+	// +optional
+	BMCVersion string `json:"bmcVersion,omitempty"`
+
+	// The expected versions of the firmware of the NICs. A NIC is
+	// compared with the first entry matching its model.
+	// +optional
+	NICs []FirmwareBaselineComponent `json:"nics,omitempty"`
+
+	// The expected versions of the firmware of the RAID controllers.
+	// A controller is compared with the first entry matching its
+	// model.
+	// +optional
+	RAIDControllers []FirmwareBaselineComponent `json:"raidControllers,omitempty"`
+
+	// The expected versions of the firmware of the disks. A disk is
+	// compared with the first entry matching its model.
+	// +optional
+	Disks []FirmwareBaselineComponent `json:"disks,omitempty"`
+}
+
+// FirmwareBaselineStatus reports how many hosts match the baseline
+type FirmwareBaselineStatus struct {
+	// ObservedGeneration is the generation of the spec the hosts were
+	// last compared with
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Hosts is the number of inspected hosts the baseline applies to
+	// +optional
+	Hosts int `json:"hosts,omitempty"`
+
+	// OutOfBaselineHosts are the names of the hosts whose firmware
+	// does not match the baseline
+	// +optional
+	OutOfBaselineHosts []string `json:"outOfBaselineHosts,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=fwb
+//+kubebuilder:printcolumn:name="Profile",type="string",JSONPath=".spec.hardwareProfile",description="Hardware profile of the hosts"
+//+kubebuilder:printcolumn:name="Hosts",type="integer",JSONPath=".status.hosts",description="Number of inspected hosts"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// FirmwareBaseline is the Schema for the firmwarebaselines API
+type FirmwareBaseline struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FirmwareBaselineSpec   `json:"spec,omitempty"`
+	Status FirmwareBaselineStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FirmwareBaselineList contains a list of FirmwareBaseline
+type FirmwareBaselineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FirmwareBaseline `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FirmwareBaseline{}, &FirmwareBaselineList{})
+}
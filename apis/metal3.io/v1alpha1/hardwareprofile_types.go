@@ -0,0 +1,151 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IntRange is an inclusive range of integer values. A zero Max leaves
+// the range open ended.
+type IntRange struct {
+	// The lowest value in the range.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Min int `json:"min,omitempty"`
+
+	// The highest value in the range.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Max int `json:"max,omitempty"`
+}
+
+// DiskMatch describes the disks a host must have.
+type DiskMatch struct {
+	// Only count disks of this type.
+	// +kubebuilder:validation:Enum=HDD;SSD;NVME;
+	// +optional
+	Type DiskType `json:"type,omitempty"`
+
+	// Only count disks with a size in this range, in Gigabytes.
+	// +optional
+	SizeGigabytes *IntRange `json:"sizeGigabytes,omitempty"`
+
+	// The number of disks the host must have, after filtering them by
+	// type and size. At least one disk is required when the count is
+	// not set.
+	// +optional
+	Count *IntRange `json:"count,omitempty"`
+}
+
+// HardwareProfileMatch holds the rules a host must satisfy to use a
+// hardware profile. Only the rules that are set are checked, and all
+// of them must match the hardware details of the host.
+type HardwareProfileMatch struct {
+	// A string the manufacturer of the host must contain, ignoring
+	// case.
+	// +optional
+	Manufacturer string `json:"manufacturer,omitempty"`
+
+	// A string the product name of the host must contain, ignoring
+	// case.
+	// +optional
+	ProductName string `json:"productName,omitempty"`
+
+	// The architecture of the CPU, e.g. "x86_64".
+	// +optional
+	CPUArch string `json:"cpuArch,omitempty"`
+
+	// The range of the number of CPUs.
+	// +optional
+	CPUCount *IntRange `json:"cpuCount,omitempty"`
+
+	// The range of the amount of RAM, in Mebibytes.
+	// +optional
+	RAMMebibytes *IntRange `json:"ramMebibytes,omitempty"`
+
+	// The disks the host must have.
+	// +optional
+	Disks *DiskMatch `json:"disks,omitempty"`
+
+	// The range of the number of network interfaces.
+	// +optional
+	NICCount *IntRange `json:"nicCount,omitempty"`
+}
+
+// HardwareProfileSpec defines the desired state of HardwareProfile
+type HardwareProfileSpec struct {
+	// Match holds the rules used to select the profile for a host
+	// based on its hardware details. A profile without rules matches
+	// every host.
+	// +optional
+	Match HardwareProfileMatch `json:"match,omitempty"`
+
+	// Priority is used to choose between several matching profiles,
+	// the highest value wins. Profiles with the same priority are
+	// ordered by the number of rules they match.
+	// +optional
+	Priority int `json:"priority,omitempty"`
+
+	// RootDeviceHints holds the suggestions for placing the storage
+	// for the root filesystem of hosts using the profile.
+	// +optional
+	RootDeviceHints *RootDeviceHints `json:"rootDeviceHints,omitempty"`
+
+	// RootGB is the size of the root volume in GB.
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RootGB int `json:"rootGB,omitempty"`
+
+	// LocalGB is the size of the local disk in GB.
+	// +kubebuilder:default=50
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	LocalGB int `json:"localGB,omitempty"`
+
+	// CPUArch is the architecture of the CPU reported to the
+	// provisioner.
+	// +kubebuilder:default=x86_64
+	// +optional
+	CPUArch string `json:"cpuArch,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:shortName=hwp
+//+kubebuilder:printcolumn:name="Priority",type="integer",JSONPath=".spec.priority",description="Priority of the profile"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// HardwareProfile is the Schema for the hardwareprofiles API
+type HardwareProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HardwareProfileSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HardwareProfileList contains a list of HardwareProfile
+type HardwareProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HardwareProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HardwareProfile{}, &HardwareProfileList{})
+}
@@ -33,13 +33,22 @@ type SchemaReference struct {
 	Name string `json:"name"`
 }
 
+// HostFirmwareSettingsValidCondition is the condition reporting
+// whether all the settings of the spec are known to the firmware.
+const HostFirmwareSettingsValidCondition = "Valid"
+
+// HostFirmwareSettingsAppliedCondition is the condition reporting
+// whether the firmware reports the values of the settings last applied
+// to the host.
+const HostFirmwareSettingsAppliedCondition = "Applied"
+
 // HostFirmwareSettingsSpec defines the desired state of HostFirmwareSettings
 type HostFirmwareSettingsSpec struct {
 
 	// Settings are the desired firmware settings stored as name/value pairs.
-	// This will be populated with the actual firmware settings and only
-	// contain the settings that can be modified (i.e. not ReadOnly), to
-	// facilitate making changes.
+	// Settings that differ from the actual value in the status are applied
+	// when the host is prepared, unless the same value was already
+	// applied.
 	// +patchStrategy=merge
 	Settings DesiredSettingsMap `json:"settings" required:"true"`
 }
@@ -53,6 +62,23 @@ type HostFirmwareSettingsStatus struct {
 
 	// Settings are the actual firmware settings stored as name/value pairs
 	Settings SettingsMap `json:"settings" required:"true"`
+
+	// AppliedSettings are the desired settings last applied to the
+	// host. They are not applied again while the spec keeps the same
+	// values, even when the firmware reports different ones.
+	// +optional
+	AppliedSettings SettingsMap `json:"appliedSettings,omitempty"`
+
+	// Conditions describe the state of the settings. The Valid
+	// condition is false when the spec names settings the firmware
+	// does not report. The Applied condition is false when the firmware
+	// reports other values than the ones last applied.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 //+kubebuilder:object:root=true
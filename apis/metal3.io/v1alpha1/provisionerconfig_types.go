@@ -0,0 +1,165 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ProvisionerConfigValidCondition reports whether the provisioner
+	// uses the current spec of the ProvisionerConfig
+	ProvisionerConfigValidCondition = "Valid"
+)
+
+// IronicTLSConfig is the TLS configuration of the connection to an
+// Ironic endpoint. The files are read again when they change, so that
+// mounted certificates can be rotated.
+type IronicTLSConfig struct {
+	// The path of the CA certificate file of Ironic. Defaults to
+	// /opt/metal3/certs/ca/tls.crt, or
+	// /opt/metal3/certs/<name>/ca/tls.crt for a named endpoint.
+	// +optional
+	CACertFile string `json:"caCertFile,omitempty"`
+
+	// The path of the client certificate file used to authenticate
+	// with Ironic. Defaults to /opt/metal3/certs/client/tls.crt, or
+	// /opt/metal3/certs/<name>/client/tls.crt for a named endpoint.
+	// +optional
+	ClientCertFile string `json:"clientCertFile,omitempty"`
+
+	// The path of the private key file of the client certificate.
+	// Defaults to /opt/metal3/certs/client/tls.key, or
+	// /opt/metal3/certs/<name>/client/tls.key for a named endpoint.
+	// +optional
+	ClientPrivateKeyFile string `json:"clientPrivateKeyFile,omitempty"`
+
+	// Whether to skip the validation of the certificate of Ironic.
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+
+	// Whether to skip the validation of the SAN of the client
+	// certificate.
+	// +optional
+	SkipClientSANVerify bool `json:"skipClientSANVerify,omitempty"`
+}
+
+// IronicEndpointConfig is an Ironic deployment and the hosts it manages
+type IronicEndpointConfig struct {
+	// The name of the endpoint. The HTTP basic auth credentials of a
+	// named endpoint are read from the subdirectory of the same name
+	// of the auth directory. The endpoint without a name manages the
+	// hosts no named endpoint manages.
+	// +kubebuilder:validation:Pattern=`^([a-z0-9]([-a-z0-9]*[a-z0-9])?)?$`
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// The URL of Ironic.
+	// +kubebuilder:validation:MinLength=1
+	IronicURL string `json:"ironicURL"`
+
+	// The URL of Ironic Inspector.
+	// +kubebuilder:validation:MinLength=1
+	InspectorURL string `json:"inspectorURL"`
+
+	// The TLS configuration of the connection to the endpoint.
+	// +optional
+	TLS IronicTLSConfig `json:"tls,omitempty"`
+
+	// The namespaces of the hosts managed by a named endpoint.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// A label selector of the hosts managed by a named endpoint.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// The maximum number of hosts (de)provisioned simultaneously by
+	// the endpoint. Defaults to the provisioning limit of the spec.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ProvisioningLimit *int `json:"provisioningLimit,omitempty"`
+}
+
+// ProvisionerConfigSpec defines the configuration of the Ironic
+// provisioner
+type ProvisionerConfigSpec struct {
+	// The URL of the kernel to go with the deploy ramdisk.
+	// +optional
+	DeployKernelURL string `json:"deployKernelURL,omitempty"`
+
+	// The URL of the ramdisk of the image containing the Ironic
+	// agent.
+	// +optional
+	DeployRamdiskURL string `json:"deployRamdiskURL,omitempty"`
+
+	// The URL of the ISO containing the Ironic agent, for drivers
+	// that support ISO boot.
+	// +optional
+	DeployISOURL string `json:"deployISOURL,omitempty"`
+
+	// The maximum number of hosts (de)provisioned simultaneously by
+	// each endpoint. Defaults to 20.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ProvisioningLimit *int `json:"provisioningLimit,omitempty"`
+
+	// The Ironic endpoints. A host is managed by the first named
+	// endpoint whose namespaces or selector match it, and by the
+	// endpoint without a name otherwise.
+	// +kubebuilder:validation:MinItems=1
+	Endpoints []IronicEndpointConfig `json:"endpoints"`
+}
+
+// ProvisionerConfigStatus reports whether the configuration is used
+type ProvisionerConfigStatus struct {
+	// ObservedGeneration is the generation of the spec last loaded
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions describing the state of the configuration
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Valid",type="string",JSONPath=".status.conditions[?(@.type==\"Valid\")].status",description="Whether the configuration is used"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ProvisionerConfig is the Schema for the provisionerconfigs API
+type ProvisionerConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProvisionerConfigSpec   `json:"spec,omitempty"`
+	Status ProvisionerConfigStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ProvisionerConfigList contains a list of ProvisionerConfig
+type ProvisionerConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProvisionerConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ProvisionerConfig{}, &ProvisionerConfigList{})
+}
@@ -22,6 +22,7 @@ package v1alpha1
 
 import (
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -43,6 +44,11 @@ func (in *BIOS) DeepCopy() *BIOS {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BMCDetails) DeepCopyInto(out *BMCDetails) {
 	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(CABundleReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BMCDetails.
@@ -55,6 +61,121 @@ func (in *BMCDetails) DeepCopy() *BMCDetails {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BMCDiscovery) DeepCopyInto(out *BMCDiscovery) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BMCDiscovery.
+func (in *BMCDiscovery) DeepCopy() *BMCDiscovery {
+	if in == nil {
+		return nil
+	}
+	out := new(BMCDiscovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BMCDiscovery) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BMCDiscoveryList) DeepCopyInto(out *BMCDiscoveryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BMCDiscovery, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BMCDiscoveryList.
+func (in *BMCDiscoveryList) DeepCopy() *BMCDiscoveryList {
+	if in == nil {
+		return nil
+	}
+	out := new(BMCDiscoveryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BMCDiscoveryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BMCDiscoverySpec) DeepCopyInto(out *BMCDiscoverySpec) {
+	*out = *in
+	if in.CIDRs != nil {
+		in, out := &in.CIDRs, &out.CIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Protocols != nil {
+		in, out := &in.Protocols, &out.Protocols
+		*out = make([]DiscoveryProtocol, len(*in))
+		copy(*out, *in)
+	}
+	if in.RescanInterval != nil {
+		in, out := &in.RescanInterval, &out.RescanInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BMCDiscoverySpec.
+func (in *BMCDiscoverySpec) DeepCopy() *BMCDiscoverySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BMCDiscoverySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BMCDiscoveryStatus) DeepCopyInto(out *BMCDiscoveryStatus) {
+	*out = *in
+	if in.LastScanTime != nil {
+		in, out := &in.LastScanTime, &out.LastScanTime
+		*out = (*in).DeepCopy()
+	}
+	if in.BMCs != nil {
+		in, out := &in.BMCs, &out.BMCs
+		*out = make([]DiscoveredBMC, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BMCDiscoveryStatus.
+func (in *BMCDiscoveryStatus) DeepCopy() *BMCDiscoveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BMCDiscoveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BareMetalHost) DeepCopyInto(out *BareMetalHost) {
 	*out = *in
@@ -82,6 +203,100 @@ func (in *BareMetalHost) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BareMetalHostClaim) DeepCopyInto(out *BareMetalHostClaim) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BareMetalHostClaim.
+func (in *BareMetalHostClaim) DeepCopy() *BareMetalHostClaim {
+	if in == nil {
+		return nil
+	}
+	out := new(BareMetalHostClaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BareMetalHostClaim) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BareMetalHostClaimList) DeepCopyInto(out *BareMetalHostClaimList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BareMetalHostClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BareMetalHostClaimList.
+func (in *BareMetalHostClaimList) DeepCopy() *BareMetalHostClaimList {
+	if in == nil {
+		return nil
+	}
+	out := new(BareMetalHostClaimList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BareMetalHostClaimList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BareMetalHostClaimSpec) DeepCopyInto(out *BareMetalHostClaimSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BareMetalHostClaimSpec.
+func (in *BareMetalHostClaimSpec) DeepCopy() *BareMetalHostClaimSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BareMetalHostClaimSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BareMetalHostClaimStatus) DeepCopyInto(out *BareMetalHostClaimStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BareMetalHostClaimStatus.
+func (in *BareMetalHostClaimStatus) DeepCopy() *BareMetalHostClaimStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BareMetalHostClaimStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BareMetalHostList) DeepCopyInto(out *BareMetalHostList) {
 	*out = *in
@@ -124,7 +339,7 @@ func (in *BareMetalHostSpec) DeepCopyInto(out *BareMetalHostSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.BMC = in.BMC
+	in.BMC.DeepCopyInto(&out.BMC)
 	if in.RAID != nil {
 		in, out := &in.RAID, &out.RAID
 		*out = new(RAIDConfig)
@@ -135,6 +350,11 @@ func (in *BareMetalHostSpec) DeepCopyInto(out *BareMetalHostSpec) {
 		*out = new(FirmwareConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.FirmwareUpdates != nil {
+		in, out := &in.FirmwareUpdates, &out.FirmwareUpdates
+		*out = make([]FirmwareUpdate, len(*in))
+		copy(*out, *in)
+	}
 	if in.RootDeviceHints != nil {
 		in, out := &in.RootDeviceHints, &out.RootDeviceHints
 		*out = new(RootDeviceHints)
@@ -198,6 +418,18 @@ func (in *BareMetalHostStatus) DeepCopyInto(out *BareMetalHostStatus) {
 	in.GoodCredentials.DeepCopyInto(&out.GoodCredentials)
 	in.TriedCredentials.DeepCopyInto(&out.TriedCredentials)
 	in.OperationHistory.DeepCopyInto(&out.OperationHistory)
+	if in.CapacityQueue != nil {
+		in, out := &in.CapacityQueue, &out.CapacityQueue
+		*out = new(CapacityQueueStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BareMetalHostStatus.
@@ -210,6 +442,21 @@ func (in *BareMetalHostStatus) DeepCopy() *BareMetalHostStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CABundleReference) DeepCopyInto(out *CABundleReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CABundleReference.
+func (in *CABundleReference) DeepCopy() *CABundleReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CABundleReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CPU) DeepCopyInto(out *CPU) {
 	*out = *in
@@ -230,6 +477,105 @@ func (in *CPU) DeepCopy() *CPU {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityPool) DeepCopyInto(out *CapacityPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityPool.
+func (in *CapacityPool) DeepCopy() *CapacityPool {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CapacityPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityPoolList) DeepCopyInto(out *CapacityPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CapacityPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityPoolList.
+func (in *CapacityPoolList) DeepCopy() *CapacityPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CapacityPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityPoolSpec) DeepCopyInto(out *CapacityPoolSpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityPoolSpec.
+func (in *CapacityPoolSpec) DeepCopy() *CapacityPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityQueueStatus) DeepCopyInto(out *CapacityQueueStatus) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityQueueStatus.
+func (in *CapacityQueueStatus) DeepCopy() *CapacityQueueStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityQueueStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CredentialsStatus) DeepCopyInto(out *CredentialsStatus) {
 	*out = *in
@@ -266,92 +612,447 @@ func (in *CustomDeploy) DeepCopy() *CustomDeploy {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in DesiredSettingsMap) DeepCopyInto(out *DesiredSettingsMap) {
-	{
-		in := &in
-		*out = make(DesiredSettingsMap, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
+func (in DesiredSettingsMap) DeepCopyInto(out *DesiredSettingsMap) {
+	{
+		in := &in
+		*out = make(DesiredSettingsMap, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DesiredSettingsMap.
+func (in DesiredSettingsMap) DeepCopy() DesiredSettingsMap {
+	if in == nil {
+		return nil
+	}
+	out := new(DesiredSettingsMap)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoveredBMC) DeepCopyInto(out *DiscoveredBMC) {
+	*out = *in
+	if in.MACAddresses != nil {
+		in, out := &in.MACAddresses, &out.MACAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveredBMC.
+func (in *DiscoveredBMC) DeepCopy() *DiscoveredBMC {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoveredBMC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskMatch) DeepCopyInto(out *DiskMatch) {
+	*out = *in
+	if in.SizeGigabytes != nil {
+		in, out := &in.SizeGigabytes, &out.SizeGigabytes
+		*out = new(IntRange)
+		**out = **in
+	}
+	if in.Count != nil {
+		in, out := &in.Count, &out.Count
+		*out = new(IntRange)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskMatch.
+func (in *DiskMatch) DeepCopy() *DiskMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Firmware) DeepCopyInto(out *Firmware) {
+	*out = *in
+	out.BIOS = in.BIOS
+	if in.RAIDControllers != nil {
+		in, out := &in.RAIDControllers, &out.RAIDControllers
+		*out = make([]RAIDController, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Firmware.
+func (in *Firmware) DeepCopy() *Firmware {
+	if in == nil {
+		return nil
+	}
+	out := new(Firmware)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirmwareBaseline) DeepCopyInto(out *FirmwareBaseline) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareBaseline.
+func (in *FirmwareBaseline) DeepCopy() *FirmwareBaseline {
+	if in == nil {
+		return nil
+	}
+	out := new(FirmwareBaseline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FirmwareBaseline) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirmwareBaselineComponent) DeepCopyInto(out *FirmwareBaselineComponent) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareBaselineComponent.
+func (in *FirmwareBaselineComponent) DeepCopy() *FirmwareBaselineComponent {
+	if in == nil {
+		return nil
+	}
+	out := new(FirmwareBaselineComponent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirmwareBaselineList) DeepCopyInto(out *FirmwareBaselineList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FirmwareBaseline, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareBaselineList.
+func (in *FirmwareBaselineList) DeepCopy() *FirmwareBaselineList {
+	if in == nil {
+		return nil
+	}
+	out := new(FirmwareBaselineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FirmwareBaselineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirmwareBaselineSpec) DeepCopyInto(out *FirmwareBaselineSpec) {
+	*out = *in
+	if in.NICs != nil {
+		in, out := &in.NICs, &out.NICs
+		*out = make([]FirmwareBaselineComponent, len(*in))
+		copy(*out, *in)
+	}
+	if in.RAIDControllers != nil {
+		in, out := &in.RAIDControllers, &out.RAIDControllers
+		*out = make([]FirmwareBaselineComponent, len(*in))
+		copy(*out, *in)
+	}
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = make([]FirmwareBaselineComponent, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareBaselineSpec.
+func (in *FirmwareBaselineSpec) DeepCopy() *FirmwareBaselineSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FirmwareBaselineSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirmwareBaselineStatus) DeepCopyInto(out *FirmwareBaselineStatus) {
+	*out = *in
+	if in.OutOfBaselineHosts != nil {
+		in, out := &in.OutOfBaselineHosts, &out.OutOfBaselineHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareBaselineStatus.
+func (in *FirmwareBaselineStatus) DeepCopy() *FirmwareBaselineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FirmwareBaselineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirmwareConfig) DeepCopyInto(out *FirmwareConfig) {
+	*out = *in
+	if in.VirtualizationEnabled != nil {
+		in, out := &in.VirtualizationEnabled, &out.VirtualizationEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SimultaneousMultithreadingEnabled != nil {
+		in, out := &in.SimultaneousMultithreadingEnabled, &out.SimultaneousMultithreadingEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SriovEnabled != nil {
+		in, out := &in.SriovEnabled, &out.SriovEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BootOrder != nil {
+		in, out := &in.BootOrder, &out.BootOrder
+		*out = make([]BootDevice, len(*in))
+		copy(*out, *in)
+	}
+	if in.CStatesEnabled != nil {
+		in, out := &in.CStatesEnabled, &out.CStatesEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TurboBoostEnabled != nil {
+		in, out := &in.TurboBoostEnabled, &out.TurboBoostEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NUMAEnabled != nil {
+		in, out := &in.NUMAEnabled, &out.NUMAEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MemoryInterleavingEnabled != nil {
+		in, out := &in.MemoryInterleavingEnabled, &out.MemoryInterleavingEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HyperTransportAssistEnabled != nil {
+		in, out := &in.HyperTransportAssistEnabled, &out.HyperTransportAssistEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TPMEnabled != nil {
+		in, out := &in.TPMEnabled, &out.TPMEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PXE != nil {
+		in, out := &in.PXE, &out.PXE
+		*out = make([]NICPXEConfig, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareConfig.
+func (in *FirmwareConfig) DeepCopy() *FirmwareConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FirmwareConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirmwareSchema) DeepCopyInto(out *FirmwareSchema) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareSchema.
+func (in *FirmwareSchema) DeepCopy() *FirmwareSchema {
+	if in == nil {
+		return nil
+	}
+	out := new(FirmwareSchema)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FirmwareSchema) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirmwareSchemaList) DeepCopyInto(out *FirmwareSchemaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FirmwareSchema, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareSchemaList.
+func (in *FirmwareSchemaList) DeepCopy() *FirmwareSchemaList {
+	if in == nil {
+		return nil
+	}
+	out := new(FirmwareSchemaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FirmwareSchemaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirmwareSchemaSpec) DeepCopyInto(out *FirmwareSchemaSpec) {
+	*out = *in
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = make(map[string]SettingSchema, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareSchemaSpec.
+func (in *FirmwareSchemaSpec) DeepCopy() *FirmwareSchemaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FirmwareSchemaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirmwareUpdate) DeepCopyInto(out *FirmwareUpdate) {
+	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DesiredSettingsMap.
-func (in DesiredSettingsMap) DeepCopy() DesiredSettingsMap {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareUpdate.
+func (in *FirmwareUpdate) DeepCopy() *FirmwareUpdate {
 	if in == nil {
 		return nil
 	}
-	out := new(DesiredSettingsMap)
+	out := new(FirmwareUpdate)
 	in.DeepCopyInto(out)
-	return *out
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Firmware) DeepCopyInto(out *Firmware) {
+func (in *FirmwareUpdateStatus) DeepCopyInto(out *FirmwareUpdateStatus) {
 	*out = *in
-	out.BIOS = in.BIOS
+	out.FirmwareUpdate = in.FirmwareUpdate
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Firmware.
-func (in *Firmware) DeepCopy() *Firmware {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareUpdateStatus.
+func (in *FirmwareUpdateStatus) DeepCopy() *FirmwareUpdateStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Firmware)
+	out := new(FirmwareUpdateStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FirmwareConfig) DeepCopyInto(out *FirmwareConfig) {
+func (in *HardwareDetails) DeepCopyInto(out *HardwareDetails) {
 	*out = *in
-	if in.VirtualizationEnabled != nil {
-		in, out := &in.VirtualizationEnabled, &out.VirtualizationEnabled
-		*out = new(bool)
-		**out = **in
-	}
-	if in.SimultaneousMultithreadingEnabled != nil {
-		in, out := &in.SimultaneousMultithreadingEnabled, &out.SimultaneousMultithreadingEnabled
-		*out = new(bool)
-		**out = **in
+	out.SystemVendor = in.SystemVendor
+	in.Firmware.DeepCopyInto(&out.Firmware)
+	if in.NIC != nil {
+		in, out := &in.NIC, &out.NIC
+		*out = make([]NIC, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.SriovEnabled != nil {
-		in, out := &in.SriovEnabled, &out.SriovEnabled
-		*out = new(bool)
-		**out = **in
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = make([]Storage, len(*in))
+		copy(*out, *in)
 	}
+	in.CPU.DeepCopyInto(&out.CPU)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareConfig.
-func (in *FirmwareConfig) DeepCopy() *FirmwareConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareDetails.
+func (in *HardwareDetails) DeepCopy() *HardwareDetails {
 	if in == nil {
 		return nil
 	}
-	out := new(FirmwareConfig)
+	out := new(HardwareDetails)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FirmwareSchema) DeepCopyInto(out *FirmwareSchema) {
+func (in *HardwareProfile) DeepCopyInto(out *HardwareProfile) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareSchema.
-func (in *FirmwareSchema) DeepCopy() *FirmwareSchema {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareProfile.
+func (in *HardwareProfile) DeepCopy() *HardwareProfile {
 	if in == nil {
 		return nil
 	}
-	out := new(FirmwareSchema)
+	out := new(HardwareProfile)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *FirmwareSchema) DeepCopyObject() runtime.Object {
+func (in *HardwareProfile) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -359,31 +1060,31 @@ func (in *FirmwareSchema) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FirmwareSchemaList) DeepCopyInto(out *FirmwareSchemaList) {
+func (in *HardwareProfileList) DeepCopyInto(out *HardwareProfileList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]FirmwareSchema, len(*in))
+		*out = make([]HardwareProfile, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareSchemaList.
-func (in *FirmwareSchemaList) DeepCopy() *FirmwareSchemaList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareProfileList.
+func (in *HardwareProfileList) DeepCopy() *HardwareProfileList {
 	if in == nil {
 		return nil
 	}
-	out := new(FirmwareSchemaList)
+	out := new(HardwareProfileList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *FirmwareSchemaList) DeepCopyObject() runtime.Object {
+func (in *HardwareProfileList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -391,53 +1092,57 @@ func (in *FirmwareSchemaList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FirmwareSchemaSpec) DeepCopyInto(out *FirmwareSchemaSpec) {
+func (in *HardwareProfileMatch) DeepCopyInto(out *HardwareProfileMatch) {
 	*out = *in
-	if in.Schema != nil {
-		in, out := &in.Schema, &out.Schema
-		*out = make(map[string]SettingSchema, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
+	if in.CPUCount != nil {
+		in, out := &in.CPUCount, &out.CPUCount
+		*out = new(IntRange)
+		**out = **in
+	}
+	if in.RAMMebibytes != nil {
+		in, out := &in.RAMMebibytes, &out.RAMMebibytes
+		*out = new(IntRange)
+		**out = **in
+	}
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = new(DiskMatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NICCount != nil {
+		in, out := &in.NICCount, &out.NICCount
+		*out = new(IntRange)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareSchemaSpec.
-func (in *FirmwareSchemaSpec) DeepCopy() *FirmwareSchemaSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareProfileMatch.
+func (in *HardwareProfileMatch) DeepCopy() *HardwareProfileMatch {
 	if in == nil {
 		return nil
 	}
-	out := new(FirmwareSchemaSpec)
+	out := new(HardwareProfileMatch)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HardwareDetails) DeepCopyInto(out *HardwareDetails) {
+func (in *HardwareProfileSpec) DeepCopyInto(out *HardwareProfileSpec) {
 	*out = *in
-	out.SystemVendor = in.SystemVendor
-	out.Firmware = in.Firmware
-	if in.NIC != nil {
-		in, out := &in.NIC, &out.NIC
-		*out = make([]NIC, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Storage != nil {
-		in, out := &in.Storage, &out.Storage
-		*out = make([]Storage, len(*in))
-		copy(*out, *in)
+	in.Match.DeepCopyInto(&out.Match)
+	if in.RootDeviceHints != nil {
+		in, out := &in.RootDeviceHints, &out.RootDeviceHints
+		*out = new(RootDeviceHints)
+		(*in).DeepCopyInto(*out)
 	}
-	in.CPU.DeepCopyInto(&out.CPU)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareDetails.
-func (in *HardwareDetails) DeepCopy() *HardwareDetails {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareProfileSpec.
+func (in *HardwareProfileSpec) DeepCopy() *HardwareProfileSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(HardwareDetails)
+	out := new(HardwareProfileSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -583,6 +1288,20 @@ func (in *HostFirmwareSettingsStatus) DeepCopyInto(out *HostFirmwareSettingsStat
 			(*out)[key] = val
 		}
 	}
+	if in.AppliedSettings != nil {
+		in, out := &in.AppliedSettings, &out.AppliedSettings
+		*out = make(SettingsMap, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostFirmwareSettingsStatus.
@@ -615,6 +1334,67 @@ func (in *Image) DeepCopy() *Image {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntRange) DeepCopyInto(out *IntRange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntRange.
+func (in *IntRange) DeepCopy() *IntRange {
+	if in == nil {
+		return nil
+	}
+	out := new(IntRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IronicEndpointConfig) DeepCopyInto(out *IronicEndpointConfig) {
+	*out = *in
+	out.TLS = in.TLS
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProvisioningLimit != nil {
+		in, out := &in.ProvisioningLimit, &out.ProvisioningLimit
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IronicEndpointConfig.
+func (in *IronicEndpointConfig) DeepCopy() *IronicEndpointConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(IronicEndpointConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IronicTLSConfig) DeepCopyInto(out *IronicTLSConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IronicTLSConfig.
+func (in *IronicTLSConfig) DeepCopy() *IronicTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(IronicTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NIC) DeepCopyInto(out *NIC) {
 	*out = *in
@@ -635,6 +1415,21 @@ func (in *NIC) DeepCopy() *NIC {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NICPXEConfig) DeepCopyInto(out *NICPXEConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NICPXEConfig.
+func (in *NICPXEConfig) DeepCopy() *NICPXEConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NICPXEConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OperationHistory) DeepCopyInto(out *OperationHistory) {
 	*out = *in
@@ -690,6 +1485,11 @@ func (in *ProvisionStatus) DeepCopyInto(out *ProvisionStatus) {
 		*out = new(FirmwareConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.FirmwareUpdates != nil {
+		in, out := &in.FirmwareUpdates, &out.FirmwareUpdates
+		*out = make([]FirmwareUpdateStatus, len(*in))
+		copy(*out, *in)
+	}
 	if in.CustomDeploy != nil {
 		in, out := &in.CustomDeploy, &out.CustomDeploy
 		*out = new(CustomDeploy)
@@ -707,6 +1507,114 @@ func (in *ProvisionStatus) DeepCopy() *ProvisionStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisionerConfig) DeepCopyInto(out *ProvisionerConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisionerConfig.
+func (in *ProvisionerConfig) DeepCopy() *ProvisionerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisionerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProvisionerConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisionerConfigList) DeepCopyInto(out *ProvisionerConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ProvisionerConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisionerConfigList.
+func (in *ProvisionerConfigList) DeepCopy() *ProvisionerConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisionerConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProvisionerConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisionerConfigSpec) DeepCopyInto(out *ProvisionerConfigSpec) {
+	*out = *in
+	if in.ProvisioningLimit != nil {
+		in, out := &in.ProvisioningLimit, &out.ProvisioningLimit
+		*out = new(int)
+		**out = **in
+	}
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]IronicEndpointConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisionerConfigSpec.
+func (in *ProvisionerConfigSpec) DeepCopy() *ProvisionerConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisionerConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisionerConfigStatus) DeepCopyInto(out *ProvisionerConfigStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisionerConfigStatus.
+func (in *ProvisionerConfigStatus) DeepCopy() *ProvisionerConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisionerConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RAIDConfig) DeepCopyInto(out *RAIDConfig) {
 	*out = *in
@@ -736,6 +1644,21 @@ func (in *RAIDConfig) DeepCopy() *RAIDConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RAIDController) DeepCopyInto(out *RAIDController) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RAIDController.
+func (in *RAIDController) DeepCopy() *RAIDController {
+	if in == nil {
+		return nil
+	}
+	out := new(RAIDController)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RebootAnnotationArguments) DeepCopyInto(out *RebootAnnotationArguments) {
 	*out = *in
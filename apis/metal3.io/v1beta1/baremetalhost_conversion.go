@@ -0,0 +1,579 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+// errorTypesToHub maps the v1beta1 error types to the free-form
+// strings used by v1alpha1. Values that are not in the map are
+// passed through unchanged in both directions.
+var errorTypesToHub = map[ErrorType]v1alpha1.ErrorType{
+	ProvisionedRegistrationError: v1alpha1.ProvisionedRegistrationError,
+	RegistrationError:            v1alpha1.RegistrationError,
+	InspectionError:              v1alpha1.InspectionError,
+	PreparationError:             v1alpha1.PreparationError,
+	ProvisioningError:            v1alpha1.ProvisioningError,
+	PowerManagementError:         v1alpha1.PowerManagementError,
+	DetachError:                  v1alpha1.DetachError,
+}
+
+var errorTypesFromHub = map[v1alpha1.ErrorType]ErrorType{}
+
+func init() {
+	for beta, alpha := range errorTypesToHub {
+		errorTypesFromHub[alpha] = beta
+	}
+}
+
+// ConvertTo converts this BareMetalHost to the hub version (v1alpha1).
+func (src *BareMetalHost) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.BareMetalHost)
+	in := src.DeepCopy()
+
+	dst.ObjectMeta = in.ObjectMeta
+	convertSpecToHub(&in.Spec, &dst.Spec)
+	convertStatusToHub(&in.Status, &dst.Status)
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version.
+func (dst *BareMetalHost) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.BareMetalHost)
+	in := src.DeepCopy()
+
+	dst.ObjectMeta = in.ObjectMeta
+	convertSpecFromHub(&in.Spec, &dst.Spec)
+	convertStatusFromHub(&in.Status, &dst.Status)
+	return nil
+}
+
+func convertSpecToHub(in *BareMetalHostSpec, out *v1alpha1.BareMetalHostSpec) {
+	out.Taints = in.Taints
+	out.BMC = convertBMCToHub(in.BMC)
+	out.RAID = convertRAIDToHub(in.RAID)
+	out.Firmware = convertFirmwareToHub(in.Firmware)
+	out.FirmwareUpdates = convertFirmwareUpdatesToHub(in.FirmwareUpdates)
+	out.HardwareProfile = in.HardwareProfile
+	out.RootDeviceHints = (*v1alpha1.RootDeviceHints)(in.RootDeviceHints)
+	out.BootMode = v1alpha1.BootMode(in.BootMode)
+	out.BootMACAddress = in.BootMACAddress
+	out.Online = in.Online
+	out.ConsumerRef = in.ConsumerRef
+	if in.Image != nil {
+		image := convertImageToHub(*in.Image)
+		out.Image = &image
+	}
+	out.UserData = in.UserData
+	out.NetworkData = in.NetworkData
+	out.MetaData = in.MetaData
+	out.Description = in.Description
+	out.ExternallyProvisioned = in.ExternallyProvisioned
+	out.AutomatedCleaningMode = v1alpha1.AutomatedCleaningMode(in.AutomatedCleaningMode)
+	out.CustomDeploy = (*v1alpha1.CustomDeploy)(in.CustomDeploy)
+}
+
+func convertSpecFromHub(in *v1alpha1.BareMetalHostSpec, out *BareMetalHostSpec) {
+	out.Taints = in.Taints
+	out.BMC = convertBMCFromHub(in.BMC)
+	out.RAID = convertRAIDFromHub(in.RAID)
+	out.Firmware = convertFirmwareFromHub(in.Firmware)
+	out.FirmwareUpdates = convertFirmwareUpdatesFromHub(in.FirmwareUpdates)
+	out.HardwareProfile = in.HardwareProfile
+	out.RootDeviceHints = (*RootDeviceHints)(in.RootDeviceHints)
+	out.BootMode = BootMode(in.BootMode)
+	out.BootMACAddress = in.BootMACAddress
+	out.Online = in.Online
+	out.ConsumerRef = in.ConsumerRef
+	if in.Image != nil {
+		image := convertImageFromHub(*in.Image)
+		out.Image = &image
+	}
+	out.UserData = in.UserData
+	out.NetworkData = in.NetworkData
+	out.MetaData = in.MetaData
+	out.Description = in.Description
+	out.ExternallyProvisioned = in.ExternallyProvisioned
+	out.AutomatedCleaningMode = AutomatedCleaningMode(in.AutomatedCleaningMode)
+	out.CustomDeploy = (*CustomDeploy)(in.CustomDeploy)
+}
+
+func convertStatusToHub(in *BareMetalHostStatus, out *v1alpha1.BareMetalHostStatus) {
+	out.OperationalStatus = v1alpha1.OperationalStatus(in.OperationalStatus)
+	out.ErrorType = v1alpha1.ErrorType(in.ErrorType)
+	if errorType, ok := errorTypesToHub[in.ErrorType]; ok {
+		out.ErrorType = errorType
+	}
+	out.LastUpdated = in.LastUpdated
+	out.HardwareProfile = in.HardwareProfile
+	if in.HardwareDetails != nil {
+		details := convertHardwareDetailsToHub(*in.HardwareDetails)
+		out.HardwareDetails = &details
+	}
+	out.Provisioning = convertProvisionStatusToHub(in.Provisioning)
+	out.GoodCredentials = v1alpha1.CredentialsStatus(in.GoodCredentials)
+	out.TriedCredentials = v1alpha1.CredentialsStatus(in.TriedCredentials)
+	out.BMCCertificateFingerprint = in.BMCCertificateFingerprint
+	out.ErrorMessage = in.ErrorMessage
+	out.PoweredOn = in.PoweredOn
+	out.OperationHistory = v1alpha1.OperationHistory{
+		Register:    v1alpha1.OperationMetric(in.OperationHistory.Register),
+		Inspect:     v1alpha1.OperationMetric(in.OperationHistory.Inspect),
+		Provision:   v1alpha1.OperationMetric(in.OperationHistory.Provision),
+		Deprovision: v1alpha1.OperationMetric(in.OperationHistory.Deprovision),
+	}
+	out.ErrorCount = in.ErrorCount
+	if in.CapacityQueue != nil {
+		out.CapacityQueue = &v1alpha1.CapacityQueueStatus{
+			Pool:     in.CapacityQueue.Pool,
+			Position: in.CapacityQueue.Position,
+			Since:    in.CapacityQueue.Since,
+		}
+	}
+	out.Conditions = in.Conditions
+}
+
+func convertStatusFromHub(in *v1alpha1.BareMetalHostStatus, out *BareMetalHostStatus) {
+	out.OperationalStatus = OperationalStatus(in.OperationalStatus)
+	out.ErrorType = ErrorType(in.ErrorType)
+	if errorType, ok := errorTypesFromHub[in.ErrorType]; ok {
+		out.ErrorType = errorType
+	}
+	out.LastUpdated = in.LastUpdated
+	out.HardwareProfile = in.HardwareProfile
+	if in.HardwareDetails != nil {
+		details := convertHardwareDetailsFromHub(*in.HardwareDetails)
+		out.HardwareDetails = &details
+	}
+	out.Provisioning = convertProvisionStatusFromHub(in.Provisioning)
+	out.GoodCredentials = CredentialsStatus(in.GoodCredentials)
+	out.TriedCredentials = CredentialsStatus(in.TriedCredentials)
+	out.BMCCertificateFingerprint = in.BMCCertificateFingerprint
+	out.ErrorMessage = in.ErrorMessage
+	out.PoweredOn = in.PoweredOn
+	out.OperationHistory = OperationHistory{
+		Register:    OperationMetric(in.OperationHistory.Register),
+		Inspect:     OperationMetric(in.OperationHistory.Inspect),
+		Provision:   OperationMetric(in.OperationHistory.Provision),
+		Deprovision: OperationMetric(in.OperationHistory.Deprovision),
+	}
+	out.ErrorCount = in.ErrorCount
+	if in.CapacityQueue != nil {
+		out.CapacityQueue = &CapacityQueueStatus{
+			Pool:     in.CapacityQueue.Pool,
+			Position: in.CapacityQueue.Position,
+			Since:    in.CapacityQueue.Since,
+		}
+	}
+	out.Conditions = in.Conditions
+}
+
+func convertBMCToHub(in BMCDetails) v1alpha1.BMCDetails {
+	out := v1alpha1.BMCDetails{
+		Address:                        in.Address,
+		CredentialsName:                in.CredentialsName,
+		CredentialsProvider:            v1alpha1.CredentialsProviderType(in.CredentialsProvider),
+		DisableCertificateVerification: in.DisableCertificateVerification,
+	}
+	if in.CABundle != nil {
+		out.CABundle = &v1alpha1.CABundleReference{
+			Kind: v1alpha1.CABundleKind(in.CABundle.Kind),
+			Name: in.CABundle.Name,
+			Key:  in.CABundle.Key,
+		}
+	}
+	return out
+}
+
+func convertBMCFromHub(in v1alpha1.BMCDetails) BMCDetails {
+	out := BMCDetails{
+		Address:                        in.Address,
+		CredentialsName:                in.CredentialsName,
+		CredentialsProvider:            CredentialsProviderType(in.CredentialsProvider),
+		DisableCertificateVerification: in.DisableCertificateVerification,
+	}
+	if in.CABundle != nil {
+		out.CABundle = &CABundleReference{
+			Kind: CABundleKind(in.CABundle.Kind),
+			Name: in.CABundle.Name,
+			Key:  in.CABundle.Key,
+		}
+	}
+	return out
+}
+
+func convertProvisionStatusToHub(in ProvisionStatus) v1alpha1.ProvisionStatus {
+	out := v1alpha1.ProvisionStatus{
+		State:           v1alpha1.ProvisioningState(in.State),
+		ID:              in.ID,
+		Image:           convertImageToHub(in.Image),
+		RootDeviceHints: (*v1alpha1.RootDeviceHints)(in.RootDeviceHints),
+		BootMode:        v1alpha1.BootMode(in.BootMode),
+		RAID:            convertRAIDToHub(in.RAID),
+		Firmware:        convertFirmwareToHub(in.Firmware),
+		CustomDeploy:    (*v1alpha1.CustomDeploy)(in.CustomDeploy),
+	}
+	for _, update := range in.FirmwareUpdates {
+		out.FirmwareUpdates = append(out.FirmwareUpdates, v1alpha1.FirmwareUpdateStatus{
+			FirmwareUpdate:  convertFirmwareUpdateToHub(update.FirmwareUpdate),
+			PreviousVersion: update.PreviousVersion,
+			CurrentVersion:  update.CurrentVersion,
+		})
+	}
+	return out
+}
+
+func convertProvisionStatusFromHub(in v1alpha1.ProvisionStatus) ProvisionStatus {
+	out := ProvisionStatus{
+		State:           ProvisioningState(in.State),
+		ID:              in.ID,
+		Image:           convertImageFromHub(in.Image),
+		RootDeviceHints: (*RootDeviceHints)(in.RootDeviceHints),
+		BootMode:        BootMode(in.BootMode),
+		RAID:            convertRAIDFromHub(in.RAID),
+		Firmware:        convertFirmwareFromHub(in.Firmware),
+		CustomDeploy:    (*CustomDeploy)(in.CustomDeploy),
+	}
+	for _, update := range in.FirmwareUpdates {
+		out.FirmwareUpdates = append(out.FirmwareUpdates, FirmwareUpdateStatus{
+			FirmwareUpdate:  convertFirmwareUpdateFromHub(update.FirmwareUpdate),
+			PreviousVersion: update.PreviousVersion,
+			CurrentVersion:  update.CurrentVersion,
+		})
+	}
+	return out
+}
+
+func convertFirmwareUpdateToHub(in FirmwareUpdate) v1alpha1.FirmwareUpdate {
+	return v1alpha1.FirmwareUpdate{
+		Component: v1alpha1.FirmwareComponent(in.Component),
+		URL:       in.URL,
+		Checksum:  in.Checksum,
+		Version:   in.Version,
+	}
+}
+
+func convertFirmwareUpdateFromHub(in v1alpha1.FirmwareUpdate) FirmwareUpdate {
+	return FirmwareUpdate{
+		Component: FirmwareComponent(in.Component),
+		URL:       in.URL,
+		Checksum:  in.Checksum,
+		Version:   in.Version,
+	}
+}
+
+func convertFirmwareUpdatesToHub(in []FirmwareUpdate) []v1alpha1.FirmwareUpdate {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1alpha1.FirmwareUpdate, 0, len(in))
+	for _, update := range in {
+		out = append(out, convertFirmwareUpdateToHub(update))
+	}
+	return out
+}
+
+func convertFirmwareUpdatesFromHub(in []v1alpha1.FirmwareUpdate) []FirmwareUpdate {
+	if in == nil {
+		return nil
+	}
+	out := make([]FirmwareUpdate, 0, len(in))
+	for _, update := range in {
+		out = append(out, convertFirmwareUpdateFromHub(update))
+	}
+	return out
+}
+
+func convertImageToHub(in Image) v1alpha1.Image {
+	return v1alpha1.Image{
+		URL:          in.URL,
+		Checksum:     in.Checksum,
+		ChecksumType: v1alpha1.ChecksumType(in.ChecksumType),
+		DiskFormat:   in.DiskFormat,
+	}
+}
+
+func convertImageFromHub(in v1alpha1.Image) Image {
+	return Image{
+		URL:          in.URL,
+		Checksum:     in.Checksum,
+		ChecksumType: ChecksumType(in.ChecksumType),
+		DiskFormat:   in.DiskFormat,
+	}
+}
+
+func convertFirmwareToHub(in *FirmwareConfig) *v1alpha1.FirmwareConfig {
+	if in == nil {
+		return nil
+	}
+	out := &v1alpha1.FirmwareConfig{
+		VirtualizationEnabled:             in.VirtualizationEnabled,
+		SimultaneousMultithreadingEnabled: in.SimultaneousMultithreadingEnabled,
+		SriovEnabled:                      in.SriovEnabled,
+		PowerProfile:                      v1alpha1.PowerProfile(in.PowerProfile),
+		CStatesEnabled:                    in.CStatesEnabled,
+		CStateLimit:                       v1alpha1.CStateLimit(in.CStateLimit),
+		TurboBoostEnabled:                 in.TurboBoostEnabled,
+		NUMAEnabled:                       in.NUMAEnabled,
+		MemoryInterleavingEnabled:         in.MemoryInterleavingEnabled,
+		HyperTransportAssistEnabled:       in.HyperTransportAssistEnabled,
+		TPMEnabled:                        in.TPMEnabled,
+		SecureBootKeys:                    v1alpha1.SecureBootKeys(in.SecureBootKeys),
+	}
+	if in.BootOrder != nil {
+		out.BootOrder = make([]v1alpha1.BootDevice, len(in.BootOrder))
+		for i, device := range in.BootOrder {
+			out.BootOrder[i] = v1alpha1.BootDevice(device)
+		}
+	}
+	if in.PXE != nil {
+		out.PXE = make([]v1alpha1.NICPXEConfig, len(in.PXE))
+		for i, nic := range in.PXE {
+			out.PXE[i] = v1alpha1.NICPXEConfig(nic)
+		}
+	}
+	return out
+}
+
+func convertFirmwareFromHub(in *v1alpha1.FirmwareConfig) *FirmwareConfig {
+	if in == nil {
+		return nil
+	}
+	out := &FirmwareConfig{
+		VirtualizationEnabled:             in.VirtualizationEnabled,
+		SimultaneousMultithreadingEnabled: in.SimultaneousMultithreadingEnabled,
+		SriovEnabled:                      in.SriovEnabled,
+		PowerProfile:                      PowerProfile(in.PowerProfile),
+		CStatesEnabled:                    in.CStatesEnabled,
+		CStateLimit:                       CStateLimit(in.CStateLimit),
+		TurboBoostEnabled:                 in.TurboBoostEnabled,
+		NUMAEnabled:                       in.NUMAEnabled,
+		MemoryInterleavingEnabled:         in.MemoryInterleavingEnabled,
+		HyperTransportAssistEnabled:       in.HyperTransportAssistEnabled,
+		TPMEnabled:                        in.TPMEnabled,
+		SecureBootKeys:                    SecureBootKeys(in.SecureBootKeys),
+	}
+	if in.BootOrder != nil {
+		out.BootOrder = make([]BootDevice, len(in.BootOrder))
+		for i, device := range in.BootOrder {
+			out.BootOrder[i] = BootDevice(device)
+		}
+	}
+	if in.PXE != nil {
+		out.PXE = make([]NICPXEConfig, len(in.PXE))
+		for i, nic := range in.PXE {
+			out.PXE[i] = NICPXEConfig(nic)
+		}
+	}
+	return out
+}
+
+func convertRAIDToHub(in *RAIDConfig) *v1alpha1.RAIDConfig {
+	if in == nil {
+		return nil
+	}
+	out := &v1alpha1.RAIDConfig{}
+	if in.HardwareRAIDVolumes != nil {
+		out.HardwareRAIDVolumes = make([]v1alpha1.HardwareRAIDVolume, len(in.HardwareRAIDVolumes))
+		for i, volume := range in.HardwareRAIDVolumes {
+			out.HardwareRAIDVolumes[i] = v1alpha1.HardwareRAIDVolume(volume)
+		}
+	}
+	if in.SoftwareRAIDVolumes != nil {
+		out.SoftwareRAIDVolumes = make([]v1alpha1.SoftwareRAIDVolume, len(in.SoftwareRAIDVolumes))
+		for i, volume := range in.SoftwareRAIDVolumes {
+			out.SoftwareRAIDVolumes[i] = v1alpha1.SoftwareRAIDVolume{
+				SizeGibibytes: volume.SizeGibibytes,
+				Level:         volume.Level,
+			}
+			if volume.PhysicalDisks != nil {
+				disks := make([]v1alpha1.RootDeviceHints, len(volume.PhysicalDisks))
+				for j, disk := range volume.PhysicalDisks {
+					disks[j] = v1alpha1.RootDeviceHints(disk)
+				}
+				out.SoftwareRAIDVolumes[i].PhysicalDisks = disks
+			}
+		}
+	}
+	return out
+}
+
+func convertRAIDFromHub(in *v1alpha1.RAIDConfig) *RAIDConfig {
+	if in == nil {
+		return nil
+	}
+	out := &RAIDConfig{}
+	if in.HardwareRAIDVolumes != nil {
+		out.HardwareRAIDVolumes = make([]HardwareRAIDVolume, len(in.HardwareRAIDVolumes))
+		for i, volume := range in.HardwareRAIDVolumes {
+			out.HardwareRAIDVolumes[i] = HardwareRAIDVolume(volume)
+		}
+	}
+	if in.SoftwareRAIDVolumes != nil {
+		out.SoftwareRAIDVolumes = make([]SoftwareRAIDVolume, len(in.SoftwareRAIDVolumes))
+		for i, volume := range in.SoftwareRAIDVolumes {
+			out.SoftwareRAIDVolumes[i] = SoftwareRAIDVolume{
+				SizeGibibytes: volume.SizeGibibytes,
+				Level:         volume.Level,
+			}
+			if volume.PhysicalDisks != nil {
+				disks := make([]RootDeviceHints, len(volume.PhysicalDisks))
+				for j, disk := range volume.PhysicalDisks {
+					disks[j] = RootDeviceHints(disk)
+				}
+				out.SoftwareRAIDVolumes[i].PhysicalDisks = disks
+			}
+		}
+	}
+	return out
+}
+
+func convertHardwareDetailsToHub(in HardwareDetails) v1alpha1.HardwareDetails {
+	out := v1alpha1.HardwareDetails{
+		SystemVendor: v1alpha1.HardwareSystemVendor(in.SystemVendor),
+		Firmware: v1alpha1.Firmware{
+			BIOS:       v1alpha1.BIOS(in.Firmware.BIOS),
+			BMCVersion: in.Firmware.BMCVersion,
+		},
+		RAMMebibytes: in.RAMMebibytes,
+		CPU: v1alpha1.CPU{
+			Arch:           in.CPU.Arch,
+			Model:          in.CPU.Model,
+			ClockMegahertz: v1alpha1.ClockSpeed(in.CPU.ClockMegahertz),
+			Flags:          in.CPU.Flags,
+			Count:          in.CPU.Count,
+		},
+		Hostname: in.Hostname,
+	}
+	if in.NIC != nil {
+		out.NIC = make([]v1alpha1.NIC, len(in.NIC))
+		for i, nic := range in.NIC {
+			out.NIC[i] = v1alpha1.NIC{
+				Name:            nic.Name,
+				Model:           nic.Model,
+				MAC:             nic.MAC,
+				IP:              nic.IP,
+				SpeedGbps:       nic.SpeedGbps,
+				VLANID:          v1alpha1.VLANID(nic.VLANID),
+				PXE:             nic.PXE,
+				FirmwareVersion: nic.FirmwareVersion,
+			}
+			if nic.VLANs != nil {
+				vlans := make([]v1alpha1.VLAN, len(nic.VLANs))
+				for j, vlan := range nic.VLANs {
+					vlans[j] = v1alpha1.VLAN{ID: v1alpha1.VLANID(vlan.ID), Name: vlan.Name}
+				}
+				out.NIC[i].VLANs = vlans
+			}
+		}
+	}
+	if in.Storage != nil {
+		out.Storage = make([]v1alpha1.Storage, len(in.Storage))
+		for i, disk := range in.Storage {
+			out.Storage[i] = v1alpha1.Storage{
+				Name:               disk.Name,
+				Rotational:         disk.Rotational,
+				Type:               v1alpha1.DiskType(disk.Type),
+				SizeBytes:          v1alpha1.Capacity(disk.SizeBytes),
+				Vendor:             disk.Vendor,
+				Model:              disk.Model,
+				SerialNumber:       disk.SerialNumber,
+				WWN:                disk.WWN,
+				WWNVendorExtension: disk.WWNVendorExtension,
+				WWNWithExtension:   disk.WWNWithExtension,
+				HCTL:               disk.HCTL,
+				FirmwareVersion:    disk.FirmwareVersion,
+			}
+		}
+	}
+	if in.Firmware.RAIDControllers != nil {
+		out.Firmware.RAIDControllers = make([]v1alpha1.RAIDController, len(in.Firmware.RAIDControllers))
+		for i, controller := range in.Firmware.RAIDControllers {
+			out.Firmware.RAIDControllers[i] = v1alpha1.RAIDController(controller)
+		}
+	}
+	return out
+}
+
+func convertHardwareDetailsFromHub(in v1alpha1.HardwareDetails) HardwareDetails {
+	out := HardwareDetails{
+		SystemVendor: HardwareSystemVendor(in.SystemVendor),
+		Firmware: Firmware{
+			BIOS:       BIOS(in.Firmware.BIOS),
+			BMCVersion: in.Firmware.BMCVersion,
+		},
+		RAMMebibytes: in.RAMMebibytes,
+		CPU: CPU{
+			Arch:           in.CPU.Arch,
+			Model:          in.CPU.Model,
+			ClockMegahertz: ClockSpeed(in.CPU.ClockMegahertz),
+			Flags:          in.CPU.Flags,
+			Count:          in.CPU.Count,
+		},
+		Hostname: in.Hostname,
+	}
+	if in.NIC != nil {
+		out.NIC = make([]NIC, len(in.NIC))
+		for i, nic := range in.NIC {
+			out.NIC[i] = NIC{
+				Name:            nic.Name,
+				Model:           nic.Model,
+				MAC:             nic.MAC,
+				IP:              nic.IP,
+				SpeedGbps:       nic.SpeedGbps,
+				VLANID:          VLANID(nic.VLANID),
+				PXE:             nic.PXE,
+				FirmwareVersion: nic.FirmwareVersion,
+			}
+			if nic.VLANs != nil {
+				vlans := make([]VLAN, len(nic.VLANs))
+				for j, vlan := range nic.VLANs {
+					vlans[j] = VLAN{ID: VLANID(vlan.ID), Name: vlan.Name}
+				}
+				out.NIC[i].VLANs = vlans
+			}
+		}
+	}
+	if in.Storage != nil {
+		out.Storage = make([]Storage, len(in.Storage))
+		for i, disk := range in.Storage {
+			out.Storage[i] = Storage{
+				Name:               disk.Name,
+				Rotational:         disk.Rotational,
+				Type:               DiskType(disk.Type),
+				SizeBytes:          Capacity(disk.SizeBytes),
+				Vendor:             disk.Vendor,
+				Model:              disk.Model,
+				SerialNumber:       disk.SerialNumber,
+				WWN:                disk.WWN,
+				WWNVendorExtension: disk.WWNVendorExtension,
+				WWNWithExtension:   disk.WWNWithExtension,
+				HCTL:               disk.HCTL,
+				FirmwareVersion:    disk.FirmwareVersion,
+			}
+		}
+	}
+	if in.Firmware.RAIDControllers != nil {
+		out.Firmware.RAIDControllers = make([]RAIDController, len(in.Firmware.RAIDControllers))
+		for i, controller := range in.Firmware.RAIDControllers {
+			out.Firmware.RAIDControllers[i] = RAIDController(controller)
+		}
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package v1beta1
+
+import (
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"github.com/stretchr/testify/assert"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+
+	"github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+func TestConvertErrorType(t *testing.T) {
+	for _, tc := range []struct {
+		Alpha v1alpha1.ErrorType
+		Beta  ErrorType
+	}{
+		{Alpha: "", Beta: ""},
+		{Alpha: v1alpha1.ProvisionedRegistrationError, Beta: ProvisionedRegistrationError},
+		{Alpha: v1alpha1.RegistrationError, Beta: RegistrationError},
+		{Alpha: v1alpha1.InspectionError, Beta: InspectionError},
+		{Alpha: v1alpha1.PreparationError, Beta: PreparationError},
+		{Alpha: v1alpha1.ProvisioningError, Beta: ProvisioningError},
+		{Alpha: v1alpha1.PowerManagementError, Beta: PowerManagementError},
+		{Alpha: v1alpha1.DetachError, Beta: DetachError},
+	} {
+		t.Run(string(tc.Beta), func(t *testing.T) {
+			alpha := &v1alpha1.BareMetalHost{}
+			beta := &BareMetalHost{Status: BareMetalHostStatus{ErrorType: tc.Beta}}
+			assert.NoError(t, beta.ConvertTo(alpha))
+			assert.Equal(t, tc.Alpha, alpha.Status.ErrorType)
+
+			beta = &BareMetalHost{}
+			assert.NoError(t, beta.ConvertFrom(alpha))
+			assert.Equal(t, tc.Beta, beta.Status.ErrorType)
+		})
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	f := fuzz.New().NilChance(0.2).NumElements(0, 3)
+
+	for i := 0; i < 100; i++ {
+		beta := &BareMetalHost{}
+		f.Fuzz(beta)
+		beta.TypeMeta = metav1.TypeMeta{}
+
+		alpha := &v1alpha1.BareMetalHost{}
+		assert.NoError(t, beta.ConvertTo(alpha))
+		restored := &BareMetalHost{}
+		assert.NoError(t, restored.ConvertFrom(alpha))
+		if !apiequality.Semantic.DeepEqual(beta, restored) {
+			t.Fatalf("v1beta1 round trip changed the host: %s", diff.ObjectReflectDiff(beta, restored))
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		alpha := &v1alpha1.BareMetalHost{}
+		f.Fuzz(alpha)
+		alpha.TypeMeta = metav1.TypeMeta{}
+
+		beta := &BareMetalHost{}
+		assert.NoError(t, beta.ConvertFrom(alpha))
+		restored := &v1alpha1.BareMetalHost{}
+		assert.NoError(t, beta.ConvertTo(restored))
+		if !apiequality.Semantic.DeepEqual(alpha, restored) {
+			t.Fatalf("v1alpha1 round trip changed the host: %s", diff.ObjectReflectDiff(alpha, restored))
+		}
+	}
+}
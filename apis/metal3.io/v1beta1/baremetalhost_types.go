@@ -0,0 +1,1085 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have
+// json tags for the fields to be serialized.
+
+// NOTE(dhellmann): Update docs/api.md when changing these data structure.
+
+const (
+	// BareMetalHostFinalizer is the name of the finalizer added to
+	// hosts to block delete operations until the physical host can be
+	// deprovisioned.
+	BareMetalHostFinalizer string = "baremetalhost.metal3.io"
+
+	// PausedAnnotation is the annotation that pauses the reconciliation (triggers
+	// an immediate requeue)
+	PausedAnnotation = "baremetalhost.metal3.io/paused"
+
+	// DetachedAnnotation is the annotation which stops provisioner management of the host
+	// unlike in the paused case, the host status may be updated
+	DetachedAnnotation = "baremetalhost.metal3.io/detached"
+
+	// RotateCredentialsAnnotation is the annotation that requests a new
+	// password to be set on the BMC account of the host and stored in
+	// its credentials secret
+	RotateCredentialsAnnotation = "baremetalhost.metal3.io/rotate-credentials"
+)
+
+// RootDeviceHints holds the hints for specifying the storage location
+// for the root filesystem for the image.
+type RootDeviceHints struct {
+	// A Linux device name like "/dev/vda". The hint must match the
+	// actual value exactly.
+	DeviceName string `json:"deviceName,omitempty"`
+
+	// A SCSI bus address like 0:0:0:0. The hint must match the actual
+	// value exactly.
+	HCTL string `json:"hctl,omitempty"`
+
+	// A vendor-specific device identifier. The hint can be a
+	// substring of the actual value.
+	Model string `json:"model,omitempty"`
+
+	// The name of the vendor or manufacturer of the device. The hint
+	// can be a substring of the actual value.
+	Vendor string `json:"vendor,omitempty"`
+
+	// Device serial number. The hint must match the actual value
+	// exactly.
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// The minimum size of the device in Gigabytes.
+	// +kubebuilder:validation:Minimum=0
+	MinSizeGigabytes int `json:"minSizeGigabytes,omitempty"`
+
+	// Unique storage identifier. The hint must match the actual value
+	// exactly.
+	WWN string `json:"wwn,omitempty"`
+
+	// Unique storage identifier with the vendor extension
+	// appended. The hint must match the actual value exactly.
+	WWNWithExtension string `json:"wwnWithExtension,omitempty"`
+
+	// Unique vendor storage identifier. The hint must match the
+	// actual value exactly.
+	WWNVendorExtension string `json:"wwnVendorExtension,omitempty"`
+
+	// True if the device should use spinning media, false otherwise.
+	Rotational *bool `json:"rotational,omitempty"`
+}
+
+// BootMode is the boot mode of the system
+// +kubebuilder:validation:Enum=UEFI;UEFISecureBoot;legacy
+type BootMode string
+
+// Allowed boot mode from metal3
+const (
+	UEFI            BootMode = "UEFI"
+	UEFISecureBoot  BootMode = "UEFISecureBoot"
+	Legacy          BootMode = "legacy"
+	DefaultBootMode BootMode = UEFI
+)
+
+// OperationalStatus represents the state of the host
+// +kubebuilder:validation:Enum="";OK;discovered;error;delayed;detached
+type OperationalStatus string
+
+const (
+	// OperationalStatusOK is the status value for when the host is
+	// configured correctly and is manageable.
+	OperationalStatusOK OperationalStatus = "OK"
+
+	// OperationalStatusDiscovered is the status value for when the
+	// host is only partially configured, such as when when the BMC
+	// address is known but the login credentials are not.
+	OperationalStatusDiscovered OperationalStatus = "discovered"
+
+	// OperationalStatusError is the status value for when the host
+	// has any sort of error.
+	OperationalStatusError OperationalStatus = "error"
+
+	// OperationalStatusDelayed is the status value for when the host
+	// deployment needs to be delayed to limit simultaneous hosts provisioning
+	OperationalStatusDelayed OperationalStatus = "delayed"
+
+	// OperationalStatusDetached is the status value when the host is
+	// marked unmanaged via the detached annotation
+	OperationalStatusDetached OperationalStatus = "detached"
+)
+
+// ErrorType indicates the class of problem that has caused the Host resource
+// to enter an error state.
+// +kubebuilder:validation:Enum="";ProvisionedRegistrationError;RegistrationError;InspectionError;PreparationError;ProvisioningError;PowerManagementError;DetachError
+type ErrorType string
+
+const (
+	// ProvisionedRegistrationError is an error condition occurring when the controller
+	// is unable to re-register an already provisioned host.
+	ProvisionedRegistrationError ErrorType = "ProvisionedRegistrationError"
+	// RegistrationError is an error condition occurring when the
+	// controller is unable to connect to the Host's baseboard management
+	// controller.
+	RegistrationError ErrorType = "RegistrationError"
+	// InspectionError is an error condition occurring when an attempt to
+	// obtain hardware details from the Host fails.
+	InspectionError ErrorType = "InspectionError"
+	// PreparationError is an error condition occurring when do
+	// cleaning steps failed.
+	PreparationError ErrorType = "PreparationError"
+	// ProvisioningError is an error condition occurring when the controller
+	// fails to provision or deprovision the Host.
+	ProvisioningError ErrorType = "ProvisioningError"
+	// PowerManagementError is an error condition occurring when the
+	// controller is unable to modify the power state of the Host.
+	PowerManagementError ErrorType = "PowerManagementError"
+	// DetachError is an error condition occurring when the
+	// controller is unable to detatch the host from the provisioner
+	DetachError ErrorType = "DetachError"
+)
+
+// Condition types reported in the status of a host
+const (
+	// RegisteredCondition is true when the host is registered with
+	// the provisioner.
+	RegisteredCondition = "Registered"
+
+	// CredentialsValidCondition is true when the current BMC
+	// credentials have been validated by the provisioner.
+	CredentialsValidCondition = "CredentialsValid"
+
+	// InspectedCondition is true when the hardware details of the host
+	// have been collected.
+	InspectedCondition = "Inspected"
+
+	// PreparedCondition is true when the RAID and firmware settings
+	// of the host have been applied.
+	PreparedCondition = "Prepared"
+
+	// ProvisionedCondition is true when an image has been written to
+	// the host, or when the host is externally provisioned.
+	ProvisionedCondition = "Provisioned"
+
+	// PowerSyncedCondition is true when the power state of the host
+	// matches the online field of its spec.
+	PowerSyncedCondition = "PowerSynced"
+
+	// DetachedCondition is true when the host has been detached from
+	// the provisioner.
+	DetachedCondition = "Detached"
+
+	// FirmwareInBaselineCondition is true when the firmware versions
+	// of the host match the FirmwareBaseline of its hardware profile.
+	FirmwareInBaselineCondition = "FirmwareInBaseline"
+
+	// BMCCertificateUnchangedCondition is true when the BMC presents
+	// the certificate whose fingerprint is recorded in the status, and
+	// false when it presents another one.
+	BMCCertificateUnchangedCondition = "BMCCertificateUnchanged"
+)
+
+// ProvisioningState defines the states the provisioner will report
+// the host has having.
+// +kubebuilder:validation:Enum="";unmanaged;registering;match profile;preparing;ready;available;provisioning;provisioned;externally provisioned;deprovisioning;inspecting;deleting
+type ProvisioningState string
+
+const (
+	// StateNone means the state is unknown
+	StateNone ProvisioningState = ""
+
+	// StateUnmanaged means there is insufficient information available to
+	// register the host
+	StateUnmanaged ProvisioningState = "unmanaged"
+
+	// StateRegistering means we are telling the backend about the host
+	StateRegistering ProvisioningState = "registering"
+
+	// StateMatchProfile means we are comparing the discovered details
+	// against known hardware profiles
+	StateMatchProfile ProvisioningState = "match profile"
+
+	// StatePreparing means we are removing existing configuration and set new configuration to the host
+	StatePreparing ProvisioningState = "preparing"
+
+	// StateReady means the host can be consumed
+	StateReady ProvisioningState = "ready"
+
+	// StateAvailable means the host can be consumed
+	StateAvailable ProvisioningState = "available"
+
+	// StateProvisioning means we are writing an image to the host's
+	// disk(s)
+	StateProvisioning ProvisioningState = "provisioning"
+
+	// StateProvisioned means we have written an image to the host's
+	// disk(s)
+	StateProvisioned ProvisioningState = "provisioned"
+
+	// StateExternallyProvisioned means something else is managing the
+	// image on the host
+	StateExternallyProvisioned ProvisioningState = "externally provisioned"
+
+	// StateDeprovisioning means we are removing an image from the
+	// host's disk(s)
+	StateDeprovisioning ProvisioningState = "deprovisioning"
+
+	// StateInspecting means we are running the agent on the host to
+	// learn about the hardware components available there
+	StateInspecting ProvisioningState = "inspecting"
+
+	// StateDeleting means we are in the process of cleaning up the host
+	// ready for deletion
+	StateDeleting ProvisioningState = "deleting"
+)
+
+// BMCDetails contains the information necessary to communicate with
+// the bare metal controller module on host.
+type BMCDetails struct {
+
+	// Address holds the URL for accessing the controller on the
+	// network.
+	Address string `json:"address"`
+
+	// The name of the secret containing the BMC credentials (requires
+	// keys "username" and "password"). With a credentials provider
+	// other than Secret, the path of the credentials in the store of
+	// the provider.
+	CredentialsName string `json:"credentialsName"`
+
+	// CredentialsProvider selects the store holding the BMC
+	// credentials. Defaults to a Secret in the namespace of the host.
+	// +optional
+	CredentialsProvider CredentialsProviderType `json:"credentialsProvider,omitempty"`
+
+	// DisableCertificateVerification disables verification of server
+	// certificates when using HTTPS to connect to the BMC. This is
+	// required when the server certificate is self-signed, but is
+	// insecure because it allows a man-in-the-middle to intercept the
+	// connection.
+	DisableCertificateVerification bool `json:"disableCertificateVerification,omitempty"`
+
+	// CABundle references the certificates of the authorities trusted
+	// to sign the server certificate of the BMC, for BMCs using an
+	// internal CA. It is ignored when DisableCertificateVerification
+	// is set.
+	// +optional
+	CABundle *CABundleReference `json:"caBundle,omitempty"`
+}
+
+// CredentialsProviderType is the store holding the credentials of a
+// BMC.
+// +kubebuilder:validation:Enum=Secret;Vault;File
+type CredentialsProviderType string
+
+// Supported credentials providers
+const (
+	// CredentialsProviderSecret reads the credentials from a Secret
+	CredentialsProviderSecret CredentialsProviderType = "Secret"
+	// CredentialsProviderVault reads the credentials from the KV
+	// secrets engine of a Vault compatible server, under the namespace
+	// of the host
+	CredentialsProviderVault CredentialsProviderType = "Vault"
+	// CredentialsProviderFile reads the credentials from files mounted
+	// in the operator, for example by a CSI driver, under a directory
+	// named after the namespace of the host
+	CredentialsProviderFile CredentialsProviderType = "File"
+)
+
+// CABundleKind is the kind of object holding a CA bundle.
+// +kubebuilder:validation:Enum=Secret;ConfigMap
+type CABundleKind string
+
+// Allowed CA bundle kinds
+const (
+	CABundleKindSecret    CABundleKind = "Secret"
+	CABundleKindConfigMap CABundleKind = "ConfigMap"
+)
+
+// DefaultCABundleKey is the key holding the CA bundle when none is
+// given.
+const DefaultCABundleKey = "ca.crt"
+
+// CABundleReference points to a Secret or ConfigMap, in the namespace
+// of the host, holding PEM encoded CA certificates.
+type CABundleReference struct {
+	// The kind of object holding the bundle, Secret or ConfigMap.
+	Kind CABundleKind `json:"kind"`
+
+	// The name of the object holding the bundle.
+	Name string `json:"name"`
+
+	// The key of the bundle in the object. Defaults to "ca.crt".
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// HardwareRAIDVolume defines the desired configuration of volume in hardware RAID
+type HardwareRAIDVolume struct {
+	// Size (Integer) of the logical disk to be created in GiB.
+	// If unspecified or set be 0, the maximum capacity of disk will be used for logical disk.
+	// +kubebuilder:validation:Minimum=0
+	SizeGibibytes *int `json:"sizeGibibytes,omitempty"`
+
+	// RAID level for the logical disk. The following levels are supported: 0;1;2;5;6;1+0;5+0;6+0.
+	// +kubebuilder:validation:Enum="0";"1";"2";"5";"6";"1+0";"5+0";"6+0"
+	Level string `json:"level" required:"true"`
+
+	// Name of the volume. Should be unique within the Node. If not specified, volume name will be auto-generated.
+	// +kubebuilder:validation:MaxLength=64
+	Name string `json:"name,omitempty"`
+
+	// Select disks with only rotational or solid-state storage
+	Rotational *bool `json:"rotational,omitempty"`
+
+	// Integer, number of physical disks to use for the logical disk. Defaults to minimum number of disks required
+	// for the particular RAID level.
+	// +kubebuilder:validation:Minimum=1
+	NumberOfPhysicalDisks *int `json:"numberOfPhysicalDisks,omitempty"`
+}
+
+// SoftwareRAIDVolume defines the desired configuration of volume in software RAID
+type SoftwareRAIDVolume struct {
+	// Size (Integer) of the logical disk to be created in GiB.
+	// If unspecified or set be 0, the maximum capacity of disk will be used for logical disk.
+	// +kubebuilder:validation:Minimum=0
+	SizeGibibytes *int `json:"sizeGibibytes,omitempty"`
+
+	// RAID level for the logical disk. The following levels are supported: 0;1;1+0.
+	// +kubebuilder:validation:Enum="0";"1";"1+0"
+	Level string `json:"level" required:"true"`
+
+	// A list of device hints, the number of items should be greater than or equal to 2.
+	// +kubebuilder:validation:MinItems=2
+	PhysicalDisks []RootDeviceHints `json:"physicalDisks,omitempty"`
+}
+
+// RAIDConfig contains the configuration that are required to config RAID in Bare Metal server
+type RAIDConfig struct {
+	// The list of logical disks for hardware RAID, if rootDeviceHints isn't used, first volume is root volume.
+	HardwareRAIDVolumes []HardwareRAIDVolume `json:"hardwareRAIDVolumes,omitempty"`
+
+	// The list of logical disks for software RAID, if rootDeviceHints isn't used, first volume is root volume.
+	// If HardwareRAIDVolumes is set this item will be invalid.
+	// The number of created Software RAID devices must be 1 or 2.
+	// If there is only one Software RAID device, it has to be a RAID-1.
+	// If there are two, the first one has to be a RAID-1, while the RAID level for the second one can be 0, 1, or 1+0.
+	// As the first RAID device will be the deployment device,
+	// enforcing a RAID-1 reduces the risk of ending up with a non-booting node in case of a disk failure.
+	// +kubebuilder:validation:MaxItems=2
+	SoftwareRAIDVolumes []SoftwareRAIDVolume `json:"softwareRAIDVolumes,omitempty"`
+}
+
+// BootDevice is a kind of device the host can boot from.
+// +kubebuilder:validation:Enum=Disk;PXE;CD;USB
+type BootDevice string
+
+// Allowed boot devices
+const (
+	BootDeviceDisk BootDevice = "Disk"
+	BootDevicePXE  BootDevice = "PXE"
+	BootDeviceCD   BootDevice = "CD"
+	BootDeviceUSB  BootDevice = "USB"
+)
+
+// PowerProfile is the trade-off between performance and power usage
+// of the host.
+// +kubebuilder:validation:Enum=Performance;Balanced;PowerSaving
+type PowerProfile string
+
+// Allowed power profiles
+const (
+	PowerProfilePerformance PowerProfile = "Performance"
+	PowerProfileBalanced    PowerProfile = "Balanced"
+	PowerProfilePowerSaving PowerProfile = "PowerSaving"
+)
+
+// CStateLimit is the deepest idle state processors can enter.
+// +kubebuilder:validation:Enum=C1;C1E;C6
+type CStateLimit string
+
+// Allowed C-state limits
+const (
+	CStateLimitC1  CStateLimit = "C1"
+	CStateLimitC1E CStateLimit = "C1E"
+	CStateLimitC6  CStateLimit = "C6"
+)
+
+// SecureBootKeys selects the keys used to verify boot loaders when
+// secure boot is enabled.
+// +kubebuilder:validation:Enum=Default;Custom
+type SecureBootKeys string
+
+// Allowed secure boot keys
+const (
+	// SecureBootKeysDefault uses the keys installed by the vendor.
+	SecureBootKeysDefault SecureBootKeys = "Default"
+	// SecureBootKeysCustom uses the keys enrolled by the user.
+	SecureBootKeysCustom SecureBootKeys = "Custom"
+)
+
+// NICPXEConfig enables or disables network boot on a network
+// interface.
+type NICPXEConfig struct {
+	// The index of the network interface in the firmware, starting
+	// from 1.
+	// +kubebuilder:validation:Minimum=1
+	Index int `json:"index"`
+
+	// Whether the host can boot from the network through the
+	// interface.
+	Enabled bool `json:"enabled"`
+}
+
+// FirmwareConfig contains the configuration that you want to configure BIOS settings in Bare metal server
+type FirmwareConfig struct {
+	// Supports the virtualization of platform hardware.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	VirtualizationEnabled *bool `json:"virtualizationEnabled,omitempty"`
+
+	// Allows a single physical processor core to appear as several logical processors.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	SimultaneousMultithreadingEnabled *bool `json:"simultaneousMultithreadingEnabled,omitempty"`
+
+	// SR-IOV support enables a hypervisor to create virtual instances of a PCI-express device, potentially increasing performance.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	SriovEnabled *bool `json:"sriovEnabled,omitempty"`
+
+	// The order in which the host tries to boot from its devices.
+	// +optional
+	BootOrder []BootDevice `json:"bootOrder,omitempty"`
+
+	// The trade-off between performance and power usage.
+	// This supports following options: Performance, Balanced, PowerSaving.
+	// +optional
+	PowerProfile PowerProfile `json:"powerProfile,omitempty"`
+
+	// Allows idle processors to enter power saving states.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	CStatesEnabled *bool `json:"cStatesEnabled,omitempty"`
+
+	// The deepest idle state processors can enter, to bound the
+	// latency of waking them up.
+	// This supports following options: C1, C1E, C6.
+	// +optional
+	CStateLimit CStateLimit `json:"cStateLimit,omitempty"`
+
+	// Allows processors to run above their base frequency.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	TurboBoostEnabled *bool `json:"turboBoostEnabled,omitempty"`
+
+	// Exposes the memory of each processor as a separate NUMA node
+	// instead of interleaving it.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	NUMAEnabled *bool `json:"numaEnabled,omitempty"`
+
+	// Interleaves memory accesses across the channels of each memory
+	// controller.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	MemoryInterleavingEnabled *bool `json:"memoryInterleavingEnabled,omitempty"`
+
+	// Enables HyperTransport Assist, which reduces the cache probe
+	// traffic between AMD processors.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	HyperTransportAssistEnabled *bool `json:"hyperTransportAssistEnabled,omitempty"`
+
+	// Enables the Trusted Platform Module.
+	// This supports following options: true, false.
+	// +kubebuilder:validation:Enum=true;false
+	TPMEnabled *bool `json:"tpmEnabled,omitempty"`
+
+	// The keys used to verify boot loaders with secure boot.
+	// This supports following options: Default, Custom.
+	// +optional
+	SecureBootKeys SecureBootKeys `json:"secureBootKeys,omitempty"`
+
+	// Enables or disables network boot per network interface.
+	// +optional
+	PXE []NICPXEConfig `json:"pxe,omitempty"`
+}
+
+// FirmwareComponent is a part of the host whose firmware can be
+// updated.
+// +kubebuilder:validation:Enum=bios;bmc;nic
+type FirmwareComponent string
+
+const (
+	// FirmwareComponentBIOS is the BIOS or UEFI firmware of the system
+	FirmwareComponentBIOS FirmwareComponent = "bios"
+
+	// FirmwareComponentBMC is the firmware of the BMC
+	FirmwareComponentBMC FirmwareComponent = "bmc"
+
+	// FirmwareComponentNIC is the firmware of the network adapters
+	FirmwareComponentNIC FirmwareComponent = "nic"
+)
+
+// FirmwareUpdate describes a firmware image to apply to a component of
+// the host while it is prepared.
+type FirmwareUpdate struct {
+	// The component updated by the image.
+	Component FirmwareComponent `json:"component"`
+
+	// URL of the firmware image, reachable by the BMC.
+	URL string `json:"url"`
+
+	// The SHA1 checksum of the image, checked before it is applied.
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+
+	// The version the component reports once the image is applied.
+	Version string `json:"version"`
+}
+
+// FirmwareUpdateStatus reports the versions of a component updated
+// while the host was prepared.
+type FirmwareUpdateStatus struct {
+	FirmwareUpdate `json:",inline"`
+
+	// The version of the component before the update, if known.
+	// +optional
+	PreviousVersion string `json:"previousVersion,omitempty"`
+
+	// The version of the component after the update, if known.
+	// +optional
+	CurrentVersion string `json:"currentVersion,omitempty"`
+}
+
+// BareMetalHostSpec defines the desired state of BareMetalHost
+type BareMetalHostSpec struct {
+	// Important: Run "make generate manifests" to regenerate code
+	// after modifying this file
+
+	// Taints is the full, authoritative list of taints to apply to
+	// the corresponding Machine. This list will overwrite any
+	// modifications made to the Machine on an ongoing basis.
+	// +optional
+	Taints []corev1.Taint `json:"taints,omitempty"`
+
+	// How do we connect to the BMC?
+	BMC BMCDetails `json:"bmc,omitempty"`
+
+	// RAID configuration for bare metal server
+	RAID *RAIDConfig `json:"raid,omitempty"`
+
+	// BIOS configuration for bare metal server
+	Firmware *FirmwareConfig `json:"firmware,omitempty"`
+
+	// Firmware images to apply to the components of the host, in
+	// order, when it is prepared.
+	// +optional
+	FirmwareUpdates []FirmwareUpdate `json:"firmwareUpdates,omitempty"`
+
+	// What is the name of the hardware profile for this host? It
+	// should only be necessary to set this when inspection cannot
+	// automatically determine the profile.
+	HardwareProfile string `json:"hardwareProfile,omitempty"`
+
+	// Provide guidance about how to choose the device for the image
+	// being provisioned.
+	RootDeviceHints *RootDeviceHints `json:"rootDeviceHints,omitempty"`
+
+	// Select the method of initializing the hardware during
+	// boot. Defaults to UEFI.
+	// +optional
+	BootMode BootMode `json:"bootMode,omitempty"`
+
+	// Which MAC address will PXE boot? This is optional for some
+	// types, but required for libvirt VMs driven by vbmc.
+	// +kubebuilder:validation:Pattern=`[0-9a-fA-F]{2}(:[0-9a-fA-F]{2}){5}`
+	BootMACAddress string `json:"bootMACAddress,omitempty"`
+
+	// Should the server be online?
+	Online bool `json:"online"`
+
+	// ConsumerRef can be used to store information about something
+	// that is using a host. When it is not empty, the host is
+	// considered "in use".
+	ConsumerRef *corev1.ObjectReference `json:"consumerRef,omitempty"`
+
+	// Image holds the details of the image to be provisioned.
+	Image *Image `json:"image,omitempty"`
+
+	// UserData holds the reference to the Secret containing the user
+	// data to be passed to the host before it boots.
+	UserData *corev1.SecretReference `json:"userData,omitempty"`
+
+	// NetworkData holds the reference to the Secret containing network
+	// configuration (e.g content of network_data.json which is passed
+	// to Config Drive).
+	NetworkData *corev1.SecretReference `json:"networkData,omitempty"`
+
+	// MetaData holds the reference to the Secret containing host metadata
+	// (e.g. meta_data.json which is passed to Config Drive).
+	MetaData *corev1.SecretReference `json:"metaData,omitempty"`
+
+	// Description is a human-entered text used to help identify the host
+	Description string `json:"description,omitempty"`
+
+	// ExternallyProvisioned means something else is managing the
+	// image running on the host and the operator should only manage
+	// the power status and hardware inventory inspection. If the
+	// Image field is filled in, this field is ignored.
+	ExternallyProvisioned bool `json:"externallyProvisioned,omitempty"`
+
+	// When set to disabled, automated cleaning will be avoided
+	// during provisioning and deprovisioning.
+	// +optional
+	// +kubebuilder:default:=metadata
+	// +kubebuilder:validation:Optional
+	AutomatedCleaningMode AutomatedCleaningMode `json:"automatedCleaningMode,omitempty"`
+
+	// A custom deploy procedure.
+	// +optional
+	CustomDeploy *CustomDeploy `json:"customDeploy,omitempty"`
+}
+
+// AutomatedCleaningMode is the interface to enable/disable automated cleaning
+// +kubebuilder:validation:Enum:=metadata;disabled
+type AutomatedCleaningMode string
+
+// Allowed automated cleaning modes
+const (
+	CleaningModeDisabled AutomatedCleaningMode = "disabled"
+	CleaningModeMetadata AutomatedCleaningMode = "metadata"
+)
+
+// ChecksumType holds the algorithm name for the checksum
+// +kubebuilder:validation:Enum=md5;sha256;sha512
+type ChecksumType string
+
+const (
+	// MD5 checksum type
+	MD5 ChecksumType = "md5"
+
+	// SHA256 checksum type
+	SHA256 ChecksumType = "sha256"
+
+	// SHA512 checksum type
+	SHA512 ChecksumType = "sha512"
+)
+
+// Image holds the details of an image either to provisioned or that
+// has been provisioned.
+type Image struct {
+	// URL is a location of an image to deploy.
+	URL string `json:"url"`
+
+	// Checksum is the checksum for the image.
+	Checksum string `json:"checksum,omitempty"`
+
+	// ChecksumType is the checksum algorithm for the image.
+	// e.g md5, sha256, sha512
+	ChecksumType ChecksumType `json:"checksumType,omitempty"`
+
+	// DiskFormat contains the format of the image (raw, qcow2, ...).
+	// Needs to be set to raw for raw images streaming.
+	// Note live-iso means an iso referenced by the url will be live-booted
+	// and not deployed to disk, and in this case the checksum options
+	// are not required and if specified will be ignored.
+	// +kubebuilder:validation:Enum=raw;qcow2;vdi;vmdk;live-iso
+	DiskFormat *string `json:"format,omitempty"`
+}
+
+// Custom deploy is a description of a customized deploy process.
+type CustomDeploy struct {
+	// Custom deploy method name.
+	// This name is specific to the deploy ramdisk used. If you don't have
+	// a custom deploy ramdisk, you shouldn't use CustomDeploy.
+	Method string `json:"method"`
+}
+
+// FIXME(dhellmann): We probably want some other module to own these
+// data structures.
+
+// ClockSpeed is a clock speed in MHz
+// +kubebuilder:validation:Format=double
+type ClockSpeed float64
+
+// ClockSpeed multipliers
+const (
+	MegaHertz ClockSpeed = 1.0
+	GigaHertz            = 1000 * MegaHertz
+)
+
+// Capacity is a disk size in Bytes
+type Capacity int64
+
+// Capacity multipliers
+const (
+	Byte     Capacity = 1
+	KibiByte          = Byte * 1024
+	KiloByte          = Byte * 1000
+	MebiByte          = KibiByte * 1024
+	MegaByte          = KiloByte * 1000
+	GibiByte          = MebiByte * 1024
+	GigaByte          = MegaByte * 1000
+	TebiByte          = GibiByte * 1024
+	TeraByte          = GigaByte * 1000
+)
+
+// DiskType is a disk type, i.e. HDD, SSD, NVME.
+type DiskType string
+
+// DiskType constants.
+const (
+	HDD  DiskType = "HDD"
+	SSD  DiskType = "SSD"
+	NVME DiskType = "NVME"
+)
+
+// CPU describes one processor on the host.
+type CPU struct {
+	Arch           string     `json:"arch,omitempty"`
+	Model          string     `json:"model,omitempty"`
+	ClockMegahertz ClockSpeed `json:"clockMegahertz,omitempty"`
+	Flags          []string   `json:"flags,omitempty"`
+	Count          int        `json:"count,omitempty"`
+}
+
+// Storage describes one storage device (disk, SSD, etc.) on the host.
+type Storage struct {
+	// The Linux device name of the disk, e.g. "/dev/sda". Note that this
+	// may not be stable across reboots.
+	Name string `json:"name,omitempty"`
+
+	// Whether this disk represents rotational storage.
+	// This field is not recommended for usage, please
+	// prefer using 'Type' field instead, this field
+	// will be deprecated eventually.
+	Rotational bool `json:"rotational,omitempty"`
+
+	// Device type, one of: HDD, SSD, NVME.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=HDD;SSD;NVME;
+	Type DiskType `json:"type,omitempty"`
+
+	// The size of the disk in Bytes
+	SizeBytes Capacity `json:"sizeBytes,omitempty"`
+
+	// The name of the vendor of the device
+	Vendor string `json:"vendor,omitempty"`
+
+	// Hardware model
+	Model string `json:"model,omitempty"`
+
+	// The serial number of the device
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// The WWN of the device
+	WWN string `json:"wwn,omitempty"`
+
+	// The WWN Vendor extension of the device
+	WWNVendorExtension string `json:"wwnVendorExtension,omitempty"`
+
+	// The WWN with the extension
+	WWNWithExtension string `json:"wwnWithExtension,omitempty"`
+
+	// The SCSI location of the device
+	HCTL string `json:"hctl,omitempty"`
+
+	// The firmware revision of the device
+	FirmwareVersion string `json:"firmwareVersion,omitempty"`
+}
+
+// VLANID is a 12-bit 802.1Q VLAN identifier
+// +kubebuilder:validation:Type=integer
+// +kubebuilder:validation:Minimum=0
+// +kubebuilder:validation:Maximum=4094
+type VLANID int32
+
+// VLAN represents the name and ID of a VLAN
+type VLAN struct {
+	ID VLANID `json:"id,omitempty"`
+
+	Name string `json:"name,omitempty"`
+}
+
+// NIC describes one network interface on the host.
+type NIC struct {
+	// The name of the network interface, e.g. "en0"
+	Name string `json:"name,omitempty"`
+
+	// The vendor and product IDs of the NIC, e.g. "0x8086 0x1572"
+	Model string `json:"model,omitempty"`
+
+	// The device MAC address
+	// +kubebuilder:validation:Pattern=`[0-9a-fA-F]{2}(:[0-9a-fA-F]{2}){5}`
+	MAC string `json:"mac,omitempty"`
+
+	// The IP address of the interface. This will be an IPv4 or IPv6 address
+	// if one is present.  If both IPv4 and IPv6 addresses are present in a
+	// dual-stack environment, two nics will be output, one with each IP.
+	IP string `json:"ip,omitempty"`
+
+	// The speed of the device in Gigabits per second
+	SpeedGbps int `json:"speedGbps,omitempty"`
+
+	// The VLANs available
+	VLANs []VLAN `json:"vlans,omitempty"`
+
+	// The untagged VLAN ID
+	VLANID VLANID `json:"vlanId,omitempty"`
+
+	// Whether the NIC is PXE Bootable
+	PXE bool `json:"pxe,omitempty"`
+
+	// The version of the firmware of the NIC
+	FirmwareVersion string `json:"firmwareVersion,omitempty"`
+}
+
+// Firmware describes the firmware on the host.
+type Firmware struct {
+	// The BIOS for this firmware
+	BIOS BIOS `json:"bios,omitempty"`
+
+	// The version of the firmware of the BMC
+	BMCVersion string `json:"bmcVersion,omitempty"`
+
+	// The RAID controllers of the host
+	RAIDControllers []RAIDController `json:"raidControllers,omitempty"`
+}
+
+// RAIDController describes the firmware of a RAID controller on the
+// host.
+type RAIDController struct {
+	// The name of the controller
+	Name string `json:"name,omitempty"`
+
+	// The model of the controller
+	Model string `json:"model,omitempty"`
+
+	// The version of the firmware of the controller
+	FirmwareVersion string `json:"firmwareVersion,omitempty"`
+}
+
+// BIOS describes the BIOS version on the host.
+type BIOS struct {
+	// The release/build date for this BIOS
+	Date string `json:"date,omitempty"`
+
+	// The vendor name for this BIOS
+	Vendor string `json:"vendor,omitempty"`
+
+	// The version of the BIOS
+	Version string `json:"version,omitempty"`
+}
+
+// HardwareDetails collects all of the information about hardware
+// discovered on the host.
+type HardwareDetails struct {
+	SystemVendor HardwareSystemVendor `json:"systemVendor,omitempty"`
+	Firmware     Firmware             `json:"firmware,omitempty"`
+	RAMMebibytes int                  `json:"ramMebibytes,omitempty"`
+	NIC          []NIC                `json:"nics,omitempty"`
+	Storage      []Storage            `json:"storage,omitempty"`
+	CPU          CPU                  `json:"cpu,omitempty"`
+	Hostname     string               `json:"hostname,omitempty"`
+}
+
+// HardwareSystemVendor stores details about the whole hardware system.
+type HardwareSystemVendor struct {
+	Manufacturer string `json:"manufacturer,omitempty"`
+	ProductName  string `json:"productName,omitempty"`
+	SerialNumber string `json:"serialNumber,omitempty"`
+}
+
+// CredentialsStatus contains the reference and version of the last
+// set of BMC credentials the controller was able to validate.
+type CredentialsStatus struct {
+	Reference *corev1.SecretReference `json:"credentials,omitempty"`
+	Version   string                  `json:"credentialsVersion,omitempty"`
+}
+
+// OperationMetric contains metadata about an operation (inspection,
+// provisioning, etc.) used for tracking metrics.
+type OperationMetric struct {
+	// +nullable
+	Start metav1.Time `json:"start,omitempty"`
+	// +nullable
+	End metav1.Time `json:"end,omitempty"`
+}
+
+// OperationHistory holds information about operations performed on a
+// host.
+type OperationHistory struct {
+	Register    OperationMetric `json:"register,omitempty"`
+	Inspect     OperationMetric `json:"inspect,omitempty"`
+	Provision   OperationMetric `json:"provision,omitempty"`
+	Deprovision OperationMetric `json:"deprovision,omitempty"`
+}
+
+// BareMetalHostStatus defines the observed state of BareMetalHost
+type BareMetalHostStatus struct {
+	// Important: Run "make generate manifests" to regenerate code
+	// after modifying this file
+
+	// OperationalStatus holds the status of the host
+	OperationalStatus OperationalStatus `json:"operationalStatus"`
+
+	// ErrorType indicates the type of failure encountered when the
+	// OperationalStatus is OperationalStatusError
+	ErrorType ErrorType `json:"errorType,omitempty"`
+
+	// Conditions describe the current state of the host.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// LastUpdated identifies when this status was last observed.
+	// +optional
+	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
+
+	// The name of the profile matching the hardware details.
+	HardwareProfile string `json:"hardwareProfile"`
+
+	// The hardware discovered to exist on the host.
+	HardwareDetails *HardwareDetails `json:"hardware,omitempty"`
+
+	// Information tracked by the provisioner.
+	Provisioning ProvisionStatus `json:"provisioning"`
+
+	// the last credentials we were able to validate as working
+	GoodCredentials CredentialsStatus `json:"goodCredentials,omitempty"`
+
+	// the last credentials we sent to the provisioning backend
+	TriedCredentials CredentialsStatus `json:"triedCredentials,omitempty"`
+
+	// The SHA-256 fingerprint of the certificate presented by the BMC
+	// when access to it was last validated, for trust-on-first-use
+	// pinning. The certificate is checked against it periodically.
+	// +optional
+	BMCCertificateFingerprint string `json:"bmcCertificateFingerprint,omitempty"`
+
+	// the last error message reported by the provisioning subsystem
+	ErrorMessage string `json:"errorMessage"`
+
+	// indicator for whether or not the host is powered on
+	PoweredOn bool `json:"poweredOn"`
+
+	// OperationHistory holds information about operations performed
+	// on this host.
+	OperationHistory OperationHistory `json:"operationHistory,omitempty"`
+
+	// ErrorCount records how many times the host has encoutered an error since the last successful operation
+	// +kubebuilder:default:=0
+	ErrorCount int `json:"errorCount"`
+
+	// CapacityQueue is the position of the host in the queue of the
+	// hosts waiting for a (de)provisioning slot, while it is delayed.
+	// +optional
+	CapacityQueue *CapacityQueueStatus `json:"capacityQueue,omitempty"`
+}
+
+// CapacityQueueStatus is the position of a delayed host in the queue
+// of the hosts of its capacity pool waiting for a (de)provisioning slot
+type CapacityQueueStatus struct {
+	// Pool is the name of the CapacityPool of the host, empty when the
+	// host belongs to no pool.
+	// +optional
+	Pool string `json:"pool,omitempty"`
+
+	// Position is the position of the host in the queue of its pool,
+	// starting at 1.
+	Position int `json:"position"`
+
+	// Since is when the host started waiting.
+	Since metav1.Time `json:"since"`
+}
+
+// ProvisionStatus holds the state information for a single target.
+type ProvisionStatus struct {
+	// An indiciator for what the provisioner is doing with the host.
+	State ProvisioningState `json:"state"`
+
+	// The machine's UUID from the underlying provisioning tool
+	ID string `json:"ID"`
+
+	// Image holds the details of the last image successfully
+	// provisioned to the host.
+	Image Image `json:"image,omitempty"`
+
+	// The RootDevicehints set by the user
+	RootDeviceHints *RootDeviceHints `json:"rootDeviceHints,omitempty"`
+
+	// BootMode indicates the boot mode used to provision the node
+	BootMode BootMode `json:"bootMode,omitempty"`
+
+	// The Raid set by the user
+	RAID *RAIDConfig `json:"raid,omitempty"`
+
+	// The Bios set by the user
+	Firmware *FirmwareConfig `json:"firmware,omitempty"`
+
+	// The firmware updates applied when the host was prepared, with
+	// the versions of the components before and after the update
+	FirmwareUpdates []FirmwareUpdateStatus `json:"firmwareUpdates,omitempty"`
+
+	// Custom deploy procedure applied to the host.
+	CustomDeploy *CustomDeploy `json:"customDeploy,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BareMetalHost is the Schema for the baremetalhosts API
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:shortName=bmh;bmhost
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.operationalStatus",description="Operational status",priority=1
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.provisioning.state",description="Provisioning status"
+// +kubebuilder:printcolumn:name="Consumer",type="string",JSONPath=".spec.consumerRef.name",description="Consumer using this host"
+// +kubebuilder:printcolumn:name="BMC",type="string",JSONPath=".spec.bmc.address",description="Address of management controller",priority=1
+// +kubebuilder:printcolumn:name="Hardware_Profile",type="string",JSONPath=".status.hardwareProfile",description="The type of hardware detected",priority=1
+// +kubebuilder:printcolumn:name="Online",type="string",JSONPath=".spec.online",description="Whether the host is online or not"
+// +kubebuilder:printcolumn:name="Error",type="string",JSONPath=".status.errorType",description="Type of the most recent error"
+// +kubebuilder:object:root=true
+type BareMetalHost struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BareMetalHostSpec   `json:"spec,omitempty"`
+	Status BareMetalHostStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BareMetalHostList contains a list of BareMetalHost
+type BareMetalHostList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BareMetalHost `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BareMetalHost{}, &BareMetalHostList{})
+}
@@ -56,6 +56,20 @@ func (r actionUpdate) Dirty() bool {
 	return true
 }
 
+// markDirty returns a result equivalent to actRes that also writes
+// the status of the host. Errors and deletions are returned unchanged,
+// as there is no status to write.
+func markDirty(actRes actionResult) actionResult {
+	switch r := actRes.(type) {
+	case actionContinue:
+		return actionUpdate{r}
+	case actionFailed:
+		r.dirty = true
+		return r
+	}
+	return actRes
+}
+
 // actionDelayed it's the same of an actionUpdate, but the requeue time
 // is calculated using a fixed backoff with jitter
 type actionDelayed struct {
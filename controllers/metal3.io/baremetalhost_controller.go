@@ -17,6 +17,7 @@ package controllers
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -24,6 +25,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -32,7 +34,9 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -54,6 +58,7 @@ const (
 	rebootAnnotationPrefix        = "reboot.metal3.io"
 	inspectAnnotationPrefix       = "inspect.metal3.io"
 	hardwareDetailsAnnotation     = inspectAnnotationPrefix + "/hardwaredetails"
+	bmcCertificateCheckInterval   = time.Hour
 
 	LabelEnvironmentName  = "environment.metal3.io"
 	LabelEnvironmentValue = "baremetal"
@@ -65,6 +70,28 @@ type BareMetalHostReconciler struct {
 	Log                logr.Logger
 	ProvisionerFactory provisioner.Factory
 	APIReader          client.Reader
+
+	// PowerClientFactory is used, when set, to manage the power of
+	// hosts through their BMC rather than through the provisioner.
+	PowerClientFactory bmc.PowerClientFactory
+
+	// AccountClientFactory is used, when set, to change the password
+	// of BMC accounts when their credentials are rotated.
+	AccountClientFactory bmc.AccountClientFactory
+
+	// FirmwareClientFactory is used, when set, to read the firmware
+	// versions of hosts from their BMC to check firmware updates.
+	FirmwareClientFactory bmc.FirmwareClientFactory
+
+	// CredentialsStores configures the external stores hosts can read
+	// their BMC credentials from.
+	CredentialsStores bmc.CredentialsStoreConfig
+
+	softPowerOffs sync.Map
+
+	// certificateReads holds, by host, the reads of the certificate of
+	// the BMC running in the background.
+	certificateReads sync.Map
 }
 
 // Instead of passing a zillion arguments to the action of a phase,
@@ -74,6 +101,8 @@ type reconcileInfo struct {
 	host              *metal3v1alpha1.BareMetalHost
 	request           ctrl.Request
 	bmcCredsSecret    *corev1.Secret
+	bmcCreds          *bmc.Credentials
+	firmwareSettings  *metal3v1alpha1.HostFirmwareSettings
 	events            []corev1.Event
 	errorMessage      string
 	postSaveCallbacks []func()
@@ -86,7 +115,9 @@ func (info *reconcileInfo) publishEvent(reason, message string) {
 
 // +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=metal3.io,resources=hardwareprofiles,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metal3.io,resources=capacitypools,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch
 
 // Reconcile handles changes to BareMetalHost resources
@@ -194,12 +225,19 @@ func (r *BareMetalHostReconciler) Reconcile(ctx context.Context, request ctrl.Re
 	// management controller.
 	var bmcCreds *bmc.Credentials
 	var bmcCredsSecret *corev1.Secret
+	var bmcCABundle []byte
 	haveCreds := false
 	switch host.Status.Provisioning.State {
 	case metal3v1alpha1.StateNone, metal3v1alpha1.StateUnmanaged:
 		bmcCreds = &bmc.Credentials{}
 	default:
 		bmcCreds, bmcCredsSecret, err = r.buildAndValidateBMCCredentials(request, host)
+		if err == nil && bmcCreds != nil {
+			bmcCABundle, err = r.getBMCCABundle(host)
+			// The native Redfish clients verify the certificate of
+			// the BMC with the bundle too
+			bmcCreds.CABundle = bmcCABundle
+		}
 		if err != nil || bmcCreds == nil {
 			if !host.DeletionTimestamp.IsZero() {
 				// If we are in the process of deletion, try with empty credentials
@@ -219,9 +257,20 @@ func (r *BareMetalHostReconciler) Reconcile(ctx context.Context, request ctrl.Re
 		host:           host,
 		request:        request,
 		bmcCredsSecret: bmcCredsSecret,
+		bmcCreds:       bmcCreds,
 	}
 
-	prov, err := r.ProvisionerFactory.NewProvisioner(provisioner.BuildHostData(*host, *bmcCreds), info.publishEvent)
+	switch initialState {
+	case metal3v1alpha1.StateReady, metal3v1alpha1.StateAvailable, metal3v1alpha1.StatePreparing:
+		info.firmwareSettings, err = r.getHostFirmwareSettings(request)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	hostData := provisioner.BuildHostData(*host, *bmcCreds)
+	hostData.BMCCABundle = bmcCABundle
+	prov, err := r.ProvisionerFactory.NewProvisioner(hostData, info.publishEvent)
 	if err != nil {
 		return ctrl.Result{}, errors.Wrap(err, "failed to create provisioner")
 	}
@@ -235,6 +284,13 @@ func (r *BareMetalHostReconciler) Reconcile(ctx context.Context, request ctrl.Re
 		return ctrl.Result{Requeue: true, RequeueAfter: provisionerNotReadyRetryDelay}, nil
 	}
 
+	if haveCreds {
+		result, done, err := r.rotateCredentials(prov, info, bmcCABundle)
+		if err != nil || !done {
+			return result, err
+		}
+	}
+
 	stateMachine := newHostStateMachine(host, r, prov, haveCreds)
 	actResult := stateMachine.ReconcileState(info)
 	result, err = actResult.Result()
@@ -274,6 +330,20 @@ func (r *BareMetalHostReconciler) Reconcile(ctx context.Context, request ctrl.Re
 	return
 }
 
+// getHostFirmwareSettings loads the HostFirmwareSettings of the host,
+// returning nil if they have not been created yet.
+func (r *BareMetalHostReconciler) getHostFirmwareSettings(request ctrl.Request) (*metal3v1alpha1.HostFirmwareSettings, error) {
+	hfs := &metal3v1alpha1.HostFirmwareSettings{}
+	err := r.Get(context.TODO(), request.NamespacedName, hfs)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "could not load host firmware settings")
+	}
+	return hfs, nil
+}
+
 // Consume inspect.metal3.io/hardwaredetails when either
 // inspect.metal3.io=disabled or there are no existing HardwareDetails
 func (r *BareMetalHostReconciler) updateHardwareDetails(request ctrl.Request, host *metal3v1alpha1.BareMetalHost) (bool, error) {
@@ -344,7 +414,9 @@ func recordActionFailure(info *reconcileInfo, errorType metal3v1alpha1.ErrorType
 	return actionFailed{dirty: true, ErrorType: errorType, errorCount: info.host.Status.ErrorCount}
 }
 
-func recordActionDelayed(info *reconcileInfo, state metal3v1alpha1.ProvisioningState) actionResult {
+// recordActionDelayed marks the host as delayed, along with its
+// position in the queue of its capacity pool when known
+func recordActionDelayed(info *reconcileInfo, state metal3v1alpha1.ProvisioningState, queue *capacityQueue) actionResult {
 	var counter prometheus.Counter
 
 	switch state {
@@ -357,6 +429,9 @@ func recordActionDelayed(info *reconcileInfo, state metal3v1alpha1.ProvisioningS
 	info.postSaveCallbacks = append(info.postSaveCallbacks, counter.Inc)
 
 	info.host.SetOperationalStatus(metal3v1alpha1.OperationalStatusDelayed)
+	if queue != nil {
+		info.host.Status.CapacityQueue = queue.status()
+	}
 	return actionDelayed{}
 }
 
@@ -365,7 +440,7 @@ func (r *BareMetalHostReconciler) credentialsErrorResult(err error, request ctrl
 	// In the event a credential secret is defined, but we cannot find it
 	// we requeue the host as we will not know if they create the secret
 	// at some point in the future.
-	case *ResolveBMCSecretRefError:
+	case *ResolveBMCSecretRefError, *ResolveBMCCredentialsError, *ResolveBMCCABundleError:
 		credentialsMissing.Inc()
 		saveErr := r.setErrorCondition(request, host, metal3v1alpha1.RegistrationError, err.Error())
 		if saveErr != nil {
@@ -485,6 +560,10 @@ func clearError(host *metal3v1alpha1.BareMetalHost) (dirty bool) {
 		host.Status.ErrorMessage = ""
 		dirty = true
 	}
+	if host.Status.CapacityQueue != nil {
+		host.Status.CapacityQueue = nil
+		dirty = true
+	}
 	return dirty
 }
 
@@ -518,6 +597,8 @@ func (r *BareMetalHostReconciler) actionDeleting(prov provisioner.Provisioner, i
 		return actionContinue{provResult.RequeueAfter}
 	}
 
+	r.certificateReads.Delete(info.host.UID)
+
 	// Remove finalizer to allow deletion
 	info.host.Finalizers = utils.FilterStringFromList(
 		info.host.Finalizers, metal3v1alpha1.BareMetalHostFinalizer)
@@ -595,7 +676,29 @@ func (r *BareMetalHostReconciler) detachHost(prov provisioner.Provisioner, info
 	return slowPoll
 }
 
+func managementAccessData(host *metal3v1alpha1.BareMetalHost) provisioner.ManagementAccessData {
+	return provisioner.ManagementAccessData{
+		BootMode:              host.Status.Provisioning.BootMode,
+		AutomatedCleaningMode: host.Spec.AutomatedCleaningMode,
+		State:                 host.Status.Provisioning.State,
+		CurrentImage:          getCurrentImage(host),
+		HasCustomDeploy:       hasCustomDeploy(host),
+	}
+}
+
 // Test the credentials by connecting to the management controller.
+// bootsFromVirtualMedia returns whether the host is booted from virtual
+// media by its BMC. An invalid address is reported when registering the
+// host.
+func bootsFromVirtualMedia(host *metal3v1alpha1.BareMetalHost) bool {
+	accessDetails, err := bmc.NewAccessDetails(host.Spec.BMC.Address,
+		host.Spec.BMC.DisableCertificateVerification)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(accessDetails.BootInterface(), "virtual-media")
+}
+
 func (r *BareMetalHostReconciler) registerHost(prov provisioner.Provisioner, info *reconcileInfo) actionResult {
 	info.log.Info("registering and validating access to management controller",
 		"credentials", info.host.Status.TriedCredentials)
@@ -609,14 +712,25 @@ func (r *BareMetalHostReconciler) registerHost(prov provisioner.Provisioner, inf
 		dirty = true
 	}
 
+	accessData := managementAccessData(info.host)
+	if info.host.Spec.NetworkData != nil && bootsFromVirtualMedia(info.host) {
+		// Only the ramdisk of hosts booted from virtual media is
+		// configured with the network data when registering.
+		hostConf := &hostConfigData{
+			host:      info.host,
+			log:       info.log.WithName("host_config_data"),
+			client:    r,
+			apiReader: r.APIReader,
+		}
+		networkData, err := hostConf.NetworkData()
+		if err != nil {
+			return actionError{errors.Wrap(err, "failed to read the network data")}
+		}
+		accessData.NetworkData = networkData
+	}
+
 	provResult, provID, err := prov.ValidateManagementAccess(
-		provisioner.ManagementAccessData{
-			BootMode:              info.host.Status.Provisioning.BootMode,
-			AutomatedCleaningMode: info.host.Spec.AutomatedCleaningMode,
-			State:                 info.host.Status.Provisioning.State,
-			CurrentImage:          getCurrentImage(info.host),
-			HasCustomDeploy:       hasCustomDeploy(info.host),
-		},
+		accessData,
 		credsChanged,
 		info.host.Status.ErrorType == metal3v1alpha1.RegistrationError)
 	if err != nil {
@@ -667,6 +781,10 @@ func (r *BareMetalHostReconciler) registerHost(prov provisioner.Provisioner, inf
 		dirty = clearError(info.host)
 	}
 
+	if r.checkBMCCertificate(info, registeredNewCreds) {
+		dirty = true
+	}
+
 	if dirty {
 		return actionComplete{}
 	}
@@ -733,17 +851,40 @@ func (r *BareMetalHostReconciler) actionMatchProfile(prov provisioner.Provisione
 		info.log.Info("using spec value for profile name",
 			"name", info.host.Spec.HardwareProfile)
 		hardwareProfile = info.host.Spec.HardwareProfile
-		_, err := hardware.GetProfile(hardwareProfile)
+		_, err := r.getHardwareProfile(info.host, hardwareProfile)
 		if err != nil {
 			info.log.Info("invalid hardware profile", "profile", hardwareProfile)
 			return actionError{err}
 		}
 	}
 
-	// Now do a bit of matching.
-	//
-	// FIXME(dhellmann): Insert more robust logic to match
-	// hardware profiles here.
+	// Match the hardware details against the HardwareProfile
+	// resources in the namespace of the host.
+	if hardwareProfile == "" {
+		profiles := &metal3v1alpha1.HardwareProfileList{}
+		if err := r.List(context.TODO(), profiles, client.InNamespace(info.host.Namespace)); err != nil {
+			return actionError{errors.Wrap(err, "failed to list hardware profiles")}
+		}
+		valid := []metal3v1alpha1.HardwareProfile{}
+		for _, profile := range profiles.Items {
+			if err := hardware.ProfileFromResource(&profile).Validate(); err != nil {
+				info.log.Info("ignoring invalid hardware profile", "name", profile.Name, "error", err.Error())
+				continue
+			}
+			valid = append(valid, profile)
+		}
+		if match, rules := hardware.MatchProfile(info.host.Status.HardwareDetails, valid); match != nil {
+			hardwareProfile = match.Name
+			info.log.Info("matched hardware details", "name", hardwareProfile, "rules", rules)
+			message := fmt.Sprintf("Hardware profile %s matched without rules", hardwareProfile)
+			if len(rules) != 0 {
+				message = fmt.Sprintf("Hardware profile %s matched rules: %s", hardwareProfile, strings.Join(rules, ", "))
+			}
+			info.publishEvent("ProfileMatched", message)
+		}
+	}
+
+	// Fall back to guessing from the BMC address.
 	if hardwareProfile == "" {
 		if strings.HasPrefix(info.host.Spec.BMC.Address, "libvirt") {
 			hardwareProfile = "libvirt"
@@ -766,21 +907,44 @@ func (r *BareMetalHostReconciler) actionMatchProfile(prov provisioner.Provisione
 	return actionComplete{}
 }
 
+// getHardwareProfile returns the settings of the named hardware
+// profile. A HardwareProfile resource in the namespace of the host
+// takes precedence over the profiles of the registry.
+func (r *BareMetalHostReconciler) getHardwareProfile(host *metal3v1alpha1.BareMetalHost, name string) (hardware.Profile, error) {
+	resource := &metal3v1alpha1.HardwareProfile{}
+	err := r.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: host.Namespace}, resource)
+	if err == nil {
+		profile := hardware.ProfileFromResource(resource)
+		return profile, profile.Validate()
+	}
+	if !k8serrors.IsNotFound(err) {
+		return hardware.Profile{}, errors.Wrap(err, "failed to get hardware profile")
+	}
+	return hardware.GetProfile(name)
+}
+
 func (r *BareMetalHostReconciler) actionPreparing(prov provisioner.Provisioner, info *reconcileInfo) actionResult {
 	info.log.Info("preparing")
 
-	dirty, newStatus, err := getHostProvisioningSettings(info.host)
+	hwProf, err := r.getHardwareProfile(info.host, info.host.HardwareProfile())
 	if err != nil {
-		return actionError{err}
+		return actionError{errors.Wrap(err, "could not determine the host provisioning settings")}
 	}
+	dirty, newStatus := getHostProvisioningSettings(info.host, hwProf)
 
 	prepareData := provisioner.PrepareData{
 		RAIDConfig:      newStatus.Provisioning.RAID.DeepCopy(),
 		RootDeviceHints: newStatus.Provisioning.RootDeviceHints.DeepCopy(),
 		FirmwareConfig:  newStatus.Provisioning.Firmware.DeepCopy(),
+		FirmwareUpdates: savedFirmwareUpdates(newStatus),
+	}
+	if info.firmwareSettings != nil {
+		prepareData.TargetFirmwareSettings = info.firmwareSettings.Spec.Settings.DeepCopy()
+		prepareData.ActualFirmwareSettings = info.firmwareSettings.Status.Settings.DeepCopy()
 	}
 	provResult, started, err := prov.Prepare(prepareData,
-		dirty || info.host.Status.ErrorType == metal3v1alpha1.PreparationError)
+		dirty || firmwareSettingsChanged(info.firmwareSettings) ||
+			info.host.Status.ErrorType == metal3v1alpha1.PreparationError)
 	if err != nil {
 		return actionError{errors.Wrap(err, "error preparing host")}
 	}
@@ -793,11 +957,17 @@ func (r *BareMetalHostReconciler) actionPreparing(prov provisioner.Provisioner,
 
 	if dirty && started {
 		info.log.Info("saving host provisioning settings")
-		_, err := saveHostProvisioningSettings(info.host)
-		if err != nil {
-			return actionError{errors.Wrap(err, "could not save the host provisioning settings")}
+		saveHostProvisioningSettings(info.host, hwProf)
+	}
+	if started && info.firmwareSettings != nil {
+		if err := saveAppliedFirmwareSettings(context.TODO(), r.Client, info.firmwareSettings); err != nil {
+			return actionError{err}
 		}
 	}
+	if started && len(info.host.Status.Provisioning.FirmwareUpdates) != 0 {
+		r.startFirmwareUpdates(info)
+		dirty = true
+	}
 	if started && clearError(info.host) {
 		dirty = true
 	}
@@ -809,6 +979,18 @@ func (r *BareMetalHostReconciler) actionPreparing(prov provisioner.Provisioner,
 		return result
 	}
 
+	if mismatch := r.checkFirmwareUpdates(info); mismatch != "" {
+		return recordActionFailure(info, metal3v1alpha1.PreparationError, mismatch)
+	}
+
+	// Record the settings resulting from the cleaning so that they are
+	// not seen as pending changes once the host is ready.
+	if info.firmwareSettings != nil {
+		if _, err := updateFirmwareSettingsStatus(context.TODO(), r.Client, prov, info.firmwareSettings); err != nil {
+			return actionError{err}
+		}
+	}
+
 	return actionComplete{}
 }
 
@@ -822,7 +1004,7 @@ func (r *BareMetalHostReconciler) actionProvisioning(prov provisioner.Provisione
 	}
 	info.log.Info("provisioning")
 
-	hwProf, err := hardware.GetProfile(info.host.HardwareProfile())
+	hwProf, err := r.getHardwareProfile(info.host, info.host.HardwareProfile())
 	if err != nil {
 		return actionError{errors.Wrap(err,
 			fmt.Sprintf("could not start provisioning with bad hardware profile %s",
@@ -892,6 +1074,7 @@ func clearHostProvisioningSettings(host *metal3v1alpha1.BareMetalHost) {
 	host.Status.Provisioning.RootDeviceHints = nil
 	host.Status.Provisioning.RAID = nil
 	host.Status.Provisioning.Firmware = nil
+	host.Status.Provisioning.FirmwareUpdates = nil
 }
 
 func (r *BareMetalHostReconciler) actionDeprovisioning(prov provisioner.Provisioner, info *reconcileInfo) actionResult {
@@ -954,9 +1137,10 @@ func (r *BareMetalHostReconciler) actionDeprovisioning(prov provisioner.Provisio
 // Check the current power status against the desired power status.
 func (r *BareMetalHostReconciler) manageHostPower(prov provisioner.Provisioner, info *reconcileInfo) actionResult {
 	var provResult provisioner.Result
+	power := r.hostPowerManager(prov, info)
 
 	// Check the current status and save it before trying to update it.
-	hwState, err := prov.UpdateHardwareState()
+	hwState, err := power.UpdateHardwareState()
 	if err != nil {
 		return actionError{errors.Wrap(err, "failed to update the host power status")}
 	}
@@ -1005,12 +1189,12 @@ func (r *BareMetalHostReconciler) manageHostPower(prov provisioner.Provisioner,
 		"reboot process", desiredPowerOnState != info.host.Spec.Online)
 
 	if desiredPowerOnState {
-		provResult, err = prov.PowerOn(info.host.Status.ErrorType == metal3v1alpha1.PowerManagementError)
+		provResult, err = power.PowerOn(info.host.Status.ErrorType == metal3v1alpha1.PowerManagementError)
 	} else {
 		if info.host.Status.ErrorCount > 0 {
 			desiredRebootMode = metal3v1alpha1.RebootModeHard
 		}
-		provResult, err = prov.PowerOff(desiredRebootMode, info.host.Status.ErrorType == metal3v1alpha1.PowerManagementError)
+		provResult, err = power.PowerOff(desiredRebootMode, info.host.Status.ErrorType == metal3v1alpha1.PowerManagementError)
 	}
 	if err != nil {
 		return actionError{errors.Wrap(err, "failed to manage power state of host")}
@@ -1082,12 +1266,9 @@ func (r *BareMetalHostReconciler) actionManageReady(prov provisioner.Provisioner
 	return r.manageHostPower(prov, info)
 }
 
-func getHostProvisioningSettings(host *metal3v1alpha1.BareMetalHost) (dirty bool, status *metal3v1alpha1.BareMetalHostStatus, err error) {
+func getHostProvisioningSettings(host *metal3v1alpha1.BareMetalHost, hwProf hardware.Profile) (dirty bool, status *metal3v1alpha1.BareMetalHostStatus) {
 	hostCopy := host.DeepCopy()
-	dirty, err = saveHostProvisioningSettings(hostCopy)
-	if err != nil {
-		err = errors.Wrap(err, "could not determine the host provisioning settings")
-	}
+	dirty = saveHostProvisioningSettings(hostCopy, hwProf)
 	status = &hostCopy.Status
 	return
 }
@@ -1095,7 +1276,7 @@ func getHostProvisioningSettings(host *metal3v1alpha1.BareMetalHost) (dirty bool
 // saveHostProvisioningSettings copies the values related to
 // provisioning that do not trigger re-provisioning into the status
 // fields of the host.
-func saveHostProvisioningSettings(host *metal3v1alpha1.BareMetalHost) (dirty bool, err error) {
+func saveHostProvisioningSettings(host *metal3v1alpha1.BareMetalHost, hwProf hardware.Profile) (dirty bool) {
 
 	// Ensure the root device hints we're going to use are stored.
 	//
@@ -1103,10 +1284,6 @@ func saveHostProvisioningSettings(host *metal3v1alpha1.BareMetalHost) (dirty boo
 	// precedence. Otherwise use the values from the hardware profile.
 	hintSource := host.Spec.RootDeviceHints
 	if hintSource == nil {
-		hwProf, err := hardware.GetProfile(host.HardwareProfile())
-		if err != nil {
-			return false, errors.Wrap(err, "Could not update root device hints")
-		}
 		hintSource = &hwProf.RootDeviceHints
 	}
 	if (hintSource != nil && host.Status.Provisioning.RootDeviceHints == nil) || *hintSource != *(host.Status.Provisioning.RootDeviceHints) {
@@ -1158,6 +1335,16 @@ func saveHostProvisioningSettings(host *metal3v1alpha1.BareMetalHost) (dirty boo
 		dirty = true
 	}
 
+	// Copy firmware updates
+	if !firmwareUpdatesSaved(host) {
+		host.Status.Provisioning.FirmwareUpdates = nil
+		for _, update := range host.Spec.FirmwareUpdates {
+			host.Status.Provisioning.FirmwareUpdates = append(host.Status.Provisioning.FirmwareUpdates,
+				metal3v1alpha1.FirmwareUpdateStatus{FirmwareUpdate: update})
+		}
+		dirty = true
+	}
+
 	return
 }
 
@@ -1209,6 +1396,7 @@ func (r *BareMetalHostReconciler) setErrorCondition(request ctrl.Request, host *
 	reqLogger := r.Log.WithValues("baremetalhost", request.NamespacedName)
 
 	setErrorMessage(host, errType, message)
+	updateConditions(host)
 
 	reqLogger.Info(
 		"adding error message",
@@ -1247,16 +1435,46 @@ func (r *BareMetalHostReconciler) getBMCSecretAndSetOwner(request ctrl.Request,
 }
 
 func credentialsFromSecret(bmcCredsSecret *corev1.Secret) *bmc.Credentials {
-	// We trim surrounding whitespace because those characters are
-	// unlikely to be part of the username or password and it is
-	// common for users to encode the values with a command like
-	//
-	//     echo "my-password" | base64
-	//
-	// which introduces a trailing newline.
-	return &bmc.Credentials{
-		Username: strings.TrimSpace(string(bmcCredsSecret.Data["username"])),
-		Password: strings.TrimSpace(string(bmcCredsSecret.Data["password"])),
+	creds, _, _ := bmc.NewSecretCredentialsProvider(bmcCredsSecret).Credentials()
+	return &creds
+}
+
+// externalCredentialsProvider returns the provider reading the
+// credentials of the host from the external store it references.
+func (r *BareMetalHostReconciler) externalCredentialsProvider(host *metal3v1alpha1.BareMetalHost) (bmc.CredentialsProvider, error) {
+	name := host.Spec.BMC.CredentialsName
+	if name == "" {
+		return nil, &EmptyBMCSecretError{message: "The BMC credentials path is empty"}
+	}
+
+	var provider bmc.CredentialsProvider
+	var err error
+	switch host.Spec.BMC.CredentialsProvider {
+	case metal3v1alpha1.CredentialsProviderVault:
+		provider, err = bmc.NewVaultCredentialsProvider(r.CredentialsStores.Vault, host.Namespace, name)
+	case metal3v1alpha1.CredentialsProviderFile:
+		provider, err = bmc.NewFileCredentialsProvider(r.CredentialsStores.FileDir, host.Namespace, name)
+	default:
+		return nil, &ResolveBMCCredentialsError{
+			message: fmt.Sprintf("unknown provider %q", host.Spec.BMC.CredentialsProvider)}
+	}
+	if err != nil {
+		return nil, &ResolveBMCCredentialsError{message: err.Error()}
+	}
+	return provider, nil
+}
+
+// externalCredentialsRecord returns a Secret standing for credentials
+// read from an external store. It is never stored, but records their
+// name and version so that changes are detected the same way as for
+// credentials held in a Secret.
+func externalCredentialsRecord(host *metal3v1alpha1.BareMetalHost, version string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            host.Spec.BMC.CredentialsName,
+			Namespace:       host.Namespace,
+			ResourceVersion: version,
+		},
 	}
 }
 
@@ -1265,10 +1483,22 @@ func credentialsFromSecret(bmcCredsSecret *corev1.Secret) *bmc.Credentials {
 // to use the credentials.
 func (r *BareMetalHostReconciler) buildAndValidateBMCCredentials(request ctrl.Request, host *metal3v1alpha1.BareMetalHost) (bmcCreds *bmc.Credentials, bmcCredsSecret *corev1.Secret, err error) {
 
-	// Retrieve the BMC secret from Kubernetes for this host
-	bmcCredsSecret, err = r.getBMCSecretAndSetOwner(request, host)
-	if err != nil {
-		return nil, nil, err
+	external := host.Spec.BMC.CredentialsProvider != "" &&
+		host.Spec.BMC.CredentialsProvider != metal3v1alpha1.CredentialsProviderSecret
+
+	var provider bmc.CredentialsProvider
+	if external {
+		provider, err = r.externalCredentialsProvider(host)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		// Retrieve the BMC secret from Kubernetes for this host
+		bmcCredsSecret, err = r.getBMCSecretAndSetOwner(request, host)
+		if err != nil {
+			return nil, nil, err
+		}
+		provider = bmc.NewSecretCredentialsProvider(bmcCredsSecret)
 	}
 
 	// Check for a "discovered" host vs. one that we have all the info for
@@ -1277,17 +1507,207 @@ func (r *BareMetalHostReconciler) buildAndValidateBMCCredentials(request ctrl.Re
 		return nil, nil, &EmptyBMCAddressError{message: "Missing BMC connection detail 'Address'"}
 	}
 
-	bmcCreds = credentialsFromSecret(bmcCredsSecret)
+	creds, version, err := provider.Credentials()
+	if err != nil {
+		return nil, nil, &ResolveBMCCredentialsError{message: err.Error()}
+	}
+	bmcCreds = &creds
+	if external {
+		bmcCredsSecret = externalCredentialsRecord(host, version)
+	}
 
-	// Verify that the secret contains the expected info.
+	// Verify that the secret contains the expected info, and that the
+	// driver of the BMC can use it. An invalid address is reported
+	// when registering the host.
 	err = bmcCreds.Validate()
+	if accessDetails, adErr := bmc.NewAccessDetails(host.Spec.BMC.Address,
+		host.Spec.BMC.DisableCertificateVerification); err == nil && adErr == nil {
+		err = accessDetails.ValidateCredentials(*bmcCreds)
+	}
 	if err != nil {
+		if external {
+			// Changes to an external store do not trigger a
+			// reconcile, so make sure the host is requeued.
+			return nil, nil, &ResolveBMCCredentialsError{message: err.Error()}
+		}
 		return nil, bmcCredsSecret, err
 	}
 
 	return bmcCreds, bmcCredsSecret, nil
 }
 
+// getBMCCABundle returns the CA certificates the host references to
+// verify the certificate of its BMC, if any.
+func (r *BareMetalHostReconciler) getBMCCABundle(host *metal3v1alpha1.BareMetalHost) ([]byte, error) {
+	ref := host.Spec.BMC.CABundle
+	if ref == nil || host.Spec.BMC.DisableCertificateVerification {
+		return nil, nil
+	}
+
+	key := ref.Key
+	if key == "" {
+		key = metal3v1alpha1.DefaultCABundleKey
+	}
+	objKey := types.NamespacedName{Namespace: host.Namespace, Name: ref.Name}
+	description := fmt.Sprintf("%s %s", ref.Kind, objKey)
+
+	var bundle []byte
+	switch ref.Kind {
+	case metal3v1alpha1.CABundleKindSecret:
+		secret, err := getSecret(r.Client, r.APIReader, objKey)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				return nil, &ResolveBMCCABundleError{message: description}
+			}
+			return nil, errors.Wrap(err, "failed to fetch the BMC CA bundle")
+		}
+		bundle = secret.Data[key]
+	case metal3v1alpha1.CABundleKindConfigMap:
+		// Reading through the API avoids caching every ConfigMap
+		configMap := &corev1.ConfigMap{}
+		if err := r.APIReader.Get(context.TODO(), objKey, configMap); err != nil {
+			if k8serrors.IsNotFound(err) {
+				return nil, &ResolveBMCCABundleError{message: description}
+			}
+			return nil, errors.Wrap(err, "failed to fetch the BMC CA bundle")
+		}
+		bundle = []byte(configMap.Data[key])
+	default:
+		return nil, &ResolveBMCCABundleError{message: fmt.Sprintf("unknown kind %q", ref.Kind)}
+	}
+
+	if !x509.NewCertPool().AppendCertsFromPEM(bundle) {
+		return nil, &ResolveBMCCABundleError{
+			message: fmt.Sprintf("%s has no certificate under key %s", description, key)}
+	}
+	return bundle, nil
+}
+
+// certificateRead is a read of the certificate of a BMC running in the
+// background, since the BMC may take a while to answer.
+type certificateRead struct {
+	address string
+	// pin records the fingerprint instead of comparing it with the
+	// recorded one
+	pin     bool
+	started time.Time
+	done    chan struct{}
+
+	fingerprint string
+	err         error
+
+	// checked is set once the result has been handled
+	checked bool
+}
+
+// startBMCCertificateRead starts reading the certificate presented by
+// the BMC, replacing any previous read for the host.
+func (r *BareMetalHostReconciler) startBMCCertificateRead(info *reconcileInfo, pin bool) {
+	read := &certificateRead{
+		address: info.host.Spec.BMC.Address,
+		pin:     pin,
+		started: time.Now(),
+		done:    make(chan struct{}),
+	}
+	r.certificateReads.Store(info.host.UID, read)
+	go func() {
+		read.fingerprint, read.err = bmc.CertificateFingerprint(read.address)
+		close(read.done)
+	}()
+}
+
+// checkBMCCertificate reads the certificate presented by the BMC when
+// new credentials are validated, to pin it, and periodically afterwards
+// to check that it did not change. It returns whether the status of
+// the host changed.
+func (r *BareMetalHostReconciler) checkBMCCertificate(info *reconcileInfo, registeredNewCreds bool) bool {
+	value, ok := r.certificateReads.Load(info.host.UID)
+	if !ok || registeredNewCreds || value.(*certificateRead).address != info.host.Spec.BMC.Address {
+		// The certificate of a new BMC address is pinned as for new
+		// credentials. Without a previous read, after a restart of
+		// the operator, it is compared with the pinned one.
+		addressChanged := ok && !registeredNewCreds
+		r.startBMCCertificateRead(info, registeredNewCreds || addressChanged)
+		return false
+	}
+
+	read := value.(*certificateRead)
+	select {
+	case <-read.done:
+	default:
+		return false
+	}
+	if read.checked {
+		if time.Since(read.started) >= bmcCertificateCheckInterval {
+			r.startBMCCertificateRead(info, false)
+		}
+		return false
+	}
+	read.checked = true
+
+	if read.err != nil {
+		info.log.Info("could not read the certificate of the BMC", "reason", read.err.Error())
+		return false
+	}
+	return r.recordBMCCertificate(info, read)
+}
+
+// recordBMCCertificate pins the fingerprint of the certificate read
+// from the BMC, or compares it with the pinned one and reports a
+// mismatch. It returns whether the status of the host changed.
+func (r *BareMetalHostReconciler) recordBMCCertificate(info *reconcileInfo, read *certificateRead) bool {
+	pinned := info.host.Status.BMCCertificateFingerprint
+	var cond hostCondition
+	switch {
+	case read.fingerprint == "":
+		// The BMC is not reached over TLS
+		return false
+	case read.pin || pinned == "":
+		cond = conditionTrue("Pinned")
+		cond.message = "The certificate presented by the BMC is pinned"
+	case read.fingerprint != pinned:
+		cond = conditionFalse("CertificateChanged")
+		cond.message = fmt.Sprintf("The BMC presents a certificate with fingerprint %s instead of the pinned %s",
+			read.fingerprint, pinned)
+	default:
+		cond = conditionTrue("Unchanged")
+		cond.message = "The BMC presents the pinned certificate"
+	}
+
+	changed := false
+	if cond.status == metav1.ConditionTrue && read.fingerprint != pinned {
+		info.log.Info("pinning the BMC certificate", "fingerprint", read.fingerprint)
+		info.host.Status.BMCCertificateFingerprint = read.fingerprint
+		changed = true
+	}
+	if setBMCCertificateCondition(info.host, cond) {
+		if cond.status == metav1.ConditionFalse {
+			info.publishEvent("BMCCertificateChanged", cond.message)
+		}
+		changed = true
+	}
+	return changed
+}
+
+// setBMCCertificateCondition sets the BMCCertificateUnchanged condition
+// of the host. It returns true when the condition changed.
+func setBMCCertificateCondition(host *metal3v1alpha1.BareMetalHost, cond hostCondition) bool {
+	condType := metal3v1alpha1.BMCCertificateUnchangedCondition
+	existing := meta.FindStatusCondition(host.Status.Conditions, condType)
+	if existing != nil && existing.Status == cond.status && existing.Reason == cond.reason &&
+		existing.Message == cond.message {
+		return false
+	}
+	meta.SetStatusCondition(&host.Status.Conditions, metav1.Condition{
+		Type:               condType,
+		Status:             cond.status,
+		ObservedGeneration: host.Generation,
+		Reason:             cond.reason,
+		Message:            cond.message,
+	})
+	return true
+}
+
 func (r *BareMetalHostReconciler) setBMCCredentialsSecretOwner(request ctrl.Request, host *metal3v1alpha1.BareMetalHost, secret *corev1.Secret) (err error) {
 	reqLogger := r.Log.WithValues("baremetalhost", request.NamespacedName)
 	if metav1.IsControlledBy(secret, host) && metav1.HasLabel(secret.ObjectMeta, LabelEnvironmentName) {
@@ -1388,5 +1808,6 @@ func (r *BareMetalHostReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			}).
 		WithOptions(opts).
 		Owns(&corev1.Secret{}).
+		Owns(&metal3v1alpha1.HostFirmwareSettings{}).
 		Complete(r)
 }
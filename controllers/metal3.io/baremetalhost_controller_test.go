@@ -5,14 +5,23 @@ import (
 	goctx "context"
 	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	corev1 "k8s.io/api/core/v1"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -25,6 +34,7 @@ import (
 
 	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+	"github.com/metal3-io/baremetal-operator/pkg/hardware"
 	"github.com/metal3-io/baremetal-operator/pkg/provisioner/fixture"
 	"github.com/metal3-io/baremetal-operator/pkg/utils"
 )
@@ -419,6 +429,55 @@ func TestInspectEnabled(t *testing.T) {
 	assert.NotNil(t, host.Status.HardwareDetails)
 }
 
+// TestMatchProfile ensures that the HardwareProfile best matching the
+// inspected hardware details is used for the host, along with its
+// root device hints.
+func TestMatchProfile(t *testing.T) {
+	host := newDefaultHost(t)
+	bigRAM := &metal3v1alpha1.HardwareProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "big-ram", Namespace: namespace},
+		Spec: metal3v1alpha1.HardwareProfileSpec{
+			Match: metal3v1alpha1.HardwareProfileMatch{
+				RAMMebibytes: &metal3v1alpha1.IntRange{Min: 64 * 1024},
+				NICCount:     &metal3v1alpha1.IntRange{Min: 2},
+			},
+			RootDeviceHints: &metal3v1alpha1.RootDeviceHints{HCTL: "1:0:0:0"},
+		},
+	}
+	smallRAM := &metal3v1alpha1.HardwareProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "small-ram", Namespace: namespace},
+		Spec: metal3v1alpha1.HardwareProfileSpec{
+			Match: metal3v1alpha1.HardwareProfileMatch{
+				RAMMebibytes: &metal3v1alpha1.IntRange{Max: 1024},
+			},
+			Priority: 10,
+		},
+	}
+	r := newTestReconciler(host, bigRAM, smallRAM)
+
+	waitForProvisioningState(t, r, host, metal3v1alpha1.StateReady)
+	assert.Equal(t, "big-ram", host.Status.HardwareProfile)
+	assert.Equal(t, "1:0:0:0", host.Status.Provisioning.RootDeviceHints.HCTL)
+}
+
+// TestMatchProfileDefault ensures that the default profile is used
+// when no HardwareProfile matches the host.
+func TestMatchProfileDefault(t *testing.T) {
+	host := newDefaultHost(t)
+	profile := &metal3v1alpha1.HardwareProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-vendor", Namespace: namespace},
+		Spec: metal3v1alpha1.HardwareProfileSpec{
+			Match: metal3v1alpha1.HardwareProfileMatch{
+				Manufacturer: "other vendor",
+			},
+		},
+	}
+	r := newTestReconciler(host, profile)
+
+	waitForProvisioningState(t, r, host, metal3v1alpha1.StateReady)
+	assert.Equal(t, hardware.DefaultProfileName, host.Status.HardwareProfile)
+}
+
 // TestAddFinalizers ensures that the finalizers for the host are
 // updated as part of reconciling it.
 func TestAddFinalizers(t *testing.T) {
@@ -813,6 +872,84 @@ func TestUpdateGoodCredentialsOnBadSecret(t *testing.T) {
 	)
 }
 
+// TestUnsupportedCredentials ensures that a host whose credentials
+// cannot be used by the driver of its BMC is put in an error state.
+func TestUnsupportedCredentials(t *testing.T) {
+	host := newDefaultHost(t)
+	host.Spec.BMC.CredentialsName = "bmc-creds-token"
+	r := newTestReconciler(host, newSecret("bmc-creds-token", map[string]string{"token": "session-token"}))
+
+	waitForError(t, r, host)
+	host = loadHostByName(t, r, host.Name)
+	assert.Equal(t, metal3v1alpha1.RegistrationError, host.Status.ErrorType)
+	assert.Contains(t, host.Status.ErrorMessage, "does not support token authentication")
+
+	// Ironic does not accept the token with Redfish either
+	host.Spec.BMC.Address = "redfish://192.168.122.1/redfish/v1/Systems/1"
+	host.Spec.BootMACAddress = "11:22:33:44:55:66"
+	assert.NoError(t, r.Update(goctx.TODO(), host))
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return strings.Contains(host.Status.ErrorMessage, "token authentication for BMC type 'redfish'")
+		},
+	)
+	assert.Equal(t, metal3v1alpha1.RegistrationError, host.Status.ErrorType)
+}
+
+// TestExternalCredentials ensures that the credentials of a host can be
+// read from an external store, and that changing them there leads to
+// their validation.
+func TestExternalCredentials(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "bmc-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+	dir := filepath.Join(baseDir, namespace, "rack-1", "host-0")
+	writeCredential := func(key, value string) {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, key), []byte(value), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	host := newDefaultHost(t)
+	host.Spec.BMC.CredentialsProvider = metal3v1alpha1.CredentialsProviderFile
+	host.Spec.BMC.CredentialsName = "rack-1/host-0"
+	r := newTestReconciler(host)
+	r.CredentialsStores.FileDir = baseDir
+
+	// The credentials do not exist yet
+	waitForError(t, r, host)
+
+	writeCredential("username", "User")
+	writeCredential("password", "Pass")
+	waitForNoError(t, r, host)
+	var version string
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			version = host.Status.GoodCredentials.Version
+			return version != ""
+		},
+	)
+	assert.Equal(t, "rack-1/host-0", host.Status.GoodCredentials.Reference.Name)
+
+	writeCredential("password", "NewPass")
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return host.Status.GoodCredentials.Version != version
+		},
+	)
+
+	// The credentials of another namespace cannot be used
+	host = loadHostByName(t, r, host.Name)
+	host.Spec.BMC.CredentialsName = "../other/host-0"
+	assert.NoError(t, r.Update(goctx.TODO(), host))
+	waitForError(t, r, host)
+}
+
 // TestDiscoveredHost ensures that a host without a BMC IP and
 // credentials is placed into the "discovered" state.
 func TestDiscoveredHost(t *testing.T) {
@@ -1008,6 +1145,156 @@ func TestBreakThenFixSecret(t *testing.T) {
 
 }
 
+// TestBMCCABundle ensures that a host referencing a missing CA bundle
+// reports an error until the bundle is created.
+func TestBMCCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	host := newDefaultHost(t)
+	host.Spec.BMC.CABundle = &metal3v1alpha1.CABundleReference{
+		Kind: metal3v1alpha1.CABundleKindConfigMap,
+		Name: "bmc-ca",
+	}
+	r := newTestReconciler(host)
+	waitForError(t, r, host)
+
+	host = loadHostByName(t, r, host.Name)
+	assert.Equal(t, metal3v1alpha1.RegistrationError, host.Status.ErrorType)
+	assert.Contains(t, host.Status.ErrorMessage, "bmc-ca")
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "bmc-ca", Namespace: namespace},
+		Data:       map[string]string{metal3v1alpha1.DefaultCABundleKey: string(bundle)},
+	}
+	assert.NoError(t, r.Create(goctx.TODO(), configMap))
+	waitForNoError(t, r, host)
+
+	bundleFromHost, err := r.getBMCCABundle(host)
+	assert.NoError(t, err)
+	assert.Equal(t, bundle, bundleFromHost)
+}
+
+// TestBMCCertificateFingerprint ensures the fingerprint of the BMC
+// certificate is recorded once access to the BMC is validated.
+func TestBMCCertificateFingerprint(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	host := newDefaultHost(t)
+	host.Spec.BMC.Address = fmt.Sprintf("redfish://%s/redfish/v1/Systems/1",
+		strings.TrimPrefix(server.URL, "https://"))
+	r := newTestReconciler(host)
+
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return host.Status.GoodCredentials.Reference != nil
+		},
+	)
+
+	// The certificate is read in the background and recorded by a
+	// following reconcile
+	read, ok := r.certificateReads.Load(host.UID)
+	if !assert.True(t, ok) {
+		return
+	}
+	<-read.(*certificateRead).done
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return host.Status.BMCCertificateFingerprint != ""
+		},
+	)
+
+	host = loadHostByName(t, r, host.Name)
+	expected, err := bmc.CertificateFingerprint(host.Spec.BMC.Address)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, host.Status.BMCCertificateFingerprint)
+	cond := meta.FindStatusCondition(host.Status.Conditions, metal3v1alpha1.BMCCertificateUnchangedCondition)
+	if assert.NotNil(t, cond) {
+		assert.Equal(t, metav1.ConditionTrue, cond.Status)
+		assert.Equal(t, "Pinned", cond.Reason)
+	}
+}
+
+// waitForBMCCertificateCheck makes the next reconcile read the
+// certificate of the BMC again, and waits for the read to finish.
+func waitForBMCCertificateCheck(t *testing.T, r *BareMetalHostReconciler, host *metal3v1alpha1.BareMetalHost) {
+	previous, _ := r.certificateReads.Load(host.UID)
+	previous.(*certificateRead).started = time.Now().Add(-bmcCertificateCheckInterval)
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			read, _ := r.certificateReads.Load(host.UID)
+			return read != previous
+		},
+	)
+	read, _ := r.certificateReads.Load(host.UID)
+	<-read.(*certificateRead).done
+}
+
+// TestBMCCertificateChanged ensures that the certificate of the BMC is
+// checked periodically against the pinned one, that a change is
+// reported, and that the read is forgotten once the host is deleted.
+func TestBMCCertificateChanged(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	host := newDefaultHost(t)
+	host.Spec.BMC.Address = fmt.Sprintf("redfish://%s/redfish/v1/Systems/1",
+		strings.TrimPrefix(server.URL, "https://"))
+	r := newTestReconciler(host)
+
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return host.Status.GoodCredentials.Reference != nil
+		},
+	)
+	read, _ := r.certificateReads.Load(host.UID)
+	<-read.(*certificateRead).done
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return host.Status.BMCCertificateFingerprint != ""
+		},
+	)
+
+	// The BMC presents the pinned certificate
+	waitForBMCCertificateCheck(t, r, host)
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			cond := meta.FindStatusCondition(host.Status.Conditions, metal3v1alpha1.BMCCertificateUnchangedCondition)
+			return cond != nil && cond.Reason == "Unchanged"
+		},
+	)
+
+	// The BMC presents another certificate than the pinned one
+	host = loadHostByName(t, r, host.Name)
+	host.Status.BMCCertificateFingerprint = "00:11:22"
+	assert.NoError(t, r.Status().Update(goctx.TODO(), host))
+	waitForBMCCertificateCheck(t, r, host)
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			cond := meta.FindStatusCondition(host.Status.Conditions, metal3v1alpha1.BMCCertificateUnchangedCondition)
+			return cond != nil && cond.Status == metav1.ConditionFalse
+		},
+	)
+	host = loadHostByName(t, r, host.Name)
+	assert.Equal(t, "00:11:22", host.Status.BMCCertificateFingerprint)
+	cond := meta.FindStatusCondition(host.Status.Conditions, metal3v1alpha1.BMCCertificateUnchangedCondition)
+	assert.Equal(t, "CertificateChanged", cond.Reason)
+	assert.Contains(t, cond.Message, "instead of the pinned 00:11:22")
+
+	now := metav1.Now()
+	host.DeletionTimestamp = &now
+	assert.NoError(t, r.Update(goctx.TODO(), host))
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return host == nil
+		},
+	)
+	_, ok := r.certificateReads.Load(host.UID)
+	assert.False(t, ok)
+}
+
 // TestSetHardwareProfile ensures that the host has a label with
 // the hardware profile name.
 func TestSetHardwareProfile(t *testing.T) {
@@ -1168,6 +1455,31 @@ func TestProvision(t *testing.T) {
 	)
 }
 
+// TestConditions ensures that the conditions in the status block
+// follow the host through provisioning.
+func TestConditions(t *testing.T) {
+	host := newDefaultHost(t)
+	host.Spec.Image = &metal3v1alpha1.Image{
+		URL:      "https://example.com/image-name",
+		Checksum: "12345",
+	}
+	host.Spec.Online = true
+	r := newTestReconciler(host)
+
+	waitForProvisioningState(t, r, host, metal3v1alpha1.StateProvisioned)
+
+	for _, condType := range []string{
+		metal3v1alpha1.RegisteredCondition,
+		metal3v1alpha1.CredentialsValidCondition,
+		metal3v1alpha1.InspectedCondition,
+		metal3v1alpha1.PreparedCondition,
+		metal3v1alpha1.ProvisionedCondition,
+	} {
+		assert.True(t, meta.IsStatusConditionTrue(host.Status.Conditions, condType), condType)
+	}
+	assert.True(t, meta.IsStatusConditionFalse(host.Status.Conditions, metal3v1alpha1.DetachedCondition))
+}
+
 // TestProvisionCustomDeploy ensures that the Provisioning.CustomDeploy portion
 // of the status block is filled in for provisioned hosts.
 func TestProvisionCustomDeploy(t *testing.T) {
@@ -1466,17 +1778,16 @@ func TestUpdateRootDeviceHints(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.Scenario, func(t *testing.T) {
-			dirty, newStatus, err := getHostProvisioningSettings(&tc.Host)
+			hwProf, err := hardware.GetProfile(tc.Host.HardwareProfile())
 			if err != nil {
 				t.Fatal(err)
 			}
+
+			dirty, newStatus := getHostProvisioningSettings(&tc.Host, hwProf)
 			assert.Equal(t, tc.Dirty, dirty, "dirty flag did not match")
 			assert.Equal(t, tc.Expected, newStatus.Provisioning.RootDeviceHints)
 
-			dirty, err = saveHostProvisioningSettings(&tc.Host)
-			if err != nil {
-				t.Fatal(err)
-			}
+			dirty = saveHostProvisioningSettings(&tc.Host, hwProf)
 			assert.Equal(t, tc.Dirty, dirty, "dirty flag did not match")
 			assert.Equal(t, tc.Expected, tc.Host.Status.Provisioning.RootDeviceHints)
 		})
@@ -2130,7 +2441,7 @@ func TestUpdateRAID(t *testing.T) {
 		t.Run(c.name, func(t *testing.T) {
 			host.Spec.RAID = c.specRAID
 			host.Status.Provisioning.RAID = c.statusRAID
-			dirty, _ := saveHostProvisioningSettings(&host)
+			dirty := saveHostProvisioningSettings(&host, hardware.Profile{})
 			assert.Equal(t, c.dirty, dirty)
 			assert.Equal(t, c.expected, host.Status.Provisioning.RAID)
 		})
@@ -2223,3 +2534,28 @@ func TestCredentialsFromSecret(t *testing.T) {
 		})
 	}
 }
+
+// TestRegisterNetworkData ensures that the network data is only read
+// when registering hosts booted from virtual media.
+func TestRegisterNetworkData(t *testing.T) {
+	host := newDefaultHost(t)
+	host.Spec.BootMACAddress = "11:22:33:44:55:66"
+	host.Spec.NetworkData = &corev1.SecretReference{Name: "missing", Namespace: namespace}
+	r := newTestReconciler(host)
+
+	waitForProvisioningState(t, r, host, metal3v1alpha1.StateInspecting)
+	assert.Equal(t, "", host.Status.ErrorMessage)
+
+	host = newDefaultNamedHost("virtual-media", t)
+	host.Spec.BMC.Address = "redfish-virtualmedia://192.168.122.1/redfish/v1/Systems/1"
+	host.Spec.NetworkData = &corev1.SecretReference{Name: "missing", Namespace: namespace}
+	r = newTestReconciler(host)
+
+	var err error
+	for i := 0; i < 5 && err == nil; i++ {
+		_, err = r.Reconcile(context.Background(), newRequest(host))
+	}
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "failed to read the network data")
+	}
+}
@@ -0,0 +1,289 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/utils"
+)
+
+const claimKind = "BareMetalHostClaim"
+
+// BareMetalHostClaimReconciler binds BareMetalHostClaims to hosts
+// meeting their requirements
+type BareMetalHostClaimReconciler struct {
+	client.Client
+	Log       logr.Logger
+	APIReader client.Reader
+}
+
+// +kubebuilder:rbac:groups=metal3.io,resources=baremetalhostclaims,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=metal3.io,resources=baremetalhostclaims/status,verbs=get;update;patch
+
+// Reconcile binds the claim to an available host by setting the
+// ConsumerRef of the host, and releases the host when the claim is
+// deleted.
+func (r *BareMetalHostClaimReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("baremetalhostclaim", request.NamespacedName)
+
+	claim := &metal3v1alpha1.BareMetalHostClaim{}
+	err := r.Get(ctx, request.NamespacedName, claim)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, errors.Wrap(err, "could not load claim")
+	}
+
+	hosts := &metal3v1alpha1.BareMetalHostList{}
+	if err := r.List(ctx, hosts, client.InNamespace(claim.Namespace)); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "could not list hosts")
+	}
+	bound := boundHost(claim, hosts.Items)
+	if bound == nil {
+		// The cached list may not show a binding made by an earlier
+		// reconcile yet, even when saving it in the status of the
+		// claim failed, so the hosts are listed directly before one
+		// is selected to avoid binding a second one.
+		hosts = &metal3v1alpha1.BareMetalHostList{}
+		if err := r.APIReader.List(ctx, hosts, client.InNamespace(claim.Namespace)); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "could not list hosts")
+		}
+		bound = boundHost(claim, hosts.Items)
+	}
+
+	if !claim.DeletionTimestamp.IsZero() {
+		if bound != nil {
+			reqLogger.Info("releasing host", "host", bound.Name)
+			bound.Spec.ConsumerRef = nil
+			if err := r.Update(ctx, bound); err != nil {
+				return ctrl.Result{}, errors.Wrap(err, "failed to release host")
+			}
+		}
+		if utils.StringInList(claim.Finalizers, metal3v1alpha1.BareMetalHostClaimFinalizer) {
+			claim.Finalizers = utils.FilterStringFromList(
+				claim.Finalizers, metal3v1alpha1.BareMetalHostClaimFinalizer)
+			if err := r.Update(ctx, claim); err != nil {
+				return ctrl.Result{}, errors.Wrap(err, "failed to remove finalizer")
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if !utils.StringInList(claim.Finalizers, metal3v1alpha1.BareMetalHostClaimFinalizer) {
+		claim.Finalizers = append(claim.Finalizers, metal3v1alpha1.BareMetalHostClaimFinalizer)
+		if err := r.Update(ctx, claim); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to add finalizer")
+		}
+	}
+
+	if bound == nil {
+		selector := labels.Everything()
+		if claim.Spec.Selector != nil {
+			selector, err = metav1.LabelSelectorAsSelector(claim.Spec.Selector)
+			if err != nil {
+				// There is no point retrying until the claim changes.
+				return ctrl.Result{}, r.setClaimStatus(ctx, claim, metal3v1alpha1.ClaimPhasePending, "",
+					"invalid selector: "+err.Error())
+			}
+		}
+
+		bound = selectHost(claim, selector, hosts.Items)
+		if bound == nil {
+			return ctrl.Result{}, r.setClaimStatus(ctx, claim, metal3v1alpha1.ClaimPhasePending, "",
+				"no available host matches the claim")
+		}
+
+		// The update is rejected if the host changed since it was
+		// read, so the host cannot be reserved by another claim or
+		// consumer at the same time.
+		reqLogger.Info("binding host", "host", bound.Name)
+		bound.Spec.ConsumerRef = claimReference(claim)
+		if err := r.Update(ctx, bound); err != nil {
+			if k8serrors.IsConflict(err) {
+				reqLogger.Info("host changed while binding, retrying", "host", bound.Name)
+				return ctrl.Result{Requeue: true}, nil
+			}
+			return ctrl.Result{}, errors.Wrap(err, "failed to bind host")
+		}
+	}
+
+	return ctrl.Result{}, r.setClaimStatus(ctx, claim, metal3v1alpha1.ClaimPhaseBound, bound.Name, "")
+}
+
+// setClaimStatus saves the status of the claim if it changed
+func (r *BareMetalHostClaimReconciler) setClaimStatus(ctx context.Context, claim *metal3v1alpha1.BareMetalHostClaim, phase metal3v1alpha1.ClaimPhase, hostName, message string) error {
+	newStatus := metal3v1alpha1.BareMetalHostClaimStatus{
+		Phase:    phase,
+		HostName: hostName,
+		Message:  message,
+	}
+	if claim.Status == newStatus {
+		return nil
+	}
+	claim.Status = newStatus
+	return errors.Wrap(r.Status().Update(ctx, claim), "failed to update claim status")
+}
+
+// claimReference returns the ConsumerRef of hosts bound to the claim
+func claimReference(claim *metal3v1alpha1.BareMetalHostClaim) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: metal3v1alpha1.GroupVersion.String(),
+		Kind:       claimKind,
+		Namespace:  claim.Namespace,
+		Name:       claim.Name,
+		UID:        claim.UID,
+	}
+}
+
+// isClaimReference checks whether the ConsumerRef of a host refers to
+// a claim
+func isClaimReference(ref *corev1.ObjectReference) bool {
+	return ref != nil && ref.Kind == claimKind && ref.APIVersion == metal3v1alpha1.GroupVersion.String()
+}
+
+// boundHost returns the host bound to the claim, if any
+func boundHost(claim *metal3v1alpha1.BareMetalHostClaim, hosts []metal3v1alpha1.BareMetalHost) *metal3v1alpha1.BareMetalHost {
+	for i := range hosts {
+		ref := hosts[i].Spec.ConsumerRef
+		if isClaimReference(ref) && ref.Namespace == claim.Namespace && ref.Name == claim.Name && ref.UID == claim.UID {
+			return &hosts[i]
+		}
+	}
+	return nil
+}
+
+// selectHost returns the first host, by name, that can be bound to the
+// claim
+func selectHost(claim *metal3v1alpha1.BareMetalHostClaim, selector labels.Selector, hosts []metal3v1alpha1.BareMetalHost) *metal3v1alpha1.BareMetalHost {
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Name < hosts[j].Name })
+	for i := range hosts {
+		if hostMatchesClaim(&hosts[i], claim, selector) {
+			return &hosts[i]
+		}
+	}
+	return nil
+}
+
+// hostMatchesClaim checks whether the host is available and meets the
+// requirements of the claim
+func hostMatchesClaim(host *metal3v1alpha1.BareMetalHost, claim *metal3v1alpha1.BareMetalHostClaim, selector labels.Selector) bool {
+	switch {
+	case !host.DeletionTimestamp.IsZero(), host.Spec.ConsumerRef != nil, host.Status.ErrorType != "":
+		return false
+	case host.Status.Provisioning.State != metal3v1alpha1.StateReady &&
+		host.Status.Provisioning.State != metal3v1alpha1.StateAvailable:
+		return false
+	case !selector.Matches(labels.Set(host.Labels)):
+		return false
+	case claim.Spec.HardwareProfile != "" && claim.Spec.HardwareProfile != host.Status.HardwareProfile:
+		return false
+	}
+
+	spec := claim.Spec
+	if spec.MinRAMMebibytes == 0 && spec.MinCPUCount == 0 && spec.DiskType == "" && spec.MinNICSpeedGbps == 0 {
+		return true
+	}
+	details := host.Status.HardwareDetails
+	if details == nil || details.RAMMebibytes < spec.MinRAMMebibytes || details.CPU.Count < spec.MinCPUCount {
+		return false
+	}
+	if spec.DiskType != "" {
+		found := false
+		for _, disk := range details.Storage {
+			if disk.Type == spec.DiskType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if spec.MinNICSpeedGbps != 0 {
+		found := false
+		for _, nic := range details.NIC {
+			if nic.SpeedGbps >= spec.MinNICSpeedGbps {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// hostToClaims returns the claims to reconcile when a host changes:
+// the claim it is bound to, or the pending claims of its namespace
+// and the claim it was bound to when it may have become available.
+func (r *BareMetalHostClaimReconciler) hostToClaims(obj client.Object) []reconcile.Request {
+	host, ok := obj.(*metal3v1alpha1.BareMetalHost)
+	if !ok {
+		return nil
+	}
+
+	if ref := host.Spec.ConsumerRef; isClaimReference(ref) {
+		return []reconcile.Request{
+			{NamespacedName: types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}},
+		}
+	}
+	if host.Spec.ConsumerRef != nil {
+		return nil
+	}
+
+	claims := &metal3v1alpha1.BareMetalHostClaimList{}
+	if err := r.List(context.TODO(), claims, client.InNamespace(host.Namespace)); err != nil {
+		r.Log.Error(err, "failed to list claims", "namespace", host.Namespace)
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, claim := range claims.Items {
+		if claim.Status.Phase != metal3v1alpha1.ClaimPhaseBound || claim.Status.HostName == host.Name {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager registers the reconciler to be run by the manager
+func (r *BareMetalHostClaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&metal3v1alpha1.BareMetalHostClaim{}).
+		Watches(&source.Kind{Type: &metal3v1alpha1.BareMetalHost{}},
+			handler.EnqueueRequestsFromMapFunc(r.hostToClaims)).
+		Complete(r)
+}
@@ -0,0 +1,336 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+func newTestClaimReconciler(initObjs ...runtime.Object) *BareMetalHostClaimReconciler {
+	c := fakeclient.NewFakeClient(initObjs...)
+	return &BareMetalHostClaimReconciler{
+		Client:    c,
+		Log:       ctrl.Log.WithName("controllers").WithName("BareMetalHostClaim"),
+		APIReader: c,
+	}
+}
+
+func newClaim(name string, spec metal3v1alpha1.BareMetalHostClaimSpec) *metal3v1alpha1.BareMetalHostClaim {
+	return &metal3v1alpha1.BareMetalHostClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: spec,
+	}
+}
+
+func newAvailableHost(name string, ramMebibytes int) *metal3v1alpha1.BareMetalHost {
+	host := newHost(name, &metal3v1alpha1.BareMetalHostSpec{})
+	host.Status.Provisioning.State = metal3v1alpha1.StateReady
+	host.Status.HardwareProfile = "unknown"
+	host.Status.HardwareDetails = &metal3v1alpha1.HardwareDetails{
+		RAMMebibytes: ramMebibytes,
+		CPU:          metal3v1alpha1.CPU{Count: 8},
+		NIC:          []metal3v1alpha1.NIC{{Name: "eth0", SpeedGbps: 25}},
+		Storage:      []metal3v1alpha1.Storage{{Name: "/dev/sda", Type: metal3v1alpha1.SSD}},
+	}
+	return host
+}
+
+func reconcileClaim(t *testing.T, r *BareMetalHostClaimReconciler, claim *metal3v1alpha1.BareMetalHostClaim) *metal3v1alpha1.BareMetalHostClaim {
+	key := types.NamespacedName{Name: claim.Name, Namespace: claim.Namespace}
+	_, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+
+	result := &metal3v1alpha1.BareMetalHostClaim{}
+	if err := r.Get(context.TODO(), key, result); err != nil {
+		return nil
+	}
+	return result
+}
+
+func loadHost(t *testing.T, r *BareMetalHostClaimReconciler, name string) *metal3v1alpha1.BareMetalHost {
+	host := &metal3v1alpha1.BareMetalHost{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, host); err != nil {
+		t.Fatal(err)
+	}
+	return host
+}
+
+func TestClaimBindsMatchingHost(t *testing.T) {
+	small := newAvailableHost("a-small", 16*1024)
+	big := newAvailableHost("b-big", 256*1024)
+	claim := newClaim("claim", metal3v1alpha1.BareMetalHostClaimSpec{
+		MinRAMMebibytes: 128 * 1024,
+		DiskType:        metal3v1alpha1.SSD,
+	})
+	r := newTestClaimReconciler(small, big, claim)
+
+	claim = reconcileClaim(t, r, claim)
+	assert.Equal(t, metal3v1alpha1.ClaimPhaseBound, claim.Status.Phase)
+	assert.Equal(t, "b-big", claim.Status.HostName)
+	assert.Contains(t, claim.Finalizers, metal3v1alpha1.BareMetalHostClaimFinalizer)
+
+	host := loadHost(t, r, "b-big")
+	if assert.NotNil(t, host.Spec.ConsumerRef) {
+		assert.Equal(t, "BareMetalHostClaim", host.Spec.ConsumerRef.Kind)
+		assert.Equal(t, "claim", host.Spec.ConsumerRef.Name)
+	}
+	assert.Nil(t, loadHost(t, r, "a-small").Spec.ConsumerRef)
+
+	// Reconciling again keeps the same host
+	claim = reconcileClaim(t, r, claim)
+	assert.Equal(t, "b-big", claim.Status.HostName)
+}
+
+func TestClaimsDoNotShareHosts(t *testing.T) {
+	host := newAvailableHost("host", 16*1024)
+	first := newClaim("first", metal3v1alpha1.BareMetalHostClaimSpec{})
+	second := newClaim("second", metal3v1alpha1.BareMetalHostClaimSpec{})
+	r := newTestClaimReconciler(host, first, second)
+
+	first = reconcileClaim(t, r, first)
+	assert.Equal(t, metal3v1alpha1.ClaimPhaseBound, first.Status.Phase)
+
+	second = reconcileClaim(t, r, second)
+	assert.Equal(t, metal3v1alpha1.ClaimPhasePending, second.Status.Phase)
+	assert.Empty(t, second.Status.HostName)
+	assert.Equal(t, "first", loadHost(t, r, "host").Spec.ConsumerRef.Name)
+}
+
+func TestClaimBindConflict(t *testing.T) {
+	host := newAvailableHost("host", 16*1024)
+	claim := newClaim("claim", metal3v1alpha1.BareMetalHostClaimSpec{})
+	r := newTestClaimReconciler(host, claim)
+
+	// Another consumer takes the host after the claim controller
+	// read it.
+	stale := loadHost(t, r, "host")
+	current := stale.DeepCopy()
+	current.Spec.ConsumerRef = &corev1.ObjectReference{Kind: "Machine", Name: "other"}
+	assert.NoError(t, r.Update(context.TODO(), current))
+
+	stale.Spec.ConsumerRef = claimReference(claim)
+	err := r.Update(context.TODO(), stale)
+	assert.Error(t, err, "stale host updates must be rejected")
+
+	claim = reconcileClaim(t, r, claim)
+	assert.Equal(t, metal3v1alpha1.ClaimPhasePending, claim.Status.Phase)
+	assert.Equal(t, "other", loadHost(t, r, "host").Spec.ConsumerRef.Name)
+}
+
+func TestClaimStaleHostList(t *testing.T) {
+	first := newAvailableHost("a-first", 16*1024)
+	bound := newAvailableHost("b-bound", 16*1024)
+	claim := newClaim("claim", metal3v1alpha1.BareMetalHostClaimSpec{})
+	claim.Status = metal3v1alpha1.BareMetalHostClaimStatus{
+		Phase:    metal3v1alpha1.ClaimPhaseBound,
+		HostName: "b-bound",
+	}
+	r := newTestClaimReconciler(first, bound.DeepCopy(), claim)
+
+	// The cache does not show the binding of the host yet
+	bound.Spec.ConsumerRef = claimReference(claim)
+	r.APIReader = fakeclient.NewFakeClient(first, bound)
+
+	claim = reconcileClaim(t, r, claim)
+	assert.Equal(t, metal3v1alpha1.ClaimPhaseBound, claim.Status.Phase)
+	assert.Equal(t, "b-bound", claim.Status.HostName)
+	assert.Nil(t, loadHost(t, r, "a-first").Spec.ConsumerRef)
+}
+
+// staleClaimClient reads from a cache that does not see the changes
+// written through it, and fails to update statuses when asked to.
+type staleClaimClient struct {
+	client.Client
+	cache      client.Reader
+	failStatus bool
+}
+
+func (c *staleClaimClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return c.cache.Get(ctx, key, obj)
+}
+
+func (c *staleClaimClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.cache.List(ctx, list, opts...)
+}
+
+func (c *staleClaimClient) Status() client.StatusWriter {
+	if c.failStatus {
+		return failingStatusWriter{}
+	}
+	return c.Client.Status()
+}
+
+type failingStatusWriter struct{}
+
+func (failingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return errors.New("status update failed")
+}
+
+func (failingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return errors.New("status update failed")
+}
+
+// TestClaimStatusUpdateFailure ensures that a claim whose status could
+// not be saved after binding a host does not bind another one while
+// the cache does not show the binding.
+func TestClaimStatusUpdateFailure(t *testing.T) {
+	first := newAvailableHost("a-first", 16*1024)
+	second := newAvailableHost("b-second", 16*1024)
+	claim := newClaim("claim", metal3v1alpha1.BareMetalHostClaimSpec{})
+	claim.Finalizers = []string{metal3v1alpha1.BareMetalHostClaimFinalizer}
+	cluster := fakeclient.NewFakeClient(first.DeepCopy(), second.DeepCopy(), claim.DeepCopy())
+	c := &staleClaimClient{
+		Client:     cluster,
+		cache:      fakeclient.NewFakeClient(first, second, claim),
+		failStatus: true,
+	}
+	r := &BareMetalHostClaimReconciler{
+		Client:    c,
+		Log:       ctrl.Log.WithName("controllers").WithName("BareMetalHostClaim"),
+		APIReader: cluster,
+	}
+	key := types.NamespacedName{Name: claim.Name, Namespace: claim.Namespace}
+
+	_, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+	assert.Error(t, err)
+
+	c.failStatus = false
+	_, err = r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+
+	assert.NoError(t, cluster.Get(context.TODO(), key, claim))
+	assert.Equal(t, metal3v1alpha1.ClaimPhaseBound, claim.Status.Phase)
+	assert.Equal(t, "a-first", claim.Status.HostName)
+	for name, bound := range map[string]bool{"a-first": true, "b-second": false} {
+		host := &metal3v1alpha1.BareMetalHost{}
+		assert.NoError(t, cluster.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, host))
+		assert.Equal(t, bound, host.Spec.ConsumerRef != nil, name)
+	}
+}
+
+func TestClaimRequirements(t *testing.T) {
+	host := newAvailableHost("host", 64*1024)
+	host.Labels = map[string]string{"rack": "r1"}
+
+	testCases := []struct {
+		Scenario string
+		Modify   func(*metal3v1alpha1.BareMetalHost)
+		Spec     metal3v1alpha1.BareMetalHostClaimSpec
+		Expected bool
+	}{
+		{
+			Scenario: "no requirements",
+			Expected: true,
+		},
+		{
+			Scenario: "all requirements met",
+			Spec: metal3v1alpha1.BareMetalHostClaimSpec{
+				Selector:        &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "r1"}},
+				MinRAMMebibytes: 64 * 1024,
+				MinCPUCount:     8,
+				DiskType:        metal3v1alpha1.SSD,
+				MinNICSpeedGbps: 25,
+				HardwareProfile: "unknown",
+			},
+			Expected: true,
+		},
+		{
+			Scenario: "labels",
+			Spec: metal3v1alpha1.BareMetalHostClaimSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "r2"}},
+			},
+		},
+		{
+			Scenario: "cpu",
+			Spec:     metal3v1alpha1.BareMetalHostClaimSpec{MinCPUCount: 16},
+		},
+		{
+			Scenario: "disk type",
+			Spec:     metal3v1alpha1.BareMetalHostClaimSpec{DiskType: metal3v1alpha1.NVME},
+		},
+		{
+			Scenario: "nic speed",
+			Spec:     metal3v1alpha1.BareMetalHostClaimSpec{MinNICSpeedGbps: 100},
+		},
+		{
+			Scenario: "hardware profile",
+			Spec:     metal3v1alpha1.BareMetalHostClaimSpec{HardwareProfile: "dell"},
+		},
+		{
+			Scenario: "not inspected",
+			Modify:   func(h *metal3v1alpha1.BareMetalHost) { h.Status.HardwareDetails = nil },
+			Spec:     metal3v1alpha1.BareMetalHostClaimSpec{MinCPUCount: 1},
+		},
+		{
+			Scenario: "available",
+			Modify: func(h *metal3v1alpha1.BareMetalHost) {
+				h.Status.Provisioning.State = metal3v1alpha1.StateAvailable
+			},
+			Expected: true,
+		},
+		{
+			Scenario: "provisioned",
+			Modify: func(h *metal3v1alpha1.BareMetalHost) {
+				h.Status.Provisioning.State = metal3v1alpha1.StateProvisioned
+			},
+		},
+		{
+			Scenario: "error",
+			Modify: func(h *metal3v1alpha1.BareMetalHost) {
+				h.Status.ErrorType = metal3v1alpha1.PowerManagementError
+			},
+		},
+		{
+			Scenario: "consumed",
+			Modify: func(h *metal3v1alpha1.BareMetalHost) {
+				h.Spec.ConsumerRef = claimReference(newClaim("other", metal3v1alpha1.BareMetalHostClaimSpec{}))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			h := host.DeepCopy()
+			if tc.Modify != nil {
+				tc.Modify(h)
+			}
+			claim := newClaim("claim", tc.Spec)
+			selector := labels.Everything()
+			if tc.Spec.Selector != nil {
+				var err error
+				selector, err = metav1.LabelSelectorAsSelector(tc.Spec.Selector)
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.Expected, hostMatchesClaim(h, claim, selector))
+		})
+	}
+}
+
+func TestClaimDeletionReleasesHost(t *testing.T) {
+	host := newAvailableHost("host", 16*1024)
+	claim := newClaim("claim", metal3v1alpha1.BareMetalHostClaimSpec{})
+	r := newTestClaimReconciler(host, claim)
+
+	claim = reconcileClaim(t, r, claim)
+	assert.Equal(t, metal3v1alpha1.ClaimPhaseBound, claim.Status.Phase)
+
+	assert.NoError(t, r.Delete(context.TODO(), claim))
+	assert.Nil(t, reconcileClaim(t, r, claim), "claim should be gone once the host is released")
+	assert.Nil(t, loadHost(t, r, "host").Spec.ConsumerRef)
+}
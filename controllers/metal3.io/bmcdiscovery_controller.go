@@ -0,0 +1,456 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+)
+
+const (
+	// maxDiscoveryAddresses limits the number of addresses of the
+	// ranges to scan
+	maxDiscoveryAddresses = 4096
+
+	// discoveryBatchSize is the number of addresses probed by each
+	// reconcile, so that a scan does not block a worker for long
+	discoveryBatchSize = 128
+
+	// discoveryWorkers is the number of addresses probed at the same
+	// time
+	discoveryWorkers = 32
+
+	// discoveryRetryDelay is the time to wait before scanning again
+	// when the credentials cannot be read
+	discoveryRetryDelay = time.Minute
+
+	defaultRedfishPort = 443
+	defaultIPMIPort    = 623
+)
+
+// BMCDiscoveryReconciler scans address ranges for BMCs and creates a
+// host for each system found
+type BMCDiscoveryReconciler struct {
+	client.Client
+	Log       logr.Logger
+	APIReader client.Reader
+}
+
+// +kubebuilder:rbac:groups=metal3.io,resources=bmcdiscoveries,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=metal3.io,resources=bmcdiscoveries/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;create
+
+// Reconcile scans the ranges of the discovery when its spec changed or
+// the rescan interval elapsed, and creates the hosts of the systems
+// found that are not managed by a host yet.
+func (r *BMCDiscoveryReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("bmcdiscovery", request.NamespacedName)
+
+	discovery := &metal3v1alpha1.BMCDiscovery{}
+	err := r.Get(ctx, request.NamespacedName, discovery)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, errors.Wrap(err, "could not load discovery")
+	}
+
+	if due, wait := scanDue(discovery, time.Now()); !due {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	addresses, err := discoveryAddresses(discovery.Spec.CIDRs)
+	if err != nil {
+		// There is no point retrying until the discovery changes.
+		return ctrl.Result{}, r.setDiscoveryError(ctx, discovery, err.Error())
+	}
+
+	secret, creds, err := r.discoveryCredentials(ctx, discovery)
+	if err != nil {
+		reqLogger.Info("cannot read the credentials", "error", err.Error())
+		return ctrl.Result{RequeueAfter: discoveryRetryDelay},
+			r.setDiscoveryError(ctx, discovery, err.Error())
+	}
+
+	status := &discovery.Status
+	if status.ObservedGeneration != discovery.Generation || status.ScannedAddresses > len(addresses) {
+		status.ScannedAddresses = 0
+	}
+	if status.ScannedAddresses == 0 {
+		status.ObservedGeneration = discovery.Generation
+		status.BMCs = nil
+	}
+	batch := addresses[status.ScannedAddresses:]
+	if len(batch) > discoveryBatchSize {
+		batch = batch[:discoveryBatchSize]
+	}
+
+	reqLogger.Info("scanning", "addresses", len(batch),
+		"scanned", status.ScannedAddresses, "total", len(addresses))
+	bmcs := scanAddresses(discovery, creds, batch)
+
+	hosts := &metal3v1alpha1.BareMetalHostList{}
+	if err := r.List(ctx, hosts, client.InNamespace(discovery.Namespace)); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "could not list hosts")
+	}
+	for i := range bmcs {
+		if err := r.ensureDiscoveredHost(ctx, reqLogger, discovery, secret, &bmcs[i], hosts.Items); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	status.BMCs = append(status.BMCs, bmcs...)
+	status.ScannedAddresses += len(batch)
+	status.ErrorMessage = ""
+	result := ctrl.Result{Requeue: true}
+	if status.ScannedAddresses == len(addresses) {
+		reqLogger.Info("scan complete", "found", len(status.BMCs))
+		now := metav1.Now()
+		status.LastScanTime = &now
+		status.ScannedAddresses = 0
+		result = ctrl.Result{}
+		if discovery.Spec.RescanInterval != nil {
+			result.RequeueAfter = discovery.Spec.RescanInterval.Duration
+		}
+	}
+	if err := r.Status().Update(ctx, discovery); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to update discovery status")
+	}
+	return result, nil
+}
+
+// scanDue returns whether the ranges of the discovery should be
+// scanned now, including the rest of a scan in progress, or else the
+// time until the next scan if one is due
+func scanDue(discovery *metal3v1alpha1.BMCDiscovery, now time.Time) (bool, time.Duration) {
+	status := discovery.Status
+	if status.LastScanTime == nil || status.ObservedGeneration != discovery.Generation ||
+		status.ScannedAddresses != 0 {
+		return true, 0
+	}
+	if discovery.Spec.RescanInterval == nil || discovery.Spec.RescanInterval.Duration <= 0 {
+		// Scan again once the spec changes.
+		return false, 0
+	}
+	wait := status.LastScanTime.Add(discovery.Spec.RescanInterval.Duration).Sub(now)
+	if wait <= 0 {
+		return true, 0
+	}
+	return false, wait
+}
+
+// setDiscoveryError saves the reason the scan failed in the status of
+// the discovery if it changed
+func (r *BMCDiscoveryReconciler) setDiscoveryError(ctx context.Context, discovery *metal3v1alpha1.BMCDiscovery, message string) error {
+	if discovery.Status.ErrorMessage == message {
+		return nil
+	}
+	discovery.Status.ErrorMessage = message
+	return errors.Wrap(r.Status().Update(ctx, discovery), "failed to update discovery status")
+}
+
+// discoveryCredentials returns the credentials secret of the
+// discovery and the credentials it holds
+func (r *BMCDiscoveryReconciler) discoveryCredentials(ctx context.Context, discovery *metal3v1alpha1.BMCDiscovery) (*corev1.Secret, bmc.Credentials, error) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: discovery.Namespace, Name: discovery.Spec.CredentialsName}
+	if err := r.APIReader.Get(ctx, key, secret); err != nil {
+		return nil, bmc.Credentials{}, errors.Wrapf(err, "failed to read the credentials secret %s", key.Name)
+	}
+	creds, _, err := bmc.NewSecretCredentialsProvider(secret).Credentials()
+	if err != nil {
+		return nil, bmc.Credentials{}, err
+	}
+	if err := creds.Validate(); err != nil {
+		return nil, bmc.Credentials{}, err
+	}
+	return secret, creds, nil
+}
+
+// discoveryAddresses returns the addresses of the ranges to scan, in
+// the order of the ranges. The network and broadcast addresses of IPv4
+// ranges are skipped.
+func discoveryAddresses(cidrs []string) ([]net.IP, error) {
+	addresses := []net.IP{}
+	total := 0
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Errorf("invalid range %q", cidr)
+		}
+		ones, bits := network.Mask.Size()
+		if bits-ones > 30 || total+1<<uint(bits-ones) > maxDiscoveryAddresses {
+			return nil, errors.Errorf("the ranges have more than %d addresses", maxDiscoveryAddresses)
+		}
+		size := 1 << uint(bits-ones)
+		total += size
+
+		first := new(big.Int).SetBytes(network.IP)
+		for i := 0; i < size; i++ {
+			if bits == 32 && size > 2 && (i == 0 || i == size-1) {
+				continue
+			}
+			ip := new(big.Int).Add(first, big.NewInt(int64(i))).FillBytes(make([]byte, len(network.IP)))
+			addresses = append(addresses, net.IP(ip))
+		}
+	}
+	return addresses, nil
+}
+
+// scanAddresses probes the addresses with the protocols of the
+// discovery and returns the systems found, ordered by address
+func scanAddresses(discovery *metal3v1alpha1.BMCDiscovery, creds bmc.Credentials, addresses []net.IP) []metal3v1alpha1.DiscoveredBMC {
+	jobs := make(chan net.IP)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	bmcs := []metal3v1alpha1.DiscoveredBMC{}
+
+	for i := 0; i < discoveryWorkers && i < len(addresses); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ip := range jobs {
+				found := probeAddress(discovery.Spec, creds, ip)
+				lock.Lock()
+				bmcs = append(bmcs, found...)
+				lock.Unlock()
+			}
+		}()
+	}
+	for _, ip := range addresses {
+		jobs <- ip
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(bmcs, func(i, j int) bool {
+		if bmcs[i].IP != bmcs[j].IP {
+			return bytes.Compare(net.ParseIP(bmcs[i].IP), net.ParseIP(bmcs[j].IP)) < 0
+		}
+		return bmcs[i].Address < bmcs[j].Address
+	})
+	return bmcs
+}
+
+// probeAddress looks for a BMC at the address with each protocol in
+// turn and returns the systems it manages
+func probeAddress(spec metal3v1alpha1.BMCDiscoverySpec, creds bmc.Credentials, ip net.IP) []metal3v1alpha1.DiscoveredBMC {
+	protocols := spec.Protocols
+	if len(protocols) == 0 {
+		protocols = []metal3v1alpha1.DiscoveryProtocol{
+			metal3v1alpha1.DiscoveryProtocolRedfish, metal3v1alpha1.DiscoveryProtocolIPMI,
+		}
+	}
+
+	for _, protocol := range protocols {
+		var systems []bmc.DiscoveredSystem
+		var err error
+
+		switch protocol {
+		case metal3v1alpha1.DiscoveryProtocolRedfish:
+			hostPort := net.JoinHostPort(ip.String(), strconv.Itoa(portOrDefault(spec.RedfishPort, defaultRedfishPort)))
+			if !bmc.ProbeRedfish(hostPort, spec.DisableCertificateVerification) {
+				continue
+			}
+			systems, err = bmc.DiscoverRedfishSystems(hostPort, creds, spec.DisableCertificateVerification)
+		case metal3v1alpha1.DiscoveryProtocolIPMI:
+			port := strconv.Itoa(portOrDefault(spec.IPMIPort, defaultIPMIPort))
+			if !bmc.PingIPMI(net.JoinHostPort(ip.String(), port)) {
+				continue
+			}
+			var system bmc.DiscoveredSystem
+			system, err = bmc.DiscoverIPMISystem(ip.String(), port, creds)
+			systems = []bmc.DiscoveredSystem{system}
+		default:
+			continue
+		}
+
+		if err != nil {
+			return []metal3v1alpha1.DiscoveredBMC{{
+				IP:           ip.String(),
+				Protocol:     protocol,
+				ErrorMessage: err.Error(),
+			}}
+		}
+		bmcs := []metal3v1alpha1.DiscoveredBMC{}
+		for _, system := range systems {
+			bmcs = append(bmcs, metal3v1alpha1.DiscoveredBMC{
+				IP:           ip.String(),
+				Protocol:     protocol,
+				Address:      system.Address,
+				SerialNumber: system.SerialNumber,
+				MACAddresses: system.MACAddresses,
+			})
+		}
+		return bmcs
+	}
+	return nil
+}
+
+func portOrDefault(port, defaultPort int) int {
+	if port == 0 {
+		return defaultPort
+	}
+	return port
+}
+
+var invalidHostNameCharacters = regexp.MustCompile("[^a-z0-9-]+")
+
+// discoveredHostName returns the name of the host of a system found by
+// a discovery. It is derived from the serial number of the system when
+// known, or from the address of the BMC otherwise. Existing hosts are
+// never modified, so a system whose BMC answers on another address is
+// reported as conflicting with its host rather than given a new one.
+func discoveredHostName(discovery *metal3v1alpha1.BMCDiscovery, found *metal3v1alpha1.DiscoveredBMC) string {
+	id := found.SerialNumber
+	if id == "" {
+		id = found.Address[strings.Index(found.Address, "://")+3:]
+	}
+	id = strings.Trim(invalidHostNameCharacters.ReplaceAllString(strings.ToLower(id), "-"), "-")
+	return fmt.Sprintf("%s-%s", discovery.Name, id)
+}
+
+// discoveredSecretName returns the name of the credentials secret of a
+// host created by a discovery
+func discoveredSecretName(hostName string) string {
+	return hostName + "-bmc-secret"
+}
+
+// ensureDiscoveredHost records the host managing a system found by the
+// discovery, creating it if no host manages the system yet. Existing
+// hosts are never modified, so scanning again has no effect on them.
+func (r *BMCDiscoveryReconciler) ensureDiscoveredHost(ctx context.Context, log logr.Logger, discovery *metal3v1alpha1.BMCDiscovery, credentials *corev1.Secret, found *metal3v1alpha1.DiscoveredBMC, hosts []metal3v1alpha1.BareMetalHost) error {
+	if found.ErrorMessage != "" {
+		return nil
+	}
+
+	name := discoveredHostName(discovery, found)
+	for _, host := range hosts {
+		switch {
+		case host.Spec.BMC.Address == found.Address:
+			found.HostName = host.Name
+			if host.Labels[metal3v1alpha1.BMCDiscoveryLabel] == discovery.Name &&
+				host.Spec.BMC.CredentialsName == discoveredSecretName(host.Name) {
+				// The secret may not have been created with the host.
+				return r.ensureDiscoveredSecret(ctx, log, credentials, &host)
+			}
+			return nil
+		case host.Name == name:
+			found.ErrorMessage = fmt.Sprintf("host %s already exists with BMC address %s",
+				name, host.Spec.BMC.Address)
+			return nil
+		}
+	}
+
+	host := &metal3v1alpha1.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: discovery.Namespace,
+			Labels: map[string]string{
+				metal3v1alpha1.BMCDiscoveryLabel: discovery.Name,
+			},
+		},
+		Spec: metal3v1alpha1.BareMetalHostSpec{
+			BMC: metal3v1alpha1.BMCDetails{
+				Address:                        found.Address,
+				CredentialsName:                discoveredSecretName(name),
+				DisableCertificateVerification: discovery.Spec.DisableCertificateVerification,
+			},
+		},
+	}
+	if len(found.MACAddresses) != 0 {
+		host.Spec.BootMACAddress = found.MACAddresses[0]
+	}
+
+	log.Info("creating host", "host", name, "address", found.Address)
+	if err := r.Create(ctx, host); err != nil {
+		if k8serrors.IsAlreadyExists(err) {
+			found.ErrorMessage = fmt.Sprintf("host %s already exists", name)
+			return nil
+		}
+		if k8serrors.IsInvalid(err) {
+			found.ErrorMessage = err.Error()
+			return nil
+		}
+		return errors.Wrapf(err, "failed to create host %s", name)
+	}
+	found.HostName = name
+	return r.ensureDiscoveredSecret(ctx, log, credentials, host)
+}
+
+// ensureDiscoveredSecret creates the credentials secret of a host
+// created by the discovery as a copy of the credentials of the
+// discovery. Each host owns its copy, so that it is deleted with the
+// host only.
+func (r *BMCDiscoveryReconciler) ensureDiscoveredSecret(ctx context.Context, log logr.Logger, credentials *corev1.Secret, host *metal3v1alpha1.BareMetalHost) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      host.Spec.BMC.CredentialsName,
+			Namespace: host.Namespace,
+			Labels:    map[string]string{LabelEnvironmentName: LabelEnvironmentValue},
+		},
+		Type: credentials.Type,
+		Data: credentials.Data,
+	}
+	if err := controllerutil.SetControllerReference(host, secret, r.Scheme()); err != nil {
+		return errors.Wrapf(err, "failed to set the owner of secret %s", secret.Name)
+	}
+
+	existing := &corev1.Secret{}
+	err := r.APIReader.Get(ctx, client.ObjectKeyFromObject(secret), existing)
+	switch {
+	case err == nil:
+		return nil
+	case !k8serrors.IsNotFound(err):
+		return errors.Wrapf(err, "failed to read secret %s", secret.Name)
+	}
+
+	log.Info("creating credentials secret", "host", host.Name, "secret", secret.Name)
+	if err := r.Create(ctx, secret); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create secret %s", secret.Name)
+	}
+	return nil
+}
+
+// SetupWithManager registers the reconciler to be run by the manager
+func (r *BMCDiscoveryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&metal3v1alpha1.BMCDiscovery{}).
+		Complete(r)
+}
@@ -0,0 +1,328 @@
+package controllers
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc/testserver"
+)
+
+func newTestDiscoveryReconciler(initObjs ...runtime.Object) *BMCDiscoveryReconciler {
+	c := fakeclient.NewFakeClient(initObjs...)
+	c.Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "discovery-creds", Namespace: namespace},
+		Data: map[string][]byte{
+			"username": []byte("admin"),
+			"password": []byte("pw"),
+		},
+	})
+	return &BMCDiscoveryReconciler{
+		Client:    c,
+		Log:       ctrl.Log.WithName("controllers").WithName("BMCDiscovery"),
+		APIReader: c,
+	}
+}
+
+// newDiscovery returns a discovery of the fake Redfish endpoint
+func newDiscovery(t *testing.T, server *testserver.RedfishMock) *metal3v1alpha1.BMCDiscovery {
+	host, port, err := net.SplitHostPort(server.Host())
+	if err != nil {
+		t.Fatal(err)
+	}
+	redfishPort, _ := strconv.Atoi(port)
+	return &metal3v1alpha1.BMCDiscovery{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "rack-1",
+			Namespace:  namespace,
+			Generation: 1,
+		},
+		Spec: metal3v1alpha1.BMCDiscoverySpec{
+			CIDRs:                          []string{host + "/32"},
+			CredentialsName:                "discovery-creds",
+			Protocols:                      []metal3v1alpha1.DiscoveryProtocol{metal3v1alpha1.DiscoveryProtocolRedfish},
+			RedfishPort:                    redfishPort,
+			DisableCertificateVerification: true,
+		},
+	}
+}
+
+func reconcileDiscovery(t *testing.T, r *BMCDiscoveryReconciler, discovery *metal3v1alpha1.BMCDiscovery) (*metal3v1alpha1.BMCDiscovery, ctrl.Result) {
+	key := types.NamespacedName{Name: discovery.Name, Namespace: discovery.Namespace}
+	result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+
+	updated := &metal3v1alpha1.BMCDiscovery{}
+	if err := r.Get(context.TODO(), key, updated); err != nil {
+		t.Fatal(err)
+	}
+	return updated, result
+}
+
+func TestDiscoveryCreatesHosts(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "pw").
+		WithInventory("SN.0001", "52:54:00:aa:bb:01").
+		StartTLS()
+	defer server.Stop()
+
+	discovery := newDiscovery(t, server)
+	r := newTestDiscoveryReconciler(discovery)
+
+	discovery, _ = reconcileDiscovery(t, r, discovery)
+	assert.NotNil(t, discovery.Status.LastScanTime)
+	assert.Equal(t, "", discovery.Status.ErrorMessage)
+	address := "redfish://" + server.Host() + testserver.RedfishSystemPath
+	assert.Equal(t, []metal3v1alpha1.DiscoveredBMC{
+		{
+			IP:           "127.0.0.1",
+			Protocol:     metal3v1alpha1.DiscoveryProtocolRedfish,
+			Address:      address,
+			SerialNumber: "SN.0001",
+			MACAddresses: []string{"52:54:00:aa:bb:01"},
+			HostName:     "rack-1-sn-0001",
+		},
+	}, discovery.Status.BMCs)
+
+	host := &metal3v1alpha1.BareMetalHost{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "rack-1-sn-0001", Namespace: namespace}, host); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, address, host.Spec.BMC.Address)
+	assert.Equal(t, "rack-1-sn-0001-bmc-secret", host.Spec.BMC.CredentialsName)
+	assert.True(t, host.Spec.BMC.DisableCertificateVerification)
+	assert.Equal(t, "52:54:00:aa:bb:01", host.Spec.BootMACAddress)
+	assert.Equal(t, "rack-1", host.Labels[metal3v1alpha1.BMCDiscoveryLabel])
+	assert.Equal(t, metal3v1alpha1.StateNone, host.Status.Provisioning.State)
+
+	secret := &corev1.Secret{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "rack-1-sn-0001-bmc-secret", Namespace: namespace}, secret); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "admin", string(secret.Data["username"]))
+	assert.Equal(t, "pw", string(secret.Data["password"]))
+	assert.True(t, metav1.IsControlledBy(secret, host))
+}
+
+// TestDiscoveredHostsRegister ensures that each host created by a
+// discovery owns its credentials, so that several of them can be
+// registered.
+func TestDiscoveredHostsRegister(t *testing.T) {
+	r := newTestReconciler()
+	d := &BMCDiscoveryReconciler{
+		Client:    r.Client,
+		Log:       ctrl.Log.WithName("controllers").WithName("BMCDiscovery"),
+		APIReader: r.Client,
+	}
+	credentials := newBMCCredsSecret("discovery-creds", "admin", "pw")
+	assert.NoError(t, r.Create(context.TODO(), credentials))
+	discovery := &metal3v1alpha1.BMCDiscovery{
+		ObjectMeta: metav1.ObjectMeta{Name: "rack-1", Namespace: namespace},
+		Spec:       metal3v1alpha1.BMCDiscoverySpec{CredentialsName: "discovery-creds"},
+	}
+
+	for i, serial := range []string{"sn-1", "sn-2"} {
+		found := &metal3v1alpha1.DiscoveredBMC{
+			IP:           "192.168.122." + strconv.Itoa(i+1),
+			Protocol:     metal3v1alpha1.DiscoveryProtocolIPMI,
+			Address:      "ipmi://192.168.122." + strconv.Itoa(i+1),
+			SerialNumber: serial,
+			MACAddresses: []string{"52:54:00:aa:bb:0" + strconv.Itoa(i+1)},
+		}
+		err := d.ensureDiscoveredHost(context.TODO(), d.Log, discovery, credentials, found, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "", found.ErrorMessage)
+	}
+
+	for _, name := range []string{"rack-1-sn-1", "rack-1-sn-2"} {
+		host := &metal3v1alpha1.BareMetalHost{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, host); err != nil {
+			t.Fatal(err)
+		}
+		// The fake client does not set UIDs, which ownership relies on
+		host.UID = types.UID(name)
+		assert.NoError(t, r.Update(context.TODO(), host))
+
+		waitForProvisioningState(t, r, host, metal3v1alpha1.StateInspecting)
+		assert.Equal(t, "", host.Status.ErrorMessage)
+
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Name: host.Spec.BMC.CredentialsName, Namespace: namespace}
+		if err := r.Get(context.TODO(), key, secret); err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, metav1.IsControlledBy(secret, host))
+	}
+
+	// The credentials of the discovery are left alone
+	assert.NoError(t, r.Get(context.TODO(), types.NamespacedName{Name: "discovery-creds", Namespace: namespace}, credentials))
+	assert.Nil(t, metav1.GetControllerOf(credentials))
+}
+
+func TestDiscoveryRescanIsIdempotent(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "pw").
+		WithInventory("SN-0001", "52:54:00:aa:bb:01").
+		StartTLS()
+	defer server.Stop()
+
+	discovery := newDiscovery(t, server)
+	discovery.Spec.RescanInterval = &metav1.Duration{Duration: time.Hour}
+	r := newTestDiscoveryReconciler(discovery)
+
+	discovery, result := reconcileDiscovery(t, r, discovery)
+	assert.Equal(t, time.Hour, result.RequeueAfter)
+	lastScan := discovery.Status.LastScanTime
+
+	// Nothing is scanned before the interval elapses
+	_, result = reconcileDiscovery(t, r, discovery)
+	assert.True(t, result.RequeueAfter > 0 && result.RequeueAfter <= time.Hour)
+
+	// Hosts modified since they were created are left alone
+	host := &metal3v1alpha1.BareMetalHost{}
+	key := types.NamespacedName{Name: "rack-1-sn-0001", Namespace: namespace}
+	assert.NoError(t, r.Get(context.TODO(), key, host))
+	host.Spec.BootMACAddress = "52:54:00:aa:bb:99"
+	assert.NoError(t, r.Update(context.TODO(), host))
+
+	discovery.Status.LastScanTime = &metav1.Time{Time: lastScan.Add(-2 * time.Hour)}
+	assert.NoError(t, r.Status().Update(context.TODO(), discovery))
+	discovery, _ = reconcileDiscovery(t, r, discovery)
+	assert.True(t, discovery.Status.LastScanTime.After(lastScan.Add(-time.Hour)))
+	assert.Equal(t, "rack-1-sn-0001", discovery.Status.BMCs[0].HostName)
+
+	hosts := &metal3v1alpha1.BareMetalHostList{}
+	assert.NoError(t, r.List(context.TODO(), hosts, client.InNamespace(namespace)))
+	assert.Len(t, hosts.Items, 1)
+	assert.Equal(t, "52:54:00:aa:bb:99", hosts.Items[0].Spec.BootMACAddress)
+}
+
+func TestDiscoveryKeepsExistingHosts(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "pw").WithInventory("").StartTLS()
+	defer server.Stop()
+
+	discovery := newDiscovery(t, server)
+	existing := newHost("worker-0", &metal3v1alpha1.BareMetalHostSpec{
+		BMC: metal3v1alpha1.BMCDetails{
+			Address: "redfish://" + server.Host() + testserver.RedfishSystemPath,
+		},
+	})
+	r := newTestDiscoveryReconciler(discovery, existing)
+
+	discovery, _ = reconcileDiscovery(t, r, discovery)
+	assert.Len(t, discovery.Status.BMCs, 1)
+	assert.Equal(t, "worker-0", discovery.Status.BMCs[0].HostName)
+
+	hosts := &metal3v1alpha1.BareMetalHostList{}
+	assert.NoError(t, r.List(context.TODO(), hosts, client.InNamespace(namespace)))
+	assert.Len(t, hosts.Items, 1)
+}
+
+func TestDiscoveryErrors(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "other-pw").StartTLS()
+	defer server.Stop()
+
+	// The BMC is found but the credentials are rejected
+	discovery := newDiscovery(t, server)
+	r := newTestDiscoveryReconciler(discovery)
+	discovery, _ = reconcileDiscovery(t, r, discovery)
+	assert.Len(t, discovery.Status.BMCs, 1)
+	assert.NotEqual(t, "", discovery.Status.BMCs[0].ErrorMessage)
+	assert.Equal(t, "", discovery.Status.BMCs[0].HostName)
+
+	// The credentials secret does not exist
+	discovery = newDiscovery(t, server)
+	discovery.Spec.CredentialsName = "missing"
+	r = newTestDiscoveryReconciler(discovery)
+	discovery, result := reconcileDiscovery(t, r, discovery)
+	assert.Contains(t, discovery.Status.ErrorMessage, "missing")
+	assert.Equal(t, discoveryRetryDelay, result.RequeueAfter)
+	assert.Nil(t, discovery.Status.LastScanTime)
+
+	// The range is too large
+	discovery = newDiscovery(t, server)
+	discovery.Spec.CIDRs = []string{"10.0.0.0/8"}
+	r = newTestDiscoveryReconciler(discovery)
+	discovery, _ = reconcileDiscovery(t, r, discovery)
+	assert.Contains(t, discovery.Status.ErrorMessage, "more than 4096 addresses")
+
+	// The ranges are too large together
+	discovery = newDiscovery(t, server)
+	discovery.Spec.CIDRs = []string{"10.0.0.0/21", "10.0.8.0/21", "10.0.16.0/32"}
+	r = newTestDiscoveryReconciler(discovery)
+	discovery, _ = reconcileDiscovery(t, r, discovery)
+	assert.Contains(t, discovery.Status.ErrorMessage, "more than 4096 addresses")
+}
+
+func TestDiscoveryScansInBatches(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "pw").
+		WithInventory("SN-0001", "52:54:00:aa:bb:01").
+		StartTLS()
+	defer server.Stop()
+
+	// Only the first address of the loopback range serves Redfish
+	discovery := newDiscovery(t, server)
+	discovery.Spec.CIDRs = []string{"127.0.0.0/24"}
+	r := newTestDiscoveryReconciler(discovery)
+
+	discovery, result := reconcileDiscovery(t, r, discovery)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, discoveryBatchSize, discovery.Status.ScannedAddresses)
+	assert.Nil(t, discovery.Status.LastScanTime)
+	assert.Len(t, discovery.Status.BMCs, 1)
+	assert.Equal(t, "rack-1-sn-0001", discovery.Status.BMCs[0].HostName)
+
+	// A change of the spec restarts the scan
+	discovery.Generation = 2
+	assert.NoError(t, r.Update(context.TODO(), discovery))
+	discovery, result = reconcileDiscovery(t, r, discovery)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, int64(2), discovery.Status.ObservedGeneration)
+	assert.Equal(t, discoveryBatchSize, discovery.Status.ScannedAddresses)
+	assert.Len(t, discovery.Status.BMCs, 1)
+
+	discovery, result = reconcileDiscovery(t, r, discovery)
+	assert.False(t, result.Requeue)
+	assert.Equal(t, 0, discovery.Status.ScannedAddresses)
+	assert.NotNil(t, discovery.Status.LastScanTime)
+	assert.Len(t, discovery.Status.BMCs, 1)
+
+	// Nothing is scanned once the scan completed
+	_, result = reconcileDiscovery(t, r, discovery)
+	assert.False(t, result.Requeue)
+	hosts := &metal3v1alpha1.BareMetalHostList{}
+	assert.NoError(t, r.List(context.TODO(), hosts, client.InNamespace(namespace)))
+	assert.Len(t, hosts.Items, 1)
+}
+
+func TestDiscoveryAddresses(t *testing.T) {
+	addresses, err := discoveryAddresses([]string{"192.168.1.0/30", "192.168.2.7/32", "fd00::/127"})
+	assert.NoError(t, err)
+	actual := []string{}
+	for _, ip := range addresses {
+		actual = append(actual, ip.String())
+	}
+	assert.Equal(t, []string{"192.168.1.1", "192.168.1.2", "192.168.2.7", "fd00::", "fd00::1"}, actual)
+
+	addresses, err = discoveryAddresses([]string{"10.0.0.0/20"})
+	assert.NoError(t, err)
+	assert.Len(t, addresses, 4094)
+
+	_, err = discoveryAddresses([]string{"10.0.0.0/19"})
+	assert.Error(t, err)
+	_, err = discoveryAddresses([]string{"fd00::/64"})
+	assert.Error(t, err)
+	_, err = discoveryAddresses([]string{"10.0.0.1"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+// capacityQueue is the position of a host in the queue of the hosts of
+// its capacity pool waiting for a (de)provisioning slot
+type capacityQueue struct {
+	pool     string
+	position int
+	since    metav1.Time
+
+	// free is the number of slots of the pool not used by its hosts,
+	// or -1 when the pool has no limit
+	free int
+
+	// yield is true when another pool using fewer slots has hosts
+	// waiting for one
+	yield bool
+}
+
+// hasCapacity returns true when the host may take a slot of the
+// provisioner, as the hosts ahead of it in the queue of its pool fit
+// in the pool and no other pool has priority.
+func (q *capacityQueue) hasCapacity() bool {
+	return (q.free < 0 || q.position <= q.free) && !q.yield
+}
+
+func (q *capacityQueue) status() *metal3v1alpha1.CapacityQueueStatus {
+	return &metal3v1alpha1.CapacityQueueStatus{
+		Pool:     q.pool,
+		Position: q.position,
+		Since:    q.since,
+	}
+}
+
+// usesCapacity returns true for the states in which hosts use a
+// (de)provisioning slot of the provisioner
+func usesCapacity(state metal3v1alpha1.ProvisioningState) bool {
+	switch state {
+	case metal3v1alpha1.StateInspecting, metal3v1alpha1.StateProvisioning,
+		metal3v1alpha1.StateDeprovisioning, metal3v1alpha1.StateDeleting:
+		return true
+	}
+	return false
+}
+
+// holdsCapacity returns true when the host uses a slot
+func holdsCapacity(host *metal3v1alpha1.BareMetalHost) bool {
+	return usesCapacity(host.Status.Provisioning.State) &&
+		host.Status.OperationalStatus != metal3v1alpha1.OperationalStatusDelayed
+}
+
+// isWaitingForCapacity returns true when the host waits for a slot.
+// Paused hosts are not reconciled, so they do not keep their place.
+func isWaitingForCapacity(host *metal3v1alpha1.BareMetalHost) bool {
+	if _, paused := host.Annotations[metal3v1alpha1.PausedAnnotation]; paused {
+		return false
+	}
+	return host.Status.OperationalStatus == metal3v1alpha1.OperationalStatusDelayed
+}
+
+// capacityPoolOf returns the first pool by name the host belongs to,
+// or nil
+func capacityPoolOf(host *metal3v1alpha1.BareMetalHost, pools []metal3v1alpha1.CapacityPool) (*metal3v1alpha1.CapacityPool, error) {
+	for i := range pools {
+		matches, err := pools[i].Matches(host)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid selector of capacity pool %s", pools[i].Name)
+		}
+		if matches {
+			return &pools[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// poolUsage is the number of slots used by the hosts of a pool, and
+// the hosts waiting for one
+type poolUsage struct {
+	limit   int
+	busy    int
+	waiting []*metal3v1alpha1.BareMetalHost
+}
+
+// full returns true when no host of the pool can take a slot
+func (u *poolUsage) full() bool {
+	return u.limit > 0 && u.busy >= u.limit
+}
+
+// capacityQueue returns the position of the host in the queue of its
+// capacity pool, or nil when the host already uses a slot. The hosts
+// belonging to no pool share a queue without limit. Within a pool the
+// hosts are queued by the time they started waiting, and the pool
+// using the fewest slots has priority over the others.
+func (r *BareMetalHostReconciler) capacityQueue(host *metal3v1alpha1.BareMetalHost) (*capacityQueue, error) {
+	if holdsCapacity(host) {
+		return nil, nil
+	}
+
+	pools := &metal3v1alpha1.CapacityPoolList{}
+	if err := r.List(context.TODO(), pools); err != nil {
+		return nil, errors.Wrap(err, "could not list capacity pools")
+	}
+	sort.Slice(pools.Items, func(i, j int) bool {
+		return pools.Items[i].Name < pools.Items[j].Name
+	})
+
+	hosts := &metal3v1alpha1.BareMetalHostList{}
+	if err := r.List(context.TODO(), hosts); err != nil {
+		return nil, errors.Wrap(err, "could not list hosts")
+	}
+
+	queue := &capacityQueue{since: metav1.Now().Rfc3339Copy()}
+	if host.Status.CapacityQueue != nil {
+		queue.since = host.Status.CapacityQueue.Since
+	}
+
+	usages := map[string]*poolUsage{}
+	addHost := func(h *metal3v1alpha1.BareMetalHost) (string, error) {
+		pool, err := capacityPoolOf(h, pools.Items)
+		if err != nil {
+			return "", err
+		}
+		name := ""
+		usage := &poolUsage{}
+		if pool != nil {
+			name = pool.Name
+			usage.limit = pool.Spec.Limit
+		}
+		if existing, ok := usages[name]; ok {
+			usage = existing
+		} else {
+			usages[name] = usage
+		}
+		switch {
+		case holdsCapacity(h):
+			usage.busy++
+		case h == host || isWaitingForCapacity(h):
+			usage.waiting = append(usage.waiting, h)
+		}
+		return name, nil
+	}
+
+	// The host is not saved yet, so its own status is used
+	for i := range hosts.Items {
+		if hosts.Items[i].Namespace == host.Namespace && hosts.Items[i].Name == host.Name {
+			continue
+		}
+		if _, err := addHost(&hosts.Items[i]); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	queue.pool, err = addHost(host)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hosts delayed without a recorded time are queued first
+	since := func(h *metal3v1alpha1.BareMetalHost) metav1.Time {
+		if h == host {
+			return queue.since
+		}
+		if h.Status.CapacityQueue != nil {
+			return h.Status.CapacityQueue.Since
+		}
+		return metav1.Time{}
+	}
+	own := usages[queue.pool]
+	sort.SliceStable(own.waiting, func(i, j int) bool {
+		si, sj := since(own.waiting[i]), since(own.waiting[j])
+		if !si.Equal(&sj) {
+			return si.Before(&sj)
+		}
+		if own.waiting[i].Namespace != own.waiting[j].Namespace {
+			return own.waiting[i].Namespace < own.waiting[j].Namespace
+		}
+		return own.waiting[i].Name < own.waiting[j].Name
+	})
+	for i, h := range own.waiting {
+		if h == host {
+			queue.position = i + 1
+		}
+	}
+
+	queue.free = -1
+	if own.limit > 0 {
+		queue.free = own.limit - own.busy
+	}
+	for name, usage := range usages {
+		if name != queue.pool && len(usage.waiting) != 0 && !usage.full() && usage.busy < own.busy {
+			queue.yield = true
+		}
+	}
+	return queue, nil
+}
@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+func capacityPool(name string, limit int, namespaces ...string) *metal3v1alpha1.CapacityPool {
+	return &metal3v1alpha1.CapacityPool{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: metal3v1alpha1.CapacityPoolSpec{
+			Namespaces: namespaces,
+			Limit:      limit,
+		},
+	}
+}
+
+func busyHost(name, namespace string) *metal3v1alpha1.BareMetalHost {
+	h := host(metal3v1alpha1.StateProvisioning).build()
+	h.Name = name
+	h.Namespace = namespace
+	return h
+}
+
+func waitingHost(name, namespace string, since time.Time) *metal3v1alpha1.BareMetalHost {
+	h := host(metal3v1alpha1.StateReady).SetOperationalStatus(metal3v1alpha1.OperationalStatusDelayed).build()
+	h.Name = name
+	h.Namespace = namespace
+	h.Status.CapacityQueue = &metal3v1alpha1.CapacityQueueStatus{
+		Since: metav1.NewTime(since),
+	}
+	return h
+}
+
+func TestCapacityQueue(t *testing.T) {
+	earlier := time.Now().Add(-time.Hour)
+	later := time.Now().Add(-time.Minute)
+
+	testCases := []struct {
+		Scenario string
+		Host     *metal3v1alpha1.BareMetalHost
+		Objects  []runtime.Object
+
+		ExpectedPool        string
+		ExpectedPosition    int
+		ExpectedHasCapacity bool
+	}{
+		{
+			Scenario: "no-pool",
+			Host:     waitingHost("host", "team-a", later),
+			Objects: []runtime.Object{
+				busyHost("busy", "team-a"),
+			},
+
+			ExpectedPosition:    1,
+			ExpectedHasCapacity: true,
+		},
+		{
+			Scenario: "pool-full",
+			Host:     waitingHost("host", "team-a", later),
+			Objects: []runtime.Object{
+				capacityPool("team-a", 1, "team-a"),
+				busyHost("busy", "team-a"),
+			},
+
+			ExpectedPool:        "team-a",
+			ExpectedPosition:    1,
+			ExpectedHasCapacity: false,
+		},
+		{
+			Scenario: "pool-other-namespace",
+			Host:     waitingHost("host", "team-b", later),
+			Objects: []runtime.Object{
+				capacityPool("team-a", 1, "team-a"),
+				busyHost("busy", "team-a"),
+			},
+
+			ExpectedPosition:    1,
+			ExpectedHasCapacity: true,
+		},
+		{
+			Scenario: "queued-behind-earlier-host",
+			Host:     waitingHost("host", "team-a", later),
+			Objects: []runtime.Object{
+				capacityPool("team-a", 2, "team-a"),
+				busyHost("busy", "team-a"),
+				waitingHost("first", "team-a", earlier),
+			},
+
+			ExpectedPool:        "team-a",
+			ExpectedPosition:    2,
+			ExpectedHasCapacity: false,
+		},
+		{
+			Scenario: "queued-ahead-of-later-host",
+			Host:     waitingHost("host", "team-a", earlier),
+			Objects: []runtime.Object{
+				capacityPool("team-a", 2, "team-a"),
+				busyHost("busy", "team-a"),
+				waitingHost("second", "team-a", later),
+			},
+
+			ExpectedPool:        "team-a",
+			ExpectedPosition:    1,
+			ExpectedHasCapacity: true,
+		},
+		{
+			Scenario: "new-host-queued-last",
+			Host:     host(metal3v1alpha1.StateReady).build(),
+			Objects: []runtime.Object{
+				capacityPool("team-a", 2, "team-a"),
+				waitingHost("first", "team-a", later),
+			},
+
+			ExpectedPool:        "team-a",
+			ExpectedPosition:    2,
+			ExpectedHasCapacity: true,
+		},
+		{
+			Scenario: "paused-host-not-queued",
+			Host:     waitingHost("host", "team-a", later),
+			Objects: []runtime.Object{
+				capacityPool("team-a", 2, "team-a"),
+				busyHost("busy", "team-a"),
+				func() *metal3v1alpha1.BareMetalHost {
+					h := waitingHost("paused", "team-a", earlier)
+					h.Annotations = map[string]string{metal3v1alpha1.PausedAnnotation: ""}
+					return h
+				}(),
+			},
+
+			ExpectedPool:        "team-a",
+			ExpectedPosition:    1,
+			ExpectedHasCapacity: true,
+		},
+		{
+			Scenario: "yield-to-pool-with-fewer-busy-hosts",
+			Host:     waitingHost("host", "team-a", earlier),
+			Objects: []runtime.Object{
+				capacityPool("team-a", 5, "team-a"),
+				capacityPool("team-b", 5, "team-b"),
+				busyHost("busy", "team-a"),
+				waitingHost("other", "team-b", later),
+			},
+
+			ExpectedPool:        "team-a",
+			ExpectedPosition:    1,
+			ExpectedHasCapacity: false,
+		},
+		{
+			Scenario: "no-yield-to-full-pool",
+			Host:     waitingHost("host", "team-a", earlier),
+			Objects: []runtime.Object{
+				capacityPool("team-a", 5, "team-a"),
+				capacityPool("team-b", 1, "team-b"),
+				busyHost("busy-a1", "team-a"),
+				busyHost("busy-a2", "team-a"),
+				busyHost("busy-b", "team-b"),
+				waitingHost("other", "team-b", later),
+			},
+
+			ExpectedPool:        "team-a",
+			ExpectedPosition:    1,
+			ExpectedHasCapacity: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			if tc.Host.Name == "" {
+				tc.Host.Name = "host"
+				tc.Host.Namespace = "team-a"
+			}
+			r := newTestReconciler(append(tc.Objects, tc.Host.DeepCopy())...)
+
+			queue, err := r.capacityQueue(tc.Host)
+
+			assert.NoError(t, err)
+			if assert.NotNil(t, queue) {
+				assert.Equal(t, tc.ExpectedPool, queue.pool)
+				assert.Equal(t, tc.ExpectedPosition, queue.position)
+				assert.Equal(t, tc.ExpectedHasCapacity, queue.hasCapacity())
+			}
+		})
+	}
+}
+
+func TestCapacityQueueBusyHost(t *testing.T) {
+	h := busyHost("host", "team-a")
+	r := newTestReconciler(capacityPool("team-a", 1, "team-a"), h.DeepCopy())
+
+	queue, err := r.capacityQueue(h)
+
+	assert.NoError(t, err)
+	assert.Nil(t, queue)
+}
+
+func TestCapacityQueueStatus(t *testing.T) {
+	since := metav1.NewTime(time.Now().Add(-time.Hour)).Rfc3339Copy()
+	h := host(metal3v1alpha1.StateReady).SaveHostProvisioningSettings().build()
+	h.Name = "host"
+	h.Namespace = "team-a"
+	r := newTestReconciler(capacityPool("team-a", 1, "team-a"), busyHost("busy", "team-a"),
+		waitingHost("first", "team-a", since.Time))
+	prov := newMockProvisioner()
+	prov.setHasCapacity(true)
+
+	// The host is delayed behind the hosts of its pool
+	hsm := newHostStateMachine(h, r, prov, true)
+	result := hsm.ReconcileState(makeDefaultReconcileInfo(h))
+
+	assert.Equal(t, actionDelayed{}, result)
+	assert.EqualValues(t, metal3v1alpha1.OperationalStatusDelayed, h.Status.OperationalStatus)
+	if assert.NotNil(t, h.Status.CapacityQueue) {
+		assert.Equal(t, "team-a", h.Status.CapacityQueue.Pool)
+		assert.Equal(t, 2, h.Status.CapacityQueue.Position)
+		assert.False(t, h.Status.CapacityQueue.Since.IsZero())
+	}
+
+	// The queue status is cleared once the host gets a slot
+	r = newTestReconciler(capacityPool("team-a", 2, "team-a"), busyHost("busy", "team-a"))
+	hsm = newHostStateMachine(h, r, prov, true)
+	result = hsm.ReconcileState(makeDefaultReconcileInfo(h))
+
+	assert.Equal(t, actionUpdate{}, result)
+	assert.EqualValues(t, metal3v1alpha1.OperationalStatusOK, h.Status.OperationalStatus)
+	assert.Nil(t, h.Status.CapacityQueue)
+}
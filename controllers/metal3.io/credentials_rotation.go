@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner"
+)
+
+// The progress of a rotation is recorded on the secret holding the new
+// credentials until they are stored in the credentials secret of the
+// host, so that the new password is never lost once it may have been
+// set on the BMC.
+const (
+	rotationStateAnnotation = "baremetalhost.metal3.io/rotation-state"
+
+	// The new password has been generated but not set on the BMC yet
+	rotationGenerated = "generated"
+	// Setting the new password on the BMC failed, but the BMC may have
+	// changed it anyway, so both passwords are tried
+	rotationSetFailed = "set-failed"
+	// The new password has been set on the BMC and is being verified
+	rotationApplied = "applied"
+	// The previous password could not be restored after a failed
+	// verification, the secret holds the last password set on the BMC
+	rotationFailed = "failed"
+)
+
+// rotationRetryDelay is the delay before trying the passwords again when
+// the BMC accepts neither of them after setting the new one failed.
+const rotationRetryDelay = time.Minute
+
+func rotationSecretKey(host *metal3v1alpha1.BareMetalHost) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: host.Namespace,
+		Name:      fmt.Sprintf("%s-bmc-secret-rotation", host.Name),
+	}
+}
+
+func hasRotateCredentialsAnnotation(host *metal3v1alpha1.BareMetalHost) bool {
+	_, present := host.GetAnnotations()[metal3v1alpha1.RotateCredentialsAnnotation]
+	return present
+}
+
+// canRotateCredentials returns true when the host is in a steady state
+// with credentials that are known to work.
+func canRotateCredentials(info *reconcileInfo) bool {
+	host := info.host
+	switch host.Status.Provisioning.State {
+	case metal3v1alpha1.StateReady, metal3v1alpha1.StateAvailable,
+		metal3v1alpha1.StateProvisioned, metal3v1alpha1.StateExternallyProvisioned:
+	default:
+		return false
+	}
+	return host.DeletionTimestamp.IsZero() &&
+		!hasDetachedAnnotation(host) &&
+		host.Status.ErrorType == "" &&
+		host.Status.GoodCredentials.Match(*info.bmcCredsSecret)
+}
+
+// rotateCredentials sets a new password on the BMC account of the host
+// when the rotate-credentials annotation is present. The credentials
+// secret is only updated once the provisioner has verified that the
+// new password works, otherwise the previous password is restored.
+// done is false while a rotation is in progress.
+func (r *BareMetalHostReconciler) rotateCredentials(prov provisioner.Provisioner, info *reconcileInfo, bmcCABundle []byte) (result ctrl.Result, done bool, err error) {
+	if !hasRotateCredentialsAnnotation(info.host) {
+		return ctrl.Result{}, true, nil
+	}
+
+	pending, err := getSecret(r.Client, r.APIReader, rotationSecretKey(info.host))
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, false, errors.Wrap(err, "failed to fetch the rotated BMC credentials")
+		}
+		pending = nil
+	}
+
+	if pending == nil {
+		if !canRotateCredentials(info) {
+			return ctrl.Result{}, true, nil
+		}
+		return r.startCredentialsRotation(info)
+	}
+
+	switch pending.Annotations[rotationStateAnnotation] {
+	case rotationGenerated:
+		return r.applyRotatedCredentials(info, pending)
+	case rotationSetFailed:
+		return r.recoverRotatedCredentials(info, pending)
+	case rotationApplied:
+		return r.verifyRotatedCredentials(prov, info, pending, bmcCABundle)
+	default:
+		// A failed rotation needs to be resolved by an administrator,
+		// who removes the secret once the BMC password is known.
+		info.log.Info("not rotating BMC credentials after a failed rotation", "secret", pending.Name)
+		return ctrl.Result{}, true, nil
+	}
+}
+
+func (r *BareMetalHostReconciler) startCredentialsRotation(info *reconcileInfo) (ctrl.Result, bool, error) {
+	if r.AccountClientFactory == nil {
+		r.publishRotationEvent(info, "CredentialsRotationFailed",
+			"Rotating BMC credentials is not enabled")
+		return ctrl.Result{Requeue: true}, false, r.endCredentialsRotation(info, nil)
+	}
+	if provider := info.host.Spec.BMC.CredentialsProvider; provider != "" &&
+		provider != metal3v1alpha1.CredentialsProviderSecret {
+		r.publishRotationEvent(info, "CredentialsRotationFailed",
+			fmt.Sprintf("Rotating BMC credentials held by the %s provider is not supported", provider))
+		return ctrl.Result{Requeue: true}, false, r.endCredentialsRotation(info, nil)
+	}
+	if info.bmcCreds.Token != "" || info.bmcCreds.HasClientCertificate() {
+		r.publishRotationEvent(info, "CredentialsRotationFailed",
+			"Rotating BMC credentials is only supported for password authentication")
+		return ctrl.Result{Requeue: true}, false, r.endCredentialsRotation(info, nil)
+	}
+
+	password, err := bmc.GeneratePassword()
+	if err != nil {
+		return ctrl.Result{}, false, errors.Wrap(err, "failed to generate a BMC password")
+	}
+
+	key := rotationSecretKey(info.host)
+	pending := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        key.Name,
+			Namespace:   key.Namespace,
+			Annotations: map[string]string{rotationStateAnnotation: rotationGenerated},
+			Labels:      map[string]string{LabelEnvironmentName: LabelEnvironmentValue},
+		},
+		Data: map[string][]byte{
+			"username": info.bmcCredsSecret.Data["username"],
+			"password": []byte(password),
+		},
+	}
+	if err := controllerutil.SetControllerReference(info.host, pending, r.Scheme()); err != nil {
+		return ctrl.Result{}, false, errors.Wrap(err, "failed to set the owner of the rotated BMC credentials")
+	}
+	if err := r.Create(context.TODO(), pending); err != nil {
+		return ctrl.Result{}, false, errors.Wrap(err, "failed to store the rotated BMC credentials")
+	}
+
+	info.log.Info("rotating BMC credentials", "secret", key.Name)
+	return ctrl.Result{Requeue: true}, false, nil
+}
+
+func (r *BareMetalHostReconciler) newAccountClient(info *reconcileInfo, creds bmc.Credentials) (bmc.AccountClient, error) {
+	accessDetails, err := bmc.NewAccessDetails(info.host.Spec.BMC.Address,
+		info.host.Spec.BMC.DisableCertificateVerification)
+	if err != nil {
+		return nil, err
+	}
+	creds.CABundle = info.bmcCreds.CABundle
+	return r.AccountClientFactory(accessDetails, creds)
+}
+
+// applyRotatedCredentials sets the new password on the BMC using the
+// current credentials.
+func (r *BareMetalHostReconciler) applyRotatedCredentials(info *reconcileInfo, pending *corev1.Secret) (ctrl.Result, bool, error) {
+	if r.AccountClientFactory == nil {
+		r.publishRotationEvent(info, "CredentialsRotationFailed",
+			"Rotating BMC credentials is not enabled")
+		return ctrl.Result{Requeue: true}, false, r.endCredentialsRotation(info, pending)
+	}
+
+	newCreds := credentialsFromSecret(pending)
+	client, err := r.newAccountClient(info, *credentialsFromSecret(info.bmcCredsSecret))
+	if err == nil {
+		err = client.SetPassword(newCreds.Password)
+	}
+	if err != nil {
+		// The new password is kept, as the BMC may have applied it
+		// before the error.
+		info.log.Info("could not set the new BMC password", "reason", err.Error())
+		return r.setRotationState(pending, rotationSetFailed)
+	}
+
+	info.log.Info("new BMC password set, verifying it")
+	return r.setRotationState(pending, rotationApplied)
+}
+
+// recoverRotatedCredentials finds out which password the BMC uses after
+// setting the new one failed. The rotation is abandoned when the BMC
+// still accepts the previous password, and the new one is verified when
+// the BMC accepts it instead.
+func (r *BareMetalHostReconciler) recoverRotatedCredentials(info *reconcileInfo, pending *corev1.Secret) (ctrl.Result, bool, error) {
+	if r.AccountClientFactory == nil {
+		r.publishRotationEvent(info, "CredentialsRotationFailed",
+			fmt.Sprintf("Rotating BMC credentials is not enabled; the BMC may use the password stored in secret %s", pending.Name))
+		return ctrl.Result{Requeue: true}, false, r.endCredentialsRotation(info, nil)
+	}
+
+	if r.passwordAccepted(info, info.bmcCredsSecret) {
+		r.publishRotationEvent(info, "CredentialsRotationFailed",
+			"Could not set the new BMC password, the previous one is still in use")
+		return ctrl.Result{Requeue: true}, false, r.endCredentialsRotation(info, pending)
+	}
+	if r.passwordAccepted(info, pending) {
+		info.log.Info("new BMC password was set despite the error, verifying it")
+		return r.setRotationState(pending, rotationApplied)
+	}
+
+	info.log.Info("the BMC accepts neither the previous nor the new password, retrying",
+		"secret", pending.Name)
+	return ctrl.Result{RequeueAfter: rotationRetryDelay}, false, nil
+}
+
+// passwordAccepted checks whether the BMC accepts the credentials held
+// by the secret.
+func (r *BareMetalHostReconciler) passwordAccepted(info *reconcileInfo, secret *corev1.Secret) bool {
+	client, err := r.newAccountClient(info, *credentialsFromSecret(secret))
+	if err == nil {
+		err = client.CheckPassword()
+	}
+	if err != nil {
+		info.log.Info("BMC password not accepted", "secret", secret.Name, "reason", err.Error())
+		return false
+	}
+	return true
+}
+
+// setRotationState records the progress of the rotation on the secret
+// holding the new credentials.
+func (r *BareMetalHostReconciler) setRotationState(pending *corev1.Secret, state string) (ctrl.Result, bool, error) {
+	pending.Annotations[rotationStateAnnotation] = state
+	if err := r.Update(context.TODO(), pending); err != nil {
+		return ctrl.Result{}, false, errors.Wrap(err, "failed to update the rotated BMC credentials")
+	}
+	return ctrl.Result{Requeue: true}, false, nil
+}
+
+// verifyRotatedCredentials checks that the provisioner can manage the
+// host with the new password before storing it in the credentials
+// secret of the host.
+func (r *BareMetalHostReconciler) verifyRotatedCredentials(prov provisioner.Provisioner, info *reconcileInfo, pending *corev1.Secret, bmcCABundle []byte) (ctrl.Result, bool, error) {
+	newCreds := credentialsFromSecret(pending)
+	hostData := provisioner.BuildHostData(*info.host, *newCreds)
+	hostData.BMCCABundle = bmcCABundle
+	newProv, err := r.ProvisionerFactory.NewProvisioner(hostData, info.publishEvent)
+	if err != nil {
+		return ctrl.Result{}, false, errors.Wrap(err, "failed to create provisioner")
+	}
+
+	provResult, _, err := newProv.ValidateManagementAccess(managementAccessData(info.host), true, false)
+	if err != nil {
+		return ctrl.Result{}, false, errors.Wrap(err, "failed to validate the rotated BMC credentials")
+	}
+	if provResult.ErrorMessage != "" {
+		return r.rollbackRotatedCredentials(prov, info, pending, provResult.ErrorMessage)
+	}
+	if provResult.Dirty {
+		return ctrl.Result{Requeue: true, RequeueAfter: provResult.RequeueAfter}, false, nil
+	}
+
+	secret := info.bmcCredsSecret.DeepCopy()
+	secret.Data["password"] = pending.Data["password"]
+	if err := r.Update(context.TODO(), secret); err != nil {
+		return ctrl.Result{}, false, errors.Wrap(err, "failed to store the rotated BMC credentials")
+	}
+
+	r.publishRotationEvent(info, "CredentialsRotated",
+		fmt.Sprintf("Rotated the BMC password stored in secret %s", secret.Name))
+	return ctrl.Result{Requeue: true}, false, r.endCredentialsRotation(info, pending)
+}
+
+// rollbackRotatedCredentials restores the previous password after the
+// new one failed to verify. When that is not possible, the new
+// password is kept so that an administrator can recover the account.
+func (r *BareMetalHostReconciler) rollbackRotatedCredentials(prov provisioner.Provisioner, info *reconcileInfo, pending *corev1.Secret, reason string) (ctrl.Result, bool, error) {
+	info.log.Info("rotated BMC credentials failed verification, restoring the previous password", "reason", reason)
+
+	oldCreds := credentialsFromSecret(info.bmcCredsSecret)
+	client, err := r.newAccountClient(info, *credentialsFromSecret(pending))
+	if err == nil {
+		err = client.SetPassword(oldCreds.Password)
+	}
+	if err != nil {
+		pending.Annotations[rotationStateAnnotation] = rotationFailed
+		if err := r.Update(context.TODO(), pending); err != nil {
+			return ctrl.Result{}, false, errors.Wrap(err, "failed to update the rotated BMC credentials")
+		}
+		r.publishRotationEvent(info, "CredentialsRotationFailed",
+			fmt.Sprintf("The new BMC password failed verification (%s) and the previous one could not be restored (%s); the BMC password is stored in secret %s",
+				reason, err, pending.Name))
+		return ctrl.Result{Requeue: true}, false, r.endCredentialsRotation(info, nil)
+	}
+
+	// The provisioner was given the new password while verifying it
+	provResult, _, err := prov.ValidateManagementAccess(managementAccessData(info.host), true, false)
+	if err != nil || provResult.ErrorMessage != "" {
+		info.log.Info("could not restore the previous credentials in the provisioner",
+			"error", err, "message", provResult.ErrorMessage)
+	}
+
+	r.publishRotationEvent(info, "CredentialsRotationFailed",
+		fmt.Sprintf("The new BMC password failed verification, restored the previous one: %s", reason))
+	return ctrl.Result{Requeue: true}, false, r.endCredentialsRotation(info, pending)
+}
+
+// endCredentialsRotation removes the rotate-credentials annotation of
+// the host and the secret of the rotation, if given.
+func (r *BareMetalHostReconciler) endCredentialsRotation(info *reconcileInfo, pending *corev1.Secret) error {
+	if pending != nil {
+		if err := r.Delete(context.TODO(), pending); err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to delete the rotated BMC credentials")
+		}
+	}
+
+	if !hasRotateCredentialsAnnotation(info.host) {
+		return nil
+	}
+	delete(info.host.Annotations, metal3v1alpha1.RotateCredentialsAnnotation)
+	if err := r.Update(context.TODO(), info.host); err != nil {
+		return errors.Wrap(err, "failed to remove the rotate-credentials annotation")
+	}
+	return nil
+}
+
+func (r *BareMetalHostReconciler) publishRotationEvent(info *reconcileInfo, reason, message string) {
+	r.publishEvent(info.request, info.host.NewEvent(reason, message))
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	goctx "context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+	bmctestserver "github.com/metal3-io/baremetal-operator/pkg/bmc/testserver"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner/fixture"
+)
+
+func newRotationHost(t *testing.T, fix *fixture.Fixture, server *bmctestserver.RedfishMock) (*metal3v1alpha1.BareMetalHost, *BareMetalHostReconciler) {
+	host := newDefaultHost(t)
+	host.Spec.BMC.Address = server.Address()
+	host.Spec.BootMACAddress = "11:22:33:44:55:66"
+	r := newTestReconcilerWithFixture(fix, host)
+	r.AccountClientFactory = bmc.NewAccountClient
+	waitForProvisioningState(t, r, host, metal3v1alpha1.StateReady)
+	return host, r
+}
+
+func requestCredentialsRotation(t *testing.T, r *BareMetalHostReconciler, host *metal3v1alpha1.BareMetalHost) {
+	host = loadHostByName(t, r, host.Name)
+	host.Annotations = map[string]string{metal3v1alpha1.RotateCredentialsAnnotation: ""}
+	assert.NoError(t, r.Update(goctx.TODO(), host))
+
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return !hasRotateCredentialsAnnotation(host)
+		},
+	)
+}
+
+func loadBMCSecret(t *testing.T, r *BareMetalHostReconciler) *corev1.Secret {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: namespace, Name: defaultSecretName}
+	if err := r.Get(goctx.TODO(), key, secret); err != nil {
+		t.Fatal(err)
+	}
+	return secret
+}
+
+func assertNoRotationSecret(t *testing.T, r *BareMetalHostReconciler, host *metal3v1alpha1.BareMetalHost) {
+	err := r.Get(goctx.TODO(), rotationSecretKey(host), &corev1.Secret{})
+	assert.True(t, k8serrors.IsNotFound(err), "unexpected error %v", err)
+}
+
+func TestRotateCredentials(t *testing.T) {
+	server := newRedfishTestServer(t).Start()
+	defer server.Stop()
+
+	host, r := newRotationHost(t, &fixture.Fixture{}, server)
+	requestCredentialsRotation(t, r, host)
+
+	secret := loadBMCSecret(t, r)
+	assert.Equal(t, server.Password(), string(secret.Data["password"]))
+	assert.NotEqual(t, base64.StdEncoding.EncodeToString([]byte("Pass")), server.Password())
+	assertNoRotationSecret(t, r, host)
+
+	// The host is registered again with the new credentials
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return host.Status.GoodCredentials.Match(*loadBMCSecret(t, r))
+		},
+	)
+}
+
+func TestRotateCredentialsRollback(t *testing.T) {
+	server := newRedfishTestServer(t).Start()
+	defer server.Stop()
+
+	fix := fixture.Fixture{}
+	host, r := newRotationHost(t, &fix, server)
+	oldSecret := loadBMCSecret(t, r)
+
+	fix.SetValidateError("new password rejected")
+	requestCredentialsRotation(t, r, host)
+
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("Pass")), server.Password())
+	assert.Equal(t, oldSecret.Data, loadBMCSecret(t, r).Data)
+	assertNoRotationSecret(t, r, host)
+}
+
+func TestRotateCredentialsRejected(t *testing.T) {
+	server := newRedfishTestServer(t).RejectingPasswordChanges().Start()
+	defer server.Stop()
+
+	host, r := newRotationHost(t, &fixture.Fixture{}, server)
+	oldSecret := loadBMCSecret(t, r)
+
+	requestCredentialsRotation(t, r, host)
+
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("Pass")), server.Password())
+	assert.Equal(t, oldSecret.Data, loadBMCSecret(t, r).Data)
+	assertNoRotationSecret(t, r, host)
+}
+
+func TestRotateCredentialsSetFailed(t *testing.T) {
+	server := newRedfishTestServer(t).FailingPasswordChanges().Start()
+	defer server.Stop()
+
+	host, r := newRotationHost(t, &fixture.Fixture{}, server)
+	requestCredentialsRotation(t, r, host)
+
+	// The BMC changed the password despite the error, so the new one
+	// is verified and stored
+	assert.NotEqual(t, base64.StdEncoding.EncodeToString([]byte("Pass")), server.Password())
+	assert.Equal(t, server.Password(), string(loadBMCSecret(t, r).Data["password"]))
+	assertNoRotationSecret(t, r, host)
+}
@@ -37,6 +37,28 @@ func (e ResolveBMCSecretRefError) Error() string {
 		e.message)
 }
 
+// ResolveBMCCredentialsError is returned when the BMC credentials of
+// a host cannot be read from an external store
+type ResolveBMCCredentialsError struct {
+	message string
+}
+
+func (e ResolveBMCCredentialsError) Error() string {
+	return fmt.Sprintf("BMC credentials cannot be resolved %s",
+		e.message)
+}
+
+// ResolveBMCCABundleError is returned when the CA bundle of the BMC of
+// a host is defined but cannot be found
+type ResolveBMCCABundleError struct {
+	message string
+}
+
+func (e ResolveBMCCABundleError) Error() string {
+	return fmt.Sprintf("BMC CA bundle cannot be resolved %s",
+		e.message)
+}
+
 // SaveBMCSecretOwnerError is returned when we
 // fail to set the owner of a secret
 type SaveBMCSecretOwnerError struct {
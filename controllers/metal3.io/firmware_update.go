@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+)
+
+// firmwareUpdatesSaved returns true when the firmware updates in the
+// status of the host are the ones requested in its spec.
+func firmwareUpdatesSaved(host *metal3v1alpha1.BareMetalHost) bool {
+	saved := host.Status.Provisioning.FirmwareUpdates
+	if len(saved) != len(host.Spec.FirmwareUpdates) {
+		return false
+	}
+	for i, update := range host.Spec.FirmwareUpdates {
+		if saved[i].FirmwareUpdate != update {
+			return false
+		}
+	}
+	return true
+}
+
+// savedFirmwareUpdates returns the firmware updates in the status of
+// the host, to pass to the provisioner.
+func savedFirmwareUpdates(status *metal3v1alpha1.BareMetalHostStatus) []metal3v1alpha1.FirmwareUpdate {
+	if len(status.Provisioning.FirmwareUpdates) == 0 {
+		return nil
+	}
+	updates := make([]metal3v1alpha1.FirmwareUpdate, 0, len(status.Provisioning.FirmwareUpdates))
+	for _, update := range status.Provisioning.FirmwareUpdates {
+		updates = append(updates, update.FirmwareUpdate)
+	}
+	return updates
+}
+
+// firmwareVersions returns the versions of the firmware of the host
+// read from its BMC, or nil when the BMC cannot report them.
+func (r *BareMetalHostReconciler) firmwareVersions(info *reconcileInfo) map[metal3v1alpha1.FirmwareComponent]string {
+	if r.FirmwareClientFactory == nil || info.bmcCreds == nil {
+		return nil
+	}
+	versions, err := r.readFirmwareVersions(info)
+	if err != nil {
+		if _, unsupported := err.(bmc.FirmwareClientUnsupportedError); !unsupported {
+			info.log.Info("cannot read the firmware versions from the BMC", "reason", err.Error())
+		}
+		return nil
+	}
+	return versions
+}
+
+func (r *BareMetalHostReconciler) readFirmwareVersions(info *reconcileInfo) (map[metal3v1alpha1.FirmwareComponent]string, error) {
+	accessDetails, err := bmc.NewAccessDetails(info.host.Spec.BMC.Address,
+		info.host.Spec.BMC.DisableCertificateVerification)
+	if err != nil {
+		return nil, err
+	}
+	client, err := r.FirmwareClientFactory(accessDetails, *info.bmcCreds)
+	if err != nil {
+		return nil, err
+	}
+	return client.FirmwareVersions()
+}
+
+// startFirmwareUpdates records the versions of the components of the
+// host before the firmware updates are applied. When the BMC cannot
+// report them, the BIOS version found by the last inspection is used.
+func (r *BareMetalHostReconciler) startFirmwareUpdates(info *reconcileInfo) {
+	updates := info.host.Status.Provisioning.FirmwareUpdates
+	if len(updates) == 0 {
+		return
+	}
+	versions := r.firmwareVersions(info)
+	if versions == nil && info.host.Status.HardwareDetails != nil {
+		versions = map[metal3v1alpha1.FirmwareComponent]string{
+			metal3v1alpha1.FirmwareComponentBIOS: info.host.Status.HardwareDetails.Firmware.BIOS.Version,
+		}
+	}
+	for i := range updates {
+		updates[i].PreviousVersion = versions[updates[i].Component]
+		updates[i].CurrentVersion = ""
+	}
+}
+
+// checkFirmwareUpdates records the versions of the components of the
+// host once the firmware updates are applied, and returns a message
+// describing the components that do not report the expected version.
+// Components whose version cannot be read from the BMC are not
+// checked, as the inspection data predates the update.
+func (r *BareMetalHostReconciler) checkFirmwareUpdates(info *reconcileInfo) (mismatch string) {
+	updates := info.host.Status.Provisioning.FirmwareUpdates
+	pending := false
+	for _, update := range updates {
+		if update.CurrentVersion == "" {
+			pending = true
+		}
+	}
+	if !pending {
+		return ""
+	}
+
+	versions := r.firmwareVersions(info)
+	mismatches := []string{}
+	for i := range updates {
+		current := versions[updates[i].Component]
+		if current == "" {
+			continue
+		}
+		updates[i].CurrentVersion = current
+		if current != updates[i].Version {
+			mismatches = append(mismatches, fmt.Sprintf("%s version is %s, expected %s",
+				updates[i].Component, current, updates[i].Version))
+		}
+	}
+	if len(mismatches) != 0 {
+		mismatch = "Firmware update failed: " + strings.Join(mismatches, "; ")
+	}
+	return mismatch
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	goctx "context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+)
+
+// fakeFirmwareClient reports each set of versions in turn, then keeps
+// reporting the last one
+type fakeFirmwareClient struct {
+	versions []map[metal3v1alpha1.FirmwareComponent]string
+}
+
+func (c *fakeFirmwareClient) FirmwareVersions() (map[metal3v1alpha1.FirmwareComponent]string, error) {
+	versions := c.versions[0]
+	if len(c.versions) > 1 {
+		c.versions = c.versions[1:]
+	}
+	return versions, nil
+}
+
+func newFirmwareUpdateHost(t *testing.T, client *fakeFirmwareClient) (*metal3v1alpha1.BareMetalHost, *BareMetalHostReconciler) {
+	host := newDefaultHost(t)
+	host.Spec.FirmwareUpdates = []metal3v1alpha1.FirmwareUpdate{
+		{
+			Component: metal3v1alpha1.FirmwareComponentBIOS,
+			URL:       "http://images.example.com/bios-2.11.0.exe",
+			Version:   "2.11.0",
+		},
+		{
+			Component: metal3v1alpha1.FirmwareComponentNIC,
+			URL:       "http://images.example.com/nic-20.5.13.bin",
+			Version:   "20.5.13",
+		},
+	}
+	r := newTestReconciler(host)
+	r.FirmwareClientFactory = func(bmc.AccessDetails, bmc.Credentials) (bmc.FirmwareClient, error) {
+		return client, nil
+	}
+	return host, r
+}
+
+func TestFirmwareUpdate(t *testing.T) {
+	client := &fakeFirmwareClient{
+		versions: []map[metal3v1alpha1.FirmwareComponent]string{
+			{
+				metal3v1alpha1.FirmwareComponentBIOS: "2.10.2",
+			},
+			{
+				metal3v1alpha1.FirmwareComponentBIOS: "2.11.0",
+				metal3v1alpha1.FirmwareComponentNIC:  "20.5.13",
+			},
+		},
+	}
+	host, r := newFirmwareUpdateHost(t, client)
+
+	waitForProvisioningState(t, r, host, metal3v1alpha1.StateReady)
+
+	host = loadHostByName(t, r, host.Name)
+	assert.Equal(t, "", host.Status.ErrorMessage)
+	assert.Equal(t, []metal3v1alpha1.FirmwareUpdateStatus{
+		{
+			FirmwareUpdate:  host.Spec.FirmwareUpdates[0],
+			PreviousVersion: "2.10.2",
+			CurrentVersion:  "2.11.0",
+		},
+		{
+			FirmwareUpdate: host.Spec.FirmwareUpdates[1],
+			CurrentVersion: "20.5.13",
+		},
+	}, host.Status.Provisioning.FirmwareUpdates)
+}
+
+func TestFirmwareUpdateVersionMismatch(t *testing.T) {
+	client := &fakeFirmwareClient{
+		versions: []map[metal3v1alpha1.FirmwareComponent]string{
+			{
+				metal3v1alpha1.FirmwareComponentBIOS: "2.10.2",
+			},
+		},
+	}
+	host, r := newFirmwareUpdateHost(t, client)
+
+	waitForError(t, r, host)
+
+	host = loadHostByName(t, r, host.Name)
+	assert.Equal(t, metal3v1alpha1.StatePreparing, host.Status.Provisioning.State)
+	assert.Equal(t, metal3v1alpha1.PreparationError, host.Status.ErrorType)
+	assert.Equal(t, "Firmware update failed: bios version is 2.10.2, expected 2.11.0", host.Status.ErrorMessage)
+	assert.Equal(t, "2.10.2", host.Status.Provisioning.FirmwareUpdates[0].PreviousVersion)
+	assert.Equal(t, "2.10.2", host.Status.Provisioning.FirmwareUpdates[0].CurrentVersion)
+
+	// Once the update applies, the host is prepared again
+	client.versions = []map[metal3v1alpha1.FirmwareComponent]string{
+		{
+			metal3v1alpha1.FirmwareComponentBIOS: "2.11.0",
+		},
+	}
+	host.Status.ErrorCount = 0
+	assert.NoError(t, r.Status().Update(goctx.TODO(), host))
+	waitForProvisioningState(t, r, host, metal3v1alpha1.StateReady)
+
+	host = loadHostByName(t, r, host.Name)
+	assert.Equal(t, "", host.Status.ErrorMessage)
+	assert.Equal(t, "2.11.0", host.Status.Provisioning.FirmwareUpdates[0].CurrentVersion)
+}
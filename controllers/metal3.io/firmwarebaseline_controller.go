@@ -0,0 +1,249 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+// FirmwareBaselineReconciler compares the firmware versions of hosts
+// with the FirmwareBaseline of their hardware profile
+type FirmwareBaselineReconciler struct {
+	client.Client
+	Log logr.Logger
+}
+
+// +kubebuilder:rbac:groups=metal3.io,resources=firmwarebaselines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metal3.io,resources=firmwarebaselines/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts/status,verbs=get;update;patch
+
+// Reconcile compares all the hosts of the namespace of the baseline
+// with the baselines of the namespace, as each host uses at most one
+// baseline, and removing a baseline changes the hosts using it.
+func (r *FirmwareBaselineReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("firmwarebaseline", request.NamespacedName)
+
+	baselines := &metal3v1alpha1.FirmwareBaselineList{}
+	if err := r.List(ctx, baselines, client.InNamespace(request.Namespace)); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "could not list baselines")
+	}
+	hosts := &metal3v1alpha1.BareMetalHostList{}
+	if err := r.List(ctx, hosts, client.InNamespace(request.Namespace)); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "could not list hosts")
+	}
+
+	// When several baselines name the same hardware profile, the
+	// first one by name is used.
+	sort.Slice(baselines.Items, func(i, j int) bool {
+		return baselines.Items[i].Name < baselines.Items[j].Name
+	})
+	byProfile := map[string]*metal3v1alpha1.FirmwareBaseline{}
+	statuses := map[string]*metal3v1alpha1.FirmwareBaselineStatus{}
+	found := false
+	for i := range baselines.Items {
+		baseline := &baselines.Items[i]
+		if baseline.Name == request.Name {
+			found = true
+		}
+		if _, exists := byProfile[baseline.Spec.HardwareProfile]; !exists {
+			byProfile[baseline.Spec.HardwareProfile] = baseline
+		}
+		statuses[baseline.Name] = &metal3v1alpha1.FirmwareBaselineStatus{
+			ObservedGeneration: baseline.Generation,
+		}
+	}
+	if !found {
+		firmwareOutOfBaselineHosts.DeleteLabelValues(request.Namespace, request.Name)
+	}
+
+	requeue := false
+	for i := range hosts.Items {
+		host := &hosts.Items[i]
+		baseline := byProfile[host.Status.HardwareProfile]
+		if baseline != nil && host.Status.HardwareDetails != nil {
+			status := statuses[baseline.Name]
+			status.Hosts++
+			if len(firmwareDrift(host.Status.HardwareDetails, &baseline.Spec)) != 0 {
+				status.OutOfBaselineHosts = append(status.OutOfBaselineHosts, host.Name)
+			}
+		}
+
+		if !setFirmwareBaselineCondition(host, baseline) {
+			continue
+		}
+		reqLogger.Info("updating firmware baseline condition", "host", host.Name)
+		if err := r.Status().Update(ctx, host); err != nil {
+			if k8serrors.IsConflict(err) || k8serrors.IsNotFound(err) {
+				requeue = true
+				continue
+			}
+			return ctrl.Result{}, errors.Wrap(err, "failed to update host status")
+		}
+	}
+
+	for i := range baselines.Items {
+		baseline := &baselines.Items[i]
+		status := statuses[baseline.Name]
+		sort.Strings(status.OutOfBaselineHosts)
+		firmwareOutOfBaselineHosts.WithLabelValues(baseline.Namespace, baseline.Name).
+			Set(float64(len(status.OutOfBaselineHosts)))
+		if reflect.DeepEqual(baseline.Status, *status) {
+			continue
+		}
+		baseline.Status = *status
+		if err := r.Status().Update(ctx, baseline); err != nil {
+			if k8serrors.IsConflict(err) || k8serrors.IsNotFound(err) {
+				requeue = true
+				continue
+			}
+			return ctrl.Result{}, errors.Wrap(err, "failed to update baseline status")
+		}
+	}
+
+	return ctrl.Result{Requeue: requeue}, nil
+}
+
+// setFirmwareBaselineCondition sets the FirmwareInBaseline condition
+// of the host from its baseline, or removes it when the host has no
+// baseline. It returns true when the condition changed.
+func setFirmwareBaselineCondition(host *metal3v1alpha1.BareMetalHost, baseline *metal3v1alpha1.FirmwareBaseline) bool {
+	condType := metal3v1alpha1.FirmwareInBaselineCondition
+	existing := meta.FindStatusCondition(host.Status.Conditions, condType)
+	if baseline == nil {
+		if existing == nil {
+			return false
+		}
+		meta.RemoveStatusCondition(&host.Status.Conditions, condType)
+		return true
+	}
+
+	var cond hostCondition
+	switch drift := firmwareDrift(host.Status.HardwareDetails, &baseline.Spec); {
+	case host.Status.HardwareDetails == nil:
+		cond = conditionUnknown("NotInspected")
+	case len(drift) != 0:
+		cond = conditionFalse("OutOfBaseline")
+		cond.message = strings.Join(drift, "; ")
+	default:
+		cond = conditionTrue("InBaseline")
+		cond.message = fmt.Sprintf("Firmware matches the baseline %s", baseline.Name)
+	}
+
+	if existing != nil && existing.Status == cond.status && existing.Reason == cond.reason &&
+		existing.Message == cond.message && existing.ObservedGeneration == host.Generation {
+		return false
+	}
+	meta.SetStatusCondition(&host.Status.Conditions, metav1.Condition{
+		Type:               condType,
+		Status:             cond.status,
+		ObservedGeneration: host.Generation,
+		Reason:             cond.reason,
+		Message:            cond.message,
+	})
+	return true
+}
+
+// firmwareDrift returns a description of each firmware version of the
+// host that differs from the baseline. Versions the inspection did not
+// report are not compared.
+func firmwareDrift(details *metal3v1alpha1.HardwareDetails, baseline *metal3v1alpha1.FirmwareBaselineSpec) (drift []string) {
+	if details == nil {
+		return nil
+	}
+	compare := func(component, actual, expected string) {
+		if actual != "" && expected != "" && actual != expected {
+			drift = append(drift, fmt.Sprintf("%s version is %s, expected %s", component, actual, expected))
+		}
+	}
+
+	compare("BIOS", details.Firmware.BIOS.Version, baseline.BIOSVersion)
+	compare("BMC", details.Firmware.BMCVersion, baseline.BMCVersion)
+
+	// A NIC with both an IPv4 and an IPv6 address is listed twice
+	seen := map[string]bool{}
+	for _, nic := range details.NIC {
+		if seen[nic.Name] {
+			continue
+		}
+		seen[nic.Name] = true
+		compare("NIC "+nic.Name, nic.FirmwareVersion, baselineVersion(baseline.NICs, nic.Model))
+	}
+	for _, controller := range details.Firmware.RAIDControllers {
+		compare("RAID controller "+controller.Name, controller.FirmwareVersion,
+			baselineVersion(baseline.RAIDControllers, controller.Model))
+	}
+	for _, disk := range details.Storage {
+		compare("disk "+disk.Name, disk.FirmwareVersion, baselineVersion(baseline.Disks, disk.Model))
+	}
+	return drift
+}
+
+// baselineVersion returns the version of the first component of the
+// baseline matching the model, if any
+func baselineVersion(components []metal3v1alpha1.FirmwareBaselineComponent, model string) string {
+	for _, component := range components {
+		if strings.Contains(strings.ToLower(model), strings.ToLower(component.Model)) {
+			return component.Version
+		}
+	}
+	return ""
+}
+
+// hostToBaselines returns the baselines of the namespace of a host, to
+// compare the host again when it changes
+func (r *FirmwareBaselineReconciler) hostToBaselines(obj client.Object) []reconcile.Request {
+	baselines := &metal3v1alpha1.FirmwareBaselineList{}
+	if err := r.List(context.TODO(), baselines, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list baselines", "namespace", obj.GetNamespace())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(baselines.Items))
+	for _, baseline := range baselines.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: baseline.Namespace, Name: baseline.Name},
+		})
+	}
+	return requests
+}
+
+// SetupWithManager registers the reconciler to be run by the manager
+func (r *FirmwareBaselineReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&metal3v1alpha1.FirmwareBaseline{}).
+		Watches(&source.Kind{Type: &metal3v1alpha1.BareMetalHost{}},
+			handler.EnqueueRequestsFromMapFunc(r.hostToBaselines)).
+		Complete(r)
+}
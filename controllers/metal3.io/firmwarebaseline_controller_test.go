@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	promutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+func newTestBaselineReconciler(initObjs ...runtime.Object) *FirmwareBaselineReconciler {
+	return &FirmwareBaselineReconciler{
+		Client: fakeclient.NewFakeClient(initObjs...),
+		Log:    ctrl.Log.WithName("controllers").WithName("FirmwareBaseline"),
+	}
+}
+
+func newBaseline(name, profile string) *metal3v1alpha1.FirmwareBaseline {
+	return &metal3v1alpha1.FirmwareBaseline{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Generation: 1},
+		Spec: metal3v1alpha1.FirmwareBaselineSpec{
+			HardwareProfile: profile,
+			BIOSVersion:     "2.11.0",
+			BMCVersion:      "4.40.10.00",
+			NICs: []metal3v1alpha1.FirmwareBaselineComponent{
+				{Model: "0x15b3", Version: "14.27.1016"},
+				{Version: "21.80.16.92"},
+			},
+			Disks: []metal3v1alpha1.FirmwareBaselineComponent{
+				{Model: "mz7kh480", Version: "HXM7904Q"},
+			},
+		},
+	}
+}
+
+// newInventoriedHost returns a host of the profile with the firmware
+// versions of the baseline
+func newInventoriedHost(name, profile string) *metal3v1alpha1.BareMetalHost {
+	host := newHost(name, &metal3v1alpha1.BareMetalHostSpec{})
+	host.Status.HardwareProfile = profile
+	host.Status.HardwareDetails = &metal3v1alpha1.HardwareDetails{
+		Firmware: metal3v1alpha1.Firmware{
+			BIOS:       metal3v1alpha1.BIOS{Version: "2.11.0"},
+			BMCVersion: "4.40.10.00",
+		},
+		NIC: []metal3v1alpha1.NIC{
+			{Name: "eno1", Model: "0x15b3 0x1015", FirmwareVersion: "14.27.1016", IP: "192.0.2.1"},
+			{Name: "eno1", Model: "0x15b3 0x1015", FirmwareVersion: "14.27.1016", IP: "2001:db8::1"},
+			{Name: "eno2", Model: "0x14e4 0x16d7", FirmwareVersion: "21.80.16.92"},
+		},
+		Storage: []metal3v1alpha1.Storage{
+			{Name: "/dev/sda", Model: "MZ7KH480", FirmwareVersion: "HXM7904Q"},
+			{Name: "/dev/sdb", Model: "ST1000NX0443"},
+		},
+	}
+	return host
+}
+
+func reconcileBaseline(t *testing.T, r *FirmwareBaselineReconciler, name string) {
+	_, err := r.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: namespace, Name: name},
+	})
+	assert.NoError(t, err)
+}
+
+func baselineCondition(t *testing.T, r *FirmwareBaselineReconciler, name string) *metav1.Condition {
+	host := &metal3v1alpha1.BareMetalHost{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, host); err != nil {
+		t.Fatal(err)
+	}
+	return meta.FindStatusCondition(host.Status.Conditions, metal3v1alpha1.FirmwareInBaselineCondition)
+}
+
+func TestFirmwareBaseline(t *testing.T) {
+	drifted := newInventoriedHost("drifted", "dell")
+	drifted.Status.HardwareDetails.Firmware.BIOS.Version = "2.10.2"
+	drifted.Status.HardwareDetails.NIC[2].FirmwareVersion = "20.6.51"
+
+	uninspected := newHost("uninspected", &metal3v1alpha1.BareMetalHostSpec{})
+	uninspected.Status.HardwareProfile = "dell"
+
+	r := newTestBaselineReconciler(
+		newBaseline("dell-2021-q3", "dell"),
+		newInventoriedHost("current", "dell"),
+		drifted,
+		uninspected,
+		newInventoriedHost("other-profile", "libvirt"),
+	)
+	reconcileBaseline(t, r, "dell-2021-q3")
+
+	cond := baselineCondition(t, r, "current")
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Equal(t, "InBaseline", cond.Reason)
+
+	cond = baselineCondition(t, r, "drifted")
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "OutOfBaseline", cond.Reason)
+	assert.Equal(t, "BIOS version is 2.10.2, expected 2.11.0; NIC eno2 version is 20.6.51, expected 21.80.16.92", cond.Message)
+
+	cond = baselineCondition(t, r, "uninspected")
+	assert.Equal(t, metav1.ConditionUnknown, cond.Status)
+
+	assert.Nil(t, baselineCondition(t, r, "other-profile"))
+
+	baseline := &metal3v1alpha1.FirmwareBaseline{}
+	assert.NoError(t, r.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "dell-2021-q3"}, baseline))
+	assert.Equal(t, metal3v1alpha1.FirmwareBaselineStatus{
+		ObservedGeneration: 1,
+		Hosts:              2,
+		OutOfBaselineHosts: []string{"drifted"},
+	}, baseline.Status)
+	assert.Equal(t, 1.0, promutil.ToFloat64(firmwareOutOfBaselineHosts.WithLabelValues(namespace, "dell-2021-q3")))
+
+	// Once the baseline is removed, so is the condition
+	assert.NoError(t, r.Delete(context.TODO(), baseline))
+	reconcileBaseline(t, r, "dell-2021-q3")
+	assert.Nil(t, baselineCondition(t, r, "drifted"))
+	assert.Nil(t, baselineCondition(t, r, "current"))
+}
+
+func TestFirmwareBaselineSameProfile(t *testing.T) {
+	older := newBaseline("a-dell", "dell")
+	newer := newBaseline("b-dell", "dell")
+	newer.Spec.BIOSVersion = "2.12.1"
+
+	r := newTestBaselineReconciler(older, newer, newInventoriedHost("current", "dell"))
+	reconcileBaseline(t, r, "b-dell")
+
+	// The first baseline by name is used
+	cond := baselineCondition(t, r, "current")
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Equal(t, "Firmware matches the baseline a-dell", cond.Message)
+	assert.Equal(t, 0.0, promutil.ToFloat64(firmwareOutOfBaselineHosts.WithLabelValues(namespace, "b-dell")))
+}
@@ -0,0 +1,115 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/metal3-io/baremetal-operator/pkg/hardware"
+)
+
+// HardwareProfilesKey is the key of the ConfigMap data holding the
+// list of hardware profiles
+const HardwareProfilesKey = "profiles.yaml"
+
+// HardwareProfileConfigReconciler loads the hardware profiles defined
+// in a ConfigMap into a registry, and reloads them whenever the
+// ConfigMap changes.
+type HardwareProfileConfigReconciler struct {
+	// Reader is set by SetupWithManager to a cache holding only the
+	// profiles ConfigMap.
+	Reader    client.Reader
+	Log       logr.Logger
+	ConfigMap types.NamespacedName
+	Registry  *hardware.Registry
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+
+// Reconcile loads the profiles of the ConfigMap into the registry. The
+// previous profiles are kept when the new ones are not valid, and the
+// registry falls back to the built-in profiles when the ConfigMap is
+// deleted.
+func (r *HardwareProfileConfigReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("configmap", request.NamespacedName)
+
+	configMap := &corev1.ConfigMap{}
+	err := r.Reader.Get(ctx, request.NamespacedName, configMap)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			reqLogger.Info("hardware profiles ConfigMap not found, using the built-in profiles")
+			return ctrl.Result{}, r.Registry.Load(nil)
+		}
+		return ctrl.Result{}, errors.Wrap(err, "could not load hardware profiles ConfigMap")
+	}
+
+	profiles, err := hardware.ParseProfiles([]byte(configMap.Data[HardwareProfilesKey]))
+	if err == nil {
+		err = r.Registry.Load(profiles)
+	}
+	if err != nil {
+		// There is no point retrying until the ConfigMap changes.
+		reqLogger.Error(err, "invalid hardware profiles, keeping the previous ones")
+		return ctrl.Result{}, nil
+	}
+
+	reqLogger.Info("loaded hardware profiles", "count", len(profiles))
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager registers the reconciler to be run by the manager.
+// The ConfigMap is watched through a dedicated cache, restricted to its
+// name and namespace, so that the cache shared with the other
+// controllers keeps holding every ConfigMap of the watched namespace.
+func (r *HardwareProfileConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	configMapCache, err := cache.New(mgr.GetConfig(), cache.Options{
+		Scheme:    mgr.GetScheme(),
+		Mapper:    mgr.GetRESTMapper(),
+		Namespace: r.ConfigMap.Namespace,
+		SelectorsByObject: cache.SelectorsByObject{
+			&corev1.ConfigMap{}: {
+				Field: fields.OneTermEqualSelector("metadata.name", r.ConfigMap.Name),
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not create hardware profiles ConfigMap cache")
+	}
+	if err = mgr.Add(configMapCache); err != nil {
+		return err
+	}
+	r.Reader = configMapCache
+
+	c, err := controller.New("hardwareprofileconfig", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	return c.Watch(source.NewKindWithCache(&corev1.ConfigMap{}, configMapCache), &handler.EnqueueRequestForObject{})
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/metal3-io/baremetal-operator/pkg/hardware"
+)
+
+func TestHardwareProfileConfigReload(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hardware-profiles",
+			Namespace: namespace,
+		},
+		Data: map[string]string{
+			HardwareProfilesKey: `
+- name: r640
+  rootDeviceHints:
+    hctl: "0:2:0:0"
+`,
+		},
+	}
+	key := types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}
+	c := fakeclient.NewFakeClient(configMap)
+	r := &HardwareProfileConfigReconciler{
+		Reader:    c,
+		Log:       ctrl.Log.WithName("controllers").WithName("HardwareProfileConfig"),
+		ConfigMap: key,
+		Registry:  &hardware.Registry{},
+	}
+	request := ctrl.Request{NamespacedName: key}
+
+	_, err := r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	profile, err := r.Registry.GetProfile("r640")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "0:2:0:0", profile.RootDeviceHints.HCTL)
+	}
+
+	// The profiles are reloaded when the ConfigMap changes
+	configMap.Data[HardwareProfilesKey] = `
+- name: r640
+  rootDeviceHints:
+    hctl: "1:0:0:0"
+`
+	assert.NoError(t, c.Update(context.TODO(), configMap))
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	profile, err = r.Registry.GetProfile("r640")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1:0:0:0", profile.RootDeviceHints.HCTL)
+	}
+
+	// Invalid profiles are ignored
+	configMap.Data[HardwareProfilesKey] = `
+- name: r640
+  rootGB: -1
+`
+	assert.NoError(t, c.Update(context.TODO(), configMap))
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	profile, err = r.Registry.GetProfile("r640")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1:0:0:0", profile.RootDeviceHints.HCTL)
+	}
+
+	// Only the built-in profiles remain once the ConfigMap is deleted
+	assert.NoError(t, c.Delete(context.TODO(), configMap))
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	_, err = r.Registry.GetProfile("r640")
+	assert.Error(t, err)
+	_, err = r.Registry.GetProfile("dell")
+	assert.NoError(t, err)
+}
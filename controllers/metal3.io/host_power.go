@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner"
+)
+
+const (
+	nativePowerRequeueDelay = time.Second * 10
+	nativeSoftPowerOffDelay = time.Second * 180
+)
+
+// powerManager is the part of the provisioner interface used to
+// monitor and change the power state of a host
+type powerManager interface {
+	UpdateHardwareState() (provisioner.HardwareState, error)
+	PowerOn(force bool) (provisioner.Result, error)
+	PowerOff(rebootMode metal3v1alpha1.RebootMode, force bool) (provisioner.Result, error)
+}
+
+// hostPowerManager returns the power manager to use for the host. The
+// BMC is used directly when the reconciler has a PowerClientFactory
+// supporting the BMC of the host, otherwise the provisioner is used.
+func (r *BareMetalHostReconciler) hostPowerManager(prov provisioner.Provisioner, info *reconcileInfo) powerManager {
+	if r.PowerClientFactory == nil || info.bmcCredsSecret == nil {
+		return prov
+	}
+
+	accessDetails, err := bmc.NewAccessDetails(info.host.Spec.BMC.Address,
+		info.host.Spec.BMC.DisableCertificateVerification)
+	if err != nil {
+		return prov
+	}
+	client, err := r.PowerClientFactory(accessDetails, *info.bmcCreds)
+	if err != nil {
+		if _, unsupported := err.(bmc.PowerClientUnsupportedError); !unsupported {
+			info.log.Info("cannot manage power through the BMC, using the provisioner", "reason", err.Error())
+		}
+		return prov
+	}
+
+	return &nativePowerManager{
+		client:        client,
+		log:           info.log.WithName("power"),
+		publisher:     info.publishEvent,
+		hostUID:       info.host.UID,
+		softPowerOffs: &r.softPowerOffs,
+	}
+}
+
+// nativePowerManager manages the power of a host by talking to its BMC
+// directly, so that the provisioner is not needed to monitor hosts in
+// a steady state.
+type nativePowerManager struct {
+	client    bmc.PowerClient
+	log       logr.Logger
+	publisher provisioner.EventPublisher
+
+	// The BMC does not report the failure of a graceful shutdown, so
+	// the time it was requested is kept, by host, to fall back to a
+	// hard power off once it takes too long.
+	hostUID       types.UID
+	softPowerOffs *sync.Map
+}
+
+func powerContinuing() (provisioner.Result, error) {
+	return provisioner.Result{Dirty: true, RequeueAfter: nativePowerRequeueDelay}, nil
+}
+
+// UpdateHardwareState reads the power state of the host from the BMC
+func (m *nativePowerManager) UpdateHardwareState() (hwState provisioner.HardwareState, err error) {
+	state, err := m.client.PowerState()
+	if err != nil {
+		return hwState, errors.Wrap(err, "failed to read the power state from the BMC")
+	}
+
+	switch state {
+	case bmc.PowerStateOn, bmc.PowerStateOff:
+		discoveredVal := state == bmc.PowerStateOn
+		hwState.PoweredOn = &discoveredVal
+	default:
+		m.log.Info("power state is changing", "value", state)
+	}
+	return hwState, nil
+}
+
+// PowerOn ensures the host is powered on
+func (m *nativePowerManager) PowerOn(force bool) (result provisioner.Result, err error) {
+	m.softPowerOffs.Delete(m.hostUID)
+
+	state, err := m.client.PowerState()
+	if err != nil {
+		return result, errors.Wrap(err, "failed to read the power state from the BMC")
+	}
+	switch state {
+	case bmc.PowerStateOn:
+		return result, nil
+	case bmc.PowerStatePoweringOn:
+		m.log.Info("waiting for power status to change")
+		return powerContinuing()
+	}
+
+	if err = m.client.Reset(bmc.ResetOn); err != nil {
+		return result, errors.Wrap(err, "failed to power on host")
+	}
+	m.publisher("PowerOn", "Host powered on")
+	return powerContinuing()
+}
+
+// PowerOff ensures the host is powered off
+func (m *nativePowerManager) PowerOff(rebootMode metal3v1alpha1.RebootMode, force bool) (result provisioner.Result, err error) {
+	state, err := m.client.PowerState()
+	if err != nil {
+		return result, errors.Wrap(err, "failed to read the power state from the BMC")
+	}
+	switch state {
+	case bmc.PowerStateOff:
+		m.softPowerOffs.Delete(m.hostUID)
+		return result, nil
+	case bmc.PowerStatePoweringOff:
+		m.log.Info("waiting for power status to change")
+		return powerContinuing()
+	}
+
+	if rebootMode == metal3v1alpha1.RebootModeSoft {
+		return m.softPowerOff()
+	}
+
+	m.softPowerOffs.Delete(m.hostUID)
+	if err = m.client.Reset(bmc.ResetForceOff); err != nil {
+		return result, errors.Wrap(err, "failed to power off host")
+	}
+	m.publisher("PowerOff", "Host powered off")
+	return powerContinuing()
+}
+
+func (m *nativePowerManager) softPowerOff() (result provisioner.Result, err error) {
+	if started, ok := m.softPowerOffs.Load(m.hostUID); ok {
+		if time.Since(started.(time.Time)) < nativeSoftPowerOffDelay {
+			m.log.Info("waiting for the host to shut down")
+			return powerContinuing()
+		}
+		m.softPowerOffs.Delete(m.hostUID)
+		result.ErrorMessage = fmt.Sprintf("host did not shut down within %s", nativeSoftPowerOffDelay)
+		return result, nil
+	}
+
+	err = m.client.Reset(bmc.ResetGracefulShutdown)
+	if err != nil {
+		if _, unsupported := err.(bmc.ResetTypeUnsupportedError); unsupported {
+			result.ErrorMessage = err.Error()
+			return result, nil
+		}
+		return result, errors.Wrap(err, "failed to power off host")
+	}
+	m.softPowerOffs.Store(m.hostUID, time.Now())
+	m.publisher("PowerOff", "Host soft powered off")
+	return powerContinuing()
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	goctx "context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+	bmctestserver "github.com/metal3-io/baremetal-operator/pkg/bmc/testserver"
+)
+
+// newRedfishTestServer returns a Redfish mock accepting the credentials
+// of the default secret
+func newRedfishTestServer(t *testing.T) *bmctestserver.RedfishMock {
+	// newSecret encodes the values it stores
+	encode := func(value string) string {
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	return bmctestserver.NewRedfish(t, encode("User"), encode("Pass"))
+}
+
+func newNativePowerHost(t *testing.T, server *bmctestserver.RedfishMock) (*metal3v1alpha1.BareMetalHost, *BareMetalHostReconciler) {
+	host := newDefaultHost(t)
+	host.Spec.BMC.Address = server.Address()
+	host.Spec.BootMACAddress = "11:22:33:44:55:66"
+	r := newTestReconciler(host)
+	r.PowerClientFactory = bmc.NewPowerClient
+	return host, r
+}
+
+func TestNativePowerOnOff(t *testing.T) {
+	server := newRedfishTestServer(t).Start()
+	defer server.Stop()
+
+	host, r := newNativePowerHost(t, server)
+	host.Spec.Online = true
+	assert.NoError(t, r.Update(goctx.TODO(), host))
+
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			t.Logf("power status: %v", host.Status.PoweredOn)
+			return host.Status.PoweredOn
+		},
+	)
+	assert.Equal(t, "On", server.PowerState())
+
+	host = loadHostByName(t, r, host.Name)
+	host.Spec.Online = false
+	assert.NoError(t, r.Update(goctx.TODO(), host))
+
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			t.Logf("power status: %v", host.Status.PoweredOn)
+			return !host.Status.PoweredOn
+		},
+	)
+	assert.Equal(t, "Off", server.PowerState())
+	assert.Equal(t, []string{"On", "GracefulShutdown"}, server.ResetRequests())
+}
+
+func TestNativePowerSoftOffFallback(t *testing.T) {
+	server := newRedfishTestServer(t).WithPowerState("On").IgnoringShutdown().Start()
+	defer server.Stop()
+
+	host, r := newNativePowerHost(t, server)
+	host.Spec.Online = true
+	assert.NoError(t, r.Update(goctx.TODO(), host))
+	waitForProvisioningState(t, r, host, metal3v1alpha1.StateReady)
+
+	host = loadHostByName(t, r, host.Name)
+	host.Spec.Online = false
+	assert.NoError(t, r.Update(goctx.TODO(), host))
+
+	// The graceful shutdown is requested but ignored by the host
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return len(server.ResetRequests()) == 1
+		},
+	)
+	assert.Equal(t, "On", server.PowerState())
+
+	// Once it takes too long, the host is powered off
+	r.softPowerOffs.Store(host.UID, time.Now().Add(-nativeSoftPowerOffDelay))
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return !host.Status.PoweredOn && host.Status.ErrorMessage == ""
+		},
+	)
+	assert.Equal(t, "Off", server.PowerState())
+	assert.Equal(t, []string{"GracefulShutdown", "ForceOff"}, server.ResetRequests())
+}
+
+// TestNativePowerCABundle ensures that the certificate of the BMC is
+// verified with the CA bundle of the host when managing its power.
+func TestNativePowerCABundle(t *testing.T) {
+	server := newRedfishTestServer(t).StartTLSWithCA()
+	defer server.Stop()
+
+	host, r := newNativePowerHost(t, server)
+	host.Spec.Online = true
+	host.Spec.BMC.CABundle = &metal3v1alpha1.CABundleReference{
+		Kind: metal3v1alpha1.CABundleKindConfigMap,
+		Name: "bmc-ca",
+	}
+	assert.NoError(t, r.Update(goctx.TODO(), host))
+	assert.NoError(t, r.Create(goctx.TODO(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "bmc-ca", Namespace: namespace},
+		Data:       map[string]string{metal3v1alpha1.DefaultCABundleKey: string(server.CABundle())},
+	}))
+
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return host.Status.PoweredOn
+		},
+	)
+	assert.Equal(t, "On", server.PowerState())
+	assert.Equal(t, []string{"On"}, server.ResetRequests())
+}
+
+func TestNativePowerUnsupportedBMC(t *testing.T) {
+	host := newDefaultHost(t)
+	host.Spec.Online = true
+	r := newTestReconciler(host)
+	r.PowerClientFactory = bmc.NewPowerClient
+
+	// The ipmi host is powered on through the fixture provisioner
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return host.Status.PoweredOn
+		},
+	)
+}
+
+func loadHostByName(t *testing.T, r *BareMetalHostReconciler, name string) *metal3v1alpha1.BareMetalHost {
+	host := &metal3v1alpha1.BareMetalHost{}
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	if err := r.Get(goctx.TODO(), key, host); err != nil {
+		t.Fatal(err)
+	}
+	return host
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/metal3-io/baremetal-operator/pkg/provisioner"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -80,13 +81,24 @@ func recordStateEnd(info *reconcileInfo, host *metal3v1alpha1.BareMetalHost, sta
 }
 
 func (hsm *hostStateMachine) ensureCapacity(info *reconcileInfo, state metal3v1alpha1.ProvisioningState) actionResult {
+	queue, err := hsm.Reconciler.capacityQueue(info.host)
+	if err != nil {
+		return actionError{errors.Wrap(err, "failed to determine capacity pool queue")}
+	}
+
+	// The provisioner is only asked for a slot when it is the turn of
+	// the host in the queue of its pool
+	if queue != nil && !queue.hasCapacity() {
+		return recordActionDelayed(info, state, queue)
+	}
+
 	hasCapacity, err := hsm.Provisioner.HasCapacity()
 	if err != nil {
 		return actionError{errors.Wrap(err, "failed to determine current provisioner capacity")}
 	}
 
 	if !hasCapacity {
-		return recordActionDelayed(info, state)
+		return recordActionDelayed(info, state, queue)
 	}
 
 	return nil
@@ -175,6 +187,11 @@ func (hsm *hostStateMachine) ReconcileState(info *reconcileInfo) (actionRes acti
 		if overrideAction := hsm.updateHostStateFrom(initialState, info); overrideAction != nil {
 			actionRes = overrideAction
 		}
+		// The status is saved when the conditions changed, even if
+		// the action did not change anything else.
+		if updateConditions(hsm.Host) && !actionRes.Dirty() {
+			actionRes = markDirty(actionRes)
+		}
 	}()
 
 	if delayedResult := hsm.checkDelayedHost(info); delayedResult != nil {
@@ -203,6 +220,180 @@ func (hsm *hostStateMachine) ReconcileState(info *reconcileInfo) (actionRes acti
 	return actionError{fmt.Errorf("No handler found for state \"%s\"", initialState)}
 }
 
+// hostCondition describes the desired value of one of the
+// conditions of a host.
+type hostCondition struct {
+	status  metav1.ConditionStatus
+	reason  string
+	message string
+}
+
+func conditionTrue(reason string) hostCondition {
+	return hostCondition{status: metav1.ConditionTrue, reason: reason}
+}
+
+func conditionFalse(reason string) hostCondition {
+	return hostCondition{status: metav1.ConditionFalse, reason: reason}
+}
+
+func conditionUnknown(reason string) hostCondition {
+	return hostCondition{status: metav1.ConditionUnknown, reason: reason}
+}
+
+// conditionError reports the current error of the host, using the
+// error type as the reason.
+func conditionError(host *metal3v1alpha1.BareMetalHost) hostCondition {
+	return hostCondition{
+		status:  metav1.ConditionFalse,
+		reason:  errorReasons[host.Status.ErrorType],
+		message: host.Status.ErrorMessage,
+	}
+}
+
+// errorReasons maps error types to condition reasons
+var errorReasons = map[metal3v1alpha1.ErrorType]string{
+	metal3v1alpha1.ProvisionedRegistrationError: "ProvisionedRegistrationError",
+	metal3v1alpha1.RegistrationError:            "RegistrationError",
+	metal3v1alpha1.InspectionError:              "InspectionError",
+	metal3v1alpha1.PreparationError:             "PreparationError",
+	metal3v1alpha1.ProvisioningError:            "ProvisioningError",
+	metal3v1alpha1.PowerManagementError:         "PowerManagementError",
+	metal3v1alpha1.DetachError:                  "DetachError",
+}
+
+// conditionTypes lists the conditions of a host in the order they are
+// added to the status.
+var conditionTypes = []string{
+	metal3v1alpha1.RegisteredCondition,
+	metal3v1alpha1.CredentialsValidCondition,
+	metal3v1alpha1.InspectedCondition,
+	metal3v1alpha1.PreparedCondition,
+	metal3v1alpha1.ProvisionedCondition,
+	metal3v1alpha1.PowerSyncedCondition,
+	metal3v1alpha1.DetachedCondition,
+}
+
+// updateConditions sets the conditions of the host from the rest of
+// its status, returning true when any of them changed.
+func updateConditions(host *metal3v1alpha1.BareMetalHost) (changed bool) {
+	state := host.Status.Provisioning.State
+	errType := host.Status.ErrorType
+	detached := host.OperationalStatus() == metal3v1alpha1.OperationalStatusDetached
+
+	conditions := map[string]hostCondition{}
+
+	switch {
+	case errType == metal3v1alpha1.RegistrationError || errType == metal3v1alpha1.ProvisionedRegistrationError:
+		conditions[metal3v1alpha1.RegisteredCondition] = conditionError(host)
+	case state == metal3v1alpha1.StateUnmanaged:
+		conditions[metal3v1alpha1.RegisteredCondition] = conditionFalse("Unmanaged")
+	case state == metal3v1alpha1.StateNone || state == metal3v1alpha1.StateRegistering:
+		conditions[metal3v1alpha1.RegisteredCondition] = conditionFalse("Registering")
+	case state == metal3v1alpha1.StateDeleting:
+		conditions[metal3v1alpha1.RegisteredCondition] = conditionFalse("Deleting")
+	case detached:
+		conditions[metal3v1alpha1.RegisteredCondition] = conditionFalse("Detached")
+	default:
+		conditions[metal3v1alpha1.RegisteredCondition] = conditionTrue("Registered")
+	}
+
+	goodCreds := host.Status.GoodCredentials
+	switch {
+	case errType == metal3v1alpha1.RegistrationError:
+		conditions[metal3v1alpha1.CredentialsValidCondition] = conditionError(host)
+	case goodCreds.Reference != nil && host.Status.TriedCredentials.Reference != nil &&
+		*goodCreds.Reference == *host.Status.TriedCredentials.Reference &&
+		goodCreds.Version == host.Status.TriedCredentials.Version:
+		conditions[metal3v1alpha1.CredentialsValidCondition] = conditionTrue("Validated")
+	default:
+		conditions[metal3v1alpha1.CredentialsValidCondition] = conditionUnknown("NotValidated")
+	}
+
+	switch {
+	case errType == metal3v1alpha1.InspectionError:
+		conditions[metal3v1alpha1.InspectedCondition] = conditionError(host)
+	case state == metal3v1alpha1.StateInspecting:
+		conditions[metal3v1alpha1.InspectedCondition] = conditionFalse("Inspecting")
+	case host.Status.HardwareDetails != nil:
+		conditions[metal3v1alpha1.InspectedCondition] = conditionTrue("Inspected")
+	case inspectionDisabled(host):
+		conditions[metal3v1alpha1.InspectedCondition] = conditionFalse("InspectionDisabled")
+	default:
+		conditions[metal3v1alpha1.InspectedCondition] = conditionFalse("NotInspected")
+	}
+
+	switch {
+	case errType == metal3v1alpha1.PreparationError:
+		conditions[metal3v1alpha1.PreparedCondition] = conditionError(host)
+	case state == metal3v1alpha1.StatePreparing:
+		conditions[metal3v1alpha1.PreparedCondition] = conditionFalse("Preparing")
+	case state == metal3v1alpha1.StateReady, state == metal3v1alpha1.StateAvailable,
+		state == metal3v1alpha1.StateProvisioning, state == metal3v1alpha1.StateProvisioned,
+		state == metal3v1alpha1.StateDeprovisioning:
+		conditions[metal3v1alpha1.PreparedCondition] = conditionTrue("Prepared")
+	default:
+		conditions[metal3v1alpha1.PreparedCondition] = conditionFalse("NotPrepared")
+	}
+
+	switch {
+	case errType == metal3v1alpha1.ProvisioningError:
+		conditions[metal3v1alpha1.ProvisionedCondition] = conditionError(host)
+	case state == metal3v1alpha1.StateProvisioned:
+		conditions[metal3v1alpha1.ProvisionedCondition] = conditionTrue("Provisioned")
+	case state == metal3v1alpha1.StateExternallyProvisioned:
+		conditions[metal3v1alpha1.ProvisionedCondition] = conditionTrue("ExternallyProvisioned")
+	case state == metal3v1alpha1.StateProvisioning:
+		conditions[metal3v1alpha1.ProvisionedCondition] = conditionFalse("Provisioning")
+	case state == metal3v1alpha1.StateDeprovisioning:
+		conditions[metal3v1alpha1.ProvisionedCondition] = conditionFalse("Deprovisioning")
+	default:
+		conditions[metal3v1alpha1.ProvisionedCondition] = conditionFalse("NotProvisioned")
+	}
+
+	// The power state is only managed by the operator in the steady
+	// states, the provisioner controls it in the others.
+	switch {
+	case errType == metal3v1alpha1.PowerManagementError:
+		conditions[metal3v1alpha1.PowerSyncedCondition] = conditionError(host)
+	case detached:
+		conditions[metal3v1alpha1.PowerSyncedCondition] = conditionUnknown("Detached")
+	case state != metal3v1alpha1.StateReady && state != metal3v1alpha1.StateAvailable &&
+		state != metal3v1alpha1.StateProvisioned && state != metal3v1alpha1.StateExternallyProvisioned:
+		conditions[metal3v1alpha1.PowerSyncedCondition] = conditionUnknown("NotManaged")
+	case host.Status.PoweredOn == host.Spec.Online:
+		conditions[metal3v1alpha1.PowerSyncedCondition] = conditionTrue("InSync")
+	default:
+		conditions[metal3v1alpha1.PowerSyncedCondition] = conditionFalse("OutOfSync")
+	}
+
+	switch {
+	case errType == metal3v1alpha1.DetachError:
+		conditions[metal3v1alpha1.DetachedCondition] = conditionError(host)
+	case detached:
+		conditions[metal3v1alpha1.DetachedCondition] = conditionTrue("Detached")
+	default:
+		conditions[metal3v1alpha1.DetachedCondition] = conditionFalse("NotDetached")
+	}
+
+	for _, condType := range conditionTypes {
+		cond := conditions[condType]
+		existing := meta.FindStatusCondition(host.Status.Conditions, condType)
+		if existing != nil && existing.Status == cond.status && existing.Reason == cond.reason &&
+			existing.Message == cond.message && existing.ObservedGeneration == host.Generation {
+			continue
+		}
+		meta.SetStatusCondition(&host.Status.Conditions, metav1.Condition{
+			Type:               condType,
+			Status:             cond.status,
+			ObservedGeneration: host.Generation,
+			Reason:             cond.reason,
+			Message:            cond.message,
+		})
+		changed = true
+	}
+	return changed
+}
+
 func updateBootModeStatus(host *metal3v1alpha1.BareMetalHost) bool {
 	// Make sure we have saved the current boot mode value.
 	bootMode := host.BootMode()
@@ -412,9 +603,11 @@ func (hsm *hostStateMachine) handleReady(info *reconcileInfo) actionResult {
 		return actionComplete{}
 	}
 
-	if dirty, _, err := getHostProvisioningSettings(info.host); err != nil {
-		return actionError{err}
-	} else if dirty {
+	hwProf, err := hsm.Reconciler.getHardwareProfile(info.host, info.host.HardwareProfile())
+	if err != nil {
+		return actionError{errors.Wrap(err, "could not determine the host provisioning settings")}
+	}
+	if dirty, _ := getHostProvisioningSettings(info.host, hwProf); dirty || firmwareSettingsChanged(info.firmwareSettings) {
 		hsm.NextState = metal3v1alpha1.StatePreparing
 		return actionComplete{}
 	}
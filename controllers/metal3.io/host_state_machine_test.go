@@ -8,6 +8,7 @@ import (
 	"github.com/metal3-io/baremetal-operator/pkg/bmc"
 
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
@@ -124,7 +125,7 @@ func TestProvisioningCapacity(t *testing.T) {
 		t.Run(tc.Scenario, func(t *testing.T) {
 			prov := newMockProvisioner()
 			prov.setHasCapacity(tc.HasProvisioningCapacity)
-			hsm := newHostStateMachine(tc.Host, &BareMetalHostReconciler{}, prov, true)
+			hsm := newHostStateMachine(tc.Host, newTestReconciler(), prov, true)
 			info := makeDefaultReconcileInfo(tc.Host)
 			delayedProvisioningHostCounters.Reset()
 
@@ -178,7 +179,7 @@ func TestDeprovisioningCapacity(t *testing.T) {
 		t.Run(tc.Scenario, func(t *testing.T) {
 			prov := newMockProvisioner()
 			prov.setHasCapacity(tc.HasDeprovisioningCapacity)
-			hsm := newHostStateMachine(tc.Host, &BareMetalHostReconciler{}, prov, true)
+			hsm := newHostStateMachine(tc.Host, newTestReconciler(), prov, true)
 			info := makeDefaultReconcileInfo(tc.Host)
 			delayedDeprovisioningHostCounters.Reset()
 
@@ -362,8 +363,11 @@ func TestDetach(t *testing.T) {
 					metal3v1alpha1.DetachedAnnotation: "true",
 				}
 			}
+			// Start from up to date conditions, so that only the
+			// changes made by the reconcile make the result dirty
+			updateConditions(tc.Host)
 			prov := newMockProvisioner()
-			hsm := newHostStateMachine(tc.Host, &BareMetalHostReconciler{}, prov, true)
+			hsm := newHostStateMachine(tc.Host, newTestReconciler(), prov, true)
 			info := makeDefaultReconcileInfo(tc.Host)
 			result := hsm.ReconcileState(info)
 
@@ -405,7 +409,7 @@ func TestDetachError(t *testing.T) {
 				metal3v1alpha1.DetachedAnnotation: "true",
 			}
 			prov := newMockProvisioner()
-			hsm := newHostStateMachine(tc.Host, &BareMetalHostReconciler{}, prov, true)
+			hsm := newHostStateMachine(tc.Host, newTestReconciler(), prov, true)
 			info := makeDefaultReconcileInfo(tc.Host)
 
 			prov.setNextError("Detach", "some error")
@@ -853,7 +857,7 @@ func TestErrorCountIncreasedOnActionFailure(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.Scenario, func(t *testing.T) {
 			prov := newMockProvisioner()
-			hsm := newHostStateMachine(tt.Host, &BareMetalHostReconciler{}, prov, true)
+			hsm := newHostStateMachine(tt.Host, newTestReconciler(), prov, true)
 			info := makeDefaultReconcileInfo(tt.Host)
 
 			prov.setNextError(tt.ProvisionerErrorOn, "some error")
@@ -913,7 +917,7 @@ func TestErrorCountClearedOnStateTransition(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.Scenario, func(t *testing.T) {
 			prov := newMockProvisioner()
-			hsm := newHostStateMachine(tt.Host, &BareMetalHostReconciler{}, prov, true)
+			hsm := newHostStateMachine(tt.Host, newTestReconciler(), prov, true)
 			info := makeDefaultReconcileInfo(tt.Host)
 
 			info.host.Status.ErrorCount = 1
@@ -950,7 +954,7 @@ func TestErrorClean(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.Scenario, func(t *testing.T) {
 			prov := newMockProvisioner()
-			hsm := newHostStateMachine(tt.Host, &BareMetalHostReconciler{}, prov, true)
+			hsm := newHostStateMachine(tt.Host, newTestReconciler(), prov, true)
 
 			info := makeDefaultReconcileInfo(tt.Host)
 			if tt.SecretName != "" {
@@ -1018,7 +1022,8 @@ func (hb *hostBuilder) build() *metal3v1alpha1.BareMetalHost {
 }
 
 func (hb *hostBuilder) SaveHostProvisioningSettings() *hostBuilder {
-	saveHostProvisioningSettings(&hb.BareMetalHost)
+	hwProf, _ := hardware.GetProfile(hb.HardwareProfile())
+	saveHostProvisioningSettings(&hb.BareMetalHost, hwProf)
 	return hb
 }
 
@@ -1120,6 +1125,10 @@ func (m *mockProvisioner) HasCapacity() (result bool, err error) {
 	return m.hasCapacity, nil
 }
 
+func (m *mockProvisioner) GetFirmwareSettings(includeSchema bool) (settings metal3v1alpha1.SettingsMap, schema map[string]metal3v1alpha1.SettingSchema, err error) {
+	return nil, nil, nil
+}
+
 func (m *mockProvisioner) setNextError(methodName, msg string) {
 	m.nextResults[methodName] = provisioner.Result{
 		ErrorMessage: msg,
@@ -1261,3 +1270,149 @@ func TestUpdateBootModeStatus(t *testing.T) {
 		})
 	}
 }
+
+// TestConditionsSaved ensures that the status is saved when only the
+// conditions changed, such as when the generation of the host changed.
+func TestConditionsSaved(t *testing.T) {
+	host := host(metal3v1alpha1.StateProvisioned).build()
+	host.Generation = 1
+	updateConditions(host)
+	host.Generation = 2
+
+	prov := newMockProvisioner()
+	hsm := newHostStateMachine(host, newTestReconciler(), prov, true)
+	result := hsm.ReconcileState(makeDefaultReconcileInfo(host))
+
+	assert.True(t, result.Dirty(), "expected the conditions to be saved")
+	cond := meta.FindStatusCondition(host.Status.Conditions, metal3v1alpha1.ProvisionedCondition)
+	if assert.NotNil(t, cond) {
+		assert.Equal(t, int64(2), cond.ObservedGeneration)
+	}
+
+	result = hsm.ReconcileState(makeDefaultReconcileInfo(host))
+	assert.False(t, result.Dirty(), "expected no change on second reconcile")
+}
+
+func TestUpdateConditions(t *testing.T) {
+	testCases := []struct {
+		Scenario string
+		Host     *metal3v1alpha1.BareMetalHost
+		Expected map[string]metav1.ConditionStatus
+		Reasons  map[string]string
+	}{
+		{
+			Scenario: "registering",
+			Host:     host(metal3v1alpha1.StateRegistering).build(),
+			Expected: map[string]metav1.ConditionStatus{
+				metal3v1alpha1.RegisteredCondition:  metav1.ConditionFalse,
+				metal3v1alpha1.InspectedCondition:   metav1.ConditionFalse,
+				metal3v1alpha1.PowerSyncedCondition: metav1.ConditionUnknown,
+				metal3v1alpha1.DetachedCondition:    metav1.ConditionFalse,
+			},
+			Reasons: map[string]string{
+				metal3v1alpha1.RegisteredCondition: "Registering",
+			},
+		},
+
+		{
+			Scenario: "registration error",
+			Host: host(metal3v1alpha1.StateRegistering).
+				SetStatusError(metal3v1alpha1.OperationalStatusError, metal3v1alpha1.RegistrationError, "bad credentials", 1).
+				build(),
+			Expected: map[string]metav1.ConditionStatus{
+				metal3v1alpha1.RegisteredCondition:       metav1.ConditionFalse,
+				metal3v1alpha1.CredentialsValidCondition: metav1.ConditionFalse,
+			},
+			Reasons: map[string]string{
+				metal3v1alpha1.RegisteredCondition:       "RegistrationError",
+				metal3v1alpha1.CredentialsValidCondition: "RegistrationError",
+			},
+		},
+
+		{
+			Scenario: "inspecting",
+			Host:     host(metal3v1alpha1.StateInspecting).build(),
+			Expected: map[string]metav1.ConditionStatus{
+				metal3v1alpha1.RegisteredCondition:       metav1.ConditionTrue,
+				metal3v1alpha1.CredentialsValidCondition: metav1.ConditionTrue,
+				metal3v1alpha1.InspectedCondition:        metav1.ConditionFalse,
+			},
+			Reasons: map[string]string{
+				metal3v1alpha1.InspectedCondition: "Inspecting",
+			},
+		},
+
+		{
+			Scenario: "ready",
+			Host: host(metal3v1alpha1.StateReady).
+				SetOnline(false).SetStatusPoweredOn(false).build(),
+			Expected: map[string]metav1.ConditionStatus{
+				metal3v1alpha1.RegisteredCondition:  metav1.ConditionTrue,
+				metal3v1alpha1.PreparedCondition:    metav1.ConditionTrue,
+				metal3v1alpha1.ProvisionedCondition: metav1.ConditionFalse,
+				metal3v1alpha1.PowerSyncedCondition: metav1.ConditionTrue,
+			},
+		},
+
+		{
+			Scenario: "provisioned out of sync",
+			Host: host(metal3v1alpha1.StateProvisioned).
+				SetOnline(true).SetStatusPoweredOn(false).build(),
+			Expected: map[string]metav1.ConditionStatus{
+				metal3v1alpha1.ProvisionedCondition: metav1.ConditionTrue,
+				metal3v1alpha1.PowerSyncedCondition: metav1.ConditionFalse,
+			},
+			Reasons: map[string]string{
+				metal3v1alpha1.PowerSyncedCondition: "OutOfSync",
+			},
+		},
+
+		{
+			Scenario: "provisioning error",
+			Host: host(metal3v1alpha1.StateProvisioning).
+				SetStatusError(metal3v1alpha1.OperationalStatusError, metal3v1alpha1.ProvisioningError, "deploy failed", 1).
+				build(),
+			Expected: map[string]metav1.ConditionStatus{
+				metal3v1alpha1.RegisteredCondition:  metav1.ConditionTrue,
+				metal3v1alpha1.ProvisionedCondition: metav1.ConditionFalse,
+			},
+			Reasons: map[string]string{
+				metal3v1alpha1.ProvisionedCondition: "ProvisioningError",
+			},
+		},
+
+		{
+			Scenario: "detached",
+			Host: host(metal3v1alpha1.StateProvisioned).
+				SetOperationalStatus(metal3v1alpha1.OperationalStatusDetached).build(),
+			Expected: map[string]metav1.ConditionStatus{
+				metal3v1alpha1.RegisteredCondition:  metav1.ConditionFalse,
+				metal3v1alpha1.PowerSyncedCondition: metav1.ConditionUnknown,
+				metal3v1alpha1.DetachedCondition:    metav1.ConditionTrue,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			tc.Host.Generation = 2
+
+			assert.True(t, updateConditions(tc.Host), "expected conditions to change")
+			assert.Len(t, tc.Host.Status.Conditions, len(conditionTypes))
+			for condType, status := range tc.Expected {
+				cond := meta.FindStatusCondition(tc.Host.Status.Conditions, condType)
+				if assert.NotNil(t, cond, condType) {
+					assert.Equal(t, status, cond.Status, condType)
+					assert.Equal(t, int64(2), cond.ObservedGeneration, condType)
+				}
+			}
+			for condType, reason := range tc.Reasons {
+				cond := meta.FindStatusCondition(tc.Host.Status.Conditions, condType)
+				if assert.NotNil(t, cond, condType) {
+					assert.Equal(t, reason, cond.Reason, condType)
+				}
+			}
+
+			assert.False(t, updateConditions(tc.Host), "expected no change on second update")
+		})
+	}
+}
@@ -0,0 +1,419 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner"
+)
+
+const (
+	firmwareSettingsRefreshDelay = time.Minute * 10
+)
+
+// HostFirmwareSettingsReconciler reconciles a HostFirmwareSettings object
+type HostFirmwareSettingsReconciler struct {
+	client.Client
+	Log                logr.Logger
+	ProvisionerFactory provisioner.Factory
+}
+
+// +kubebuilder:rbac:groups=metal3.io,resources=hostfirmwaresettings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=metal3.io,resources=hostfirmwaresettings/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metal3.io,resources=firmwareschemas,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile ensures there is a HostFirmwareSettings resource for each
+// BareMetalHost and keeps its status in sync with the settings
+// reported by the provisioner.
+func (r *HostFirmwareSettingsReconciler) Reconcile(ctx context.Context, request ctrl.Request) (result ctrl.Result, err error) {
+	reqLogger := r.Log.WithValues("hostfirmwaresettings", request.NamespacedName)
+	reqLogger.Info("start")
+
+	// The settings share the name of the host they belong to
+	host := &metal3v1alpha1.BareMetalHost{}
+	err = r.Get(ctx, request.NamespacedName, host)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			// The settings are owned by the host and will be garbage
+			// collected along with it.
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, errors.Wrap(err, "could not load host data")
+	}
+
+	if !host.DeletionTimestamp.IsZero() {
+		reqLogger.Info("host is being deleted, no work to do")
+		return ctrl.Result{}, nil
+	}
+
+	if _, paused := host.GetAnnotations()[metal3v1alpha1.PausedAnnotation]; paused {
+		reqLogger.Info("host is paused, no work to do")
+		return ctrl.Result{}, nil
+	}
+
+	hfs := &metal3v1alpha1.HostFirmwareSettings{}
+	err = r.Get(ctx, request.NamespacedName, hfs)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, errors.Wrap(err, "could not load host firmware settings")
+		}
+		reqLogger.Info("creating host firmware settings")
+		hfs, err = r.newHostFirmwareSettings(host)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if err = r.Create(ctx, hfs); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to create host firmware settings")
+		}
+	}
+
+	if !hostHasFirmwareSettings(host) {
+		reqLogger.Info("host is not registered, settings are not available yet",
+			"provisioningState", host.Status.Provisioning.State)
+		return ctrl.Result{}, nil
+	}
+
+	prov, err := r.ProvisionerFactory.NewProvisioner(provisioner.BuildHostData(*host, bmc.Credentials{}),
+		func(reason, message string) {})
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to create provisioner")
+	}
+
+	ready, err := prov.IsReady()
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to check services availability")
+	}
+	if !ready {
+		reqLogger.Info("provisioner is not ready", "RequeueAfter:", provisionerNotReadyRetryDelay)
+		return ctrl.Result{Requeue: true, RequeueAfter: provisionerNotReadyRetryDelay}, nil
+	}
+
+	// The attribute registry is only read once the host has been
+	// inspected, as the schema is shared by all hosts of the same model.
+	includeSchema := host.Status.HardwareDetails != nil
+	settings, schema, err := prov.GetFirmwareSettings(includeSchema)
+	if err != nil {
+		if errors.Is(err, provisioner.ErrNeedsRegistration) {
+			reqLogger.Info("host is not registered yet")
+			return ctrl.Result{RequeueAfter: provisionerNotReadyRetryDelay}, nil
+		}
+		return ctrl.Result{}, errors.Wrap(err, "could not get firmware settings")
+	}
+
+	newStatus := hfs.Status.DeepCopy()
+	newStatus.Settings = settings
+	if newStatus.Settings == nil {
+		newStatus.Settings = make(metal3v1alpha1.SettingsMap)
+	}
+	if len(schema) != 0 {
+		newStatus.FirmwareSchema, err = r.ensureFirmwareSchema(ctx, host, hfs, schema)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	setFirmwareSettingsValidCondition(hfs, newStatus)
+	setFirmwareSettingsAppliedCondition(hfs, newStatus)
+
+	if !reflect.DeepEqual(*newStatus, hfs.Status) {
+		hfs.Status = *newStatus
+		if err = r.Status().Update(ctx, hfs); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to update host firmware settings status")
+		}
+		reqLogger.Info("updated firmware settings", "count", len(hfs.Status.Settings))
+	}
+
+	return ctrl.Result{RequeueAfter: firmwareSettingsRefreshDelay}, nil
+}
+
+// newHostFirmwareSettings builds an empty HostFirmwareSettings
+// resource owned by the host.
+func (r *HostFirmwareSettingsReconciler) newHostFirmwareSettings(host *metal3v1alpha1.BareMetalHost) (*metal3v1alpha1.HostFirmwareSettings, error) {
+	hfs := &metal3v1alpha1.HostFirmwareSettings{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      host.Name,
+			Namespace: host.Namespace,
+		},
+		Spec: metal3v1alpha1.HostFirmwareSettingsSpec{
+			Settings: make(metal3v1alpha1.DesiredSettingsMap),
+		},
+	}
+	if err := controllerutil.SetControllerReference(host, hfs, r.Scheme()); err != nil {
+		return nil, errors.Wrap(err, "failed to set owner of host firmware settings")
+	}
+	return hfs, nil
+}
+
+// ensureFirmwareSchema finds the FirmwareSchema describing the
+// settings of the host, creating it if no other host of the same model
+// has done so, and adds the settings to the owners of the schema.
+func (r *HostFirmwareSettingsReconciler) ensureFirmwareSchema(ctx context.Context, host *metal3v1alpha1.BareMetalHost, hfs *metal3v1alpha1.HostFirmwareSettings, schema map[string]metal3v1alpha1.SettingSchema) (*metal3v1alpha1.SchemaReference, error) {
+	spec := metal3v1alpha1.FirmwareSchemaSpec{
+		HardwareVendor: host.Status.HardwareDetails.SystemVendor.Manufacturer,
+		HardwareModel:  host.Status.HardwareDetails.SystemVendor.ProductName,
+		Schema:         schema,
+	}
+	name, err := firmwareSchemaName(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := &metal3v1alpha1.FirmwareSchema{}
+	err = r.Get(ctx, types.NamespacedName{Namespace: hfs.Namespace, Name: name}, fs)
+	switch {
+	case err == nil:
+		if fs.Spec.HardwareVendor != spec.HardwareVendor || fs.Spec.HardwareModel != spec.HardwareModel {
+			return nil, fmt.Errorf("firmware schema %s belongs to %s %s", name,
+				fs.Spec.HardwareVendor, fs.Spec.HardwareModel)
+		}
+	case k8serrors.IsNotFound(err):
+		r.Log.Info("creating firmware schema", "schema", name,
+			"vendor", spec.HardwareVendor, "model", spec.HardwareModel)
+		fs = &metal3v1alpha1.FirmwareSchema{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: hfs.Namespace,
+			},
+			Spec: spec,
+		}
+		if err = controllerutil.SetOwnerReference(hfs, fs, r.Scheme()); err != nil {
+			return nil, errors.Wrap(err, "failed to set owner of firmware schema")
+		}
+		if err = r.Create(ctx, fs); err != nil {
+			return nil, errors.Wrap(err, "failed to create firmware schema")
+		}
+	default:
+		return nil, errors.Wrap(err, "could not load firmware schema")
+	}
+
+	// Each host using the schema is an owner, so that it is removed
+	// along with the last one.
+	owned := false
+	for _, ref := range fs.OwnerReferences {
+		if ref.Kind == "HostFirmwareSettings" && ref.Name == hfs.Name && ref.UID == hfs.UID {
+			owned = true
+			break
+		}
+	}
+	if !owned {
+		if err = controllerutil.SetOwnerReference(hfs, fs, r.Scheme()); err != nil {
+			return nil, errors.Wrap(err, "failed to set owner of firmware schema")
+		}
+		if err = r.Update(ctx, fs); err != nil {
+			return nil, errors.Wrap(err, "failed to update owners of firmware schema")
+		}
+	}
+
+	return &metal3v1alpha1.SchemaReference{
+		Namespace: fs.Namespace,
+		Name:      fs.Name,
+	}, nil
+}
+
+// firmwareSchemaName builds a name for the schema from its contents, so
+// that hosts of the same model with the same BIOS attribute registry
+// share the same schema.
+func firmwareSchemaName(spec metal3v1alpha1.FirmwareSchemaSpec) (string, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode firmware schema")
+	}
+	hash := fnv.New32a()
+	hash.Write(data)
+	return fmt.Sprintf("schema-%08x", hash.Sum32()), nil
+}
+
+// hostHasFirmwareSettings returns true when the host is known to the
+// provisioner so its firmware settings can be read.
+func hostHasFirmwareSettings(host *metal3v1alpha1.BareMetalHost) bool {
+	if host.Status.Provisioning.ID == "" {
+		return false
+	}
+	if host.Status.OperationalStatus == metal3v1alpha1.OperationalStatusDetached {
+		return false
+	}
+	switch host.Status.Provisioning.State {
+	case metal3v1alpha1.StateNone, metal3v1alpha1.StateUnmanaged,
+		metal3v1alpha1.StateDeleting:
+		return false
+	}
+	return true
+}
+
+// firmwareSettingsChanged returns true when any of the desired
+// settings differs from the value last read from the host and from the
+// value last applied to it. Settings the firmware does not report
+// cannot be applied, so they are not changes; they are reported by the
+// Valid condition instead. Applied settings the firmware reports with
+// another value, for example because it normalizes them, are reported
+// by the Applied condition rather than applied again.
+func firmwareSettingsChanged(hfs *metal3v1alpha1.HostFirmwareSettings) bool {
+	if hfs == nil {
+		return false
+	}
+	for name, value := range hfs.Spec.Settings {
+		current, ok := hfs.Status.Settings[name]
+		if !ok || current == value.String() {
+			continue
+		}
+		if applied, ok := hfs.Status.AppliedSettings[name]; !ok || applied != value.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// mismatchedFirmwareSettings returns the sorted names of the settings
+// last applied to the host for which the firmware reports another
+// value.
+func mismatchedFirmwareSettings(status metal3v1alpha1.HostFirmwareSettingsStatus) []string {
+	var names []string
+	for name, applied := range status.AppliedSettings {
+		if current, ok := status.Settings[name]; ok && current != applied {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// unknownFirmwareSettings returns the sorted names of the desired
+// settings that the firmware does not report.
+func unknownFirmwareSettings(spec metal3v1alpha1.DesiredSettingsMap, status metal3v1alpha1.SettingsMap) []string {
+	var names []string
+	for name := range spec {
+		if _, ok := status[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setFirmwareSettingsValidCondition sets the Valid condition of the new
+// status from the settings of the spec the firmware does not report.
+func setFirmwareSettingsValidCondition(hfs *metal3v1alpha1.HostFirmwareSettings, status *metal3v1alpha1.HostFirmwareSettingsStatus) {
+	cond := metav1.Condition{
+		Type:               metal3v1alpha1.HostFirmwareSettingsValidCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: hfs.Generation,
+		Reason:             "Valid",
+	}
+	if unknown := unknownFirmwareSettings(hfs.Spec.Settings, status.Settings); len(unknown) != 0 {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "UnknownSettings"
+		cond.Message = fmt.Sprintf("the firmware does not report the settings %s, they are not applied",
+			strings.Join(unknown, ", "))
+	}
+	meta.SetStatusCondition(&status.Conditions, cond)
+}
+
+// setFirmwareSettingsAppliedCondition sets the Applied condition of the
+// new status from the settings last applied that the firmware reports
+// with another value.
+func setFirmwareSettingsAppliedCondition(hfs *metal3v1alpha1.HostFirmwareSettings, status *metal3v1alpha1.HostFirmwareSettingsStatus) {
+	cond := metav1.Condition{
+		Type:               metal3v1alpha1.HostFirmwareSettingsAppliedCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: hfs.Generation,
+		Reason:             "Applied",
+	}
+	if mismatched := mismatchedFirmwareSettings(*status); len(mismatched) != 0 {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "ValueMismatch"
+		cond.Message = fmt.Sprintf("the firmware reports other values than the ones applied for the settings %s, change the spec to apply them again",
+			strings.Join(mismatched, ", "))
+	}
+	meta.SetStatusCondition(&status.Conditions, cond)
+}
+
+// saveAppliedFirmwareSettings records the desired settings in the
+// status of the HostFirmwareSettings once they are being applied, so
+// that they are not applied again if the firmware reports other
+// values.
+func saveAppliedFirmwareSettings(ctx context.Context, c client.Client, hfs *metal3v1alpha1.HostFirmwareSettings) error {
+	applied := make(metal3v1alpha1.SettingsMap, len(hfs.Spec.Settings))
+	for name, value := range hfs.Spec.Settings {
+		applied[name] = value.String()
+	}
+	if reflect.DeepEqual(applied, hfs.Status.AppliedSettings) {
+		return nil
+	}
+
+	hfs.Status.AppliedSettings = applied
+	if err := c.Status().Update(ctx, hfs); err != nil {
+		return errors.Wrap(err, "failed to save applied firmware settings")
+	}
+	return nil
+}
+
+// updateFirmwareSettingsStatus reads the current settings from the
+// provisioner and saves them in the status of the HostFirmwareSettings
+// when they differ from the ones already recorded.
+func updateFirmwareSettingsStatus(ctx context.Context, c client.Client, prov provisioner.Provisioner, hfs *metal3v1alpha1.HostFirmwareSettings) (changed bool, err error) {
+	settings, _, err := prov.GetFirmwareSettings(false)
+	if err != nil {
+		return false, errors.Wrap(err, "could not get firmware settings")
+	}
+	if settings == nil {
+		settings = make(metal3v1alpha1.SettingsMap)
+	}
+
+	newStatus := hfs.Status.DeepCopy()
+	newStatus.Settings = settings
+	setFirmwareSettingsAppliedCondition(hfs, newStatus)
+	if reflect.DeepEqual(*newStatus, hfs.Status) {
+		return false, nil
+	}
+
+	hfs.Status = *newStatus
+	if err = c.Status().Update(ctx, hfs); err != nil {
+		return false, errors.Wrap(err, "failed to update host firmware settings status")
+	}
+	return true, nil
+}
+
+// SetupWithManager registers the reconciler to be run by the manager
+func (r *HostFirmwareSettingsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&metal3v1alpha1.HostFirmwareSettings{}).
+		// The settings share the name of the host, so a request for
+		// the host is also a request for its settings.
+		Watches(&source.Kind{Type: &metal3v1alpha1.BareMetalHost{}}, &handler.EnqueueRequestForObject{}).
+		Complete(r)
+}
@@ -0,0 +1,463 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner/fixture"
+)
+
+func newTestHostFirmwareSettingsReconciler(fix *fixture.Fixture, initObjs ...runtime.Object) *HostFirmwareSettingsReconciler {
+	c := fakeclient.NewFakeClient(initObjs...)
+
+	return &HostFirmwareSettingsReconciler{
+		Client:             c,
+		ProvisionerFactory: fix,
+		Log:                ctrl.Log.WithName("controllers").WithName("HostFirmwareSettings"),
+	}
+}
+
+func loadHostFirmwareSettings(t *testing.T, c *HostFirmwareSettingsReconciler, host *metal3v1alpha1.BareMetalHost) *metal3v1alpha1.HostFirmwareSettings {
+	hfs := &metal3v1alpha1.HostFirmwareSettings{}
+	err := c.Get(context.TODO(), newRequest(host).NamespacedName, hfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hfs
+}
+
+func TestHostFirmwareSettingsCreate(t *testing.T) {
+	host := newDefaultHost(t)
+	r := newTestHostFirmwareSettingsReconciler(&fixture.Fixture{}, host)
+
+	result, err := r.Reconcile(context.TODO(), newRequest(host))
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+
+	hfs := loadHostFirmwareSettings(t, r, host)
+	assert.Empty(t, hfs.Spec.Settings)
+	assert.Empty(t, hfs.Status.Settings)
+	if assert.Len(t, hfs.OwnerReferences, 1) {
+		assert.Equal(t, host.Name, hfs.OwnerReferences[0].Name)
+		assert.Equal(t, "BareMetalHost", hfs.OwnerReferences[0].Kind)
+	}
+}
+
+func TestHostFirmwareSettingsNoHost(t *testing.T) {
+	host := newDefaultHost(t)
+	r := newTestHostFirmwareSettingsReconciler(&fixture.Fixture{})
+
+	result, err := r.Reconcile(context.TODO(), newRequest(host))
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+
+	hfs := &metal3v1alpha1.HostFirmwareSettings{}
+	err = r.Get(context.TODO(), newRequest(host).NamespacedName, hfs)
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestHostFirmwareSettingsStatus(t *testing.T) {
+	host := newDefaultHost(t)
+	host.Status.Provisioning.ID = "provID"
+	host.Status.Provisioning.State = metal3v1alpha1.StateReady
+	fix := &fixture.Fixture{
+		FirmwareSettings: metal3v1alpha1.SettingsMap{
+			"ProcVirtualization": "Enabled",
+			"SerialNumber":       "22654891",
+		},
+	}
+	hfs := &metal3v1alpha1.HostFirmwareSettings{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      host.Name,
+			Namespace: host.Namespace,
+		},
+		Spec: metal3v1alpha1.HostFirmwareSettingsSpec{
+			Settings: metal3v1alpha1.DesiredSettingsMap{
+				"ProcVirtualization": intstr.FromString("Disabled"),
+			},
+		},
+	}
+	r := newTestHostFirmwareSettingsReconciler(fix, host, hfs)
+
+	result, err := r.Reconcile(context.TODO(), newRequest(host))
+	assert.NoError(t, err)
+	assert.Equal(t, firmwareSettingsRefreshDelay, result.RequeueAfter)
+
+	hfs = loadHostFirmwareSettings(t, r, host)
+	assert.Equal(t, fix.FirmwareSettings, hfs.Status.Settings)
+	assert.Equal(t, intstr.FromString("Disabled"), hfs.Spec.Settings["ProcVirtualization"])
+	cond := meta.FindStatusCondition(hfs.Status.Conditions, metal3v1alpha1.HostFirmwareSettingsValidCondition)
+	if assert.NotNil(t, cond) {
+		assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	}
+}
+
+// TestHostFirmwareSettingsUnknownSettings ensures that settings the
+// firmware does not report are reported instead of being applied.
+func TestHostFirmwareSettingsUnknownSettings(t *testing.T) {
+	host := newDefaultHost(t)
+	host.Status.Provisioning.ID = "provID"
+	host.Status.Provisioning.State = metal3v1alpha1.StateReady
+	fix := &fixture.Fixture{
+		FirmwareSettings: metal3v1alpha1.SettingsMap{
+			"ProcVirtualization": "Enabled",
+		},
+	}
+	hfs := &metal3v1alpha1.HostFirmwareSettings{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      host.Name,
+			Namespace: host.Namespace,
+		},
+		Spec: metal3v1alpha1.HostFirmwareSettingsSpec{
+			Settings: metal3v1alpha1.DesiredSettingsMap{
+				"ProcVirtualization": intstr.FromString("Enabled"),
+				"SRIOV":              intstr.FromString("Enabled"),
+				"LogicalProc":        intstr.FromString("Disabled"),
+			},
+		},
+	}
+	r := newTestHostFirmwareSettingsReconciler(fix, host, hfs)
+
+	_, err := r.Reconcile(context.TODO(), newRequest(host))
+	assert.NoError(t, err)
+
+	hfs = loadHostFirmwareSettings(t, r, host)
+	assert.False(t, firmwareSettingsChanged(hfs))
+	cond := meta.FindStatusCondition(hfs.Status.Conditions, metal3v1alpha1.HostFirmwareSettingsValidCondition)
+	if assert.NotNil(t, cond) {
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, "UnknownSettings", cond.Reason)
+		assert.Contains(t, cond.Message, "LogicalProc, SRIOV")
+	}
+}
+
+func TestFirmwareSettingsChanged(t *testing.T) {
+	testCases := []struct {
+		Scenario string
+		Spec     metal3v1alpha1.DesiredSettingsMap
+		Status   metal3v1alpha1.SettingsMap
+		Applied  metal3v1alpha1.SettingsMap
+		Expected bool
+	}{
+		{
+			Scenario: "empty",
+			Expected: false,
+		},
+		{
+			Scenario: "same",
+			Spec: metal3v1alpha1.DesiredSettingsMap{
+				"ProcVirtualization":    intstr.FromString("Enabled"),
+				"NetworkBootRetryCount": intstr.FromInt(10),
+			},
+			Status: metal3v1alpha1.SettingsMap{
+				"ProcVirtualization":    "Enabled",
+				"NetworkBootRetryCount": "10",
+				"SerialNumber":          "22654891",
+			},
+			Expected: false,
+		},
+		{
+			Scenario: "different-value",
+			Spec: metal3v1alpha1.DesiredSettingsMap{
+				"NetworkBootRetryCount": intstr.FromInt(20),
+			},
+			Status: metal3v1alpha1.SettingsMap{
+				"NetworkBootRetryCount": "10",
+			},
+			Expected: true,
+		},
+		{
+			Scenario: "unknown-setting",
+			Spec: metal3v1alpha1.DesiredSettingsMap{
+				"SRIOV": intstr.FromString("Enabled"),
+			},
+			Status: metal3v1alpha1.SettingsMap{
+				"ProcVirtualization": "Enabled",
+			},
+			Expected: false,
+		},
+		{
+			Scenario: "already-applied",
+			Spec: metal3v1alpha1.DesiredSettingsMap{
+				"BootMode": intstr.FromString("uefi"),
+			},
+			Status: metal3v1alpha1.SettingsMap{
+				"BootMode": "Uefi",
+			},
+			Applied: metal3v1alpha1.SettingsMap{
+				"BootMode": "uefi",
+			},
+			Expected: false,
+		},
+		{
+			Scenario: "changed-since-applied",
+			Spec: metal3v1alpha1.DesiredSettingsMap{
+				"BootMode": intstr.FromString("Bios"),
+			},
+			Status: metal3v1alpha1.SettingsMap{
+				"BootMode": "Uefi",
+			},
+			Applied: metal3v1alpha1.SettingsMap{
+				"BootMode": "uefi",
+			},
+			Expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			hfs := &metal3v1alpha1.HostFirmwareSettings{
+				Spec: metal3v1alpha1.HostFirmwareSettingsSpec{Settings: tc.Spec},
+				Status: metal3v1alpha1.HostFirmwareSettingsStatus{
+					Settings:        tc.Status,
+					AppliedSettings: tc.Applied,
+				},
+			}
+			assert.Equal(t, tc.Expected, firmwareSettingsChanged(hfs))
+		})
+	}
+}
+
+// TestApplyFirmwareSettings ensures that changing the settings of a
+// ready host sends it through preparing to apply them.
+func TestApplyFirmwareSettings(t *testing.T) {
+	host := newDefaultHost(t)
+	fix := &fixture.Fixture{
+		FirmwareSettings: metal3v1alpha1.SettingsMap{
+			"ProcVirtualization": "Enabled",
+		},
+	}
+	r := newTestReconcilerWithFixture(fix, host)
+
+	waitForProvisioningState(t, r, host, metal3v1alpha1.StateReady)
+
+	hfs := &metal3v1alpha1.HostFirmwareSettings{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      host.Name,
+			Namespace: host.Namespace,
+		},
+		Spec: metal3v1alpha1.HostFirmwareSettingsSpec{
+			Settings: metal3v1alpha1.DesiredSettingsMap{
+				"ProcVirtualization": intstr.FromString("Disabled"),
+			},
+		},
+		Status: metal3v1alpha1.HostFirmwareSettingsStatus{
+			Settings: metal3v1alpha1.SettingsMap{
+				"ProcVirtualization": "Enabled",
+			},
+		},
+	}
+	assert.NoError(t, r.Create(context.TODO(), hfs))
+
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			t.Logf("provisioning state: %v", host.Status.Provisioning.State)
+			return host.Status.Provisioning.State == metal3v1alpha1.StatePreparing
+		},
+	)
+	waitForProvisioningState(t, r, host, metal3v1alpha1.StateReady)
+
+	assert.NoError(t, r.Get(context.TODO(), newRequest(host).NamespacedName, hfs))
+	assert.Equal(t, "Disabled", hfs.Status.Settings["ProcVirtualization"])
+	assert.False(t, firmwareSettingsChanged(hfs))
+}
+
+// TestApplyFirmwareSettingsMismatch ensures that a setting the firmware
+// keeps reporting with another value once applied is reported rather
+// than applied over and over.
+func TestApplyFirmwareSettingsMismatch(t *testing.T) {
+	host := newDefaultHost(t)
+	fix := &fixture.Fixture{
+		FirmwareSettings: metal3v1alpha1.SettingsMap{
+			"ProcVirtualization": "Enabled",
+		},
+		IgnoredFirmwareSettings: []string{"ProcVirtualization"},
+	}
+	r := newTestReconcilerWithFixture(fix, host)
+
+	waitForProvisioningState(t, r, host, metal3v1alpha1.StateReady)
+
+	hfs := &metal3v1alpha1.HostFirmwareSettings{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      host.Name,
+			Namespace: host.Namespace,
+		},
+		Spec: metal3v1alpha1.HostFirmwareSettingsSpec{
+			Settings: metal3v1alpha1.DesiredSettingsMap{
+				"ProcVirtualization": intstr.FromString("Disabled"),
+			},
+		},
+		Status: metal3v1alpha1.HostFirmwareSettingsStatus{
+			Settings: metal3v1alpha1.SettingsMap{
+				"ProcVirtualization": "Enabled",
+			},
+		},
+	}
+	assert.NoError(t, r.Create(context.TODO(), hfs))
+
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return host.Status.Provisioning.State == metal3v1alpha1.StatePreparing
+		},
+	)
+	waitForProvisioningState(t, r, host, metal3v1alpha1.StateReady)
+
+	// The host stays ready although the firmware still reports the
+	// old value
+	for i := 0; i < 5; i++ {
+		tryReconcile(t, r, host,
+			func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+				return true
+			},
+		)
+		assert.Equal(t, metal3v1alpha1.StateReady, host.Status.Provisioning.State)
+	}
+
+	assert.NoError(t, r.Get(context.TODO(), newRequest(host).NamespacedName, hfs))
+	assert.Equal(t, "Enabled", hfs.Status.Settings["ProcVirtualization"])
+	assert.Equal(t, "Disabled", hfs.Status.AppliedSettings["ProcVirtualization"])
+	cond := meta.FindStatusCondition(hfs.Status.Conditions, metal3v1alpha1.HostFirmwareSettingsAppliedCondition)
+	if assert.NotNil(t, cond) {
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, "ValueMismatch", cond.Reason)
+		assert.Contains(t, cond.Message, "ProcVirtualization")
+	}
+
+	// Changing the spec applies the setting again
+	hfs.Spec.Settings["ProcVirtualization"] = intstr.FromString("Disabled-Alias")
+	assert.NoError(t, r.Update(context.TODO(), hfs))
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			return host.Status.Provisioning.State == metal3v1alpha1.StatePreparing
+		},
+	)
+}
+
+// TestApplyFirmwareSettingsAvailable ensures that the settings of hosts
+// in the available state, handled like ready ones, are applied too.
+func TestApplyFirmwareSettingsAvailable(t *testing.T) {
+	host := newDefaultHost(t)
+	fix := &fixture.Fixture{
+		FirmwareSettings: metal3v1alpha1.SettingsMap{
+			"ProcVirtualization": "Enabled",
+		},
+	}
+	r := newTestReconcilerWithFixture(fix, host)
+
+	waitForProvisioningState(t, r, host, metal3v1alpha1.StateReady)
+	assert.NoError(t, r.Get(context.TODO(), newRequest(host).NamespacedName, host))
+	host.Status.Provisioning.State = metal3v1alpha1.StateAvailable
+	assert.NoError(t, r.Status().Update(context.TODO(), host))
+
+	hfs := &metal3v1alpha1.HostFirmwareSettings{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      host.Name,
+			Namespace: host.Namespace,
+		},
+		Spec: metal3v1alpha1.HostFirmwareSettingsSpec{
+			Settings: metal3v1alpha1.DesiredSettingsMap{
+				"ProcVirtualization": intstr.FromString("Disabled"),
+			},
+		},
+		Status: metal3v1alpha1.HostFirmwareSettingsStatus{
+			Settings: metal3v1alpha1.SettingsMap{
+				"ProcVirtualization": "Enabled",
+			},
+		},
+	}
+	assert.NoError(t, r.Create(context.TODO(), hfs))
+
+	tryReconcile(t, r, host,
+		func(host *metal3v1alpha1.BareMetalHost, result reconcile.Result) bool {
+			t.Logf("provisioning state: %v", host.Status.Provisioning.State)
+			return host.Status.Provisioning.State == metal3v1alpha1.StatePreparing
+		},
+	)
+	waitForProvisioningState(t, r, host, metal3v1alpha1.StateReady)
+
+	assert.NoError(t, r.Get(context.TODO(), newRequest(host).NamespacedName, hfs))
+	assert.Equal(t, "Disabled", hfs.Status.Settings["ProcVirtualization"])
+}
+
+// TestHostFirmwareSettingsSchema ensures that hosts of the same model
+// share a single FirmwareSchema.
+func TestHostFirmwareSettingsSchema(t *testing.T) {
+	readOnly := true
+	fix := &fixture.Fixture{
+		FirmwareSettings: metal3v1alpha1.SettingsMap{
+			"ProcVirtualization": "Enabled",
+			"SerialNumber":       "22654891",
+		},
+		FirmwareSchema: map[string]metal3v1alpha1.SettingSchema{
+			"ProcVirtualization": {
+				AttributeType:   "Enumeration",
+				AllowableValues: []string{"Enabled", "Disabled"},
+			},
+			"SerialNumber": {
+				AttributeType: "String",
+				ReadOnly:      &readOnly,
+			},
+		},
+	}
+
+	newInspectedHost := func(name string) *metal3v1alpha1.BareMetalHost {
+		host := newDefaultNamedHost(name, t)
+		host.Status.Provisioning.ID = name
+		host.Status.Provisioning.State = metal3v1alpha1.StateReady
+		host.Status.HardwareDetails = &metal3v1alpha1.HardwareDetails{
+			SystemVendor: metal3v1alpha1.HardwareSystemVendor{
+				Manufacturer: "Dell Inc.",
+				ProductName:  "PowerEdge R640",
+			},
+		}
+		return host
+	}
+	host1 := newInspectedHost("host-1")
+	host2 := newInspectedHost("host-2")
+	uninspected := newDefaultNamedHost("host-3", t)
+	uninspected.Status.Provisioning.ID = "host-3"
+	uninspected.Status.Provisioning.State = metal3v1alpha1.StateInspecting
+
+	r := newTestHostFirmwareSettingsReconciler(fix, host1, host2, uninspected)
+
+	for _, host := range []*metal3v1alpha1.BareMetalHost{host1, host2, uninspected} {
+		_, err := r.Reconcile(context.TODO(), newRequest(host))
+		assert.NoError(t, err)
+	}
+
+	schemas := &metal3v1alpha1.FirmwareSchemaList{}
+	assert.NoError(t, r.List(context.TODO(), schemas))
+	if !assert.Len(t, schemas.Items, 1) {
+		return
+	}
+	schema := schemas.Items[0]
+	assert.Equal(t, "Dell Inc.", schema.Spec.HardwareVendor)
+	assert.Equal(t, "PowerEdge R640", schema.Spec.HardwareModel)
+	assert.Equal(t, fix.FirmwareSchema, schema.Spec.Schema)
+	assert.Len(t, schema.OwnerReferences, 2)
+
+	for _, host := range []*metal3v1alpha1.BareMetalHost{host1, host2} {
+		hfs := loadHostFirmwareSettings(t, r, host)
+		assert.Equal(t, fix.FirmwareSettings, hfs.Status.Settings)
+		assert.Equal(t, &metal3v1alpha1.SchemaReference{
+			Namespace: schema.Namespace,
+			Name:      schema.Name,
+		}, hfs.Status.FirmwareSchema)
+	}
+
+	hfs := loadHostFirmwareSettings(t, r, uninspected)
+	assert.Equal(t, fix.FirmwareSettings, hfs.Status.Settings)
+	assert.Nil(t, hfs.Status.FirmwareSchema)
+}
@@ -17,6 +17,7 @@ const (
 	labelPrevState     = "prev_state"
 	labelNewState      = "new_state"
 	labelHostDataType  = "host_data_type"
+	labelBaseline      = "baseline"
 )
 
 var reconcileCounters = prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -70,6 +71,11 @@ var delayedDeprovisioningHostCounters = prometheus.NewCounterVec(prometheus.Coun
 	Help: "The number of times hosts have been delayed while deprovisioning due a busy provisioner",
 }, []string{labelHostNamespace, labelHostName})
 
+var firmwareOutOfBaselineHosts = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "metal3_firmware_out_of_baseline_hosts",
+	Help: "The number of hosts whose firmware versions differ from their FirmwareBaseline",
+}, []string{labelHostNamespace, labelBaseline})
+
 var slowOperationBuckets = []float64{30, 90, 180, 360, 720, 1440}
 
 var stateTime = map[metal3v1alpha1.ProvisioningState]*prometheus.HistogramVec{
@@ -140,6 +146,8 @@ func init() {
 		hostRegistrationRequired,
 		hostUnmanaged,
 		deleteWithoutDeprov)
+
+	metrics.Registry.MustRegister(firmwareOutOfBaselineHosts)
 }
 
 func hostMetricLabels(request ctrl.Request) prometheus.Labels {
@@ -0,0 +1,130 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+// provisionerConfigResyncPeriod is how often the configuration is
+// loaded again, to pick up the credentials and certificate files that
+// changed
+const provisionerConfigResyncPeriod = time.Minute
+
+// ProvisionerConfigLoader loads the configuration of a provisioner
+type ProvisionerConfigLoader interface {
+	// Load uses the configuration for the provisioners created
+	// afterwards, and returns true when it changed. The previous
+	// configuration is kept when the new one is not valid.
+	Load(spec *metal3v1alpha1.ProvisionerConfigSpec) (changed bool, err error)
+}
+
+// ProvisionerConfigReconciler loads the ProvisionerConfig of the
+// operator into the provisioner whenever it changes, and reports
+// whether it is valid in its status.
+type ProvisionerConfigReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Name   string
+	Loader ProvisionerConfigLoader
+}
+
+// +kubebuilder:rbac:groups=metal3.io,resources=provisionerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metal3.io,resources=provisionerconfigs/status,verbs=get;update;patch
+
+// Reconcile loads the configuration into the provisioner. The current
+// configuration is kept when the ProvisionerConfig is deleted.
+func (r *ProvisionerConfigReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.Log.WithValues("provisionerconfig", request.Name)
+
+	config := &metal3v1alpha1.ProvisionerConfig{}
+	err := r.Get(ctx, request.NamespacedName, config)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			reqLogger.Info("provisioner configuration not found, keeping the current one")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, errors.Wrap(err, "could not load provisioner configuration")
+	}
+
+	var cond hostCondition
+	changed, err := r.Loader.Load(&config.Spec)
+	if err != nil {
+		reqLogger.Error(err, "invalid provisioner configuration, keeping the previous one")
+		cond = conditionFalse("InvalidConfiguration")
+		cond.message = err.Error()
+	} else {
+		if changed {
+			reqLogger.Info("loaded provisioner configuration")
+		}
+		cond = conditionTrue("Loaded")
+	}
+
+	if setProvisionerConfigCondition(config, cond) {
+		if err := r.Status().Update(ctx, config); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to update provisioner configuration status")
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: provisionerConfigResyncPeriod}, nil
+}
+
+// setProvisionerConfigCondition sets the Valid condition of the
+// configuration, and returns true when the status changed
+func setProvisionerConfigCondition(config *metal3v1alpha1.ProvisionerConfig, cond hostCondition) bool {
+	existing := meta.FindStatusCondition(config.Status.Conditions, metal3v1alpha1.ProvisionerConfigValidCondition)
+	if existing != nil && existing.Status == cond.status && existing.Reason == cond.reason &&
+		existing.Message == cond.message && existing.ObservedGeneration == config.Generation &&
+		config.Status.ObservedGeneration == config.Generation {
+		return false
+	}
+	config.Status.ObservedGeneration = config.Generation
+	meta.SetStatusCondition(&config.Status.Conditions, metav1.Condition{
+		Type:               metal3v1alpha1.ProvisionerConfigValidCondition,
+		Status:             cond.status,
+		ObservedGeneration: config.Generation,
+		Reason:             cond.reason,
+		Message:            cond.message,
+	})
+	return true
+}
+
+// isProvisionerConfig filters the events to the ones for the
+// configuration of the operator.
+func (r *ProvisionerConfigReconciler) isProvisionerConfig(obj client.Object) bool {
+	return obj.GetName() == r.Name
+}
+
+// SetupWithManager registers the reconciler to be run by the manager
+func (r *ProvisionerConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&metal3v1alpha1.ProvisionerConfig{}).
+		WithEventFilter(predicate.NewPredicateFuncs(r.isProvisionerConfig)).
+		Complete(r)
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+type fakeProvisionerConfigLoader struct {
+	loaded *metal3v1alpha1.ProvisionerConfigSpec
+}
+
+func (l *fakeProvisionerConfigLoader) Load(spec *metal3v1alpha1.ProvisionerConfigSpec) (bool, error) {
+	if spec.DeployISOURL == "" {
+		return false, errors.New("deployISOURL must be set")
+	}
+	l.loaded = spec.DeepCopy()
+	return true, nil
+}
+
+func TestProvisionerConfigReload(t *testing.T) {
+	config := &metal3v1alpha1.ProvisionerConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "ironic",
+			Generation: 1,
+		},
+		Spec: metal3v1alpha1.ProvisionerConfigSpec{
+			DeployISOURL: "http://deploy.test/ipa.iso",
+		},
+	}
+	loader := &fakeProvisionerConfigLoader{}
+	r := &ProvisionerConfigReconciler{
+		Client: fakeclient.NewFakeClient(config),
+		Log:    ctrl.Log.WithName("controllers").WithName("ProvisionerConfig"),
+		Name:   config.Name,
+		Loader: loader,
+	}
+	key := types.NamespacedName{Name: config.Name}
+	request := ctrl.Request{NamespacedName: key}
+
+	result, err := r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, provisionerConfigResyncPeriod, result.RequeueAfter)
+	assert.Equal(t, "http://deploy.test/ipa.iso", loader.loaded.DeployISOURL)
+	assert.NoError(t, r.Get(context.TODO(), key, config))
+	cond := meta.FindStatusCondition(config.Status.Conditions, metal3v1alpha1.ProvisionerConfigValidCondition)
+	if assert.NotNil(t, cond) {
+		assert.Equal(t, metav1.ConditionTrue, cond.Status)
+		assert.Equal(t, "Loaded", cond.Reason)
+	}
+	assert.EqualValues(t, 1, config.Status.ObservedGeneration)
+
+	// An invalid configuration is reported instead of being loaded
+	config.Spec.DeployISOURL = ""
+	config.Generation = 2
+	assert.NoError(t, r.Update(context.TODO(), config))
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://deploy.test/ipa.iso", loader.loaded.DeployISOURL)
+	assert.NoError(t, r.Get(context.TODO(), key, config))
+	cond = meta.FindStatusCondition(config.Status.Conditions, metal3v1alpha1.ProvisionerConfigValidCondition)
+	if assert.NotNil(t, cond) {
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, "InvalidConfiguration", cond.Reason)
+		assert.Equal(t, "deployISOURL must be set", cond.Message)
+		assert.EqualValues(t, 2, cond.ObservedGeneration)
+	}
+
+	// The current configuration is kept once the resource is deleted
+	assert.NoError(t, r.Delete(context.TODO(), config))
+	result, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, "http://deploy.test/ipa.iso", loader.loaded.DeployISOURL)
+}
@@ -25,21 +25,28 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 
 	metal3iov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	metal3iov1beta1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1beta1"
 	controllers "github.com/metal3-io/baremetal-operator/controllers/metal3.io"
 	metal3iocontroller "github.com/metal3-io/baremetal-operator/controllers/metal3.io"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+	"github.com/metal3-io/baremetal-operator/pkg/hardware"
 	"github.com/metal3-io/baremetal-operator/pkg/provisioner"
 	"github.com/metal3-io/baremetal-operator/pkg/provisioner/demo"
 	"github.com/metal3-io/baremetal-operator/pkg/provisioner/fixture"
 	"github.com/metal3-io/baremetal-operator/pkg/provisioner/ironic"
 	"github.com/metal3-io/baremetal-operator/pkg/version"
+	webhooks "github.com/metal3-io/baremetal-operator/webhooks/metal3.io"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -53,6 +60,7 @@ func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
 
 	_ = metal3iov1alpha1.AddToScheme(scheme)
+	_ = metal3iov1beta1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -76,6 +84,27 @@ func setupChecks(mgr ctrl.Manager) {
 	}
 }
 
+func setupWebhooks(mgr ctrl.Manager) {
+	mgr.GetWebhookServer().Register(webhooks.BareMetalHostDefaulterPath, &webhook.Admission{
+		Handler: &webhooks.BareMetalHostDefaulter{
+			Log: ctrl.Log.WithName("webhooks").WithName("BareMetalHost"),
+		},
+	})
+	mgr.GetWebhookServer().Register(webhooks.BareMetalHostValidatorPath, &webhook.Admission{
+		Handler: &webhooks.BareMetalHostValidator{
+			Client: mgr.GetClient(),
+			Log:    ctrl.Log.WithName("webhooks").WithName("BareMetalHost"),
+		},
+	})
+	mgr.GetWebhookServer().Register(webhooks.HostFirmwareSettingsValidatorPath, &webhook.Admission{
+		Handler: &webhooks.HostFirmwareSettingsValidator{
+			Client: mgr.GetClient(),
+			Log:    ctrl.Log.WithName("webhooks").WithName("HostFirmwareSettings"),
+		},
+	})
+	mgr.GetWebhookServer().Register("/convert", &conversion.Webhook{})
+}
+
 func main() {
 	var watchNamespace string
 	var metricsAddr string
@@ -83,6 +112,10 @@ func main() {
 	var devLogging bool
 	var runInTestMode bool
 	var runInDemoMode bool
+	var webhookPort int
+	var hardwareProfilesConfigMap string
+	var nativePowerManagement bool
+	var provisionerConfigName string
 
 	// From CAPI point of view, BMO should be able to watch all namespaces
 	// in case of a deployment that is not multi-tenant. If the deployment
@@ -101,6 +134,14 @@ func main() {
 		"use the demo provisioner to set host states")
 	flag.StringVar(&healthAddr, "health-addr", ":9440",
 		"The address the health endpoint binds to.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443,
+		"Webhook Server port (set to 0 to disable)")
+	flag.StringVar(&hardwareProfilesConfigMap, "hardware-profiles-configmap", os.Getenv("HARDWARE_PROFILES_CONFIGMAP"),
+		"Name of the ConfigMap holding user defined hardware profiles, in the namespace of the operator.")
+	flag.BoolVar(&nativePowerManagement, "native-power-management", os.Getenv("NATIVE_POWER_MANAGEMENT") == "true",
+		"Manage the power of hosts with a Redfish BMC directly instead of through Ironic.")
+	flag.StringVar(&provisionerConfigName, "provisioner-config", os.Getenv("PROVISIONER_CONFIG"),
+		"Name of the ProvisionerConfig holding the Ironic configuration, instead of the environment.")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseDevMode(devLogging)))
@@ -115,7 +156,7 @@ func main() {
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                  scheme,
 		MetricsBindAddress:      metricsAddr,
-		Port:                    0, // Add flag with default of 9443 when adding webhooks
+		Port:                    webhookPort,
 		LeaderElection:          enableLeaderElection,
 		LeaderElectionID:        "baremetal-operator",
 		LeaderElectionNamespace: leaderElectionNamespace,
@@ -136,6 +177,10 @@ func main() {
 	}
 
 	var provisionerFactory provisioner.Factory
+	var powerClientFactory bmc.PowerClientFactory
+	var accountClientFactory bmc.AccountClientFactory
+	var firmwareClientFactory bmc.FirmwareClientFactory
+	var provisionerConfigLoader metal3iocontroller.ProvisionerConfigLoader
 	if runInTestMode {
 		ctrl.Log.Info("using test provisioner")
 		provisionerFactory = &fixture.Fixture{}
@@ -143,19 +188,101 @@ func main() {
 		ctrl.Log.Info("using demo provisioner")
 		provisionerFactory = &demo.Demo{}
 	} else {
-		provisionerFactory = ironic.NewProvisionerFactory()
+		if provisionerConfigName != "" {
+			ctrl.Log.Info("using the provisioner configuration", "provisionerconfig", provisionerConfigName)
+			configurableFactory := ironic.NewConfigurableProvisionerFactory()
+			provisionerFactory = configurableFactory
+			provisionerConfigLoader = configurableFactory
+		} else {
+			provisionerFactory = ironic.NewProvisionerFactory()
+		}
+		accountClientFactory = bmc.NewAccountClient
+		firmwareClientFactory = bmc.NewFirmwareClient
+		if nativePowerManagement {
+			ctrl.Log.Info("managing the power of Redfish hosts directly")
+			powerClientFactory = bmc.NewPowerClient
+		}
 	}
 
 	if err = (&metal3iocontroller.BareMetalHostReconciler{
+		Client:                mgr.GetClient(),
+		Log:                   ctrl.Log.WithName("controllers").WithName("BareMetalHost"),
+		ProvisionerFactory:    provisionerFactory,
+		APIReader:             mgr.GetAPIReader(),
+		PowerClientFactory:    powerClientFactory,
+		AccountClientFactory:  accountClientFactory,
+		FirmwareClientFactory: firmwareClientFactory,
+		CredentialsStores:     bmc.CredentialsStoreConfigFromEnv(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BareMetalHost")
+		os.Exit(1)
+	}
+
+	if err = (&metal3iocontroller.HostFirmwareSettingsReconciler{
 		Client:             mgr.GetClient(),
-		Log:                ctrl.Log.WithName("controllers").WithName("BareMetalHost"),
+		Log:                ctrl.Log.WithName("controllers").WithName("HostFirmwareSettings"),
 		ProvisionerFactory: provisionerFactory,
-		APIReader:          mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "BareMetalHost")
+		setupLog.Error(err, "unable to create controller", "controller", "HostFirmwareSettings")
+		os.Exit(1)
+	}
+
+	if err = (&metal3iocontroller.BareMetalHostClaimReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("BareMetalHostClaim"),
+		APIReader: mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BareMetalHostClaim")
 		os.Exit(1)
 	}
 
+	if err = (&metal3iocontroller.BMCDiscoveryReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("BMCDiscovery"),
+		APIReader: mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BMCDiscovery")
+		os.Exit(1)
+	}
+
+	if err = (&metal3iocontroller.FirmwareBaselineReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("FirmwareBaseline"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "FirmwareBaseline")
+		os.Exit(1)
+	}
+
+	if provisionerConfigLoader != nil {
+		if err = (&metal3iocontroller.ProvisionerConfigReconciler{
+			Client: mgr.GetClient(),
+			Log:    ctrl.Log.WithName("controllers").WithName("ProvisionerConfig"),
+			Name:   provisionerConfigName,
+			Loader: provisionerConfigLoader,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ProvisionerConfig")
+			os.Exit(1)
+		}
+	}
+
+	if hardwareProfilesConfigMap != "" {
+		if err = (&metal3iocontroller.HardwareProfileConfigReconciler{
+			Log: ctrl.Log.WithName("controllers").WithName("HardwareProfileConfig"),
+			ConfigMap: types.NamespacedName{
+				Name:      hardwareProfilesConfigMap,
+				Namespace: leaderElectionNamespace,
+			},
+			Registry: hardware.DefaultRegistry(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "HardwareProfileConfig")
+			os.Exit(1)
+		}
+	}
+
+	if webhookPort != 0 {
+		setupWebhooks(mgr)
+	}
+
 	setupChecks(mgr)
 
 	// +kubebuilder:scaffold:builder
@@ -54,6 +54,11 @@ type AccessDetails interface {
 	// (such as the kernel and ramdisk locations).
 	DriverInfo(bmcCreds Credentials) map[string]interface{}
 
+	// ValidateCredentials returns a CredentialsValidationError if the
+	// credentials are invalid, or include authentication material the
+	// driver cannot use.
+	ValidateCredentials(bmcCreds Credentials) error
+
 	// Boot interface to set
 	BootInterface() string
 
@@ -65,6 +70,10 @@ type AccessDetails interface {
 	// Whether the driver supports changing secure boot state.
 	SupportsSecureBoot() bool
 
+	// Whether the driver can update the firmware of the host from
+	// images, through the update_firmware clean step.
+	SupportsFirmwareUpdate() bool
+
 	// Build bios clean steps for ironic
 	BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error)
 }
@@ -113,6 +122,52 @@ func getParsedURL(address string) (parsedURL *url.URL, err error) {
 	return parsedURL, nil
 }
 
+// defaultPort returns the port a BMC of the type is reached on when
+// its address does not include one, or an empty string for unknown
+// types.
+func defaultPort(scheme string) string {
+	if _, ok := factories[scheme]; !ok {
+		return ""
+	}
+	switch {
+	case scheme == "ipmi" || scheme == "libvirt":
+		return ipmiDefaultPort
+	case strings.HasSuffix(scheme, "+http"):
+		return "80"
+	default:
+		return "443"
+	}
+}
+
+// NormalizeAddress returns the BMC address in its canonical
+// "type://host:port/path" form, filling in the ipmi type when it is
+// missing and the default port of the type when there is none. The
+// path and query are only included when present in the original
+// address.
+func NormalizeAddress(address string) (string, error) {
+	parsedURL, err := getParsedURL(address)
+	if err != nil {
+		return "", err
+	}
+	if parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return "", fmt.Errorf("failed to parse BMC address information")
+	}
+
+	normalized := url.URL{
+		Scheme:   strings.ToLower(parsedURL.Scheme),
+		Host:     strings.ToLower(parsedURL.Host),
+		Path:     parsedURL.Path,
+		RawPath:  parsedURL.RawPath,
+		RawQuery: parsedURL.RawQuery,
+	}
+	if parsedURL.Port() == "" {
+		if port := defaultPort(normalized.Scheme); port != "" {
+			normalized.Host = net.JoinHostPort(strings.ToLower(parsedURL.Hostname()), port)
+		}
+	}
+	return normalized.String(), nil
+}
+
 // NewAccessDetails creates an AccessDetails structure from the URL
 // for a BMC.
 func NewAccessDetails(address string, disableCertificateVerification bool) (AccessDetails, error) {
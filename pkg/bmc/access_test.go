@@ -375,6 +375,26 @@ func TestParse(t *testing.T) {
 			Hostname: "192.168.122.1",
 			Path:     "",
 		},
+
+		{
+			Scenario: "supermicro redfish url",
+			Address:  "supermicro-redfish://192.168.122.1/redfish/v1/Systems/1",
+			Type:     "supermicro-redfish",
+			Port:     "",
+			Host:     "192.168.122.1",
+			Hostname: "192.168.122.1",
+			Path:     "/redfish/v1/Systems/1",
+		},
+
+		{
+			Scenario: "xclarity url",
+			Address:  "xclarity://192.168.122.1:8443/ABCD1234",
+			Type:     "xclarity",
+			Port:     "8443",
+			Host:     "192.168.122.1",
+			Hostname: "192.168.122.1:8443",
+			Path:     "/ABCD1234",
+		},
 	} {
 		t.Run(tc.Scenario, func(t *testing.T) {
 			url, err := getParsedURL(tc.Address)
@@ -508,7 +528,7 @@ func TestStaticDriverInfo(t *testing.T) {
 		{
 			Scenario:   "redfish virtual media",
 			input:      "redfish-virtualmedia://192.168.122.1",
-			needsMac:   true,
+			needsMac:   false,
 			driver:     "redfish",
 			boot:       "redfish-virtual-media",
 			management: "",
@@ -520,7 +540,7 @@ func TestStaticDriverInfo(t *testing.T) {
 		{
 			Scenario:   "redfish virtual media HTTP",
 			input:      "redfish-virtualmedia+http://192.168.122.1",
-			needsMac:   true,
+			needsMac:   false,
 			driver:     "redfish",
 			boot:       "redfish-virtual-media",
 			management: "",
@@ -532,7 +552,7 @@ func TestStaticDriverInfo(t *testing.T) {
 		{
 			Scenario:   "redfish virtual media HTTPS",
 			input:      "redfish-virtualmedia+https://192.168.122.1",
-			needsMac:   true,
+			needsMac:   false,
 			driver:     "redfish",
 			boot:       "redfish-virtual-media",
 			management: "",
@@ -556,7 +576,7 @@ func TestStaticDriverInfo(t *testing.T) {
 		{
 			Scenario: "ilo5 virtual media",
 			input:    "ilo5-virtualmedia://192.168.122.1",
-			needsMac: true,
+			needsMac: false,
 			driver:   "redfish",
 			boot:     "redfish-virtual-media",
 		},
@@ -564,7 +584,7 @@ func TestStaticDriverInfo(t *testing.T) {
 		{
 			Scenario: "ilo5 virtual media HTTP",
 			input:    "ilo5-virtualmedia+http://192.168.122.1",
-			needsMac: true,
+			needsMac: false,
 			driver:   "redfish",
 			boot:     "redfish-virtual-media",
 		},
@@ -572,7 +592,7 @@ func TestStaticDriverInfo(t *testing.T) {
 		{
 			Scenario: "ilo5 virtual media HTTPS",
 			input:    "ilo5-virtualmedia+https://192.168.122.1",
-			needsMac: true,
+			needsMac: false,
 			driver:   "redfish",
 			boot:     "redfish-virtual-media",
 		},
@@ -580,7 +600,7 @@ func TestStaticDriverInfo(t *testing.T) {
 		{
 			Scenario:   "idrac virtual media",
 			input:      "idrac-virtualmedia://192.168.122.1",
-			needsMac:   true,
+			needsMac:   false,
 			driver:     "idrac",
 			boot:       "idrac-redfish-virtual-media",
 			management: "idrac-redfish",
@@ -592,7 +612,7 @@ func TestStaticDriverInfo(t *testing.T) {
 		{
 			Scenario:   "idrac virtual media HTTP",
 			input:      "idrac-virtualmedia+http://192.168.122.1",
-			needsMac:   true,
+			needsMac:   false,
 			driver:     "idrac",
 			boot:       "idrac-redfish-virtual-media",
 			management: "idrac-redfish",
@@ -604,7 +624,7 @@ func TestStaticDriverInfo(t *testing.T) {
 		{
 			Scenario:   "idrac virtual media HTTPS",
 			input:      "idrac-virtualmedia+https://192.168.122.1",
-			needsMac:   true,
+			needsMac:   false,
 			driver:     "idrac",
 			boot:       "idrac-redfish-virtual-media",
 			management: "idrac-redfish",
@@ -648,6 +668,42 @@ func TestStaticDriverInfo(t *testing.T) {
 			raid:       "ilo5",
 			vendor:     "",
 		},
+
+		{
+			Scenario:   "supermicro redfish",
+			input:      "supermicro-redfish://192.168.122.1/redfish/v1/Systems/1",
+			needsMac:   true,
+			driver:     "redfish",
+			boot:       "ipxe",
+			management: "",
+			power:      "",
+			raid:       "no-raid",
+			vendor:     "",
+		},
+
+		{
+			Scenario:   "supermicro redfish HTTP",
+			input:      "supermicro-redfish+http://192.168.122.1/redfish/v1/Systems/1",
+			needsMac:   true,
+			driver:     "redfish",
+			boot:       "ipxe",
+			management: "",
+			power:      "",
+			raid:       "no-raid",
+			vendor:     "",
+		},
+
+		{
+			Scenario:   "xclarity",
+			input:      "xclarity://192.168.122.1/ABCD1234",
+			needsMac:   true,
+			driver:     "xclarity",
+			boot:       "ipxe",
+			management: "xclarity",
+			power:      "xclarity",
+			raid:       "no-raid",
+			vendor:     "",
+		},
 	} {
 		t.Run(tc.Scenario, func(t *testing.T) {
 			acc, err := NewAccessDetails(tc.input, false)
@@ -1109,6 +1165,53 @@ func TestDriverInfo(t *testing.T) {
 				"ilo_verify_ca": false,
 			},
 		},
+
+		{
+			Scenario: "supermicro redfish",
+			input:    "supermicro-redfish://192.168.122.1/redfish/v1/Systems/1",
+			expects: map[string]interface{}{
+				"redfish_address":   "https://192.168.122.1",
+				"redfish_system_id": "/redfish/v1/Systems/1",
+				"redfish_password":  "",
+				"redfish_username":  "",
+				"redfish_verify_ca": false,
+			},
+		},
+
+		{
+			Scenario: "supermicro redfish http",
+			input:    "supermicro-redfish+http://192.168.122.1/redfish/v1/Systems/1",
+			expects: map[string]interface{}{
+				"redfish_address":   "http://192.168.122.1",
+				"redfish_system_id": "/redfish/v1/Systems/1",
+				"redfish_password":  "",
+				"redfish_username":  "",
+				"redfish_verify_ca": false,
+			},
+		},
+
+		{
+			Scenario: "xclarity",
+			input:    "xclarity://192.168.122.1/ABCD1234",
+			expects: map[string]interface{}{
+				"xclarity_manager_ip":  "192.168.122.1",
+				"xclarity_hardware_id": "ABCD1234",
+				"xclarity_password":    "",
+				"xclarity_username":    "",
+			},
+		},
+
+		{
+			Scenario: "xclarity port",
+			input:    "xclarity://192.168.122.1:8443/ABCD1234",
+			expects: map[string]interface{}{
+				"xclarity_manager_ip":  "192.168.122.1",
+				"xclarity_port":        "8443",
+				"xclarity_hardware_id": "ABCD1234",
+				"xclarity_password":    "",
+				"xclarity_username":    "",
+			},
+		},
 	} {
 		t.Run(tc.Scenario, func(t *testing.T) {
 			acc, err := NewAccessDetails(tc.input, true)
@@ -1139,6 +1242,99 @@ func TestUnknownType(t *testing.T) {
 	}
 }
 
+func TestXClarityMissingHardwareID(t *testing.T) {
+	acc, err := NewAccessDetails("xclarity://192.168.122.1", false)
+	if err == nil || acc != nil {
+		t.Fatalf("unexpected parse success")
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	for _, tc := range []struct {
+		Scenario    string
+		Address     string
+		Expected    string
+		ExpectError bool
+	}{
+		{
+			Scenario: "canonical",
+			Address:  "redfish://192.168.122.1:8000/redfish/v1/Systems/1",
+			Expected: "redfish://192.168.122.1:8000/redfish/v1/Systems/1",
+		},
+		{
+			Scenario: "ipmi default scheme",
+			Address:  "192.168.122.1",
+			Expected: "ipmi://192.168.122.1:623",
+		},
+		{
+			Scenario: "ipmi default scheme with port",
+			Address:  "192.168.122.1:6233",
+			Expected: "ipmi://192.168.122.1:6233",
+		},
+		{
+			Scenario: "ipmi default scheme, IPv6 with port",
+			Address:  "[fe80::fc33:62ff:fe83:8a76]:6233",
+			Expected: "ipmi://[fe80::fc33:62ff:fe83:8a76]:6233",
+		},
+		{
+			Scenario: "missing slashes",
+			Address:  "ipmi:192.168.122.1",
+			Expected: "ipmi://192.168.122.1:623",
+		},
+		{
+			Scenario: "upper case",
+			Address:  "iDRAC-VirtualMedia://BMC.Example.COM/redfish/v1/Systems/System.Embedded.1",
+			Expected: "idrac-virtualmedia://bmc.example.com:443/redfish/v1/Systems/System.Embedded.1",
+		},
+		{
+			Scenario: "redfish default port",
+			Address:  "redfish://192.168.122.1/redfish/v1/Systems/1",
+			Expected: "redfish://192.168.122.1:443/redfish/v1/Systems/1",
+		},
+		{
+			Scenario: "redfish over http default port",
+			Address:  "redfish+http://192.168.122.1/redfish/v1/Systems/1",
+			Expected: "redfish+http://192.168.122.1:80/redfish/v1/Systems/1",
+		},
+		{
+			Scenario: "IPv6 default port",
+			Address:  "ipmi://[FE80::FC33:62FF:FE83:8A76]",
+			Expected: "ipmi://[fe80::fc33:62ff:fe83:8a76]:623",
+		},
+		{
+			Scenario: "unknown type",
+			Address:  "foo://192.168.122.1",
+			Expected: "foo://192.168.122.1",
+		},
+		{
+			Scenario: "query",
+			Address:  "libvirt://192.168.122.1:6233/?abc=def",
+			Expected: "libvirt://192.168.122.1:6233/?abc=def",
+		},
+		{
+			Scenario:    "unparseable",
+			Address:     "[fe80::fc33:62ff:fe83:8a76]:6233:2",
+			ExpectError: true,
+		},
+	} {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			address, err := NormalizeAddress(tc.Address)
+			if tc.ExpectError {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", address)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if address != tc.Expected {
+				t.Fatalf("expected %q, got %q", tc.Expected, address)
+			}
+		})
+	}
+}
+
 func TestBuildBIOSCleanSteps(t *testing.T) {
 	var True bool = true
 	var False bool = false
@@ -1243,6 +1439,78 @@ func TestBuildBIOSCleanSteps(t *testing.T) {
 			firmware: &metal3v1alpha1.FirmwareConfig{},
 			expected: nil,
 		},
+		// supermicro-redfish
+		{
+			name:    "supermicro-redfish",
+			address: "supermicro-redfish://192.168.122.1/redfish/v1/Systems/1",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				VirtualizationEnabled:             &True,
+				SimultaneousMultithreadingEnabled: &False,
+				SriovEnabled:                      &True,
+			},
+			expected: []map[string]string{
+				{
+					"name":  "IntelVirtualizationTechnology",
+					"value": "Enabled",
+				},
+				{
+					"name":  "Hyper-Threading",
+					"value": "Disabled",
+				},
+				{
+					"name":  "SR-IOVSupport",
+					"value": "Enabled",
+				},
+			},
+		},
+		{
+			name:     "supermicro-redfish, firmware is nil",
+			address:  "supermicro-redfish://192.168.122.1/redfish/v1/Systems/1",
+			firmware: nil,
+			expected: nil,
+		},
+		{
+			name:     "supermicro-redfish, firmware is empty",
+			address:  "supermicro-redfish://192.168.122.1/redfish/v1/Systems/1",
+			firmware: &metal3v1alpha1.FirmwareConfig{},
+			expected: nil,
+		},
+		// xclarity
+		{
+			name:    "xclarity",
+			address: "xclarity://192.168.122.1/ABCD1234",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				VirtualizationEnabled:             &True,
+				SimultaneousMultithreadingEnabled: &False,
+				SriovEnabled:                      &True,
+			},
+			expected: []map[string]string{
+				{
+					"name":  "Processors_IntelVirtualizationTechnology",
+					"value": "Enable",
+				},
+				{
+					"name":  "Processors_HyperThreading",
+					"value": "Disable",
+				},
+				{
+					"name":  "DevicesandIOPorts_SRIOV",
+					"value": "Enable",
+				},
+			},
+		},
+		{
+			name:     "xclarity, firmware is nil",
+			address:  "xclarity://192.168.122.1/ABCD1234",
+			firmware: nil,
+			expected: nil,
+		},
+		{
+			name:     "xclarity, firmware is empty",
+			address:  "xclarity://192.168.122.1/ABCD1234",
+			firmware: &metal3v1alpha1.FirmwareConfig{},
+			expected: nil,
+		},
 	}
 
 	for _, c := range cases {
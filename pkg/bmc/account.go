@@ -0,0 +1,94 @@
+package bmc
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// AccountClient changes the password of the BMC account used to manage
+// a host, so that its credentials can be rotated.
+type AccountClient interface {
+	// SetPassword changes the password of the account of the
+	// credentials the client was created with.
+	SetPassword(password string) error
+
+	// CheckPassword returns an error unless the BMC accepts the
+	// credentials the client was created with.
+	CheckPassword() error
+}
+
+// AccountClientFactory describes a callable that returns a new
+// AccountClient for the BMC described by the access details.
+type AccountClientFactory func(accessDetails AccessDetails, creds Credentials) (AccountClient, error)
+
+// NewAccountClient returns an AccountClient using the Redfish
+// AccountService of the BMC, or ipmitool for IPMI BMCs. It returns an
+// AccountClientUnsupportedError for other BMC types.
+func NewAccountClient(accessDetails AccessDetails, creds Credentials) (AccountClient, error) {
+	if err := accessDetails.ValidateCredentials(creds); err != nil {
+		return nil, err
+	}
+
+	switch details := accessDetails.(type) {
+	case redfishSystem:
+		client, err := newRedfishClient(details.DisableCertificateVerification(), creds)
+		if err != nil {
+			return nil, err
+		}
+		return &redfishAccountClient{
+			redfishClient: client,
+			systemURL:     details.redfishSystemURL(),
+		}, nil
+	case *ipmiAccessDetails:
+		return newIPMIAccountClient(details, creds), nil
+	}
+	return nil, AccountClientUnsupportedError{bmcType: accessDetails.Type()}
+}
+
+const (
+	passwordLength     = 16
+	passwordLowercase  = "abcdefghijkmnopqrstuvwxyz"
+	passwordUppercase  = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	passwordDigits     = "23456789"
+	passwordSpecial    = "-_.!"
+	passwordCharacters = passwordLowercase + passwordUppercase + passwordDigits + passwordSpecial
+)
+
+// GeneratePassword returns a random password accepted by the password
+// policies of common BMCs: 16 characters, short enough for IPMI, with
+// at least a lowercase and an uppercase letter, a digit and one of a
+// few special characters that BMCs do not reject.
+func GeneratePassword() (string, error) {
+	classes := []string{passwordLowercase, passwordUppercase, passwordDigits, passwordSpecial}
+
+	password := make([]byte, passwordLength)
+	for i := range password {
+		charset := passwordCharacters
+		if i < len(classes) {
+			charset = classes[i]
+		}
+		c, err := randomChar(charset)
+		if err != nil {
+			return "", err
+		}
+		password[i] = c
+	}
+
+	// Shuffle so that the required classes are not always first
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+	return string(password), nil
+}
+
+func randomChar(charset string) (byte, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+	if err != nil {
+		return 0, err
+	}
+	return charset[i.Int64()], nil
+}
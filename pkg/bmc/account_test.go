@@ -0,0 +1,174 @@
+package bmc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metal3-io/baremetal-operator/pkg/bmc/testserver"
+)
+
+func newTestAccountClient(t *testing.T, address string, creds Credentials) AccountClient {
+	accessDetails, err := NewAccessDetails(address, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewAccountClient(accessDetails, creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestRedfishAccountClient(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "pw").Start()
+	defer server.Stop()
+
+	client := newTestAccountClient(t, server.Address(), Credentials{Username: "admin", Password: "pw"})
+	assert.NoError(t, client.CheckPassword())
+	assert.NoError(t, client.SetPassword("new-pw"))
+	assert.Equal(t, "new-pw", server.Password())
+
+	// The old password does not work anymore
+	assert.Error(t, client.CheckPassword())
+	assert.Error(t, client.SetPassword("other-pw"))
+	assert.Equal(t, "new-pw", server.Password())
+}
+
+func TestRedfishAccountClientRejected(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "pw").RejectingPasswordChanges().Start()
+	defer server.Stop()
+
+	client := newTestAccountClient(t, server.Address(), Credentials{Username: "admin", Password: "pw"})
+	assert.Error(t, client.SetPassword("new-pw"))
+	assert.Equal(t, "pw", server.Password())
+}
+
+func TestNewAccountClient(t *testing.T) {
+	for _, tc := range []struct {
+		Scenario    string
+		address     string
+		unsupported bool
+	}{
+		{
+			Scenario: "redfish",
+			address:  "redfish://192.168.122.1/redfish/v1/Systems/1",
+		},
+		{
+			Scenario: "idrac-redfish",
+			address:  "idrac-redfish://192.168.122.1/redfish/v1/Systems/1",
+		},
+		{
+			Scenario: "ipmi",
+			address:  "ipmi://192.168.122.1",
+		},
+		{
+			Scenario:    "idrac",
+			address:     "idrac://192.168.122.1",
+			unsupported: true,
+		},
+	} {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			accessDetails, err := NewAccessDetails(tc.address, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = NewAccountClient(accessDetails, Credentials{Username: "admin", Password: "pw"})
+			_, unsupported := err.(AccountClientUnsupportedError)
+			assert.Equal(t, tc.unsupported, unsupported, "unexpected error %v", err)
+			if !tc.unsupported {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+const testIPMIUserList = `ID  Name	     Callin  Link Auth	IPMI Msg   Channel Priv Limit
+1                    true    false      false      NO ACCESS
+2   root             true    true       true       ADMINISTRATOR
+3   metal3           true    true       true       ADMINISTRATOR
+`
+
+// fakeIPMITool replaces ipmitool with a script printing the output, and
+// logging its arguments, the password of its environment and the
+// commands and mode of the files it executes. It returns the log.
+func fakeIPMITool(t *testing.T, output string) (log func() string) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "ipmitool.log")
+	outputPath := filepath.Join(dir, "output")
+	if err := ioutil.WriteFile(outputPath, []byte(output), 0600); err != nil {
+		t.Fatal(err)
+	}
+	script := fmt.Sprintf(`#!/bin/sh
+echo "args: $*" >>%[1]s
+echo "password: $IPMI_PASSWORD" >>%[1]s
+if [ "${10}" = "exec" ]; then
+	echo "file: $(cat "${11}") $(stat -c %%a "${11}")" >>%[1]s
+fi
+cat %[2]s
+`, logPath, outputPath)
+	scriptPath := filepath.Join(dir, "ipmitool")
+	if err := ioutil.WriteFile(scriptPath, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := ipmitoolCommand
+	ipmitoolCommand = scriptPath
+	t.Cleanup(func() { ipmitoolCommand = previous })
+
+	return func() string {
+		content, err := ioutil.ReadFile(logPath)
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+}
+
+func TestIPMIAccountClient(t *testing.T) {
+	log := fakeIPMITool(t, testIPMIUserList)
+
+	client := newTestAccountClient(t, "ipmi://192.168.122.1:6230", Credentials{Username: "metal3", Password: "pw"})
+	assert.NoError(t, client.CheckPassword())
+	assert.NoError(t, client.SetPassword("new-pw"))
+
+	calls := log()
+	assert.Contains(t, calls, "args: -I lanplus -H 192.168.122.1 -p 6230 -U metal3 -E user list 1\n")
+	assert.Contains(t, calls, "password: pw\n")
+	// The new password is only passed in a file readable by the
+	// operator
+	assert.Contains(t, calls, "file: user set password 3 new-pw 600\n")
+	for _, line := range strings.Split(calls, "\n") {
+		if strings.HasPrefix(line, "args: ") {
+			assert.NotContains(t, line, "new-pw")
+		}
+	}
+}
+
+func TestIPMIUserID(t *testing.T) {
+	id, err := ipmiUserID(testIPMIUserList, "metal3")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", id)
+
+	_, err = ipmiUserID(testIPMIUserList, "admin")
+	assert.Error(t, err)
+}
+
+func TestGeneratePassword(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		password, err := GeneratePassword()
+		assert.NoError(t, err)
+		assert.Len(t, password, passwordLength)
+		for _, charset := range []string{passwordLowercase, passwordUppercase, passwordDigits, passwordSpecial} {
+			assert.True(t, strings.ContainsAny(password, charset), "%q has none of %q", password, charset)
+		}
+		assert.False(t, seen[password])
+		seen[password] = true
+	}
+}
@@ -0,0 +1,196 @@
+package bmc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+// firmwareField identifies a setting of the FirmwareConfig, by the
+// name of its JSON field.
+type firmwareField string
+
+const (
+	fieldVirtualization firmwareField = "virtualizationEnabled"
+	fieldSMT            firmwareField = "simultaneousMultithreadingEnabled"
+	fieldSriov          firmwareField = "sriovEnabled"
+	fieldBootOrder      firmwareField = "bootOrder"
+	fieldPowerProfile   firmwareField = "powerProfile"
+	fieldCStates        firmwareField = "cStatesEnabled"
+	fieldCStateLimit    firmwareField = "cStateLimit"
+	fieldTurboBoost     firmwareField = "turboBoostEnabled"
+	fieldNUMA           firmwareField = "numaEnabled"
+	fieldMemInterleave  firmwareField = "memoryInterleavingEnabled"
+	fieldHTAssist       firmwareField = "hyperTransportAssistEnabled"
+	fieldTPM            firmwareField = "tpmEnabled"
+	fieldSecureBootKeys firmwareField = "secureBootKeys"
+	fieldPXE            firmwareField = "pxe"
+)
+
+// biosAttribute describes how a FirmwareConfig field is set through a
+// vendor BIOS attribute.
+type biosAttribute struct {
+	// The name of the attribute. For per-NIC fields, %d is replaced
+	// by the index of the NIC.
+	name string
+
+	// Maps the values of the field to the values of the
+	// attribute. Booleans use "true" and "false".
+	values map[string]string
+
+	// Joins the values of list fields, such as the boot order.
+	separator string
+}
+
+// biosTable maps the FirmwareConfig fields supported by a vendor to
+// its BIOS attributes.
+type biosTable map[firmwareField]biosAttribute
+
+// enabledDisabled maps a boolean field to an "Enabled"/"Disabled"
+// attribute.
+func enabledDisabled(name string) biosAttribute {
+	return biosAttribute{name: name, values: map[string]string{"true": "Enabled", "false": "Disabled"}}
+}
+
+// inverted maps a boolean field to an attribute with the opposite
+// meaning.
+func inverted(attribute biosAttribute) biosAttribute {
+	return biosAttribute{
+		name: attribute.name,
+		values: map[string]string{
+			"true":  attribute.values["false"],
+			"false": attribute.values["true"],
+		},
+	}
+}
+
+// boolValues maps a boolean field to an attribute with the given
+// values for true and false.
+func boolValues(name, enabled, disabled string) biosAttribute {
+	return biosAttribute{name: name, values: map[string]string{"true": enabled, "false": disabled}}
+}
+
+// firmwareValue holds the value of a FirmwareConfig field.
+type firmwareValue struct {
+	field firmwareField
+	// The index of the NIC, for per-NIC fields.
+	index int
+	// The value, or values of list fields.
+	values []string
+}
+
+func boolValue(field firmwareField, value *bool) []firmwareValue {
+	if value == nil {
+		return nil
+	}
+	return []firmwareValue{{field: field, values: []string{strconv.FormatBool(*value)}}}
+}
+
+func stringValue(field firmwareField, value string) []firmwareValue {
+	if value == "" {
+		return nil
+	}
+	return []firmwareValue{{field: field, values: []string{value}}}
+}
+
+// firmwareValues returns the fields of the FirmwareConfig that are
+// set, in a stable order.
+func firmwareValues(firmwareConfig *metal3v1alpha1.FirmwareConfig) (values []firmwareValue) {
+	values = append(values, boolValue(fieldVirtualization, firmwareConfig.VirtualizationEnabled)...)
+	values = append(values, boolValue(fieldSMT, firmwareConfig.SimultaneousMultithreadingEnabled)...)
+	values = append(values, boolValue(fieldSriov, firmwareConfig.SriovEnabled)...)
+	if len(firmwareConfig.BootOrder) > 0 {
+		devices := make([]string, len(firmwareConfig.BootOrder))
+		for i, device := range firmwareConfig.BootOrder {
+			devices[i] = string(device)
+		}
+		values = append(values, firmwareValue{field: fieldBootOrder, values: devices})
+	}
+	values = append(values, stringValue(fieldPowerProfile, string(firmwareConfig.PowerProfile))...)
+	values = append(values, boolValue(fieldCStates, firmwareConfig.CStatesEnabled)...)
+	values = append(values, stringValue(fieldCStateLimit, string(firmwareConfig.CStateLimit))...)
+	values = append(values, boolValue(fieldTurboBoost, firmwareConfig.TurboBoostEnabled)...)
+	values = append(values, boolValue(fieldNUMA, firmwareConfig.NUMAEnabled)...)
+	values = append(values, boolValue(fieldMemInterleave, firmwareConfig.MemoryInterleavingEnabled)...)
+	values = append(values, boolValue(fieldHTAssist, firmwareConfig.HyperTransportAssistEnabled)...)
+	values = append(values, boolValue(fieldTPM, firmwareConfig.TPMEnabled)...)
+	values = append(values, stringValue(fieldSecureBootKeys, string(firmwareConfig.SecureBootKeys))...)
+	for _, nic := range firmwareConfig.PXE {
+		values = append(values, firmwareValue{
+			field:  fieldPXE,
+			index:  nic.Index,
+			values: []string{strconv.FormatBool(nic.Enabled)},
+		})
+	}
+	return values
+}
+
+// buildBIOSSettings translates the FirmwareConfig into the BIOS
+// settings of a vendor using its table. Drivers without a table do
+// not support firmware settings at all. All the fields the vendor
+// does not support are reported together, so that they can be fixed
+// at once.
+func buildBIOSSettings(driver string, table biosTable, firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
+	if firmwareConfig == nil {
+		return nil, nil
+	}
+	if table == nil {
+		return nil, fmt.Errorf("firmware settings for %s are not supported", driver)
+	}
+
+	values := firmwareValues(firmwareConfig)
+
+	var unsupported []string
+	for _, value := range values {
+		if _, ok := table[value.field]; !ok {
+			unsupported = append(unsupported, string(value.field))
+		}
+	}
+	switch len(unsupported) {
+	case 0:
+	case 1:
+		return nil, fmt.Errorf("firmware setting %s is not supported by %s", unsupported[0], driver)
+	default:
+		return nil, fmt.Errorf("firmware settings %s are not supported by %s",
+			strings.Join(unsupported, ", "), driver)
+	}
+
+	// Some vendors use the same attribute for several fields
+	setBy := map[string]firmwareField{}
+	for _, value := range values {
+		attribute := table[value.field]
+
+		vendorValues := make([]string, len(value.values))
+		for i, v := range value.values {
+			vendorValue, ok := attribute.values[v]
+			if !ok {
+				return nil, fmt.Errorf("value %s of firmware setting %s is not supported by %s", v, value.field, driver)
+			}
+			vendorValues[i] = vendorValue
+		}
+
+		name := attribute.name
+		if value.field == fieldPXE {
+			name = fmt.Sprintf(name, value.index)
+		}
+		if field, ok := setBy[name]; ok {
+			return nil, fmt.Errorf("firmware settings %s and %s cannot be used together with %s",
+				field, value.field, driver)
+		}
+		setBy[name] = value.field
+		separator := attribute.separator
+		if separator == "" {
+			separator = ","
+		}
+		settings = append(settings,
+			map[string]string{
+				"name":  name,
+				"value": strings.Join(vendorValues, separator),
+			},
+		)
+	}
+
+	return
+}
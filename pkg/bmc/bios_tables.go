@@ -0,0 +1,121 @@
+package bmc
+
+// The BIOS attributes of each vendor. Fields missing from a table are
+// reported as unsupported for the drivers using it.
+
+var iDracBIOSTable = biosTable{
+	fieldVirtualization: enabledDisabled("ProcVirtualization"),
+	fieldSMT:            enabledDisabled("LogicalProc"),
+	fieldSriov:          enabledDisabled("SriovGlobalEnable"),
+	fieldPowerProfile: {
+		name: "SysProfile",
+		values: map[string]string{
+			"Performance": "PerfOptimized",
+			"Balanced":    "PerfPerWattOptimizedOs",
+			"PowerSaving": "PerfPerWattOptimizedDapc",
+		},
+	},
+	fieldCStates:    enabledDisabled("ProcCStates"),
+	fieldTurboBoost: enabledDisabled("ProcTurboMode"),
+	// Interleaving the memory of the nodes hides the NUMA topology
+	fieldNUMA:     inverted(enabledDisabled("NodeInterleave")),
+	fieldHTAssist: enabledDisabled("HtAssist"),
+	fieldTPM:      boolValues("TpmSecurity", "On", "Off"),
+	fieldSecureBootKeys: {
+		name: "SecureBootPolicy",
+		values: map[string]string{
+			"Default": "Standard",
+			"Custom":  "Custom",
+		},
+	},
+	fieldPXE: enabledDisabled("PxeDev%dEnDis"),
+}
+
+// iLOBIOSTable is shared by iLO 4 and iLO 5.
+var iLOBIOSTable = biosTable{
+	fieldVirtualization: enabledDisabled("ProcVirtualization"),
+	fieldSMT:            enabledDisabled("ProcHyperthreading"),
+	fieldSriov:          enabledDisabled("Sriov"),
+	fieldPowerProfile: {
+		name: "PowerRegulator",
+		values: map[string]string{
+			"Performance": "StaticHighPerf",
+			"Balanced":    "DynamicPowerSavings",
+			"PowerSaving": "StaticLowPower",
+		},
+	},
+	fieldCStates: boolValues("MinProcIdlePower", "C6", "NoCStates"),
+	fieldCStateLimit: {
+		name: "MinProcIdlePower",
+		values: map[string]string{
+			"C1":  "NoCStates",
+			"C1E": "C1E",
+			"C6":  "C6",
+		},
+	},
+	fieldTurboBoost:    enabledDisabled("ProcTurbo"),
+	fieldNUMA:          inverted(enabledDisabled("NodeInterleaving")),
+	fieldMemInterleave: enabledDisabled("ChannelInterleaving"),
+	fieldTPM:           boolValues("TpmState", "PresentEnabled", "PresentDisabled"),
+	fieldPXE:           boolValues("NicBoot%d", "NetworkBoot", "Disabled"),
+}
+
+// iRMCBIOSTable uses the setting names of the Ironic irmc BIOS
+// interface rather than the names of the firmware.
+var iRMCBIOSTable = biosTable{
+	fieldVirtualization: boolValues("cpu_vt_enabled", "True", "False"),
+	fieldSMT:            boolValues("hyper_threading_enabled", "True", "False"),
+	fieldSriov:          boolValues("single_root_io_virtualization_support_enabled", "True", "False"),
+	fieldPowerProfile: {
+		name: "cpu_energy_performance_mode",
+		values: map[string]string{
+			"Performance": "performance",
+			"Balanced":    "balanced_performance",
+			"PowerSaving": "energy_efficient",
+		},
+	},
+	fieldTurboBoost: boolValues("cpu_turbo_mode_enabled", "True", "False"),
+}
+
+var supermicroBIOSTable = biosTable{
+	fieldVirtualization: enabledDisabled("IntelVirtualizationTechnology"),
+	fieldSMT:            enabledDisabled("Hyper-Threading"),
+	fieldSriov:          enabledDisabled("SR-IOVSupport"),
+	fieldTurboBoost:     enabledDisabled("TurboMode"),
+	fieldTPM:            enabledDisabled("SecurityDeviceSupport"),
+	fieldPXE:            boolValues("OnboardLAN%dOptionROM", "PXE", "Disabled"),
+	fieldCStateLimit: {
+		name: "PackageCState",
+		values: map[string]string{
+			"C1": "C0/C1 state",
+			"C6": "C6(non Retention) state",
+		},
+	},
+}
+
+var xClarityBIOSTable = biosTable{
+	fieldVirtualization: boolValues("Processors_IntelVirtualizationTechnology", "Enable", "Disable"),
+	fieldSMT:            boolValues("Processors_HyperThreading", "Enable", "Disable"),
+	fieldSriov:          boolValues("DevicesandIOPorts_SRIOV", "Enable", "Disable"),
+	fieldBootOrder: {
+		name: "BootOrder_BootOrder",
+		values: map[string]string{
+			"Disk": "Hard Disk",
+			"PXE":  "Network",
+			"CD":   "CD/DVD Rom",
+			"USB":  "USB Storage",
+		},
+		separator: ";",
+	},
+	fieldPowerProfile: {
+		name: "OperatingModes_ChooseOperatingMode",
+		values: map[string]string{
+			"Performance": "Maximum Performance",
+			"Balanced":    "Efficiency - Favor Performance",
+			"PowerSaving": "Minimal Power",
+		},
+	},
+	fieldCStates:       boolValues("Processors_CStates", "Enable", "Disable"),
+	fieldTurboBoost:    boolValues("Processors_TurboMode", "Enable", "Disable"),
+	fieldMemInterleave: boolValues("Memory_ChannelInterleaving", "Enable", "Disable"),
+}
@@ -0,0 +1,224 @@
+package bmc
+
+import (
+	"reflect"
+	"testing"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+func TestBuildBIOSSettingsFromTables(t *testing.T) {
+	var True bool = true
+	var False bool = false
+
+	cases := []struct {
+		name          string
+		address       string
+		firmware      *metal3v1alpha1.FirmwareConfig
+		expected      []map[string]string
+		expectedError bool
+	}{
+		{
+			name:    "idrac, all fields",
+			address: "idrac://192.168.122.1",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				PowerProfile:      metal3v1alpha1.PowerProfilePerformance,
+				CStatesEnabled:    &False,
+				TurboBoostEnabled: &True,
+				NUMAEnabled:       &True,
+				TPMEnabled:        &True,
+				SecureBootKeys:    metal3v1alpha1.SecureBootKeysCustom,
+				PXE: []metal3v1alpha1.NICPXEConfig{
+					{Index: 1, Enabled: true},
+					{Index: 2, Enabled: false},
+				},
+			},
+			expected: []map[string]string{
+				{"name": "SysProfile", "value": "PerfOptimized"},
+				{"name": "ProcCStates", "value": "Disabled"},
+				{"name": "ProcTurboMode", "value": "Enabled"},
+				{"name": "NodeInterleave", "value": "Disabled"},
+				{"name": "TpmSecurity", "value": "On"},
+				{"name": "SecureBootPolicy", "value": "Custom"},
+				{"name": "PxeDev1EnDis", "value": "Enabled"},
+				{"name": "PxeDev2EnDis", "value": "Disabled"},
+			},
+		},
+		{
+			name:    "idrac, boot order",
+			address: "idrac://192.168.122.1",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				BootOrder: []metal3v1alpha1.BootDevice{metal3v1alpha1.BootDevicePXE},
+			},
+			expectedError: true,
+		},
+		{
+			name:    "ilo5, power and numa",
+			address: "ilo5://192.168.122.1",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				PowerProfile:   metal3v1alpha1.PowerProfilePowerSaving,
+				CStatesEnabled: &True,
+				NUMAEnabled:    &False,
+				PXE:            []metal3v1alpha1.NICPXEConfig{{Index: 3, Enabled: true}},
+			},
+			expected: []map[string]string{
+				{"name": "PowerRegulator", "value": "StaticLowPower"},
+				{"name": "MinProcIdlePower", "value": "C6"},
+				{"name": "NodeInterleaving", "value": "Enabled"},
+				{"name": "NicBoot3", "value": "NetworkBoot"},
+			},
+		},
+		{
+			name:    "ilo4, secure boot keys",
+			address: "ilo4://192.168.122.1",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				SecureBootKeys: metal3v1alpha1.SecureBootKeysDefault,
+			},
+			expectedError: true,
+		},
+		{
+			name:    "ilo5, latency tuning",
+			address: "ilo5://192.168.122.1",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				PowerProfile:              metal3v1alpha1.PowerProfilePerformance,
+				CStateLimit:               metal3v1alpha1.CStateLimitC1E,
+				TurboBoostEnabled:         &True,
+				MemoryInterleavingEnabled: &True,
+			},
+			expected: []map[string]string{
+				{"name": "PowerRegulator", "value": "StaticHighPerf"},
+				{"name": "MinProcIdlePower", "value": "C1E"},
+				{"name": "ProcTurbo", "value": "Enabled"},
+				{"name": "ChannelInterleaving", "value": "Enabled"},
+			},
+		},
+		{
+			name:    "ilo5, c-states and c-state limit",
+			address: "ilo5://192.168.122.1",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				CStatesEnabled: &True,
+				CStateLimit:    metal3v1alpha1.CStateLimitC6,
+			},
+			expectedError: true,
+		},
+		{
+			name:    "idrac, hypertransport assist",
+			address: "idrac://192.168.122.1",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				HyperTransportAssistEnabled: &False,
+			},
+			expected: []map[string]string{
+				{"name": "HtAssist", "value": "Disabled"},
+			},
+		},
+		{
+			name:    "supermicro-redfish, unsupported c-state limit",
+			address: "supermicro-redfish://192.168.122.1/redfish/v1/Systems/1",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				CStateLimit: metal3v1alpha1.CStateLimitC1E,
+			},
+			expectedError: true,
+		},
+		{
+			name:    "irmc, turbo",
+			address: "irmc://192.168.122.1",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				PowerProfile:      metal3v1alpha1.PowerProfileBalanced,
+				TurboBoostEnabled: &False,
+			},
+			expected: []map[string]string{
+				{"name": "cpu_energy_performance_mode", "value": "balanced_performance"},
+				{"name": "cpu_turbo_mode_enabled", "value": "False"},
+			},
+		},
+		{
+			name:    "irmc, pxe",
+			address: "irmc://192.168.122.1",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				PXE: []metal3v1alpha1.NICPXEConfig{{Index: 1, Enabled: true}},
+			},
+			expectedError: true,
+		},
+		{
+			name:    "supermicro-redfish, pxe",
+			address: "supermicro-redfish://192.168.122.1/redfish/v1/Systems/1",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				TPMEnabled: &True,
+				PXE:        []metal3v1alpha1.NICPXEConfig{{Index: 2, Enabled: true}},
+			},
+			expected: []map[string]string{
+				{"name": "SecurityDeviceSupport", "value": "Enabled"},
+				{"name": "OnboardLAN2OptionROM", "value": "PXE"},
+			},
+		},
+		{
+			name:    "xclarity, boot order",
+			address: "xclarity://192.168.122.1/ABCD1234",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				BootOrder: []metal3v1alpha1.BootDevice{
+					metal3v1alpha1.BootDevicePXE,
+					metal3v1alpha1.BootDeviceDisk,
+				},
+				PowerProfile: metal3v1alpha1.PowerProfilePerformance,
+			},
+			expected: []map[string]string{
+				{"name": "BootOrder_BootOrder", "value": "Network;Hard Disk"},
+				{"name": "OperatingModes_ChooseOperatingMode", "value": "Maximum Performance"},
+			},
+		},
+		{
+			name:    "xclarity, unknown boot device",
+			address: "xclarity://192.168.122.1/ABCD1234",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				BootOrder: []metal3v1alpha1.BootDevice{"Floppy"},
+			},
+			expectedError: true,
+		},
+		{
+			name:    "ipmi",
+			address: "ipmi://192.168.122.1",
+			firmware: &metal3v1alpha1.FirmwareConfig{
+				TurboBoostEnabled: &True,
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			acc, err := NewAccessDetails(c.address, false)
+			if err != nil {
+				t.Fatalf("new AccessDetails failed: %v", err)
+			}
+
+			settings, err := acc.BuildBIOSSettings(c.firmware)
+			if (err != nil) != c.expectedError {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(c.expected, settings) {
+				t.Errorf("expected settings: %v, got: %v", c.expected, settings)
+			}
+		})
+	}
+}
+
+func TestBuildBIOSSettingsReportsAllUnsupportedFields(t *testing.T) {
+	var True bool = true
+
+	acc, err := NewAccessDetails("irmc://192.168.122.1", false)
+	if err != nil {
+		t.Fatalf("new AccessDetails failed: %v", err)
+	}
+
+	_, err = acc.BuildBIOSSettings(&metal3v1alpha1.FirmwareConfig{
+		TurboBoostEnabled:           &True,
+		CStateLimit:                 metal3v1alpha1.CStateLimitC1,
+		MemoryInterleavingEnabled:   &True,
+		HyperTransportAssistEnabled: &True,
+	})
+	expected := "firmware settings cStateLimit, memoryInterleavingEnabled, hyperTransportAssistEnabled are not supported by irmc"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got: %v", expected, err)
+	}
+}
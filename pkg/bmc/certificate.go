@@ -0,0 +1,85 @@
+package bmc
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const certificateDialTimeout = time.Second * 10
+
+// setVerifyCA sets the driver info field telling the provisioner how to
+// verify the certificate of the BMC. Disabling the verification takes
+// precedence over the CA bundle.
+func setVerifyCA(driverInfo map[string]interface{}, field string, disableCertificateVerification bool, bmcCreds Credentials) {
+	switch {
+	case disableCertificateVerification:
+		driverInfo[field] = false
+	case bmcCreds.CABundlePath != "":
+		driverInfo[field] = bmcCreds.CABundlePath
+	}
+}
+
+// certificateAddress returns the host:port to connect to in order to
+// read the certificate of the BMC, or an empty string when the BMC is
+// not reached over TLS.
+func certificateAddress(address string) (string, error) {
+	accessDetails, err := NewAccessDetails(address, false)
+	if err != nil {
+		return "", err
+	}
+	if accessDetails.Driver() == "ipmi" {
+		return "", nil
+	}
+
+	parsedURL, err := getParsedURL(address)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasSuffix(parsedURL.Scheme, "+http") {
+		return "", nil
+	}
+	port := parsedURL.Port()
+	if port == "" {
+		port = "443"
+	}
+	return net.JoinHostPort(parsedURL.Hostname(), port), nil
+}
+
+// CertificateFingerprint connects to the BMC and returns the SHA-256
+// fingerprint of the certificate it presents, as colon separated hex
+// bytes. The certificate is not verified, the fingerprint is meant to
+// be compared with the one seen previously. An empty string is
+// returned for BMCs that are not reached over TLS.
+func CertificateFingerprint(address string) (string, error) {
+	hostPort, err := certificateAddress(address)
+	if err != nil || hostPort == "" {
+		return "", err
+	}
+
+	dialer := &net.Dialer{Timeout: certificateDialTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", hostPort, &tls.Config{
+		InsecureSkipVerify: true, // #nosec
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read the certificate of the BMC")
+	}
+	defer conn.Close()
+
+	certificates := conn.ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return "", errors.New("the BMC did not present a certificate")
+	}
+
+	sum := sha256.Sum256(certificates[0].Raw)
+	hexBytes := make([]string, len(sum))
+	for i, b := range sum {
+		hexBytes[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(hexBytes, ":"), nil
+}
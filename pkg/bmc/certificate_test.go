@@ -0,0 +1,125 @@
+package bmc
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDriverInfoVerifyCA(t *testing.T) {
+	for _, tc := range []struct {
+		Scenario                       string
+		input                          string
+		disableCertificateVerification bool
+		caBundlePath                   string
+		field                          string
+		expected                       interface{}
+	}{
+		{
+			Scenario: "redfish, system trust store",
+			input:    "redfish://192.168.122.1/redfish/v1/Systems/1",
+			field:    "redfish_verify_ca",
+		},
+		{
+			Scenario:     "redfish, ca bundle",
+			input:        "redfish://192.168.122.1/redfish/v1/Systems/1",
+			caBundlePath: "/shared/bmc-ca/bundle.pem",
+			field:        "redfish_verify_ca",
+			expected:     "/shared/bmc-ca/bundle.pem",
+		},
+		{
+			Scenario:                       "redfish, verification disabled",
+			input:                          "redfish://192.168.122.1/redfish/v1/Systems/1",
+			disableCertificateVerification: true,
+			caBundlePath:                   "/shared/bmc-ca/bundle.pem",
+			field:                          "redfish_verify_ca",
+			expected:                       false,
+		},
+		{
+			Scenario:     "ipmi, ca bundle",
+			input:        "ipmi://192.168.122.1",
+			caBundlePath: "/shared/bmc-ca/bundle.pem",
+			field:        "ipmi_verify_ca",
+			expected:     "/shared/bmc-ca/bundle.pem",
+		},
+		{
+			Scenario:     "idrac, ca bundle",
+			input:        "idrac://192.168.122.1",
+			caBundlePath: "/shared/bmc-ca/bundle.pem",
+			field:        "drac_verify_ca",
+			expected:     "/shared/bmc-ca/bundle.pem",
+		},
+		{
+			Scenario:     "ilo5, ca bundle",
+			input:        "ilo5://192.168.122.1",
+			caBundlePath: "/shared/bmc-ca/bundle.pem",
+			field:        "ilo_verify_ca",
+			expected:     "/shared/bmc-ca/bundle.pem",
+		},
+	} {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			acc, err := NewAccessDetails(tc.input, tc.disableCertificateVerification)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			di := acc.DriverInfo(Credentials{CABundlePath: tc.caBundlePath})
+			value, ok := di[tc.field]
+			if tc.expected == nil {
+				if ok {
+					t.Fatalf("unexpected value for %s: %v", tc.field, value)
+				}
+				return
+			}
+			if value != tc.expected {
+				t.Fatalf("unexpected value for %s: %v, expected %v", tc.field, value, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCertificateFingerprint(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	sum := sha256.Sum256(server.Certificate().Raw)
+	expected := strings.ToUpper(strings.Join(strings.Split(fmt.Sprintf("% x", sum), " "), ":"))
+
+	for _, tc := range []struct {
+		Scenario string
+		address  string
+		expected string
+	}{
+		{
+			Scenario: "redfish",
+			address:  fmt.Sprintf("redfish://%s/redfish/v1/Systems/1", host),
+			expected: expected,
+		},
+		{
+			Scenario: "idrac",
+			address:  fmt.Sprintf("idrac://%s", host),
+			expected: expected,
+		},
+		{
+			Scenario: "redfish over http",
+			address:  fmt.Sprintf("redfish+http://%s/redfish/v1/Systems/1", host),
+		},
+		{
+			Scenario: "ipmi",
+			address:  fmt.Sprintf("ipmi://%s", host),
+		},
+	} {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			fingerprint, err := CertificateFingerprint(tc.address)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fingerprint != tc.expected {
+				t.Fatalf("unexpected fingerprint %q, expected %q", fingerprint, tc.expected)
+			}
+		})
+	}
+}
@@ -1,13 +1,59 @@
 package bmc
 
+import (
+	"crypto/tls"
+	"fmt"
+)
+
 // Credentials holds the information for authenticating with the BMC.
 type Credentials struct {
 	Username string
 	Password string
+
+	// Token is a session token used to authenticate instead of the
+	// username and password by the native Redfish client.
+	Token string
+
+	// ClientCertificate and ClientKey are the PEM encoded certificate
+	// and private key used by the native Redfish client to
+	// authenticate with the BMC over TLS.
+	ClientCertificate []byte
+	ClientKey         []byte
+
+	// CABundle holds the PEM encoded certificates of the authorities
+	// trusted by the native Redfish client to sign the certificate of
+	// the BMC. The system trust store is used when it is empty.
+	CABundle []byte
+
+	// The path of a file holding the certificates of the authorities
+	// trusted to sign the certificate of the BMC, as seen by the
+	// provisioner. The system trust store is used when it is empty.
+	CABundlePath string
+}
+
+// HasClientCertificate returns true when the credentials include a
+// client certificate.
+func (creds Credentials) HasClientCertificate() bool {
+	return len(creds.ClientCertificate) != 0
 }
 
-// Validate returns an error if the credentials are invalid
+// Validate returns an error if the credentials are invalid. A token or
+// a client certificate may be used instead of the username and password
+// by the native Redfish client. Ironic does not support them, so the
+// ValidateCredentials method of the access details rejects them.
 func (creds Credentials) Validate() error {
+	if len(creds.ClientCertificate) != 0 || len(creds.ClientKey) != 0 {
+		if _, err := tls.X509KeyPair(creds.ClientCertificate, creds.ClientKey); err != nil {
+			return &CredentialsValidationError{message: fmt.Sprintf("Invalid client certificate in credentials: %s", err)}
+		}
+	}
+	if creds.Token != "" || creds.HasClientCertificate() {
+		return nil
+	}
+	return creds.validatePassword()
+}
+
+func (creds Credentials) validatePassword() error {
 	if creds.Username == "" {
 		return &CredentialsValidationError{message: "Missing BMC connection detail 'username' in credentials"}
 	}
@@ -16,3 +62,21 @@ func (creds Credentials) Validate() error {
 	}
 	return nil
 }
+
+// validatePasswordOnly validates the credentials of hosts managed by
+// Ironic, which only authenticates with a username and password,
+// rejecting any other authentication material.
+func (creds Credentials) validatePasswordOnly(bmcType string) error {
+	if err := creds.Validate(); err != nil {
+		return err
+	}
+	if creds.Token != "" {
+		return &CredentialsValidationError{
+			message: fmt.Sprintf("Ironic does not support token authentication for BMC type '%s'", bmcType)}
+	}
+	if creds.HasClientCertificate() {
+		return &CredentialsValidationError{
+			message: fmt.Sprintf("Ironic does not support client certificate authentication for BMC type '%s'", bmcType)}
+	}
+	return creds.validatePassword()
+}
@@ -0,0 +1,48 @@
+package bmc
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+type fileCredentialsProvider struct {
+	dir string
+}
+
+// NewFileCredentialsProvider returns a provider reading the credentials
+// from the "username" and "password" files, or "token", or "tls.crt"
+// and "tls.key", in the directory <baseDir>/<namespace>/<name>, as
+// mounted for example by the Secrets Store CSI driver.
+func NewFileCredentialsProvider(baseDir, namespace, name string) (CredentialsProvider, error) {
+	credsPath, err := credentialsPath(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return &fileCredentialsProvider{dir: filepath.Join(baseDir, filepath.FromSlash(credsPath))}, nil
+}
+
+func (p *fileCredentialsProvider) Credentials() (Credentials, string, error) {
+	if _, err := os.Stat(p.dir); err != nil {
+		return Credentials{}, "", CredentialsStoreError{store: "files", name: p.dir, err: err}
+	}
+
+	data := map[string][]byte{}
+	checksum := sha256.New()
+	for _, key := range credentialsKeys {
+		value, err := ioutil.ReadFile(filepath.Join(p.dir, key))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return Credentials{}, "", CredentialsStoreError{store: "files", name: p.dir, err: err}
+		}
+		data[key] = value
+		fmt.Fprintf(checksum, "%s=%x\n", key, sha256.Sum256(value))
+	}
+	// The files do not carry a version, use the checksum of their
+	// content instead
+	return credentialsFromData(data), fmt.Sprintf("%x", checksum.Sum(nil))[:16], nil
+}
@@ -0,0 +1,126 @@
+package bmc
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// CredentialsProvider retrieves the credentials of a BMC from the store
+// holding them.
+type CredentialsProvider interface {
+	// Credentials returns the credentials and a version that changes
+	// whenever they do.
+	Credentials() (creds Credentials, version string, err error)
+}
+
+// CredentialsStoreError is returned when the credentials cannot be read
+// from their store.
+type CredentialsStoreError struct {
+	store string
+	name  string
+	err   error
+}
+
+func (e CredentialsStoreError) Error() string {
+	return fmt.Sprintf("cannot read BMC credentials %s from %s: %s", e.name, e.store, e.err)
+}
+
+// The keys holding the authentication material in credentials stores
+const (
+	credentialsUsernameKey   = "username"
+	credentialsPasswordKey   = "password"
+	credentialsTokenKey      = "token"
+	credentialsClientCertKey = "tls.crt"
+	credentialsClientKeyKey  = "tls.key"
+)
+
+var credentialsKeys = []string{
+	credentialsUsernameKey, credentialsPasswordKey, credentialsTokenKey,
+	credentialsClientCertKey, credentialsClientKeyKey,
+}
+
+// credentialsFromData builds credentials from values keyed "username"
+// and "password", or "token", or "tls.crt" and "tls.key" for a client
+// certificate.
+func credentialsFromData(data map[string][]byte) Credentials {
+	// We trim surrounding whitespace because those characters are
+	// unlikely to be part of the username or password and it is
+	// common for users to encode the values with a command like
+	//
+	//     echo "my-password" | base64
+	//
+	// which introduces a trailing newline.
+	return Credentials{
+		Username:          strings.TrimSpace(string(data[credentialsUsernameKey])),
+		Password:          strings.TrimSpace(string(data[credentialsPasswordKey])),
+		Token:             strings.TrimSpace(string(data[credentialsTokenKey])),
+		ClientCertificate: data[credentialsClientCertKey],
+		ClientKey:         data[credentialsClientKeyKey],
+	}
+}
+
+type secretCredentialsProvider struct {
+	secret *corev1.Secret
+}
+
+// NewSecretCredentialsProvider returns a provider reading the
+// credentials from the "username" and "password" keys of a Secret.
+func NewSecretCredentialsProvider(secret *corev1.Secret) CredentialsProvider {
+	return &secretCredentialsProvider{secret: secret}
+}
+
+func (p *secretCredentialsProvider) Credentials() (Credentials, string, error) {
+	return credentialsFromData(p.secret.Data), p.secret.ResourceVersion, nil
+}
+
+// CredentialsStoreConfig holds the settings of the external stores
+// the credentials of BMCs can be read from.
+type CredentialsStoreConfig struct {
+	// Vault configures access to a Vault compatible KV secrets engine
+	Vault VaultConfig
+
+	// FileDir is the directory where the credentials files are
+	// mounted
+	FileDir string
+}
+
+// CredentialsStoreConfigFromEnv returns the configuration of the
+// credentials stores given in the environment of the operator.
+func CredentialsStoreConfigFromEnv() CredentialsStoreConfig {
+	config := CredentialsStoreConfig{
+		Vault: VaultConfig{
+			Address:    os.Getenv("VAULT_ADDR"),
+			Token:      os.Getenv("VAULT_TOKEN"),
+			TokenPath:  os.Getenv("VAULT_TOKEN_PATH"),
+			CACertPath: os.Getenv("VAULT_CACERT"),
+			Mount:      os.Getenv("VAULT_KV_MOUNT"),
+		},
+		FileDir: os.Getenv("BMC_CREDENTIALS_DIR"),
+	}
+	if config.Vault.Mount == "" {
+		config.Vault.Mount = "secret"
+	}
+	if config.FileDir == "" {
+		config.FileDir = "/etc/bmc-credentials"
+	}
+	return config
+}
+
+// credentialsPath returns the path of the credentials of a host in an
+// external store. The credentials of each namespace are kept apart so
+// that a host cannot reference those of another namespace.
+func credentialsPath(namespace, name string) (string, error) {
+	if name == "" || path.IsAbs(name) {
+		return "", fmt.Errorf("invalid BMC credentials path %q", name)
+	}
+	for _, element := range strings.Split(name, "/") {
+		if element == "" || element == "." || element == ".." {
+			return "", fmt.Errorf("invalid BMC credentials path %q", name)
+		}
+	}
+	return path.Join(namespace, name), nil
+}
@@ -0,0 +1,174 @@
+package bmc
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSecretCredentialsProvider(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{ResourceVersion: "42"},
+		Data: map[string][]byte{
+			"username": []byte("admin\n"),
+			"password": []byte(" pw "),
+		},
+	}
+	creds, version, err := NewSecretCredentialsProvider(secret).Credentials()
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Username: "admin", Password: "pw"}, creds)
+	assert.Equal(t, "42", version)
+
+	secret.Data = map[string][]byte{
+		"token":   []byte("session-token\n"),
+		"tls.crt": []byte("certificate"),
+		"tls.key": []byte("key"),
+	}
+	creds, _, err = NewSecretCredentialsProvider(secret).Credentials()
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{
+		Token:             "session-token",
+		ClientCertificate: []byte("certificate"),
+		ClientKey:         []byte("key"),
+	}, creds)
+}
+
+func TestCredentialsPath(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		expected string
+	}{
+		{name: "host-0", expected: "ns/host-0"},
+		{name: "rack-1/host-0", expected: "ns/rack-1/host-0"},
+		{name: ""},
+		{name: "/host-0"},
+		{name: "../other/host-0"},
+		{name: "rack-1/../../other/host-0"},
+		{name: "rack-1//host-0"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := credentialsPath("ns", tc.name)
+			if tc.expected == "" {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestVaultCredentialsProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/ns/host-0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data": {"data": {"username": "admin", "password": "pw"}, "metadata": {"version": 3}}}`))
+	}))
+	defer server.Close()
+
+	config := VaultConfig{Address: server.URL + "/", Token: "s.token", Mount: "kv"}
+
+	provider, err := NewVaultCredentialsProvider(config, "ns", "host-0")
+	assert.NoError(t, err)
+	creds, version, err := provider.Credentials()
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Username: "admin", Password: "pw"}, creds)
+	assert.Equal(t, "3", version)
+
+	provider, err = NewVaultCredentialsProvider(config, "other", "host-0")
+	assert.NoError(t, err)
+	_, _, err = provider.Credentials()
+	assert.IsType(t, CredentialsStoreError{}, err)
+
+	config.Token = "s.expired"
+	provider, err = NewVaultCredentialsProvider(config, "ns", "host-0")
+	assert.NoError(t, err)
+	_, _, err = provider.Credentials()
+	assert.IsType(t, CredentialsStoreError{}, err)
+
+	_, err = NewVaultCredentialsProvider(VaultConfig{}, "ns", "host-0")
+	assert.Error(t, err)
+}
+
+func TestVaultHTTPClient(t *testing.T) {
+	config := VaultConfig{Address: "https://vault.example.com", Token: "s.token", Mount: "kv"}
+	first := &vaultCredentialsProvider{config: config, path: "ns/host-0"}
+	client, err := first.httpClient()
+	assert.NoError(t, err)
+	transport := client.Transport.(*http.Transport)
+	assert.NotNil(t, transport.Proxy, "the proxy environment must be used")
+
+	// The client is shared with the other providers of the server
+	config.Token = "s.other"
+	second := &vaultCredentialsProvider{config: config, path: "ns/host-1"}
+	other, err := second.httpClient()
+	assert.NoError(t, err)
+	assert.Same(t, client, other)
+
+	// and replaced when the CA certificate changes
+	caCert, _ := newClientCertificate(t)
+	caCertPath := filepath.Join(t.TempDir(), "ca.crt")
+	assert.NoError(t, ioutil.WriteFile(caCertPath, caCert, 0600))
+	config.CACertPath = caCertPath
+	third := &vaultCredentialsProvider{config: config, path: "ns/host-0"}
+	withCA, err := third.httpClient()
+	assert.NoError(t, err)
+	assert.NotSame(t, client, withCA)
+	other, err = third.httpClient()
+	assert.NoError(t, err)
+	assert.Same(t, withCA, other)
+
+	caCert, _ = newClientCertificate(t)
+	assert.NoError(t, ioutil.WriteFile(caCertPath, caCert, 0600))
+	other, err = third.httpClient()
+	assert.NoError(t, err)
+	assert.NotSame(t, withCA, other)
+}
+
+func TestFileCredentialsProvider(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "bmc-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+
+	dir := filepath.Join(baseDir, "ns", "host-0")
+	assert.NoError(t, os.MkdirAll(dir, 0700))
+	write := func(key, value string) {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, key), []byte(value), 0600))
+	}
+	write("username", "admin\n")
+	write("password", "pw\n")
+
+	provider, err := NewFileCredentialsProvider(baseDir, "ns", "host-0")
+	assert.NoError(t, err)
+	creds, version, err := provider.Credentials()
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Username: "admin", Password: "pw"}, creds)
+
+	// The version changes with the content of the files
+	write("password", "new-pw\n")
+	creds, newVersion, err := provider.Credentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "new-pw", creds.Password)
+	assert.NotEqual(t, version, newVersion)
+
+	provider, err = NewFileCredentialsProvider(baseDir, "other", "host-0")
+	assert.NoError(t, err)
+	_, _, err = provider.Credentials()
+	assert.IsType(t, CredentialsStoreError{}, err)
+}
@@ -1,7 +1,17 @@
 package bmc
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	logz "sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -41,3 +51,130 @@ func TestMissingPassword(t *testing.T) {
 		t.Fatal("got unexpected valid result")
 	}
 }
+
+// newClientCertificate returns a PEM encoded self-signed certificate
+// and its private key.
+func newClientCertificate(t *testing.T) (cert, key []byte) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "metal3"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestValidateCredentials(t *testing.T) {
+	cert, key := newClientCertificate(t)
+	otherCert, _ := newClientCertificate(t)
+
+	password := Credentials{Username: "username", Password: "password"}
+	token := Credentials{Token: "token"}
+	clientCert := Credentials{ClientCertificate: cert, ClientKey: key}
+
+	for _, tc := range []struct {
+		Scenario string
+		address  string
+		creds    Credentials
+		valid    bool
+	}{
+		{
+			Scenario: "redfish, password",
+			address:  "redfish://192.168.122.1/redfish/v1/Systems/1",
+			creds:    password,
+			valid:    true,
+		},
+		{
+			Scenario: "redfish, token",
+			address:  "redfish://192.168.122.1/redfish/v1/Systems/1",
+			creds:    token,
+		},
+		{
+			Scenario: "redfish-virtualmedia, client certificate",
+			address:  "redfish-virtualmedia://192.168.122.1/redfish/v1/Systems/1",
+			creds:    clientCert,
+		},
+		{
+			Scenario: "idrac-virtualmedia, token",
+			address:  "idrac-virtualmedia://192.168.122.1/redfish/v1/Systems/1",
+			creds:    token,
+		},
+		{
+			Scenario: "redfish, certificate without key",
+			address:  "redfish://192.168.122.1/redfish/v1/Systems/1",
+			creds:    Credentials{ClientCertificate: cert},
+		},
+		{
+			Scenario: "redfish, mismatched key",
+			address:  "redfish://192.168.122.1/redfish/v1/Systems/1",
+			creds:    Credentials{ClientCertificate: otherCert, ClientKey: key},
+		},
+		{
+			Scenario: "redfish, nothing",
+			address:  "redfish://192.168.122.1/redfish/v1/Systems/1",
+		},
+		{
+			Scenario: "ipmi, password",
+			address:  "ipmi://192.168.122.1",
+			creds:    password,
+			valid:    true,
+		},
+		{
+			Scenario: "ipmi, token",
+			address:  "ipmi://192.168.122.1",
+			creds:    token,
+		},
+		{
+			Scenario: "ipmi, password and token",
+			address:  "ipmi://192.168.122.1",
+			creds:    Credentials{Username: "username", Password: "password", Token: "token"},
+		},
+		{
+			Scenario: "idrac, client certificate",
+			address:  "idrac://192.168.122.1",
+			creds:    clientCert,
+		},
+		{
+			Scenario: "ilo5, token",
+			address:  "ilo5://192.168.122.1",
+			creds:    token,
+		},
+	} {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			acc, err := NewAccessDetails(tc.address, false)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			err = acc.ValidateCredentials(tc.creds)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.IsType(t, &CredentialsValidationError{}, err)
+			}
+		})
+	}
+}
+
+func TestValidateNativeCredentials(t *testing.T) {
+	cert, key := newClientCertificate(t)
+
+	// The native Redfish client accepts what Ironic does not
+	assert.NoError(t, Credentials{Token: "token"}.Validate())
+	assert.NoError(t, Credentials{ClientCertificate: cert, ClientKey: key}.Validate())
+	assert.Error(t, Credentials{ClientCertificate: cert}.Validate())
+	assert.Error(t, Credentials{}.Validate())
+}
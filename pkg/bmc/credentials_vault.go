@@ -0,0 +1,180 @@
+package bmc
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const vaultTimeout = 30 * time.Second
+
+// VaultConfig holds the settings to read credentials from the version 2
+// KV secrets engine of a Vault compatible server.
+type VaultConfig struct {
+	// Address is the URL of the server
+	Address string
+	// Token authenticates with the server, when TokenPath is not set
+	Token string
+	// TokenPath is a file holding the token, read before each request
+	// so that it can be renewed by an agent
+	TokenPath string
+	// CACertPath is a file holding the certificates of the authorities
+	// trusted to sign the certificate of the server
+	CACertPath string
+	// Mount is the path where the KV secrets engine is mounted
+	Mount string
+}
+
+type vaultCredentialsProvider struct {
+	config VaultConfig
+	path   string
+}
+
+// NewVaultCredentialsProvider returns a provider reading the
+// credentials from the "username" and "password" keys of the secret
+// at <mount>/<namespace>/<name> on a Vault compatible server.
+func NewVaultCredentialsProvider(config VaultConfig, namespace, name string) (CredentialsProvider, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("no Vault address configured to read BMC credentials %s", name)
+	}
+	secretPath, err := credentialsPath(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return &vaultCredentialsProvider{config: config, path: secretPath}, nil
+}
+
+type vaultKVResponse struct {
+	Data struct {
+		Data     map[string]string `json:"data"`
+		Metadata struct {
+			Version int `json:"version"`
+		} `json:"metadata"`
+	} `json:"data"`
+}
+
+func (p *vaultCredentialsProvider) token() (string, error) {
+	if p.config.TokenPath == "" {
+		return p.config.Token, nil
+	}
+	token, err := ioutil.ReadFile(p.config.TokenPath)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read the Vault token")
+	}
+	return strings.TrimSpace(string(token)), nil
+}
+
+// vaultClient is the HTTP client shared by the providers reading from
+// the same server, so that its connections are reused.
+type vaultClient struct {
+	lock   sync.Mutex
+	caCert []byte
+	client *http.Client
+}
+
+type vaultClientKey struct {
+	address    string
+	caCertPath string
+}
+
+// vaultClients holds a *vaultClient by vaultClientKey
+var vaultClients sync.Map
+
+// httpClient returns the HTTP client for the server of the
+// configuration. It is only replaced when the CA certificate changes,
+// so that it can be rotated.
+func (p *vaultCredentialsProvider) httpClient() (*http.Client, error) {
+	var caCert []byte
+	if p.config.CACertPath != "" {
+		var err error
+		caCert, err = ioutil.ReadFile(p.config.CACertPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the Vault CA certificate")
+		}
+	}
+
+	key := vaultClientKey{address: p.config.Address, caCertPath: p.config.CACertPath}
+	value, _ := vaultClients.LoadOrStore(key, &vaultClient{})
+	c := value.(*vaultClient)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.client != nil && bytes.Equal(c.caCert, caCert) {
+		return c.client, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{} // #nosec
+	if caCert != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in %s", p.config.CACertPath)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+	if c.client != nil {
+		c.client.CloseIdleConnections()
+	}
+	c.client = &http.Client{Timeout: vaultTimeout, Transport: transport}
+	c.caCert = caCert
+	return c.client, nil
+}
+
+func (p *vaultCredentialsProvider) Credentials() (Credentials, string, error) {
+	creds, version, err := p.read()
+	if err != nil {
+		return Credentials{}, "", CredentialsStoreError{store: "Vault", name: p.path, err: err}
+	}
+	return creds, version, nil
+}
+
+func (p *vaultCredentialsProvider) read() (Credentials, string, error) {
+	token, err := p.token()
+	if err != nil {
+		return Credentials{}, "", err
+	}
+	client, err := p.httpClient()
+	if err != nil {
+		return Credentials{}, "", err
+	}
+
+	address := fmt.Sprintf("%s/v1/%s/data/%s",
+		strings.TrimSuffix(p.config.Address, "/"), strings.Trim(p.config.Mount, "/"), p.path)
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return Credentials{}, "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Credentials{}, "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Credentials{}, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Credentials{}, "", fmt.Errorf("unexpected status %s from Vault", resp.Status)
+	}
+
+	secret := &vaultKVResponse{}
+	if err := json.Unmarshal(body, secret); err != nil {
+		return Credentials{}, "", errors.Wrap(err, "failed to parse the Vault response")
+	}
+	data := map[string][]byte{}
+	for key, value := range secret.Data.Data {
+		data[key] = []byte(value)
+	}
+	return credentialsFromData(data), strconv.Itoa(secret.Data.Metadata.Version), nil
+}
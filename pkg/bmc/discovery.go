@@ -0,0 +1,165 @@
+package bmc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// discoveryTimeout bounds the time spent waiting for an address that
+// may not have a BMC behind it.
+const discoveryTimeout = 5 * time.Second
+
+const redfishServiceRootPath = "/redfish/v1/"
+
+// DiscoveredSystem describes a system found behind a BMC by a scan.
+type DiscoveredSystem struct {
+	// Address is the BMC address of the system, as used in the spec
+	// of a host
+	Address string
+	// SerialNumber is the serial number of the system, if the BMC
+	// reports it
+	SerialNumber string
+	// MACAddresses are the MAC addresses of the network interfaces of
+	// the system, if the BMC reports them
+	MACAddresses []string
+}
+
+type redfishServiceRoot struct {
+	RedfishVersion string `json:"RedfishVersion"`
+	Systems        struct {
+		ID string `json:"@odata.id"`
+	} `json:"Systems"`
+}
+
+type redfishSystemDetails struct {
+	SerialNumber       string `json:"SerialNumber"`
+	EthernetInterfaces struct {
+		ID string `json:"@odata.id"`
+	} `json:"EthernetInterfaces"`
+}
+
+type redfishEthernetInterface struct {
+	MACAddress          string `json:"MACAddress"`
+	PermanentMACAddress string `json:"PermanentMACAddress"`
+}
+
+// ProbeRedfish checks whether a Redfish service answers on the host
+// and port, by reading its service root which does not require
+// authentication.
+func ProbeRedfish(hostPort string, disableCertificateVerification bool) bool {
+	client, err := newRedfishClient(disableCertificateVerification, Credentials{})
+	if err != nil {
+		return false
+	}
+	client.client.Timeout = discoveryTimeout
+	_, err = client.serviceRoot(hostPort)
+	return err == nil
+}
+
+// DiscoverRedfishSystems returns the systems managed by the Redfish
+// service on the host and port, with their serial number and MAC
+// addresses.
+func DiscoverRedfishSystems(hostPort string, creds Credentials, disableCertificateVerification bool) ([]DiscoveredSystem, error) {
+	client, err := newRedfishClient(disableCertificateVerification, creds)
+	if err != nil {
+		return nil, err
+	}
+	client.client.Timeout = discoveryTimeout
+
+	root, err := client.serviceRoot(hostPort)
+	if err != nil {
+		return nil, err
+	}
+	base := &url.URL{Scheme: "https", Host: hostPort, Path: redfishServiceRootPath}
+
+	systemIDs, err := client.collectionMembers(base, root.Systems.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the Redfish systems")
+	}
+	systems := []DiscoveredSystem{}
+	for _, systemID := range systemIDs {
+		system := &redfishSystemDetails{}
+		if err := client.get(base, systemID, system); err != nil {
+			return nil, errors.Wrap(err, "failed to read the Redfish system")
+		}
+		macs, err := client.macAddresses(base, system.EthernetInterfaces.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the network interfaces of the Redfish system")
+		}
+		systems = append(systems, DiscoveredSystem{
+			Address:      "redfish://" + hostPort + systemID,
+			SerialNumber: strings.TrimSpace(system.SerialNumber),
+			MACAddresses: macs,
+		})
+	}
+	return systems, nil
+}
+
+func (c *redfishClient) serviceRoot(hostPort string) (*redfishServiceRoot, error) {
+	base := &url.URL{Scheme: "https", Host: hostPort, Path: redfishServiceRootPath}
+	root := &redfishServiceRoot{}
+	if err := c.get(base, redfishServiceRootPath, root); err != nil {
+		return nil, err
+	}
+	if root.RedfishVersion == "" && root.Systems.ID == "" {
+		return nil, errors.New("not a Redfish service root")
+	}
+	return root, nil
+}
+
+// get reads the resource at path, relative to base, into value
+func (c *redfishClient) get(base *url.URL, path string, value interface{}) error {
+	resourceURL, err := base.Parse(path)
+	if err != nil {
+		return errors.Wrapf(err, "invalid Redfish resource %s", path)
+	}
+	data, err := c.do(http.MethodGet, resourceURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(json.Unmarshal(data, value), "failed to parse %s", resourceURL)
+}
+
+// collectionMembers returns the paths of the members of a collection.
+// Resources a BMC does not implement have no path and no members.
+func (c *redfishClient) collectionMembers(base *url.URL, path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	collection := &redfishCollection{}
+	if err := c.get(base, path, collection); err != nil {
+		return nil, err
+	}
+	members := []string{}
+	for _, member := range collection.Members {
+		members = append(members, member.ID)
+	}
+	return members, nil
+}
+
+func (c *redfishClient) macAddresses(base *url.URL, path string) ([]string, error) {
+	nicIDs, err := c.collectionMembers(base, path)
+	if err != nil {
+		return nil, err
+	}
+	macs := []string{}
+	for _, nicID := range nicIDs {
+		nic := &redfishEthernetInterface{}
+		if err := c.get(base, nicID, nic); err != nil {
+			return nil, err
+		}
+		mac := nic.PermanentMACAddress
+		if mac == "" {
+			mac = nic.MACAddress
+		}
+		if mac != "" {
+			macs = append(macs, strings.ToLower(mac))
+		}
+	}
+	return macs, nil
+}
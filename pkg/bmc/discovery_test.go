@@ -0,0 +1,100 @@
+package bmc
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metal3-io/baremetal-operator/pkg/bmc/testserver"
+)
+
+func TestDiscoverRedfishSystems(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "pw").
+		WithInventory("SN-0001", "52:54:00:AA:BB:01", "52:54:00:aa:bb:02").
+		StartTLS()
+	defer server.Stop()
+
+	assert.False(t, ProbeRedfish(server.Host(), false), "self-signed certificate accepted")
+	assert.True(t, ProbeRedfish(server.Host(), true))
+
+	systems, err := DiscoverRedfishSystems(server.Host(), Credentials{Username: "admin", Password: "pw"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []DiscoveredSystem{
+		{
+			Address:      "redfish://" + server.Host() + testserver.RedfishSystemPath,
+			SerialNumber: "SN-0001",
+			MACAddresses: []string{"52:54:00:aa:bb:01", "52:54:00:aa:bb:02"},
+		},
+	}, systems)
+
+	// The address of the system can be used by a host
+	accessDetails, err := NewAccessDetails(systems[0].Address, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "redfish", accessDetails.Type())
+
+	_, err = DiscoverRedfishSystems(server.Host(), Credentials{Username: "admin", Password: "wrong"}, true)
+	assert.Error(t, err)
+}
+
+func TestProbeRedfishOtherService(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "not a BMC"}`))
+	}))
+	defer server.Close()
+
+	assert.False(t, ProbeRedfish(server.Listener.Addr().String(), true))
+}
+
+func TestPingIPMI(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	go func() {
+		ping := make([]byte, 64)
+		for {
+			n, addr, err := conn.ReadFrom(ping)
+			if err != nil {
+				return
+			}
+			if n != len(rmcpPresencePing) {
+				continue
+			}
+			pong := append([]byte{}, ping[:8]...)
+			pong = append(pong, rmcpPresencePong, ping[9], 0x00, 0x10)
+			pong = append(pong, make([]byte, 16)...)
+			conn.WriteTo(pong, addr)
+		}
+	}()
+
+	assert.True(t, PingIPMI(conn.LocalAddr().String()))
+}
+
+func TestIPMISerialNumber(t *testing.T) {
+	fru := ` Chassis Type          : Rack Mount Chassis
+ Board Mfg             : Supermicro
+ Board Serial          : BOARD-0001
+ Product Manufacturer  : Supermicro
+ Product Serial        : SN-0001
+`
+	assert.Equal(t, "SN-0001", ipmiSerialNumber(fru))
+	assert.Equal(t, "BOARD-0001", ipmiSerialNumber(" Board Serial          : BOARD-0001\n"))
+	assert.Equal(t, "", ipmiSerialNumber("Device not present\n"))
+}
+
+func TestDiscoverIPMISystem(t *testing.T) {
+	log := fakeIPMITool(t, " Board Serial          : BOARD-0001\n Product Serial        : SN-0001\n")
+
+	system, err := DiscoverIPMISystem("192.168.122.1", "6230", Credentials{Username: "admin", Password: "pw"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, DiscoveredSystem{Address: "ipmi://192.168.122.1:6230", SerialNumber: "SN-0001"}, system)
+	calls := log()
+	assert.Contains(t, calls, "args: -I lanplus -H 192.168.122.1 -p 6230 -U admin -E fru print 0\n")
+	assert.Contains(t, calls, "password: pw\n")
+}
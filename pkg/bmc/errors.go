@@ -26,3 +26,47 @@ func (e CredentialsValidationError) Error() string {
 	return fmt.Sprintf("Validation error with BMC credentials: %s",
 		e.message)
 }
+
+// PowerClientUnsupportedError is returned when the power of hosts
+// with the BMC type cannot be managed without the provisioner.
+type PowerClientUnsupportedError struct {
+	bmcType string
+}
+
+func (e PowerClientUnsupportedError) Error() string {
+	return fmt.Sprintf("Native power management is not supported for BMC type '%s'",
+		e.bmcType)
+}
+
+// ResetTypeUnsupportedError is returned when the BMC does not support
+// the requested power change.
+type ResetTypeUnsupportedError struct {
+	resetType ResetType
+}
+
+func (e ResetTypeUnsupportedError) Error() string {
+	return fmt.Sprintf("Reset type '%s' is not supported by the BMC",
+		e.resetType)
+}
+
+// AccountClientUnsupportedError is returned when the password of the
+// BMC account cannot be changed for the BMC type.
+type AccountClientUnsupportedError struct {
+	bmcType string
+}
+
+func (e AccountClientUnsupportedError) Error() string {
+	return fmt.Sprintf("Changing the BMC password is not supported for BMC type '%s'",
+		e.bmcType)
+}
+
+// FirmwareClientUnsupportedError is returned when the firmware versions
+// of hosts with the BMC type cannot be read from the BMC.
+type FirmwareClientUnsupportedError struct {
+	bmcType string
+}
+
+func (e FirmwareClientUnsupportedError) Error() string {
+	return fmt.Sprintf("Reading firmware versions is not supported for BMC type '%s'",
+		e.bmcType)
+}
@@ -1,7 +1,6 @@
 package bmc
 
 import (
-	"fmt"
 	"net/url"
 	"strings"
 
@@ -74,13 +73,15 @@ func (a *ibmcAccessDetails) DriverInfo(bmcCreds Credentials) map[string]interfac
 		"ibmc_address":  strings.Join(ibmcAddress, ""),
 	}
 
-	if a.disableCertificateVerification {
-		result["ibmc_verify_ca"] = false
-	}
+	setVerifyCA(result, "ibmc_verify_ca", a.disableCertificateVerification, bmcCreds)
 
 	return result
 }
 
+func (a *ibmcAccessDetails) ValidateCredentials(bmcCreds Credentials) error {
+	return bmcCreds.validatePasswordOnly(a.Type())
+}
+
 func (a *ibmcAccessDetails) BootInterface() string {
 	return "pxe"
 }
@@ -105,9 +106,10 @@ func (a *ibmcAccessDetails) SupportsSecureBoot() bool {
 	return false
 }
 
+func (a *ibmcAccessDetails) SupportsFirmwareUpdate() bool {
+	return false
+}
+
 func (a *ibmcAccessDetails) BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
-	if firmwareConfig != nil {
-		return nil, fmt.Errorf("firmware settings for %s are not supported", a.Driver())
-	}
-	return nil, nil
+	return buildBIOSSettings(a.Driver(), nil, firmwareConfig)
 }
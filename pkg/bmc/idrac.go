@@ -58,9 +58,7 @@ func (a *iDracAccessDetails) DriverInfo(bmcCreds Credentials) map[string]interfa
 		"drac_password": bmcCreds.Password,
 		"drac_address":  a.hostname,
 	}
-	if a.disableCertificateVerification {
-		result["drac_verify_ca"] = false
-	}
+	setVerifyCA(result, "drac_verify_ca", a.disableCertificateVerification, bmcCreds)
 
 	schemes := strings.Split(a.bmcType, "+")
 	if len(schemes) > 1 {
@@ -76,6 +74,10 @@ func (a *iDracAccessDetails) DriverInfo(bmcCreds Credentials) map[string]interfa
 	return result
 }
 
+func (a *iDracAccessDetails) ValidateCredentials(bmcCreds Credentials) error {
+	return bmcCreds.validatePasswordOnly(a.Type())
+}
+
 func (a *iDracAccessDetails) BootInterface() string {
 	return "ipxe"
 }
@@ -102,51 +104,10 @@ func (a *iDracAccessDetails) SupportsSecureBoot() bool {
 	return false
 }
 
-func (a *iDracAccessDetails) BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
-	if firmwareConfig == nil {
-		return nil, nil
-	}
-
-	var value string
-
-	if firmwareConfig.VirtualizationEnabled != nil {
-		value = "Disabled"
-		if *firmwareConfig.VirtualizationEnabled {
-			value = "Enabled"
-		}
-		settings = append(settings,
-			map[string]string{
-				"name":  "ProcVirtualization",
-				"value": value,
-			},
-		)
-	}
-
-	if firmwareConfig.SimultaneousMultithreadingEnabled != nil {
-		value = "Disabled"
-		if *firmwareConfig.SimultaneousMultithreadingEnabled {
-			value = "Enabled"
-		}
-		settings = append(settings,
-			map[string]string{
-				"name":  "LogicalProc",
-				"value": value,
-			},
-		)
-	}
-
-	if firmwareConfig.SriovEnabled != nil {
-		value = "Disabled"
-		if *firmwareConfig.SriovEnabled {
-			value = "Enabled"
-		}
-		settings = append(settings,
-			map[string]string{
-				"name":  "SriovGlobalEnable",
-				"value": value,
-			},
-		)
-	}
+func (a *iDracAccessDetails) SupportsFirmwareUpdate() bool {
+	return false
+}
 
-	return
+func (a *iDracAccessDetails) BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
+	return buildBIOSSettings(a.Driver(), iDracBIOSTable, firmwareConfig)
 }
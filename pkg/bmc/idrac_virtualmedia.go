@@ -1,7 +1,6 @@
 package bmc
 
 import (
-	"fmt"
 	"net/url"
 
 	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
@@ -35,9 +34,10 @@ func (a *redfishiDracVirtualMediaAccessDetails) Type() string {
 // NeedsMAC returns true when the host is going to need a separate
 // port created rather than having it discovered.
 func (a *redfishiDracVirtualMediaAccessDetails) NeedsMAC() bool {
-	// For the inspection to work, we need a MAC address
-	// https://github.com/metal3-io/baremetal-operator/pull/284#discussion_r317579040
-	return true
+	// The deploy ramdisk is booted from virtual media and configures
+	// its network from the network data of the node, without DHCP, so
+	// the ports can be created from the inspection data.
+	return false
 }
 
 func (a *redfishiDracVirtualMediaAccessDetails) DisableCertificateVerification() bool {
@@ -57,13 +57,15 @@ func (a *redfishiDracVirtualMediaAccessDetails) DriverInfo(bmcCreds Credentials)
 		"redfish_address":   getRedfishAddress(a.bmcType, a.host),
 	}
 
-	if a.disableCertificateVerification {
-		result["redfish_verify_ca"] = false
-	}
+	setVerifyCA(result, "redfish_verify_ca", a.disableCertificateVerification, bmcCreds)
 
 	return result
 }
 
+func (a *redfishiDracVirtualMediaAccessDetails) ValidateCredentials(bmcCreds Credentials) error {
+	return bmcCreds.validatePasswordOnly(a.Type())
+}
+
 // iDrac Virtual Media Overrides
 
 func (a *redfishiDracVirtualMediaAccessDetails) Driver() string {
@@ -94,9 +96,10 @@ func (a *redfishiDracVirtualMediaAccessDetails) SupportsSecureBoot() bool {
 	return true
 }
 
+func (a *redfishiDracVirtualMediaAccessDetails) SupportsFirmwareUpdate() bool {
+	return true
+}
+
 func (a *redfishiDracVirtualMediaAccessDetails) BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
-	if firmwareConfig != nil {
-		return nil, fmt.Errorf("firmware settings for %s are not supported", a.Driver())
-	}
-	return nil, nil
+	return buildBIOSSettings(a.Driver(), nil, firmwareConfig)
 }
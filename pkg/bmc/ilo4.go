@@ -61,9 +61,7 @@ func (a *iLOAccessDetails) DriverInfo(bmcCreds Credentials) map[string]interface
 		"ilo_address":  a.hostname,
 	}
 
-	if a.disableCertificateVerification {
-		result["ilo_verify_ca"] = false
-	}
+	setVerifyCA(result, "ilo_verify_ca", a.disableCertificateVerification, bmcCreds)
 
 	if a.portNum != "" {
 		result["client_port"] = a.portNum
@@ -72,6 +70,10 @@ func (a *iLOAccessDetails) DriverInfo(bmcCreds Credentials) map[string]interface
 	return result
 }
 
+func (a *iLOAccessDetails) ValidateCredentials(bmcCreds Credentials) error {
+	return bmcCreds.validatePasswordOnly(a.Type())
+}
+
 func (a *iLOAccessDetails) BootInterface() string {
 	return "ilo-ipxe"
 }
@@ -96,51 +98,10 @@ func (a *iLOAccessDetails) SupportsSecureBoot() bool {
 	return true
 }
 
-func (a *iLOAccessDetails) BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
-	if firmwareConfig == nil {
-		return nil, nil
-	}
-
-	var value string
-
-	if firmwareConfig.VirtualizationEnabled != nil {
-		value = "Disabled"
-		if *firmwareConfig.VirtualizationEnabled {
-			value = "Enabled"
-		}
-		settings = append(settings,
-			map[string]string{
-				"name":  "ProcVirtualization",
-				"value": value,
-			},
-		)
-	}
-
-	if firmwareConfig.SimultaneousMultithreadingEnabled != nil {
-		value = "Disabled"
-		if *firmwareConfig.SimultaneousMultithreadingEnabled {
-			value = "Enabled"
-		}
-		settings = append(settings,
-			map[string]string{
-				"name":  "ProcHyperthreading",
-				"value": value,
-			},
-		)
-	}
-
-	if firmwareConfig.SriovEnabled != nil {
-		value = "Disabled"
-		if *firmwareConfig.SriovEnabled {
-			value = "Enabled"
-		}
-		settings = append(settings,
-			map[string]string{
-				"name":  "Sriov",
-				"value": value,
-			},
-		)
-	}
+func (a *iLOAccessDetails) SupportsFirmwareUpdate() bool {
+	return false
+}
 
-	return
+func (a *iLOAccessDetails) BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
+	return buildBIOSSettings(a.Driver(), iLOBIOSTable, firmwareConfig)
 }
@@ -1,7 +1,6 @@
 package bmc
 
 import (
-	"fmt"
 	"net/url"
 
 	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
@@ -82,15 +81,17 @@ func (a *ipmiAccessDetails) DriverInfo(bmcCreds Credentials) map[string]interfac
 		"ipmi_priv_level": a.privilegelevel,
 	}
 
-	if a.disableCertificateVerification {
-		result["ipmi_verify_ca"] = false
-	}
+	setVerifyCA(result, "ipmi_verify_ca", a.disableCertificateVerification, bmcCreds)
 	if a.portNum == "" {
 		result["ipmi_port"] = ipmiDefaultPort
 	}
 	return result
 }
 
+func (a *ipmiAccessDetails) ValidateCredentials(bmcCreds Credentials) error {
+	return bmcCreds.validatePasswordOnly(a.Type())
+}
+
 func (a *ipmiAccessDetails) BootInterface() string {
 	return "ipxe"
 }
@@ -115,9 +116,10 @@ func (a *ipmiAccessDetails) SupportsSecureBoot() bool {
 	return false
 }
 
+func (a *ipmiAccessDetails) SupportsFirmwareUpdate() bool {
+	return false
+}
+
 func (a *ipmiAccessDetails) BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
-	if firmwareConfig != nil {
-		return nil, fmt.Errorf("firmware settings for %s are not supported", a.Driver())
-	}
-	return nil, nil
+	return buildBIOSSettings(a.Driver(), nil, firmwareConfig)
 }
@@ -0,0 +1,110 @@
+package bmc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ipmitoolCommand is the command used to manage IPMI accounts and read
+// the inventory of IPMI BMCs. It is shipped in the operator image.
+var ipmitoolCommand = "ipmitool"
+
+// The channel of the LAN interface of the BMC
+const ipmiLANChannel = "1"
+
+type ipmiAccountClient struct {
+	hostname string
+	port     string
+	creds    Credentials
+}
+
+func newIPMIAccountClient(details *ipmiAccessDetails, creds Credentials) *ipmiAccountClient {
+	port := details.portNum
+	if port == "" {
+		port = ipmiDefaultPort
+	}
+	return &ipmiAccountClient{
+		hostname: details.hostname,
+		port:     port,
+		creds:    creds,
+	}
+}
+
+// ipmitool runs ipmitool against the BMC. The arguments are not
+// included in errors, as they may contain a password.
+func (c *ipmiAccountClient) ipmitool(description string, args ...string) (string, error) {
+	// The password of the credentials is passed through the
+	// environment rather than on the command line.
+	cmdArgs := append([]string{"-I", "lanplus", "-H", c.hostname, "-p", c.port,
+		"-U", c.creds.Username, "-E"}, args...)
+	cmd := exec.Command(ipmitoolCommand, cmdArgs...) // #nosec
+	cmd.Env = append(os.Environ(), "IPMI_PASSWORD="+c.creds.Password)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", errors.Wrapf(err, "ipmitool %s failed: %s", description, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// run runs an ipmitool user subcommand.
+func (c *ipmiAccountClient) run(subcommand string, args ...string) (string, error) {
+	return c.ipmitool("user "+subcommand, append([]string{"user", subcommand}, args...)...)
+}
+
+// runFromFile runs an ipmitool command written to a temporary file only
+// readable by the operator, so that secrets in its arguments do not show
+// in the list of processes.
+func (c *ipmiAccountClient) runFromFile(description, command string) (string, error) {
+	file, err := ioutil.TempFile("", "ipmitool-")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to write the ipmitool %s command", description)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(command + "\n")
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to write the ipmitool %s command", description)
+	}
+	return c.ipmitool(description, "exec", file.Name())
+}
+
+// ipmiUserID returns the ID of the user in the output of "ipmitool
+// user list".
+func ipmiUserID(userList, username string) (string, error) {
+	for _, line := range strings.Split(userList, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[1] == username && fields[0] != "ID" {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("no IPMI user found for %s", username)
+}
+
+// SetPassword changes the password of the IPMI user with ipmitool.
+func (c *ipmiAccountClient) SetPassword(password string) error {
+	userList, err := c.run("list", ipmiLANChannel)
+	if err != nil {
+		return err
+	}
+	id, err := ipmiUserID(userList, c.creds.Username)
+	if err != nil {
+		return err
+	}
+	// ipmitool only takes the new password on the command line
+	_, err = c.runFromFile("user set password", fmt.Sprintf("user set password %s %s", id, password))
+	return err
+}
+
+// CheckPassword lists the IPMI users, which requires the BMC to accept
+// the credentials.
+func (c *ipmiAccountClient) CheckPassword() error {
+	_, err := c.run("list", ipmiLANChannel)
+	return err
+}
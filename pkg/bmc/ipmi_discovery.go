@@ -0,0 +1,78 @@
+package bmc
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"time"
+)
+
+// An ASF presence ping, as defined by the Alerting Standards Format
+// and sent over RMCP: the RMCP header (version 6, no acknowledgement,
+// ASF class), the ASF IANA enterprise number, the message type, a tag,
+// a reserved byte and an empty data length.
+var rmcpPresencePing = []byte{
+	0x06, 0x00, 0xff, 0x06,
+	0x00, 0x00, 0x11, 0xbe,
+	0x80, 0x00, 0x00, 0x00,
+}
+
+const (
+	rmcpMessageTypeOffset = 8
+	rmcpPresencePong      = 0x40
+)
+
+// PingIPMI checks whether an IPMI BMC answers on the host and UDP port,
+// by sending an RMCP presence ping.
+func PingIPMI(hostPort string) bool {
+	conn, err := net.DialTimeout("udp", hostPort, discoveryTimeout)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(discoveryTimeout)); err != nil {
+		return false
+	}
+	if _, err := conn.Write(rmcpPresencePing); err != nil {
+		return false
+	}
+
+	pong := make([]byte, 64)
+	n, err := conn.Read(pong)
+	if err != nil || n <= rmcpMessageTypeOffset {
+		return false
+	}
+	return bytes.Equal(pong[:4], rmcpPresencePing[:4]) && pong[rmcpMessageTypeOffset] == rmcpPresencePong
+}
+
+// DiscoverIPMISystem returns the system managed by the IPMI BMC on the
+// host and UDP port, with the serial number read from its FRU
+// inventory. IPMI does not report the MAC addresses of the system.
+func DiscoverIPMISystem(host, port string, creds Credentials) (DiscoveredSystem, error) {
+	client := &ipmiAccountClient{hostname: host, port: port, creds: creds}
+	out, err := client.ipmitool("fru print", "fru", "print", "0")
+	if err != nil {
+		return DiscoveredSystem{}, err
+	}
+	return DiscoveredSystem{
+		Address:      "ipmi://" + net.JoinHostPort(host, port),
+		SerialNumber: ipmiSerialNumber(out),
+	}, nil
+}
+
+// ipmiSerialNumber returns the serial number of the product in the
+// output of "ipmitool fru print", or of the board when the product
+// has none.
+func ipmiSerialNumber(fru string) string {
+	serials := map[string]string{}
+	for _, line := range strings.Split(fru, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) == 2 {
+			serials[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	if serial := serials["Product Serial"]; serial != "" {
+		return serial
+	}
+	return serials["Board Serial"]
+}
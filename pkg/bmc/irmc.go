@@ -59,9 +59,7 @@ func (a *iRMCAccessDetails) DriverInfo(bmcCreds Credentials) map[string]interfac
 		"ipmi_address":  a.hostname,
 	}
 
-	if a.disableCertificateVerification {
-		result["irmc_verify_ca"] = false
-	}
+	setVerifyCA(result, "irmc_verify_ca", a.disableCertificateVerification, bmcCreds)
 
 	if a.portNum != "" {
 		result["irmc_port"] = a.portNum
@@ -70,6 +68,10 @@ func (a *iRMCAccessDetails) DriverInfo(bmcCreds Credentials) map[string]interfac
 	return result
 }
 
+func (a *iRMCAccessDetails) ValidateCredentials(bmcCreds Credentials) error {
+	return bmcCreds.validatePasswordOnly(a.Type())
+}
+
 func (a *iRMCAccessDetails) BootInterface() string {
 	return "pxe"
 }
@@ -94,51 +96,10 @@ func (a *iRMCAccessDetails) SupportsSecureBoot() bool {
 	return true
 }
 
-func (a *iRMCAccessDetails) BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
-	if firmwareConfig == nil {
-		return nil, nil
-	}
-
-	var value string
-
-	if firmwareConfig.VirtualizationEnabled != nil {
-		value = "False"
-		if *firmwareConfig.VirtualizationEnabled {
-			value = "True"
-		}
-		settings = append(settings,
-			map[string]string{
-				"name":  "cpu_vt_enabled",
-				"value": value,
-			},
-		)
-	}
-
-	if firmwareConfig.SimultaneousMultithreadingEnabled != nil {
-		value = "False"
-		if *firmwareConfig.SimultaneousMultithreadingEnabled {
-			value = "True"
-		}
-		settings = append(settings,
-			map[string]string{
-				"name":  "hyper_threading_enabled",
-				"value": value,
-			},
-		)
-	}
-
-	if firmwareConfig.SriovEnabled != nil {
-		value = "False"
-		if *firmwareConfig.SriovEnabled {
-			value = "True"
-		}
-		settings = append(settings,
-			map[string]string{
-				"name":  "single_root_io_virtualization_support_enabled",
-				"value": value,
-			},
-		)
-	}
+func (a *iRMCAccessDetails) SupportsFirmwareUpdate() bool {
+	return false
+}
 
-	return
+func (a *iRMCAccessDetails) BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
+	return buildBIOSSettings(a.Driver(), iRMCBIOSTable, firmwareConfig)
 }
@@ -1,7 +1,6 @@
 package bmc
 
 import (
-	"fmt"
 	"net/url"
 	"strings"
 
@@ -93,13 +92,15 @@ func (a *redfishAccessDetails) DriverInfo(bmcCreds Credentials) map[string]inter
 		"redfish_address":   getRedfishAddress(a.bmcType, a.host),
 	}
 
-	if a.disableCertificateVerification {
-		result["redfish_verify_ca"] = false
-	}
+	setVerifyCA(result, "redfish_verify_ca", a.disableCertificateVerification, bmcCreds)
 
 	return result
 }
 
+func (a *redfishAccessDetails) ValidateCredentials(bmcCreds Credentials) error {
+	return bmcCreds.validatePasswordOnly(a.Type())
+}
+
 // That can be either pxe or redfish-virtual-media
 func (a *redfishAccessDetails) BootInterface() string {
 	return "ipxe"
@@ -125,11 +126,12 @@ func (a *redfishAccessDetails) SupportsSecureBoot() bool {
 	return true
 }
 
+func (a *redfishAccessDetails) SupportsFirmwareUpdate() bool {
+	return true
+}
+
 func (a *redfishAccessDetails) BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
-	if firmwareConfig != nil {
-		return nil, fmt.Errorf("firmware settings for %s are not supported", a.Driver())
-	}
-	return nil, nil
+	return buildBIOSSettings(a.Driver(), nil, firmwareConfig)
 }
 
 // iDrac Redfish Overrides
@@ -159,8 +161,5 @@ func (a *redfishiDracAccessDetails) VendorInterface() string {
 }
 
 func (a *redfishiDracAccessDetails) BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
-	if firmwareConfig != nil {
-		return nil, fmt.Errorf("firmware settings for %s are not supported", a.Driver())
-	}
-	return nil, nil
+	return buildBIOSSettings(a.Driver(), nil, firmwareConfig)
 }
@@ -0,0 +1,93 @@
+package bmc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+const redfishAccountsPath = "/redfish/v1/AccountService/Accounts"
+
+type redfishAccountClient struct {
+	redfishClient
+	systemURL string
+}
+
+type redfishCollection struct {
+	Members []struct {
+		ID string `json:"@odata.id"`
+	} `json:"Members"`
+}
+
+type redfishAccount struct {
+	ETag     string `json:"@odata.etag"`
+	UserName string `json:"UserName"`
+}
+
+// findAccount returns the URL and ETag of the account of the
+// credentials.
+func (c *redfishAccountClient) findAccount() (accountURL *url.URL, etag string, err error) {
+	base, err := url.Parse(c.systemURL)
+	if err != nil {
+		return nil, "", err
+	}
+	accountsURL, err := base.Parse(redfishAccountsPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := c.do(http.MethodGet, accountsURL.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	accounts := &redfishCollection{}
+	if err := json.Unmarshal(data, accounts); err != nil {
+		return nil, "", errors.Wrap(err, "failed to parse the Redfish accounts")
+	}
+
+	for _, member := range accounts.Members {
+		memberURL, err := base.Parse(member.ID)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "invalid Redfish account")
+		}
+		data, err := c.do(http.MethodGet, memberURL.String(), nil)
+		if err != nil {
+			return nil, "", err
+		}
+		account := &redfishAccount{}
+		if err := json.Unmarshal(data, account); err != nil {
+			return nil, "", errors.Wrap(err, "failed to parse the Redfish account")
+		}
+		if account.UserName == c.creds.Username {
+			return memberURL, account.ETag, nil
+		}
+	}
+	return nil, "", fmt.Errorf("no Redfish account found for user %s", c.creds.Username)
+}
+
+// SetPassword changes the password of the account through the Redfish
+// AccountService.
+func (c *redfishAccountClient) SetPassword(password string) error {
+	accountURL, etag, err := c.findAccount()
+	if err != nil {
+		return err
+	}
+
+	var header map[string]string
+	if etag != "" {
+		header = map[string]string{"If-Match": etag}
+	}
+	_, err = c.doWithHeader(http.MethodPatch, accountURL.String(),
+		map[string]string{"Password": password}, header)
+	return err
+}
+
+// CheckPassword reads the ComputerSystem, which requires the BMC to
+// accept the credentials.
+func (c *redfishAccountClient) CheckPassword() error {
+	_, err := c.do(http.MethodGet, c.systemURL, nil)
+	return err
+}
@@ -0,0 +1,108 @@
+package bmc
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const redfishTimeout = 30 * time.Second
+
+// redfishSystem is implemented by the access details of the BMC types
+// that expose a Redfish ComputerSystem.
+type redfishSystem interface {
+	redfishSystemURL() string
+	DisableCertificateVerification() bool
+}
+
+func (a *redfishAccessDetails) redfishSystemURL() string {
+	return getRedfishAddress(a.bmcType, a.host) + a.path
+}
+
+// redfishClient sends authenticated requests to the Redfish API of a
+// BMC.
+type redfishClient struct {
+	creds  Credentials
+	client *http.Client
+}
+
+func newRedfishClient(disableCertificateVerification bool, creds Credentials) (redfishClient, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{} // #nosec
+	if disableCertificateVerification {
+		transport.TLSClientConfig.InsecureSkipVerify = true
+	} else if len(creds.CABundle) != 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(creds.CABundle) {
+			return redfishClient{}, errors.New("no certificate found in the CA bundle of the BMC")
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+	if creds.HasClientCertificate() {
+		cert, err := tls.X509KeyPair(creds.ClientCertificate, creds.ClientKey)
+		if err != nil {
+			return redfishClient{}, &CredentialsValidationError{message: err.Error()}
+		}
+		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+	return redfishClient{
+		creds:  creds,
+		client: &http.Client{Transport: transport, Timeout: redfishTimeout},
+	}, nil
+}
+
+func (c *redfishClient) do(method, address string, body interface{}) ([]byte, error) {
+	return c.doWithHeader(method, address, body, nil)
+}
+
+// doWithHeader sends a request with additional headers, such as the
+// If-Match header some BMCs require to modify resources.
+func (c *redfishClient) doWithHeader(method, address string, body interface{}, header map[string]string) ([]byte, error) {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		if reqBody, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest(method, address, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c.creds.Token != "":
+		req.Header.Set("X-Auth-Token", c.creds.Token)
+	case c.creds.Username != "":
+		req.SetBasicAuth(c.creds.Username, c.creds.Password)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to reach the BMC at %s", address)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the BMC response")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s returned %s", method, address, resp.Status)
+	}
+	return respBody, nil
+}
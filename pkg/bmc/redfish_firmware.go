@@ -0,0 +1,160 @@
+package bmc
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+// FirmwareClient reads the versions of the firmware of a host from its
+// BMC, to check the result of firmware updates.
+type FirmwareClient interface {
+	// FirmwareVersions returns the version of each component the
+	// BMC reports.
+	FirmwareVersions() (map[metal3v1alpha1.FirmwareComponent]string, error)
+}
+
+// FirmwareClientFactory describes a callable that returns a new
+// FirmwareClient for the BMC described by the access details.
+type FirmwareClientFactory func(accessDetails AccessDetails, creds Credentials) (FirmwareClient, error)
+
+// NewFirmwareClient returns a FirmwareClient using the Redfish API of
+// the BMC. It returns a FirmwareClientUnsupportedError for other BMC
+// types.
+func NewFirmwareClient(accessDetails AccessDetails, creds Credentials) (FirmwareClient, error) {
+	system, ok := accessDetails.(redfishSystem)
+	if !ok {
+		return nil, FirmwareClientUnsupportedError{bmcType: accessDetails.Type()}
+	}
+	// Unlike Ironic, the native client accepts tokens and client
+	// certificates
+	if err := creds.Validate(); err != nil {
+		return nil, err
+	}
+
+	client, err := newRedfishClient(system.DisableCertificateVerification(), creds)
+	if err != nil {
+		return nil, err
+	}
+	return &redfishFirmwareClient{
+		redfishClient: client,
+		systemURL:     system.redfishSystemURL(),
+	}, nil
+}
+
+type redfishFirmwareClient struct {
+	redfishClient
+	systemURL string
+}
+
+type redfishLink struct {
+	ID string `json:"@odata.id"`
+}
+
+// redfishSystemFirmware holds the fields of a Redfish ComputerSystem
+// leading to the versions of its firmware.
+type redfishSystemFirmware struct {
+	BiosVersion string `json:"BiosVersion"`
+	Links       struct {
+		ManagedBy []redfishLink `json:"ManagedBy"`
+	} `json:"Links"`
+	NetworkInterfaces redfishLink `json:"NetworkInterfaces"`
+}
+
+type redfishManager struct {
+	FirmwareVersion string `json:"FirmwareVersion"`
+}
+
+type redfishNetworkInterface struct {
+	Links struct {
+		NetworkAdapter redfishLink `json:"NetworkAdapter"`
+	} `json:"Links"`
+}
+
+type redfishNetworkAdapter struct {
+	Controllers []struct {
+		FirmwarePackageVersion string `json:"FirmwarePackageVersion"`
+	} `json:"Controllers"`
+}
+
+// FirmwareVersions returns the version of the BIOS of the system, of
+// the BMC managing it and of its network adapters. Components the BMC
+// does not report are left out. When the network adapters run
+// different versions, they are all listed, separated by commas.
+func (c *redfishFirmwareClient) FirmwareVersions() (map[metal3v1alpha1.FirmwareComponent]string, error) {
+	base, err := url.Parse(c.systemURL)
+	if err != nil {
+		return nil, err
+	}
+
+	system := &redfishSystemFirmware{}
+	if err := c.get(base, base.Path, system); err != nil {
+		return nil, errors.Wrap(err, "failed to read the Redfish system")
+	}
+	versions := map[metal3v1alpha1.FirmwareComponent]string{}
+	if system.BiosVersion != "" {
+		versions[metal3v1alpha1.FirmwareComponentBIOS] = system.BiosVersion
+	}
+
+	if len(system.Links.ManagedBy) != 0 {
+		manager := &redfishManager{}
+		if err := c.get(base, system.Links.ManagedBy[0].ID, manager); err != nil {
+			return nil, errors.Wrap(err, "failed to read the Redfish manager")
+		}
+		if manager.FirmwareVersion != "" {
+			versions[metal3v1alpha1.FirmwareComponentBMC] = manager.FirmwareVersion
+		}
+	}
+
+	nicVersions, err := c.networkAdapterVersions(base, system.NetworkInterfaces.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the Redfish network adapters")
+	}
+	if len(nicVersions) != 0 {
+		versions[metal3v1alpha1.FirmwareComponentNIC] = strings.Join(nicVersions, ",")
+	}
+	return versions, nil
+}
+
+// networkAdapterVersions returns the distinct firmware versions of the
+// network adapters of the system, sorted
+func (c *redfishFirmwareClient) networkAdapterVersions(base *url.URL, path string) ([]string, error) {
+	nicIDs, err := c.collectionMembers(base, path)
+	if err != nil {
+		return nil, err
+	}
+	adapters := map[string]bool{}
+	found := map[string]bool{}
+	for _, nicID := range nicIDs {
+		nic := &redfishNetworkInterface{}
+		if err := c.get(base, nicID, nic); err != nil {
+			return nil, err
+		}
+		adapterID := nic.Links.NetworkAdapter.ID
+		if adapterID == "" || adapters[adapterID] {
+			continue
+		}
+		adapters[adapterID] = true
+
+		adapter := &redfishNetworkAdapter{}
+		if err := c.get(base, adapterID, adapter); err != nil {
+			return nil, err
+		}
+		for _, controller := range adapter.Controllers {
+			if controller.FirmwarePackageVersion != "" {
+				found[controller.FirmwarePackageVersion] = true
+			}
+		}
+	}
+
+	versions := make([]string, 0, len(found))
+	for version := range found {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions, nil
+}
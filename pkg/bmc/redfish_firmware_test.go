@@ -0,0 +1,66 @@
+package bmc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc/testserver"
+)
+
+func newTestFirmwareClient(t *testing.T, address string, creds Credentials) FirmwareClient {
+	accessDetails, err := NewAccessDetails(address, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewFirmwareClient(accessDetails, creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestRedfishFirmwareVersions(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "pw").
+		WithFirmwareVersions("2.10.2", "4.40.00.00", "20.5.13", "14.27.1016", "20.5.13").
+		Start()
+	defer server.Stop()
+
+	client := newTestFirmwareClient(t, server.Address(), Credentials{Username: "admin", Password: "pw"})
+
+	versions, err := client.FirmwareVersions()
+	assert.NoError(t, err)
+	assert.Equal(t, map[metal3v1alpha1.FirmwareComponent]string{
+		metal3v1alpha1.FirmwareComponentBIOS: "2.10.2",
+		metal3v1alpha1.FirmwareComponentBMC:  "4.40.00.00",
+		metal3v1alpha1.FirmwareComponentNIC:  "14.27.1016,20.5.13",
+	}, versions)
+
+	// Versions the BMC does not report are left out
+	server.WithFirmwareVersions("2.11.0", "")
+	versions, err = client.FirmwareVersions()
+	assert.NoError(t, err)
+	assert.Equal(t, map[metal3v1alpha1.FirmwareComponent]string{
+		metal3v1alpha1.FirmwareComponentBIOS: "2.11.0",
+	}, versions)
+}
+
+func TestRedfishFirmwareVersionsBadCredentials(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "pw").WithFirmwareVersions("2.10.2", "4.40.00.00").Start()
+	defer server.Stop()
+
+	client := newTestFirmwareClient(t, server.Address(), Credentials{Username: "admin", Password: "wrong"})
+
+	_, err := client.FirmwareVersions()
+	assert.Error(t, err)
+}
+
+func TestNewFirmwareClient(t *testing.T) {
+	accessDetails, err := NewAccessDetails("ipmi://192.168.122.1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewFirmwareClient(accessDetails, Credentials{Username: "admin", Password: "pw"})
+	assert.IsType(t, FirmwareClientUnsupportedError{}, err)
+}
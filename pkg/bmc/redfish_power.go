@@ -0,0 +1,151 @@
+package bmc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// PowerState is the power state of a host as reported by its BMC.
+type PowerState string
+
+const (
+	// PowerStateOn means the host is powered on.
+	PowerStateOn PowerState = "On"
+	// PowerStateOff means the host is powered off.
+	PowerStateOff PowerState = "Off"
+	// PowerStatePoweringOn means the host is being powered on.
+	PowerStatePoweringOn PowerState = "PoweringOn"
+	// PowerStatePoweringOff means the host is being powered off.
+	PowerStatePoweringOff PowerState = "PoweringOff"
+)
+
+// ResetType is the kind of power change requested from the BMC.
+type ResetType string
+
+const (
+	// ResetOn powers the host on.
+	ResetOn ResetType = "On"
+	// ResetForceOff powers the host off immediately.
+	ResetForceOff ResetType = "ForceOff"
+	// ResetGracefulShutdown asks the operating system of the host to
+	// shut down.
+	ResetGracefulShutdown ResetType = "GracefulShutdown"
+)
+
+// PowerClient manages the power of a host by talking to its BMC
+// directly, without going through the provisioner.
+type PowerClient interface {
+	// PowerState returns the current power state of the host.
+	PowerState() (PowerState, error)
+
+	// Reset requests a power change. It returns a
+	// ResetTypeUnsupportedError if the BMC does not support the
+	// reset type.
+	Reset(resetType ResetType) error
+}
+
+// PowerClientFactory describes a callable that returns a new
+// PowerClient for the BMC described by the access details.
+type PowerClientFactory func(accessDetails AccessDetails, creds Credentials) (PowerClient, error)
+
+// NewPowerClient returns a PowerClient using the Redfish API of the
+// BMC. It returns a PowerClientUnsupportedError for other BMC types.
+func NewPowerClient(accessDetails AccessDetails, creds Credentials) (PowerClient, error) {
+	system, ok := accessDetails.(redfishSystem)
+	if !ok {
+		return nil, PowerClientUnsupportedError{bmcType: accessDetails.Type()}
+	}
+	// Unlike Ironic, the native client accepts tokens and client
+	// certificates
+	if err := creds.Validate(); err != nil {
+		return nil, err
+	}
+
+	client, err := newRedfishClient(system.DisableCertificateVerification(), creds)
+	if err != nil {
+		return nil, err
+	}
+	return &redfishPowerClient{
+		redfishClient: client,
+		systemURL:     system.redfishSystemURL(),
+	}, nil
+}
+
+type redfishPowerClient struct {
+	redfishClient
+	systemURL string
+}
+
+// redfishComputerSystem holds the fields of a Redfish ComputerSystem
+// used to manage its power.
+type redfishComputerSystem struct {
+	PowerState PowerState `json:"PowerState"`
+	Actions    struct {
+		Reset struct {
+			Target          string      `json:"target"`
+			AllowableValues []ResetType `json:"ResetType@Redfish.AllowableValues"`
+		} `json:"#ComputerSystem.Reset"`
+	} `json:"Actions"`
+}
+
+func (c *redfishPowerClient) getSystem() (*redfishComputerSystem, error) {
+	data, err := c.do(http.MethodGet, c.systemURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	system := &redfishComputerSystem{}
+	if err := json.Unmarshal(data, system); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the Redfish system")
+	}
+	return system, nil
+}
+
+// PowerState returns the current power state of the host.
+func (c *redfishPowerClient) PowerState() (PowerState, error) {
+	system, err := c.getSystem()
+	if err != nil {
+		return "", err
+	}
+	return system.PowerState, nil
+}
+
+// Reset calls the ComputerSystem.Reset action of the system.
+func (c *redfishPowerClient) Reset(resetType ResetType) error {
+	system, err := c.getSystem()
+	if err != nil {
+		return err
+	}
+
+	action := system.Actions.Reset
+	if action.Target == "" {
+		return fmt.Errorf("the Redfish system %s has no reset action", c.systemURL)
+	}
+	if action.AllowableValues != nil {
+		supported := false
+		for _, value := range action.AllowableValues {
+			if value == resetType {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return ResetTypeUnsupportedError{resetType: resetType}
+		}
+	}
+
+	// The target is a path on the BMC
+	base, err := url.Parse(c.systemURL)
+	if err != nil {
+		return err
+	}
+	target, err := base.Parse(action.Target)
+	if err != nil {
+		return errors.Wrap(err, "invalid Redfish reset target")
+	}
+	_, err = c.do(http.MethodPost, target.String(), map[string]ResetType{"ResetType": resetType})
+	return err
+}
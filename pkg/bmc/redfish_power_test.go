@@ -0,0 +1,151 @@
+package bmc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metal3-io/baremetal-operator/pkg/bmc/testserver"
+)
+
+func newTestPowerClient(t *testing.T, address string, creds Credentials) PowerClient {
+	accessDetails, err := NewAccessDetails(address, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewPowerClient(accessDetails, creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestRedfishPowerClient(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "pw").Start()
+	defer server.Stop()
+
+	client := newTestPowerClient(t, server.Address(), Credentials{Username: "admin", Password: "pw"})
+
+	state, err := client.PowerState()
+	assert.NoError(t, err)
+	assert.Equal(t, PowerStateOff, state)
+
+	assert.NoError(t, client.Reset(ResetOn))
+	state, err = client.PowerState()
+	assert.NoError(t, err)
+	assert.Equal(t, PowerStateOn, state)
+
+	assert.NoError(t, client.Reset(ResetGracefulShutdown))
+	assert.Equal(t, "Off", server.PowerState())
+	assert.Equal(t, []string{"On", "GracefulShutdown"}, server.ResetRequests())
+}
+
+func TestRedfishPowerClientUnsupportedResetType(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "pw").
+		WithPowerState("On").
+		WithAllowedResetTypes("On", "ForceOff").
+		Start()
+	defer server.Stop()
+
+	client := newTestPowerClient(t, server.Address(), Credentials{Username: "admin", Password: "pw"})
+
+	err := client.Reset(ResetGracefulShutdown)
+	assert.IsType(t, ResetTypeUnsupportedError{}, err)
+	assert.Empty(t, server.ResetRequests())
+
+	assert.NoError(t, client.Reset(ResetForceOff))
+	assert.Equal(t, "Off", server.PowerState())
+}
+
+func TestRedfishPowerClientBadCredentials(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "pw").Start()
+	defer server.Stop()
+
+	client := newTestPowerClient(t, server.Address(), Credentials{Username: "admin", Password: "wrong"})
+
+	_, err := client.PowerState()
+	assert.Error(t, err)
+	assert.Error(t, client.Reset(ResetOn))
+	assert.Empty(t, server.ResetRequests())
+}
+
+func TestRedfishPowerClientToken(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "pw").WithToken("session-token").Start()
+	defer server.Stop()
+
+	client := newTestPowerClient(t, server.Address(), Credentials{Token: "session-token"})
+
+	state, err := client.PowerState()
+	assert.NoError(t, err)
+	assert.Equal(t, PowerStateOff, state)
+}
+
+func TestNewPowerClient(t *testing.T) {
+	creds := Credentials{Username: "admin", Password: "pw"}
+	for _, tc := range []struct {
+		Scenario    string
+		Address     string
+		Creds       Credentials
+		ExpectError bool
+	}{
+		{
+			Scenario: "redfish",
+			Address:  "redfish://192.168.122.1/redfish/v1/Systems/1",
+			Creds:    creds,
+		},
+		{
+			Scenario: "idrac redfish",
+			Address:  "idrac-redfish://192.168.122.1/redfish/v1/Systems/System.Embedded.1",
+			Creds:    creds,
+		},
+		{
+			Scenario:    "ipmi",
+			Address:     "ipmi://192.168.122.1",
+			Creds:       creds,
+			ExpectError: true,
+		},
+		{
+			Scenario:    "missing credentials",
+			Address:     "redfish://192.168.122.1/redfish/v1/Systems/1",
+			ExpectError: true,
+		},
+	} {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			accessDetails, err := NewAccessDetails(tc.Address, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = NewPowerClient(accessDetails, tc.Creds)
+			if tc.ExpectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRedfishPowerClientCABundle(t *testing.T) {
+	server := testserver.NewRedfish(t, "admin", "pw").StartTLSWithCA()
+	defer server.Stop()
+	creds := Credentials{Username: "admin", Password: "pw"}
+
+	// The certificate of the BMC is not trusted without the bundle
+	client := newTestPowerClient(t, server.Address(), creds)
+	_, err := client.PowerState()
+	assert.Error(t, err)
+
+	creds.CABundle = server.CABundle()
+	client = newTestPowerClient(t, server.Address(), creds)
+	state, err := client.PowerState()
+	assert.NoError(t, err)
+	assert.Equal(t, PowerStateOff, state)
+
+	accessDetails, err := NewAccessDetails(server.Address(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds.CABundle = []byte("not a certificate")
+	_, err = NewPowerClient(accessDetails, creds)
+	assert.Error(t, err)
+}
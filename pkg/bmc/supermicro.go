@@ -0,0 +1,28 @@
+package bmc
+
+import (
+	"net/url"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+func init() {
+	RegisterFactory("supermicro-redfish", newSupermicroRedfishAccessDetails, []string{"http", "https"})
+}
+
+func newSupermicroRedfishAccessDetails(parsedURL *url.URL, disableCertificateVerification bool) (AccessDetails, error) {
+	return &supermicroRedfishAccessDetails{
+		*redfishDetails(parsedURL, disableCertificateVerification),
+	}, nil
+}
+
+// supermicroRedfishAccessDetails manages Supermicro hosts with the
+// generic Redfish driver, using the BIOS attribute names of the
+// Supermicro firmware.
+type supermicroRedfishAccessDetails struct {
+	redfishAccessDetails
+}
+
+func (a *supermicroRedfishAccessDetails) BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
+	return buildBIOSSettings(a.Driver(), supermicroBIOSTable, firmwareConfig)
+}
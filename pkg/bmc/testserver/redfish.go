@@ -0,0 +1,612 @@
+package testserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	redfishServiceRootPath = "/redfish/v1/"
+	redfishSystemsPath     = "/redfish/v1/Systems"
+
+	// RedfishSystemPath is the path of the system served by the mock
+	RedfishSystemPath = redfishSystemsPath + "/1"
+
+	redfishNICsPath = RedfishSystemPath + "/EthernetInterfaces"
+
+	redfishManagerPath           = "/redfish/v1/Managers/1"
+	redfishNetworkInterfacesPath = RedfishSystemPath + "/NetworkInterfaces"
+	redfishNetworkAdaptersPath   = "/redfish/v1/Chassis/1/NetworkAdapters"
+
+	redfishResetPath = RedfishSystemPath + "/Actions/ComputerSystem.Reset"
+
+	redfishAccountsPath = "/redfish/v1/AccountService/Accounts"
+	// The account of the credentials, the first one belongs to
+	// another user
+	redfishAccountPath      = redfishAccountsPath + "/2"
+	redfishOtherAccountPath = redfishAccountsPath + "/1"
+)
+
+// RedfishMock is a test server that implements the power management
+// part of the Redfish API for a single ComputerSystem, its inventory,
+// and the AccountService for the account of its credentials
+type RedfishMock struct {
+	t        *testing.T
+	server   *httptest.Server
+	caBundle []byte
+	username string
+	password string
+	token    string
+
+	serialNumber string
+	macAddresses []string
+
+	biosVersion string
+	bmcVersion  string
+	nicVersions []string
+
+	lock              sync.Mutex
+	powerState        string
+	allowedResetTypes []string
+	ignoreShutdown    bool
+	resetRequests     []string
+
+	rejectPasswordChanges bool
+	failPasswordChanges   bool
+	accountVersion        int
+}
+
+// NewRedfish builds a new Redfish mock server accepting the given
+// credentials. The system is initially powered off.
+func NewRedfish(t *testing.T, username, password string) *RedfishMock {
+	return &RedfishMock{
+		t:          t,
+		username:   username,
+		password:   password,
+		powerState: "Off",
+	}
+}
+
+// WithToken makes the mock accept a session token in the X-Auth-Token
+// header, in addition to the username and password.
+func (m *RedfishMock) WithToken(token string) *RedfishMock {
+	m.token = token
+	return m
+}
+
+// WithPowerState sets the power state of the system
+func (m *RedfishMock) WithPowerState(state string) *RedfishMock {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.powerState = state
+	return m
+}
+
+// WithAllowedResetTypes restricts the reset types accepted by the
+// system. All reset types are accepted by default.
+func (m *RedfishMock) WithAllowedResetTypes(resetTypes ...string) *RedfishMock {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.allowedResetTypes = resetTypes
+	return m
+}
+
+// IgnoringShutdown makes the system ignore graceful shutdown
+// requests, like a host whose operating system does not respond.
+func (m *RedfishMock) IgnoringShutdown() *RedfishMock {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.ignoreShutdown = true
+	return m
+}
+
+// RejectingPasswordChanges makes the BMC refuse to change the
+// password of the account.
+func (m *RedfishMock) RejectingPasswordChanges() *RedfishMock {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.rejectPasswordChanges = true
+	return m
+}
+
+// FailingPasswordChanges makes the BMC change the password of the
+// account but report an error, as when the response is lost.
+func (m *RedfishMock) FailingPasswordChanges() *RedfishMock {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.failPasswordChanges = true
+	return m
+}
+
+// WithInventory sets the serial number of the system and the MAC
+// addresses of its network interfaces
+func (m *RedfishMock) WithInventory(serialNumber string, macAddresses ...string) *RedfishMock {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.serialNumber = serialNumber
+	m.macAddresses = macAddresses
+	return m
+}
+
+// WithFirmwareVersions sets the versions of the BIOS of the system,
+// of the BMC, and of one network adapter per NIC version
+func (m *RedfishMock) WithFirmwareVersions(bios, bmc string, nics ...string) *RedfishMock {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.biosVersion = bios
+	m.bmcVersion = bmc
+	m.nicVersions = nics
+	return m
+}
+
+// Start runs the server
+func (m *RedfishMock) Start() *RedfishMock {
+	m.server = httptest.NewServer(m.handler())
+	return m
+}
+
+// StartTLS runs the server with HTTPS, using a self-signed certificate
+func (m *RedfishMock) StartTLS() *RedfishMock {
+	m.server = httptest.NewTLSServer(m.handler())
+	return m
+}
+
+// StartTLSWithCA runs the server with HTTPS, using a certificate
+// signed by a private certificate authority whose certificate is
+// returned by CABundle
+func (m *RedfishMock) StartTLSWithCA() *RedfishMock {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Redfish mock CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "Redfish mock"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+
+	m.caBundle = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	m.server = httptest.NewUnstartedServer(m.handler())
+	m.server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+	m.server.StartTLS()
+	return m
+}
+
+// CABundle returns the PEM encoded certificate of the authority that
+// signed the certificate of a server started with StartTLSWithCA
+func (m *RedfishMock) CABundle() []byte {
+	return m.caBundle
+}
+
+func (m *RedfishMock) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(redfishServiceRootPath, m.handleServiceRoot)
+	mux.HandleFunc(redfishSystemsPath, m.handleSystems)
+	mux.HandleFunc(RedfishSystemPath, m.handleSystem)
+	mux.HandleFunc(redfishNICsPath, m.handleNICs)
+	mux.HandleFunc(redfishNICsPath+"/", m.handleNIC)
+	mux.HandleFunc(redfishResetPath, m.handleReset)
+	mux.HandleFunc(redfishManagerPath, m.handleManager)
+	mux.HandleFunc(redfishNetworkInterfacesPath, m.handleNetworkInterfaces)
+	mux.HandleFunc(redfishNetworkInterfacesPath+"/", m.handleNetworkInterface)
+	mux.HandleFunc(redfishNetworkAdaptersPath+"/", m.handleNetworkAdapter)
+	mux.HandleFunc(redfishAccountsPath, m.handleAccounts)
+	mux.HandleFunc(redfishAccountPath, m.handleAccount)
+	mux.HandleFunc(redfishOtherAccountPath, m.handleAccount)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		m.t.Logf("redfish: no handler for [%s] %s", r.Method, r.URL)
+		http.NotFound(w, r)
+	})
+	return mux
+}
+
+// Stop closes the server down
+func (m *RedfishMock) Stop() {
+	m.server.Close()
+}
+
+// Address returns the BMC address of the system, to be used in the
+// spec of a host
+func (m *RedfishMock) Address() string {
+	return "redfish+" + m.server.URL + RedfishSystemPath
+}
+
+// Host returns the host and port the server listens on
+func (m *RedfishMock) Host() string {
+	return m.server.Listener.Addr().String()
+}
+
+// PowerState returns the current power state of the system
+func (m *RedfishMock) PowerState() string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.powerState
+}
+
+// ResetRequests returns the reset types requested so far
+func (m *RedfishMock) ResetRequests() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]string{}, m.resetRequests...)
+}
+
+// Password returns the current password of the account
+func (m *RedfishMock) Password() string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.password
+}
+
+func (m *RedfishMock) authorized(w http.ResponseWriter, r *http.Request) bool {
+	username, password, ok := r.BasicAuth()
+	m.lock.Lock()
+	valid := ok && username == m.username && password == m.password
+	m.lock.Unlock()
+	if m.token != "" && r.Header.Get("X-Auth-Token") == m.token {
+		valid = true
+	}
+	if !valid {
+		m.t.Logf("redfish: [%s] %s -> unauthorized", r.Method, r.URL)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (m *RedfishMock) handleSystem(w http.ResponseWriter, r *http.Request) {
+	if !m.authorized(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	m.lock.Lock()
+	reset := map[string]interface{}{
+		"target": redfishResetPath,
+	}
+	if m.allowedResetTypes != nil {
+		reset["ResetType@Redfish.AllowableValues"] = m.allowedResetTypes
+	}
+	system := map[string]interface{}{
+		"@odata.id":    RedfishSystemPath,
+		"Id":           "1",
+		"PowerState":   m.powerState,
+		"SerialNumber": m.serialNumber,
+		"Actions": map[string]interface{}{
+			"#ComputerSystem.Reset": reset,
+		},
+		"EthernetInterfaces": map[string]string{"@odata.id": redfishNICsPath},
+		"NetworkInterfaces":  map[string]string{"@odata.id": redfishNetworkInterfacesPath},
+		"BiosVersion":        m.biosVersion,
+		"Links": map[string]interface{}{
+			"ManagedBy": []map[string]string{{"@odata.id": redfishManagerPath}},
+		},
+	}
+	m.lock.Unlock()
+
+	m.t.Logf("redfish: [%s] %s -> %s", r.Method, r.URL, system["PowerState"])
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(system); err != nil {
+		m.t.Error(err)
+	}
+}
+
+// handleServiceRoot serves the service root, which does not require
+// authentication
+func (m *RedfishMock) handleServiceRoot(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != redfishServiceRootPath {
+		m.t.Logf("redfish: no handler for [%s] %s", r.Method, r.URL)
+		http.NotFound(w, r)
+		return
+	}
+	m.t.Logf("redfish: [%s] %s", r.Method, r.URL)
+	m.writeJSON(w, map[string]interface{}{
+		"@odata.id":      redfishServiceRootPath,
+		"RedfishVersion": "1.6.0",
+		"Systems":        map[string]string{"@odata.id": redfishSystemsPath},
+	})
+}
+
+func (m *RedfishMock) handleSystems(w http.ResponseWriter, r *http.Request) {
+	if !m.authorized(w, r) {
+		return
+	}
+	m.t.Logf("redfish: [%s] %s", r.Method, r.URL)
+	m.writeJSON(w, map[string]interface{}{
+		"@odata.id": redfishSystemsPath,
+		"Members":   []map[string]string{{"@odata.id": RedfishSystemPath}},
+	})
+}
+
+func (m *RedfishMock) handleNICs(w http.ResponseWriter, r *http.Request) {
+	if !m.authorized(w, r) {
+		return
+	}
+	m.lock.Lock()
+	members := []map[string]string{}
+	for i := range m.macAddresses {
+		members = append(members, map[string]string{"@odata.id": fmt.Sprintf("%s/%d", redfishNICsPath, i)})
+	}
+	m.lock.Unlock()
+	m.t.Logf("redfish: [%s] %s", r.Method, r.URL)
+	m.writeJSON(w, map[string]interface{}{
+		"@odata.id": redfishNICsPath,
+		"Members":   members,
+	})
+}
+
+func (m *RedfishMock) handleNIC(w http.ResponseWriter, r *http.Request) {
+	if !m.authorized(w, r) {
+		return
+	}
+	var index int
+	m.lock.Lock()
+	_, err := fmt.Sscanf(r.URL.Path, redfishNICsPath+"/%d", &index)
+	if err != nil || index < 0 || index >= len(m.macAddresses) {
+		m.lock.Unlock()
+		http.NotFound(w, r)
+		return
+	}
+	mac := m.macAddresses[index]
+	m.lock.Unlock()
+	m.t.Logf("redfish: [%s] %s", r.Method, r.URL)
+	m.writeJSON(w, map[string]interface{}{
+		"@odata.id":  r.URL.Path,
+		"MACAddress": mac,
+	})
+}
+
+func (m *RedfishMock) handleManager(w http.ResponseWriter, r *http.Request) {
+	if !m.authorized(w, r) {
+		return
+	}
+	m.lock.Lock()
+	version := m.bmcVersion
+	m.lock.Unlock()
+	m.t.Logf("redfish: [%s] %s", r.Method, r.URL)
+	m.writeJSON(w, map[string]interface{}{
+		"@odata.id":       redfishManagerPath,
+		"FirmwareVersion": version,
+	})
+}
+
+func (m *RedfishMock) handleNetworkInterfaces(w http.ResponseWriter, r *http.Request) {
+	if !m.authorized(w, r) {
+		return
+	}
+	m.lock.Lock()
+	members := []map[string]string{}
+	for i := range m.nicVersions {
+		members = append(members, map[string]string{"@odata.id": fmt.Sprintf("%s/%d", redfishNetworkInterfacesPath, i)})
+	}
+	m.lock.Unlock()
+	m.t.Logf("redfish: [%s] %s", r.Method, r.URL)
+	m.writeJSON(w, map[string]interface{}{
+		"@odata.id": redfishNetworkInterfacesPath,
+		"Members":   members,
+	})
+}
+
+// nicVersion returns the firmware version of the network adapter
+// with the index at the end of the path of the request
+func (m *RedfishMock) nicVersion(w http.ResponseWriter, r *http.Request, prefix string) (int, string, bool) {
+	var index int
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	_, err := fmt.Sscanf(r.URL.Path, prefix+"/%d", &index)
+	if err != nil || index < 0 || index >= len(m.nicVersions) {
+		http.NotFound(w, r)
+		return 0, "", false
+	}
+	return index, m.nicVersions[index], true
+}
+
+func (m *RedfishMock) handleNetworkInterface(w http.ResponseWriter, r *http.Request) {
+	if !m.authorized(w, r) {
+		return
+	}
+	index, _, ok := m.nicVersion(w, r, redfishNetworkInterfacesPath)
+	if !ok {
+		return
+	}
+	m.t.Logf("redfish: [%s] %s", r.Method, r.URL)
+	m.writeJSON(w, map[string]interface{}{
+		"@odata.id": r.URL.Path,
+		"Links": map[string]interface{}{
+			"NetworkAdapter": map[string]string{
+				"@odata.id": fmt.Sprintf("%s/%d", redfishNetworkAdaptersPath, index),
+			},
+		},
+	})
+}
+
+func (m *RedfishMock) handleNetworkAdapter(w http.ResponseWriter, r *http.Request) {
+	if !m.authorized(w, r) {
+		return
+	}
+	_, version, ok := m.nicVersion(w, r, redfishNetworkAdaptersPath)
+	if !ok {
+		return
+	}
+	m.t.Logf("redfish: [%s] %s", r.Method, r.URL)
+	m.writeJSON(w, map[string]interface{}{
+		"@odata.id": r.URL.Path,
+		"Controllers": []map[string]string{
+			{"FirmwarePackageVersion": version},
+		},
+	})
+}
+
+func (m *RedfishMock) handleReset(w http.ResponseWriter, r *http.Request) {
+	if !m.authorized(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body := struct {
+		ResetType string
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m.t.Logf("redfish: [%s] %s -> %s", r.Method, r.URL, body.ResetType)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.allowedResetTypes != nil && !contains(m.allowedResetTypes, body.ResetType) {
+		http.Error(w, "unsupported reset type", http.StatusBadRequest)
+		return
+	}
+	m.resetRequests = append(m.resetRequests, body.ResetType)
+
+	switch body.ResetType {
+	case "On", "ForceOn":
+		m.powerState = "On"
+	case "ForceOff":
+		m.powerState = "Off"
+	case "GracefulShutdown":
+		if !m.ignoreShutdown {
+			m.powerState = "Off"
+		}
+	case "ForceRestart", "GracefulRestart":
+	default:
+		http.Error(w, "unsupported reset type", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (m *RedfishMock) writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		m.t.Error(err)
+	}
+}
+
+func (m *RedfishMock) handleAccounts(w http.ResponseWriter, r *http.Request) {
+	if !m.authorized(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	m.t.Logf("redfish: [%s] %s", r.Method, r.URL)
+	m.writeJSON(w, map[string]interface{}{
+		"@odata.id": redfishAccountsPath,
+		"Members": []map[string]string{
+			{"@odata.id": redfishOtherAccountPath},
+			{"@odata.id": redfishAccountPath},
+		},
+	})
+}
+
+func (m *RedfishMock) handleAccount(w http.ResponseWriter, r *http.Request) {
+	if !m.authorized(w, r) {
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	username := "root"
+	if r.URL.Path == redfishAccountPath {
+		username = m.username
+	}
+	etag := fmt.Sprintf("W/\"%d\"", m.accountVersion)
+
+	switch r.Method {
+	case http.MethodGet:
+		m.t.Logf("redfish: [%s] %s", r.Method, r.URL)
+		m.writeJSON(w, map[string]interface{}{
+			"@odata.id":   r.URL.Path,
+			"@odata.etag": etag,
+			"UserName":    username,
+		})
+	case http.MethodPatch:
+		body := struct {
+			Password string
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		m.t.Logf("redfish: [%s] %s -> new password", r.Method, r.URL)
+		switch {
+		case r.Header.Get("If-Match") != etag:
+			http.Error(w, "precondition failed", http.StatusPreconditionFailed)
+		case r.URL.Path != redfishAccountPath || m.rejectPasswordChanges || body.Password == "":
+			http.Error(w, "password change refused", http.StatusBadRequest)
+		case m.failPasswordChanges:
+			m.password = body.Password
+			m.accountVersion++
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		default:
+			m.password = body.Password
+			m.accountVersion++
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
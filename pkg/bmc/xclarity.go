@@ -0,0 +1,111 @@
+package bmc
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+func init() {
+	RegisterFactory("xclarity", newXClarityAccessDetails, []string{})
+}
+
+func newXClarityAccessDetails(parsedURL *url.URL, disableCertificateVerification bool) (AccessDetails, error) {
+	hardwareID := strings.Trim(parsedURL.Path, "/")
+	if hardwareID == "" {
+		return nil, fmt.Errorf("the hardware ID of the host is missing from the XClarity address")
+	}
+	return &xClarityAccessDetails{
+		bmcType:                        parsedURL.Scheme,
+		portNum:                        parsedURL.Port(),
+		hostname:                       parsedURL.Hostname(),
+		hardwareID:                     hardwareID,
+		disableCertificateVerification: disableCertificateVerification,
+	}, nil
+}
+
+// xClarityAccessDetails manages Lenovo hosts through the Lenovo
+// XClarity Administrator.
+type xClarityAccessDetails struct {
+	bmcType                        string
+	portNum                        string
+	hostname                       string
+	hardwareID                     string
+	disableCertificateVerification bool
+}
+
+func (a *xClarityAccessDetails) Type() string {
+	return a.bmcType
+}
+
+// NeedsMAC returns true when the host is going to need a separate
+// port created rather than having it discovered.
+func (a *xClarityAccessDetails) NeedsMAC() bool {
+	return true
+}
+
+func (a *xClarityAccessDetails) Driver() string {
+	return "xclarity"
+}
+
+func (a *xClarityAccessDetails) DisableCertificateVerification() bool {
+	return a.disableCertificateVerification
+}
+
+// DriverInfo returns a data structure to pass as the DriverInfo
+// parameter when creating a node in Ironic. The structure is
+// pre-populated with the access information, and the caller is
+// expected to add any other information that might be needed (such as
+// the kernel and ramdisk locations).
+func (a *xClarityAccessDetails) DriverInfo(bmcCreds Credentials) map[string]interface{} {
+	result := map[string]interface{}{
+		"xclarity_manager_ip":  a.hostname,
+		"xclarity_username":    bmcCreds.Username,
+		"xclarity_password":    bmcCreds.Password,
+		"xclarity_hardware_id": a.hardwareID,
+	}
+
+	if a.portNum != "" {
+		result["xclarity_port"] = a.portNum
+	}
+
+	return result
+}
+
+func (a *xClarityAccessDetails) ValidateCredentials(bmcCreds Credentials) error {
+	return bmcCreds.validatePasswordOnly(a.Type())
+}
+
+func (a *xClarityAccessDetails) BootInterface() string {
+	return "ipxe"
+}
+
+func (a *xClarityAccessDetails) ManagementInterface() string {
+	return "xclarity"
+}
+
+func (a *xClarityAccessDetails) PowerInterface() string {
+	return "xclarity"
+}
+
+func (a *xClarityAccessDetails) RAIDInterface() string {
+	return "no-raid"
+}
+
+func (a *xClarityAccessDetails) VendorInterface() string {
+	return ""
+}
+
+func (a *xClarityAccessDetails) SupportsSecureBoot() bool {
+	return false
+}
+
+func (a *xClarityAccessDetails) SupportsFirmwareUpdate() bool {
+	return false
+}
+
+func (a *xClarityAccessDetails) BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
+	return buildBIOSSettings(a.Driver(), xClarityBIOSTable, firmwareConfig)
+}
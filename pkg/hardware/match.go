@@ -0,0 +1,120 @@
+package hardware
+
+import (
+	"strings"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+// MatchProfile returns the profile best matching the hardware
+// details of a host, along with the names of the rules of the profile
+// that matched. Profiles with a higher priority are preferred, then
+// the ones matching more rules, then the name is used to make the
+// choice stable. It returns nil if no profile matches.
+func MatchProfile(details *metal3v1alpha1.HardwareDetails, profiles []metal3v1alpha1.HardwareProfile) (*metal3v1alpha1.HardwareProfile, []string) {
+	if details == nil {
+		return nil, nil
+	}
+
+	var best *metal3v1alpha1.HardwareProfile
+	var bestRules []string
+	for i := range profiles {
+		profile := &profiles[i]
+		rules, ok := matchRules(details, &profile.Spec.Match)
+		if !ok {
+			continue
+		}
+		if best != nil {
+			if profile.Spec.Priority != best.Spec.Priority {
+				if profile.Spec.Priority < best.Spec.Priority {
+					continue
+				}
+			} else if len(rules) != len(bestRules) {
+				if len(rules) < len(bestRules) {
+					continue
+				}
+			} else if profile.Name > best.Name {
+				continue
+			}
+		}
+		best, bestRules = profile, rules
+	}
+	return best, bestRules
+}
+
+// matchRules checks the rules that are set against the hardware
+// details, returning the names of the rules when all of them match.
+func matchRules(details *metal3v1alpha1.HardwareDetails, match *metal3v1alpha1.HardwareProfileMatch) (rules []string, ok bool) {
+	check := func(name string, matched bool) {
+		if matched {
+			rules = append(rules, name)
+		} else {
+			ok = false
+		}
+	}
+	ok = true
+
+	if match.Manufacturer != "" {
+		check("manufacturer", containsFold(details.SystemVendor.Manufacturer, match.Manufacturer))
+	}
+	if match.ProductName != "" {
+		check("productName", containsFold(details.SystemVendor.ProductName, match.ProductName))
+	}
+	if match.CPUArch != "" {
+		check("cpuArch", details.CPU.Arch == match.CPUArch)
+	}
+	if match.CPUCount != nil {
+		check("cpuCount", inRange(details.CPU.Count, match.CPUCount))
+	}
+	if match.RAMMebibytes != nil {
+		check("ramMebibytes", inRange(details.RAMMebibytes, match.RAMMebibytes))
+	}
+	if match.Disks != nil {
+		check("disks", matchDisks(details.Storage, match.Disks))
+	}
+	if match.NICCount != nil {
+		check("nicCount", inRange(len(details.NIC), match.NICCount))
+	}
+	return rules, ok
+}
+
+func matchDisks(storage []metal3v1alpha1.Storage, match *metal3v1alpha1.DiskMatch) bool {
+	count := 0
+	for _, disk := range storage {
+		if match.Type != "" && disk.Type != match.Type {
+			continue
+		}
+		if match.SizeGigabytes != nil && !inRange(int(disk.SizeBytes/metal3v1alpha1.GigaByte), match.SizeGigabytes) {
+			continue
+		}
+		count++
+	}
+	if match.Count == nil {
+		return count > 0
+	}
+	return inRange(count, match.Count)
+}
+
+func inRange(value int, r *metal3v1alpha1.IntRange) bool {
+	return value >= r.Min && (r.Max == 0 || value <= r.Max)
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// ProfileFromResource returns the settings of a HardwareProfile
+// resource, using the values of the default profile for the ones that
+// are not set.
+func ProfileFromResource(resource *metal3v1alpha1.HardwareProfile) Profile {
+	profile := Profile{
+		Name:    resource.Name,
+		RootGB:  resource.Spec.RootGB,
+		LocalGB: resource.Spec.LocalGB,
+		CPUArch: resource.Spec.CPUArch,
+	}
+	if resource.Spec.RootDeviceHints != nil {
+		profile.RootDeviceHints = *resource.Spec.RootDeviceHints
+	}
+	return withDefaults(profile)
+}
@@ -0,0 +1,182 @@
+package hardware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+func newProfile(name string, priority int, match metal3v1alpha1.HardwareProfileMatch) metal3v1alpha1.HardwareProfile {
+	return metal3v1alpha1.HardwareProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: metal3v1alpha1.HardwareProfileSpec{
+			Match:    match,
+			Priority: priority,
+		},
+	}
+}
+
+func TestMatchProfile(t *testing.T) {
+	details := &metal3v1alpha1.HardwareDetails{
+		SystemVendor: metal3v1alpha1.HardwareSystemVendor{
+			Manufacturer: "Dell Inc.",
+			ProductName:  "PowerEdge R640",
+		},
+		RAMMebibytes: 192 * 1024,
+		NIC:          []metal3v1alpha1.NIC{{Name: "eno1"}, {Name: "eno2"}},
+		Storage: []metal3v1alpha1.Storage{
+			{Name: "/dev/sda", Type: metal3v1alpha1.SSD, SizeBytes: 480 * metal3v1alpha1.GigaByte},
+			{Name: "/dev/sdb", Type: metal3v1alpha1.HDD, SizeBytes: 4000 * metal3v1alpha1.GigaByte},
+			{Name: "/dev/sdc", Type: metal3v1alpha1.HDD, SizeBytes: 4000 * metal3v1alpha1.GigaByte},
+		},
+		CPU: metal3v1alpha1.CPU{Arch: "x86_64", Count: 48},
+	}
+
+	testCases := []struct {
+		Scenario        string
+		Profiles        []metal3v1alpha1.HardwareProfile
+		ExpectedProfile string
+		ExpectedRules   []string
+	}{
+		{
+			Scenario: "no profiles",
+		},
+
+		{
+			Scenario: "vendor and product",
+			Profiles: []metal3v1alpha1.HardwareProfile{
+				newProfile("r640", 0, metal3v1alpha1.HardwareProfileMatch{
+					Manufacturer: "dell",
+					ProductName:  "R640",
+				}),
+			},
+			ExpectedProfile: "r640",
+			ExpectedRules:   []string{"manufacturer", "productName"},
+		},
+
+		{
+			Scenario: "one rule does not match",
+			Profiles: []metal3v1alpha1.HardwareProfile{
+				newProfile("r640-arm", 0, metal3v1alpha1.HardwareProfileMatch{
+					ProductName: "R640",
+					CPUArch:     "aarch64",
+				}),
+			},
+		},
+
+		{
+			Scenario: "cpu and ram ranges",
+			Profiles: []metal3v1alpha1.HardwareProfile{
+				newProfile("small", 0, metal3v1alpha1.HardwareProfileMatch{
+					CPUCount: &metal3v1alpha1.IntRange{Max: 16},
+				}),
+				newProfile("large", 0, metal3v1alpha1.HardwareProfileMatch{
+					CPUCount:     &metal3v1alpha1.IntRange{Min: 32, Max: 64},
+					RAMMebibytes: &metal3v1alpha1.IntRange{Min: 128 * 1024},
+				}),
+			},
+			ExpectedProfile: "large",
+			ExpectedRules:   []string{"cpuCount", "ramMebibytes"},
+		},
+
+		{
+			Scenario: "disks by type and size",
+			Profiles: []metal3v1alpha1.HardwareProfile{
+				newProfile("storage", 0, metal3v1alpha1.HardwareProfileMatch{
+					Disks: &metal3v1alpha1.DiskMatch{
+						Type:          metal3v1alpha1.HDD,
+						SizeGigabytes: &metal3v1alpha1.IntRange{Min: 2000},
+						Count:         &metal3v1alpha1.IntRange{Min: 2, Max: 2},
+					},
+				}),
+				newProfile("nvme", 0, metal3v1alpha1.HardwareProfileMatch{
+					Disks: &metal3v1alpha1.DiskMatch{
+						Type: metal3v1alpha1.NVME,
+					},
+				}),
+			},
+			ExpectedProfile: "storage",
+			ExpectedRules:   []string{"disks"},
+		},
+
+		{
+			Scenario: "most rules wins",
+			Profiles: []metal3v1alpha1.HardwareProfile{
+				newProfile("dell", 0, metal3v1alpha1.HardwareProfileMatch{
+					Manufacturer: "Dell",
+				}),
+				newProfile("dell-2-nics", 0, metal3v1alpha1.HardwareProfileMatch{
+					Manufacturer: "Dell",
+					NICCount:     &metal3v1alpha1.IntRange{Min: 2, Max: 2},
+				}),
+			},
+			ExpectedProfile: "dell-2-nics",
+			ExpectedRules:   []string{"manufacturer", "nicCount"},
+		},
+
+		{
+			Scenario: "priority wins",
+			Profiles: []metal3v1alpha1.HardwareProfile{
+				newProfile("dell", 5, metal3v1alpha1.HardwareProfileMatch{
+					Manufacturer: "Dell",
+				}),
+				newProfile("dell-2-nics", 0, metal3v1alpha1.HardwareProfileMatch{
+					Manufacturer: "Dell",
+					NICCount:     &metal3v1alpha1.IntRange{Min: 2},
+				}),
+			},
+			ExpectedProfile: "dell",
+			ExpectedRules:   []string{"manufacturer"},
+		},
+
+		{
+			Scenario: "name breaks ties",
+			Profiles: []metal3v1alpha1.HardwareProfile{
+				newProfile("b", 0, metal3v1alpha1.HardwareProfileMatch{}),
+				newProfile("a", 0, metal3v1alpha1.HardwareProfileMatch{}),
+			},
+			ExpectedProfile: "a",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			profile, rules := MatchProfile(details, tc.Profiles)
+			if tc.ExpectedProfile == "" {
+				assert.Nil(t, profile)
+				return
+			}
+			if assert.NotNil(t, profile) {
+				assert.Equal(t, tc.ExpectedProfile, profile.Name)
+			}
+			assert.Equal(t, tc.ExpectedRules, rules)
+		})
+	}
+}
+
+func TestMatchProfileWithoutDetails(t *testing.T) {
+	profiles := []metal3v1alpha1.HardwareProfile{
+		newProfile("any", 0, metal3v1alpha1.HardwareProfileMatch{}),
+	}
+	profile, _ := MatchProfile(nil, profiles)
+	assert.Nil(t, profile)
+}
+
+func TestProfileFromResource(t *testing.T) {
+	resource := &metal3v1alpha1.HardwareProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "r640"},
+		Spec: metal3v1alpha1.HardwareProfileSpec{
+			RootDeviceHints: &metal3v1alpha1.RootDeviceHints{HCTL: "0:2:0:0"},
+			LocalGB:         100,
+		},
+	}
+	profile := ProfileFromResource(resource)
+	assert.Equal(t, "r640", profile.Name)
+	assert.Equal(t, metal3v1alpha1.RootDeviceHints{HCTL: "0:2:0:0"}, profile.RootDeviceHints)
+	assert.Equal(t, 10, profile.RootGB)
+	assert.Equal(t, 100, profile.LocalGB)
+	assert.Equal(t, "x86_64", profile.CPUArch)
+}
@@ -2,6 +2,7 @@ package hardware
 
 import (
 	"fmt"
+	"strings"
 
 	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 )
@@ -15,22 +16,24 @@ const (
 // Profile holds the settings for a class of hardware.
 type Profile struct {
 	// Name holds the profile name
-	Name string
+	Name string `json:"name"`
 
 	// RootDeviceHints holds the suggestions for placing the storage
 	// for the root filesystem.
-	RootDeviceHints metal3v1alpha1.RootDeviceHints
+	RootDeviceHints metal3v1alpha1.RootDeviceHints `json:"rootDeviceHints,omitempty"`
 
 	// RootGB is the size of the root volume in GB
-	RootGB int
+	RootGB int `json:"rootGB,omitempty"`
 
 	// LocalGB is the size of something(?)
-	LocalGB int
+	LocalGB int `json:"localGB,omitempty"`
 
 	// CPUArch is the architecture of the CPU.
-	CPUArch string
+	CPUArch string `json:"cpuArch,omitempty"`
 }
 
+// profiles holds the built-in profiles, which are used when no
+// profile with the same name has been loaded into the registry.
 var profiles = make(map[string]Profile)
 
 func init() {
@@ -85,11 +88,32 @@ func init() {
 	}
 }
 
-// GetProfile returns the named profile
+// GetProfile returns the named profile from the default registry
 func GetProfile(name string) (Profile, error) {
-	profile, ok := profiles[name]
-	if !ok {
-		return Profile{}, fmt.Errorf("No hardware profile named %q", name)
+	return defaultRegistry.GetProfile(name)
+}
+
+// Validate checks that the settings of the profile can be used to
+// provision a host.
+func (p Profile) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("hardware profile has no name")
+	}
+	if p.RootGB <= 0 {
+		return fmt.Errorf("hardware profile %q: rootGB must be greater than 0", p.Name)
+	}
+	if p.LocalGB < 0 {
+		return fmt.Errorf("hardware profile %q: localGB must not be negative", p.Name)
+	}
+	if p.CPUArch == "" {
+		return fmt.Errorf("hardware profile %q: cpuArch is required", p.Name)
+	}
+	if p.RootDeviceHints.DeviceName != "" && !strings.HasPrefix(p.RootDeviceHints.DeviceName, "/dev/") {
+		return fmt.Errorf("hardware profile %q: root device name %q must start with /dev/",
+			p.Name, p.RootDeviceHints.DeviceName)
+	}
+	if p.RootDeviceHints.MinSizeGigabytes < 0 {
+		return fmt.Errorf("hardware profile %q: root device minSizeGigabytes must not be negative", p.Name)
 	}
-	return profile, nil
+	return nil
 }
@@ -0,0 +1,95 @@
+package hardware
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+// Registry holds the hardware profiles defined by the user. The
+// built-in profiles remain available as defaults, a loaded profile
+// with the same name takes precedence over them.
+type Registry struct {
+	lock   sync.RWMutex
+	loaded map[string]Profile
+}
+
+var defaultRegistry = &Registry{}
+
+// DefaultRegistry returns the registry used by GetProfile
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// GetProfile returns the named profile
+func (r *Registry) GetProfile(name string) (Profile, error) {
+	r.lock.RLock()
+	profile, ok := r.loaded[name]
+	r.lock.RUnlock()
+	if ok {
+		return profile, nil
+	}
+
+	profile, ok = profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("No hardware profile named %q", name)
+	}
+	return profile, nil
+}
+
+// Load replaces the user defined profiles of the registry. The
+// profiles are all validated first, and the registry is left
+// unchanged if any of them is invalid.
+func (r *Registry) Load(newProfiles []Profile) error {
+	loaded := make(map[string]Profile, len(newProfiles))
+	for _, profile := range newProfiles {
+		if err := profile.Validate(); err != nil {
+			return err
+		}
+		if _, dup := loaded[profile.Name]; dup {
+			return fmt.Errorf("hardware profile %q is defined more than once", profile.Name)
+		}
+		loaded[profile.Name] = profile
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.loaded = loaded
+	return nil
+}
+
+// ParseProfiles reads a YAML list of profiles. Settings that are not
+// given take the value of the default profile.
+func ParseProfiles(data []byte) ([]Profile, error) {
+	var result []Profile
+	if err := yaml.UnmarshalStrict(data, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to parse hardware profiles")
+	}
+	for i := range result {
+		result[i] = withDefaults(result[i])
+	}
+	return result, nil
+}
+
+// withDefaults returns the profile with the settings that are not set
+// taken from the default profile.
+func withDefaults(profile Profile) Profile {
+	defaults := profiles[DefaultProfileName]
+	if profile.RootDeviceHints == (metal3v1alpha1.RootDeviceHints{}) {
+		profile.RootDeviceHints = defaults.RootDeviceHints
+	}
+	if profile.RootGB == 0 {
+		profile.RootGB = defaults.RootGB
+	}
+	if profile.LocalGB == 0 {
+		profile.LocalGB = defaults.LocalGB
+	}
+	if profile.CPUArch == "" {
+		profile.CPUArch = defaults.CPUArch
+	}
+	return profile
+}
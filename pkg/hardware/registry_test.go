@@ -0,0 +1,151 @@
+package hardware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+func TestRegistryBuiltinProfiles(t *testing.T) {
+	r := &Registry{}
+	for _, name := range []string{DefaultProfileName, "libvirt", "dell", "dell-raid", "openstack"} {
+		profile, err := r.GetProfile(name)
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, name, profile.Name)
+			assert.NoError(t, profile.Validate(), name)
+		}
+	}
+
+	_, err := r.GetProfile("no-such-profile")
+	assert.Error(t, err)
+}
+
+func TestRegistryLoad(t *testing.T) {
+	r := &Registry{}
+
+	err := r.Load([]Profile{
+		{Name: "r640", RootDeviceHints: metal3v1alpha1.RootDeviceHints{HCTL: "0:2:0:0"}, RootGB: 10, CPUArch: "x86_64"},
+		{Name: "dell", RootDeviceHints: metal3v1alpha1.RootDeviceHints{HCTL: "1:0:0:0"}, RootGB: 10, CPUArch: "x86_64"},
+	})
+	assert.NoError(t, err)
+
+	profile, err := r.GetProfile("r640")
+	assert.NoError(t, err)
+	assert.Equal(t, "0:2:0:0", profile.RootDeviceHints.HCTL)
+
+	// Loaded profiles take precedence over the built-in ones
+	profile, err = r.GetProfile("dell")
+	assert.NoError(t, err)
+	assert.Equal(t, "1:0:0:0", profile.RootDeviceHints.HCTL)
+
+	// Invalid profiles leave the registry unchanged
+	err = r.Load([]Profile{
+		{Name: "r740", RootGB: 10, CPUArch: "x86_64"},
+		{Name: "broken", RootGB: 0, CPUArch: "x86_64"},
+	})
+	assert.Error(t, err)
+	_, err = r.GetProfile("r740")
+	assert.Error(t, err)
+	_, err = r.GetProfile("r640")
+	assert.NoError(t, err)
+
+	// Loading nothing restores the built-in profiles
+	assert.NoError(t, r.Load(nil))
+	_, err = r.GetProfile("r640")
+	assert.Error(t, err)
+	profile, err = r.GetProfile("dell")
+	assert.NoError(t, err)
+	assert.Equal(t, "0:0:0:0", profile.RootDeviceHints.HCTL)
+}
+
+func TestProfileValidate(t *testing.T) {
+	valid := profiles[DefaultProfileName]
+
+	testCases := []struct {
+		Scenario string
+		Modify   func(*Profile)
+		Valid    bool
+	}{
+		{
+			Scenario: "valid",
+			Modify:   func(p *Profile) {},
+			Valid:    true,
+		},
+		{
+			Scenario: "no name",
+			Modify:   func(p *Profile) { p.Name = "" },
+		},
+		{
+			Scenario: "no root size",
+			Modify:   func(p *Profile) { p.RootGB = 0 },
+		},
+		{
+			Scenario: "negative local size",
+			Modify:   func(p *Profile) { p.LocalGB = -1 },
+		},
+		{
+			Scenario: "no cpu arch",
+			Modify:   func(p *Profile) { p.CPUArch = "" },
+		},
+		{
+			Scenario: "bad device name",
+			Modify:   func(p *Profile) { p.RootDeviceHints.DeviceName = "sda" },
+		},
+		{
+			Scenario: "negative minimum size",
+			Modify:   func(p *Profile) { p.RootDeviceHints.MinSizeGigabytes = -1 },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			profile := valid
+			tc.Modify(&profile)
+			err := profile.Validate()
+			if tc.Valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestParseProfiles(t *testing.T) {
+	profiles, err := ParseProfiles([]byte(`
+- name: r640
+  rootDeviceHints:
+    hctl: "0:2:0:0"
+  localGB: 100
+- name: arm
+  cpuArch: aarch64
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Profile{
+		{
+			Name:            "r640",
+			RootDeviceHints: metal3v1alpha1.RootDeviceHints{HCTL: "0:2:0:0"},
+			RootGB:          10,
+			LocalGB:         100,
+			CPUArch:         "x86_64",
+		},
+		{
+			Name:            "arm",
+			RootDeviceHints: metal3v1alpha1.RootDeviceHints{DeviceName: "/dev/sda"},
+			RootGB:          10,
+			LocalGB:         50,
+			CPUArch:         "aarch64",
+		},
+	}, profiles)
+
+	_, err = ParseProfiles([]byte(`
+- name: r640
+  rootDeviceHint:
+    hctl: "0:2:0:0"
+`))
+	assert.Error(t, err, "unknown fields should be rejected")
+}
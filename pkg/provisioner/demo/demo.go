@@ -349,3 +349,8 @@ func (p *demoProvisioner) PowerOff(rebootMode metal3v1alpha1.RebootMode, force b
 func (p *demoProvisioner) IsReady() (result bool, err error) {
 	return true, nil
 }
+
+// GetFirmwareSettings always returns no settings for the demo provisioner
+func (p *demoProvisioner) GetFirmwareSettings(includeSchema bool) (settings metal3v1alpha1.SettingsMap, schema map[string]metal3v1alpha1.SettingSchema, err error) {
+	return nil, nil, nil
+}
@@ -72,6 +72,14 @@ type Fixture struct {
 
 	validateError string
 
+	// FirmwareSettings are the BIOS settings reported for the host
+	FirmwareSettings metal3v1alpha1.SettingsMap
+	// FirmwareSchema describes the BIOS settings reported for the host
+	FirmwareSchema map[string]metal3v1alpha1.SettingSchema
+	// IgnoredFirmwareSettings are the BIOS settings left unchanged
+	// when they are applied
+	IgnoredFirmwareSettings []string
+
 	customDeploy *metal3v1alpha1.CustomDeploy
 }
 
@@ -190,6 +198,20 @@ func (p *fixtureProvisioner) UpdateHardwareState() (hwState provisioner.Hardware
 func (p *fixtureProvisioner) Prepare(data provisioner.PrepareData, unprepared bool) (result provisioner.Result, started bool, err error) {
 	p.log.Info("preparing host")
 	started = unprepared
+	if unprepared && len(data.TargetFirmwareSettings) != 0 {
+		if p.state.FirmwareSettings == nil {
+			p.state.FirmwareSettings = make(metal3v1alpha1.SettingsMap)
+		}
+	settings:
+		for name, value := range data.TargetFirmwareSettings {
+			for _, ignored := range p.state.IgnoredFirmwareSettings {
+				if name == ignored {
+					continue settings
+				}
+			}
+			p.state.FirmwareSettings[name] = value.String()
+		}
+	}
 	return
 }
 
@@ -331,3 +353,12 @@ func (p *fixtureProvisioner) IsReady() (result bool, err error) {
 
 	return p.state.BecomeReadyCounter == 0, nil
 }
+
+// GetFirmwareSettings returns the BIOS settings stored in the fixture
+func (p *fixtureProvisioner) GetFirmwareSettings(includeSchema bool) (settings metal3v1alpha1.SettingsMap, schema map[string]metal3v1alpha1.SettingSchema, err error) {
+	p.log.Info("getting firmware settings")
+	if includeSchema {
+		schema = p.state.FirmwareSchema
+	}
+	return p.state.FirmwareSettings, schema, nil
+}
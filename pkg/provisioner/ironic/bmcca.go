@@ -0,0 +1,68 @@
+package ironic
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// writeBMCCABundle stores the CA bundle of the BMC in the directory
+// shared with Ironic and returns its path. The file is named after the
+// checksum of its content, so that hosts using the same bundle share
+// the file and a changed bundle gets a new path.
+func (p *ironicProvisioner) writeBMCCABundle() (string, error) {
+	path := filepath.Join(p.config.bmcCABundleDir,
+		fmt.Sprintf("%x.pem", sha256.Sum256(p.bmcCABundle)))
+
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(p.config.bmcCABundleDir, 0755); err != nil {
+		return "", errors.Wrap(err, "failed to create the BMC CA bundle directory")
+	}
+	// Write to a temporary file first so that Ironic never reads a
+	// partial bundle.
+	tmp, err := ioutil.TempFile(p.config.bmcCABundleDir, ".bundle-")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to write the BMC CA bundle")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(p.bmcCABundle); err != nil {
+		tmp.Close()
+		return "", errors.Wrap(err, "failed to write the BMC CA bundle")
+	}
+	if err = tmp.Close(); err != nil {
+		return "", errors.Wrap(err, "failed to write the BMC CA bundle")
+	}
+	if err = os.Chmod(tmp.Name(), 0644); err != nil {
+		return "", errors.Wrap(err, "failed to write the BMC CA bundle")
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", errors.Wrap(err, "failed to write the BMC CA bundle")
+	}
+
+	p.log.Info("stored BMC CA bundle", "path", path)
+	return path, nil
+}
+
+// verifyCAChanged returns true when the certificate verification
+// settings of the driver info differ from those of the node. Unlike
+// the credentials, Ironic does not mask them.
+func verifyCAChanged(driverInfo, current map[string]interface{}) bool {
+	for _, info := range []map[string]interface{}{driverInfo, current} {
+		for key := range info {
+			if strings.HasSuffix(key, "_verify_ca") &&
+				!reflect.DeepEqual(driverInfo[key], current[key]) {
+				return true
+			}
+		}
+	}
+	return false
+}
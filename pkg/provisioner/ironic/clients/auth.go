@@ -70,13 +70,16 @@ func load(clientType string) (auth AuthConfig, err error) {
 	return
 }
 
-// LoadAuth loads the Ironic and Inspector configuration from the environment
-func LoadAuth() (ironicAuth, inspectorAuth AuthConfig, err error) {
-	ironicAuth, err = load("ironic")
+// LoadAuth loads the Ironic and Inspector configuration from the
+// environment. The configuration of a named endpoint is loaded from the
+// subdirectory of the same name, and the default one when the name is
+// empty.
+func LoadAuth(endpointName string) (ironicAuth, inspectorAuth AuthConfig, err error) {
+	ironicAuth, err = load(path.Join(endpointName, "ironic"))
 	if err != nil {
 		return
 	}
-	inspectorAuth, err = load("ironic-inspector")
+	inspectorAuth, err = load(path.Join(endpointName, "ironic-inspector"))
 	return
 }
 
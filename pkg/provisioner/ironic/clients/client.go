@@ -16,6 +16,12 @@ import (
 
 var tlsConnectionTimeout = time.Second * 30
 
+// BIOSRegistryMicroversion is the Ironic API version introducing the
+// BIOS registry fields to the BIOS settings. It is only requested by
+// the calls reading them, so that older Ironic releases can still be
+// used without the registry.
+const BIOSRegistryMicroversion = "1.74"
+
 // TLSConfig contains the TLS configuration for the Ironic connection.
 // Using Go default values for this will result in no additional trusted
 // CA certificates and a secure connection.
@@ -4,28 +4,64 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/gophercloud/gophercloud"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 	logz "sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/metal3-io/baremetal-operator/pkg/provisioner"
 	"github.com/metal3-io/baremetal-operator/pkg/provisioner/ironic/clients"
 )
 
-type ironicProvisionerFactory struct {
-	log    logr.Logger
-	config ironicConfig
+// ironicEndpoint is an Ironic deployment, along with the hosts it
+// manages when it is a named endpoint
+type ironicEndpoint struct {
+	name       string
+	namespaces []string
+	selector   labels.Selector
+
+	// maxBusyHosts overrides the provisioning limit of the global
+	// configuration when it is not 0
+	maxBusyHosts int
 
 	// Keep pointers to ironic and inspector clients configured with
-	// the global auth settings to reuse the connection between
-	// reconcilers.
+	// the auth settings of the endpoint to reuse the connection
+	// between reconcilers.
 	clientIronic    *gophercloud.ServiceClient
 	clientInspector *gophercloud.ServiceClient
 }
 
+// manages returns true when the host is mapped to the endpoint by its
+// namespace or its labels
+func (e *ironicEndpoint) manages(objectMeta metav1.ObjectMeta) bool {
+	for _, namespace := range e.namespaces {
+		if namespace == objectMeta.Namespace {
+			return true
+		}
+	}
+	return e.selector != nil && e.selector.Matches(labels.Set(objectMeta.Labels))
+}
+
+// defaultMaxBusyHosts is the provisioning limit when none is configured
+const defaultMaxBusyHosts = 20
+
+type ironicProvisionerFactory struct {
+	log    logr.Logger
+	config ironicConfig
+
+	// defaultEndpoint is used by the hosts that are not mapped to a
+	// named endpoint. It is nil when only named endpoints are
+	// configured.
+	defaultEndpoint *ironicEndpoint
+	namedEndpoints  []*ironicEndpoint
+}
+
 func NewProvisionerFactory() provisioner.Factory {
 	factory := ironicProvisionerFactory{}
 
@@ -39,67 +75,149 @@ func NewProvisionerFactory() provisioner.Factory {
 	return factory
 }
 
-func (f *ironicProvisionerFactory) init() error {
-	ironicAuth, inspectorAuth, err := clients.LoadAuth()
+func (f *ironicProvisionerFactory) init() (err error) {
+	f.config, err = loadConfigFromEnv()
 	if err != nil {
 		return err
 	}
 
-	f.config, err = loadConfigFromEnv()
+	names, err := loadEndpointNamesFromEnv()
 	if err != nil {
 		return err
 	}
 
-	ironicEndpoint, inspectorEndpoint, err := loadEndpointsFromEnv()
+	// The default endpoint is optional when there are named endpoints
+	if len(names) == 0 || os.Getenv("IRONIC_ENDPOINT") != "" || os.Getenv("IRONIC_INSPECTOR_ENDPOINT") != "" {
+		f.defaultEndpoint, err = f.loadEndpoint("")
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		endpoint, err := f.loadEndpoint(name)
+		if err != nil {
+			return err
+		}
+		f.namedEndpoints = append(f.namedEndpoints, endpoint)
+	}
+
+	return nil
+}
+
+// endpointSettings are the settings used to create the clients of an
+// endpoint
+type endpointSettings struct {
+	ironicURL     string
+	inspectorURL  string
+	ironicAuth    clients.AuthConfig
+	inspectorAuth clients.AuthConfig
+	tls           clients.TLSConfig
+}
+
+// loadEndpoint creates the clients of the endpoint from its
+// configuration in the environment
+func (f *ironicProvisionerFactory) loadEndpoint(name string) (*ironicEndpoint, error) {
+	var settings endpointSettings
+	var err error
+	settings.ironicAuth, settings.inspectorAuth, err = clients.LoadAuth(name)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	tlsConf := loadTLSConfigFromEnv()
+	settings.ironicURL, settings.inspectorURL, err = loadEndpointsFromEnv(name)
+	if err != nil {
+		return nil, err
+	}
 
-	f.log.Info("ironic settings",
-		"endpoint", ironicEndpoint,
-		"ironicAuthType", ironicAuth.Type,
-		"inspectorEndpoint", inspectorEndpoint,
-		"inspectorAuthType", inspectorAuth.Type,
+	settings.tls = loadTLSConfigFromEnv(name)
+
+	endpoint, err := loadEndpointMappingFromEnv(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint, f.connectEndpoint(endpoint, settings)
+}
+
+// connectEndpoint creates the clients of the endpoint
+func (f *ironicProvisionerFactory) connectEndpoint(endpoint *ironicEndpoint, settings endpointSettings) (err error) {
+	logger := f.log
+	if endpoint.name != "" {
+		logger = logger.WithValues("ironicEndpointName", endpoint.name)
+	}
+	logger.Info("ironic settings",
+		"endpoint", settings.ironicURL,
+		"ironicAuthType", settings.ironicAuth.Type,
+		"inspectorEndpoint", settings.inspectorURL,
+		"inspectorAuthType", settings.inspectorAuth.Type,
 		"deployKernelURL", f.config.deployKernelURL,
 		"deployRamdiskURL", f.config.deployRamdiskURL,
 		"deployISOURL", f.config.deployISOURL,
-		"CACertFile", tlsConf.TrustedCAFile,
-		"ClientCertFile", tlsConf.ClientCertificateFile,
-		"ClientPrivKeyFile", tlsConf.ClientPrivateKeyFile,
-		"TLSInsecure", tlsConf.InsecureSkipVerify,
-		"SkipClientSANVerify", tlsConf.SkipClientSANVerify,
+		"bmcCABundleDir", f.config.bmcCABundleDir,
+		"CACertFile", settings.tls.TrustedCAFile,
+		"ClientCertFile", settings.tls.ClientCertificateFile,
+		"ClientPrivKeyFile", settings.tls.ClientPrivateKeyFile,
+		"TLSInsecure", settings.tls.InsecureSkipVerify,
+		"SkipClientSANVerify", settings.tls.SkipClientSANVerify,
+		"namespaces", endpoint.namespaces,
+		"selector", endpoint.selector,
+		"provisioningLimit", endpoint.maxBusyHosts,
 	)
 
-	f.clientIronic, err = clients.IronicClient(
-		ironicEndpoint, ironicAuth, tlsConf)
+	endpoint.clientIronic, err = clients.IronicClient(
+		settings.ironicURL, settings.ironicAuth, settings.tls)
 	if err != nil {
 		return err
 	}
 
-	f.clientInspector, err = clients.InspectorClient(
-		inspectorEndpoint, inspectorAuth, tlsConf)
-	if err != nil {
-		return err
-	}
+	endpoint.clientInspector, err = clients.InspectorClient(
+		settings.inspectorURL, settings.inspectorAuth, settings.tls)
+	return err
+}
 
-	return nil
+// endpoint returns the endpoint managing the host: the first named
+// endpoint the host is mapped to, or the default endpoint.
+func (f ironicProvisionerFactory) endpoint(objectMeta metav1.ObjectMeta) (*ironicEndpoint, error) {
+	for _, endpoint := range f.namedEndpoints {
+		if endpoint.manages(objectMeta) {
+			return endpoint, nil
+		}
+	}
+	if f.defaultEndpoint == nil {
+		return nil, fmt.Errorf("no Ironic endpoint manages hosts in namespace %s with labels %v",
+			objectMeta.Namespace, objectMeta.Labels)
+	}
+	return f.defaultEndpoint, nil
 }
 
 func (f ironicProvisionerFactory) ironicProvisioner(hostData provisioner.HostData, publisher provisioner.EventPublisher) (*ironicProvisioner, error) {
+	endpoint, err := f.endpoint(hostData.ObjectMeta)
+	if err != nil {
+		return nil, err
+	}
+
 	provisionerLogger := f.log.WithValues("host", ironicNodeName(hostData.ObjectMeta))
+	if endpoint.name != "" {
+		provisionerLogger = provisionerLogger.WithValues("ironicEndpointName", endpoint.name)
+	}
+
+	config := f.config
+	if endpoint.maxBusyHosts != 0 {
+		config.maxBusyHosts = endpoint.maxBusyHosts
+	}
 
 	p := &ironicProvisioner{
-		config:                  f.config,
+		config:                  config,
 		objectMeta:              hostData.ObjectMeta,
 		nodeID:                  hostData.ProvisionerID,
 		bmcCreds:                hostData.BMCCredentials,
 		bmcAddress:              hostData.BMCAddress,
 		disableCertVerification: hostData.DisableCertificateVerification,
+		bmcCABundle:             hostData.BMCCABundle,
 		bootMACAddress:          hostData.BootMACAddress,
-		client:                  f.clientIronic,
-		inspector:               f.clientInspector,
+		client:                  endpoint.clientIronic,
+		inspector:               endpoint.clientInspector,
 		log:                     provisionerLogger,
 		debugLog:                provisionerLogger.V(1),
 		publisher:               publisher,
@@ -108,8 +226,9 @@ func (f ironicProvisionerFactory) ironicProvisioner(hostData provisioner.HostDat
 	return p, nil
 }
 
-// NewProvisioner returns a new Ironic Provisioner using the global
-// configuration for finding the Ironic services.
+// NewProvisioner returns a new Ironic Provisioner using the
+// configuration of the endpoint managing the host for finding the
+// Ironic services.
 func (f ironicProvisionerFactory) NewProvisioner(hostData provisioner.HostData, publisher provisioner.EventPublisher) (provisioner.Provisioner, error) {
 	return f.ironicProvisioner(hostData, publisher)
 }
@@ -129,7 +248,9 @@ func loadConfigFromEnv() (ironicConfig, error) {
 		return c, errors.New("DEPLOY_KERNEL_URL and DEPLOY_RAMDISK_URL can only be set together")
 	}
 
-	c.maxBusyHosts = 20
+	loadBMCCABundleDirFromEnv(&c)
+
+	c.maxBusyHosts = defaultMaxBusyHosts
 	if maxHostsStr := os.Getenv("PROVISIONING_LIMIT"); maxHostsStr != "" {
 		value, err := strconv.Atoi(maxHostsStr)
 		if err != nil {
@@ -141,39 +262,126 @@ func loadConfigFromEnv() (ironicConfig, error) {
 	return c, nil
 }
 
-func loadEndpointsFromEnv() (ironicEndpoint, inspectorEndpoint string, err error) {
-	ironicEndpoint = os.Getenv("IRONIC_ENDPOINT")
+// endpointVariable returns the name of the environment variable
+// holding the setting of a named endpoint, which has the name of the
+// endpoint in upper case as a suffix.
+func endpointVariable(variable, endpointName string) string {
+	if endpointName == "" {
+		return variable
+	}
+	return variable + "_" + strings.ToUpper(strings.ReplaceAll(endpointName, "-", "_"))
+}
+
+func endpointEnv(variable, endpointName string) string {
+	return os.Getenv(endpointVariable(variable, endpointName))
+}
+
+func loadEndpointNamesFromEnv() (names []string, err error) {
+	seen := map[string]bool{}
+	for _, name := range strings.Split(os.Getenv("IRONIC_ENDPOINT_NAMES"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) != 0 {
+			return nil, fmt.Errorf("Invalid Ironic endpoint name %q in IRONIC_ENDPOINT_NAMES: %s",
+				name, strings.Join(errs, ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("Duplicate Ironic endpoint name %q in IRONIC_ENDPOINT_NAMES", name)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// loadBMCCABundleDirFromEnv loads the directory shared with Ironic,
+// which is mounted in the pod of the operator
+func loadBMCCABundleDirFromEnv(c *ironicConfig) {
+	c.bmcCABundleDir = os.Getenv("BMC_CA_BUNDLE_DIR")
+	if c.bmcCABundleDir == "" {
+		c.bmcCABundleDir = "/shared/bmc-ca"
+	}
+}
+
+func loadEndpointsFromEnv(endpointName string) (ironicEndpoint, inspectorEndpoint string, err error) {
+	ironicEndpoint = endpointEnv("IRONIC_ENDPOINT", endpointName)
 	if ironicEndpoint == "" {
-		err = errors.New("No IRONIC_ENDPOINT variable set")
+		err = fmt.Errorf("No %s variable set", endpointVariable("IRONIC_ENDPOINT", endpointName))
 	}
-	inspectorEndpoint = os.Getenv("IRONIC_INSPECTOR_ENDPOINT")
+	inspectorEndpoint = endpointEnv("IRONIC_INSPECTOR_ENDPOINT", endpointName)
 	if inspectorEndpoint == "" {
-		err = errors.New("No IRONIC_INSPECTOR_ENDPOINT variable set")
+		err = fmt.Errorf("No %s variable set", endpointVariable("IRONIC_INSPECTOR_ENDPOINT", endpointName))
 	}
 
 	return
 }
 
-func loadTLSConfigFromEnv() clients.TLSConfig {
-	ironicCACertFile := os.Getenv("IRONIC_CACERT_FILE")
-	if ironicCACertFile == "" {
-		ironicCACertFile = "/opt/metal3/certs/ca/tls.crt"
+// loadEndpointMappingFromEnv loads the namespaces and the label
+// selector of the hosts managed by a named endpoint, along with its
+// provisioning limit.
+func loadEndpointMappingFromEnv(endpointName string) (*ironicEndpoint, error) {
+	endpoint := &ironicEndpoint{name: endpointName}
+	if endpointName == "" {
+		return endpoint, nil
 	}
-	ironicClientCertFile := os.Getenv("IRONIC_CLIENT_CERT_FILE")
-	if ironicClientCertFile == "" {
-		ironicClientCertFile = "/opt/metal3/certs/client/tls.crt"
+
+	for _, namespace := range strings.Split(endpointEnv("IRONIC_ENDPOINT_NAMESPACES", endpointName), ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			endpoint.namespaces = append(endpoint.namespaces, namespace)
+		}
 	}
-	ironicClientPrivKeyFile := os.Getenv("IRONIC_CLIENT_PRIVATE_KEY_FILE")
-	if ironicClientPrivKeyFile == "" {
-		ironicClientPrivKeyFile = "/opt/metal3/certs/client/tls.key"
+
+	selectorVariable := endpointVariable("IRONIC_ENDPOINT_SELECTOR", endpointName)
+	if selectorStr := os.Getenv(selectorVariable); selectorStr != "" {
+		selector, err := labels.Parse(selectorStr)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value set for variable %s=%s: %w", selectorVariable, selectorStr, err)
+		}
+		endpoint.selector = selector
+	}
+
+	if len(endpoint.namespaces) == 0 && endpoint.selector == nil {
+		return nil, fmt.Errorf("Either %s or %s must be set",
+			endpointVariable("IRONIC_ENDPOINT_NAMESPACES", endpointName), selectorVariable)
 	}
+
+	limitVariable := endpointVariable("PROVISIONING_LIMIT", endpointName)
+	if maxHostsStr := os.Getenv(limitVariable); maxHostsStr != "" {
+		value, err := strconv.Atoi(maxHostsStr)
+		if err != nil || value < 1 {
+			return nil, fmt.Errorf("Invalid value set for variable %s=%s", limitVariable, maxHostsStr)
+		}
+		endpoint.maxBusyHosts = value
+	}
+
+	return endpoint, nil
+}
+
+// certFile returns the path of a certificate file of an endpoint,
+// which defaults to a subdirectory named after the endpoint
+func certFile(endpointName, file, defaultFile string) string {
+	if file != "" {
+		return file
+	}
+	return path.Join("/opt/metal3/certs", endpointName, defaultFile)
+}
+
+func loadTLSConfigFromEnv(endpointName string) clients.TLSConfig {
+	ironicCACertFile := certFile(endpointName,
+		endpointEnv("IRONIC_CACERT_FILE", endpointName), "ca/tls.crt")
+	ironicClientCertFile := certFile(endpointName,
+		endpointEnv("IRONIC_CLIENT_CERT_FILE", endpointName), "client/tls.crt")
+	ironicClientPrivKeyFile := certFile(endpointName,
+		endpointEnv("IRONIC_CLIENT_PRIVATE_KEY_FILE", endpointName), "client/tls.key")
 	insecure := false
-	ironicInsecureStr := os.Getenv("IRONIC_INSECURE")
+	ironicInsecureStr := endpointEnv("IRONIC_INSECURE", endpointName)
 	if strings.ToLower(ironicInsecureStr) == "true" {
 		insecure = true
 	}
 	skipClientSANVerify := false
-	ironicSkipClientSANVerifyStr := os.Getenv("IRONIC_SKIP_CLIENT_SAN_VERIFY")
+	ironicSkipClientSANVerifyStr := endpointEnv("IRONIC_SKIP_CLIENT_SAN_VERIFY", endpointName)
 	if strings.ToLower(ironicSkipClientSANVerifyStr) == "true" {
 		skipClientSANVerify = true
 	}
@@ -1,10 +1,18 @@
 package ironic
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner/ironic/clients"
 )
 
 type EnvFixture struct {
@@ -170,7 +178,7 @@ func TestLoadEndpointsFromEnv(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			defer tc.env.TearDown()
 			tc.env.SetUp()
-			i, ii, err := loadEndpointsFromEnv()
+			i, ii, err := loadEndpointsFromEnv("")
 			if tc.expectError {
 				assert.NotNil(t, err)
 			} else {
@@ -180,3 +188,176 @@ func TestLoadEndpointsFromEnv(t *testing.T) {
 		})
 	}
 }
+
+// setUpEnv replaces the environment variables, which are restored by
+// the TearDown of the fixture returned
+func setUpEnv(env map[string]string) *EnvFixture {
+	f := &EnvFixture{origEnv: map[string]string{}}
+	for e, v := range env {
+		f.replace(e, v)
+	}
+	return f
+}
+
+func TestLoadEndpointNamesFromEnv(t *testing.T) {
+	cases := []struct {
+		names         string
+		expected      []string
+		expectedError string
+	}{
+		{names: ""},
+		{names: "dc1, dc-2,", expected: []string{"dc1", "dc-2"}},
+		{names: "dc1,DC2", expectedError: "Invalid Ironic endpoint name \"DC2\""},
+		{names: "dc1,dc1", expectedError: "Duplicate Ironic endpoint name \"dc1\""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.names, func(t *testing.T) {
+			env := setUpEnv(map[string]string{"IRONIC_ENDPOINT_NAMES": tc.names})
+			defer env.TearDown()
+			names, err := loadEndpointNamesFromEnv()
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, names)
+			}
+		})
+	}
+}
+
+func TestLoadNamedEndpointFromEnv(t *testing.T) {
+	env := setUpEnv(map[string]string{
+		"IRONIC_ENDPOINT":                     "http://ironic.test",
+		"IRONIC_ENDPOINT_DC_2":                "http://ironic.dc2.test",
+		"IRONIC_INSPECTOR_ENDPOINT_DC_2":      "http://ironic-inspector.dc2.test",
+		"IRONIC_CACERT_FILE_DC_2":             "/certs/dc2/ca.crt",
+		"IRONIC_INSECURE_DC_2":                "True",
+		"IRONIC_ENDPOINT_NAMESPACES_DC_2":     "rack-1, rack-2",
+		"IRONIC_ENDPOINT_SELECTOR_DC_2":       "datacenter=dc2",
+		"PROVISIONING_LIMIT_DC_2":             "5",
+		"IRONIC_ENDPOINT_NAMESPACES_DC_3":     "rack-3",
+		"IRONIC_ENDPOINT_SELECTOR_DC_3":       "datacenter in (",
+		"IRONIC_ENDPOINT_NAMESPACES_DC_4":     "",
+		"IRONIC_ENDPOINT_SELECTOR_DC_4":       "",
+		"PROVISIONING_LIMIT_DC_5":             "0",
+		"IRONIC_ENDPOINT_NAMESPACES_DC_5":     "rack-5",
+		"IRONIC_CLIENT_CERT_FILE":             "",
+		"IRONIC_CLIENT_CERT_FILE_DC_2":        "",
+		"IRONIC_CLIENT_PRIVATE_KEY_FILE_DC_2": "",
+	})
+	defer env.TearDown()
+
+	ironic, inspector, err := loadEndpointsFromEnv("dc-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://ironic.dc2.test", ironic)
+	assert.Equal(t, "http://ironic-inspector.dc2.test", inspector)
+
+	_, _, err = loadEndpointsFromEnv("dc-3")
+	assert.Regexp(t, "No IRONIC_INSPECTOR_ENDPOINT_DC_3 variable set", err)
+
+	tlsConf := loadTLSConfigFromEnv("dc-2")
+	assert.Equal(t, "/certs/dc2/ca.crt", tlsConf.TrustedCAFile)
+	assert.Equal(t, "/opt/metal3/certs/dc-2/client/tls.crt", tlsConf.ClientCertificateFile)
+	assert.Equal(t, "/opt/metal3/certs/dc-2/client/tls.key", tlsConf.ClientPrivateKeyFile)
+	assert.True(t, tlsConf.InsecureSkipVerify)
+	assert.Equal(t, "/opt/metal3/certs/client/tls.crt", loadTLSConfigFromEnv("").ClientCertificateFile)
+
+	endpoint, err := loadEndpointMappingFromEnv("dc-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "dc-2", endpoint.name)
+	assert.Equal(t, []string{"rack-1", "rack-2"}, endpoint.namespaces)
+	assert.Equal(t, "datacenter=dc2", endpoint.selector.String())
+	assert.Equal(t, 5, endpoint.maxBusyHosts)
+
+	_, err = loadEndpointMappingFromEnv("dc-3")
+	assert.Regexp(t, "Invalid value set for variable IRONIC_ENDPOINT_SELECTOR_DC_3", err)
+	_, err = loadEndpointMappingFromEnv("dc-4")
+	assert.Regexp(t, "Either IRONIC_ENDPOINT_NAMESPACES_DC_4 or IRONIC_ENDPOINT_SELECTOR_DC_4 must be set", err)
+	_, err = loadEndpointMappingFromEnv("dc-5")
+	assert.Regexp(t, "Invalid value set for variable PROVISIONING_LIMIT_DC_5=0", err)
+}
+
+func TestProvisionerEndpoint(t *testing.T) {
+	dc1 := &ironicEndpoint{name: "dc1", namespaces: []string{"rack-1"}}
+	dc2 := &ironicEndpoint{name: "dc2", selector: labels.SelectorFromSet(labels.Set{"datacenter": "dc2"}), maxBusyHosts: 5}
+	defaultEndpoint := &ironicEndpoint{}
+
+	factory := newTestProvisionerFactory()
+	factory.defaultEndpoint = nil
+	factory.namedEndpoints = []*ironicEndpoint{dc1, dc2}
+
+	host := makeHost()
+	_, err := factory.ironicProvisioner(provisioner.BuildHostData(host, bmc.Credentials{}), nullEventPublisher)
+	assert.Regexp(t, "no Ironic endpoint manages hosts in namespace myns", err)
+
+	factory.defaultEndpoint = defaultEndpoint
+	cases := []struct {
+		name         string
+		namespace    string
+		labels       map[string]string
+		expected     *ironicEndpoint
+		maxBusyHosts int
+	}{
+		{name: "default", namespace: "myns", expected: defaultEndpoint, maxBusyHosts: 20},
+		{name: "namespace", namespace: "rack-1", expected: dc1, maxBusyHosts: 20},
+		{name: "labels", namespace: "myns", labels: map[string]string{"datacenter": "dc2"}, expected: dc2, maxBusyHosts: 5},
+		{name: "first match", namespace: "rack-1", labels: map[string]string{"datacenter": "dc2"}, expected: dc1, maxBusyHosts: 20},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			host := makeHost()
+			host.Namespace = tc.namespace
+			host.Labels = tc.labels
+			endpoint, err := factory.endpoint(host.ObjectMeta)
+			assert.NoError(t, err)
+			assert.Same(t, tc.expected, endpoint)
+
+			prov, err := factory.ironicProvisioner(provisioner.BuildHostData(host, bmc.Credentials{}), nullEventPublisher)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.maxBusyHosts, prov.config.maxBusyHosts)
+		})
+	}
+}
+
+func TestFactoryInitNamedEndpoints(t *testing.T) {
+	authRoot, err := ioutil.TempDir("", "metal3-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(authRoot)
+	for _, file := range []string{"username", "password"} {
+		dir := filepath.Join(authRoot, "dc1", "ironic")
+		assert.NoError(t, os.MkdirAll(dir, 0700))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, file), []byte("dc1-"+file), 0600))
+	}
+
+	env := setUpEnv(map[string]string{
+		"METAL3_AUTH_ROOT_DIR":           authRoot,
+		"DEPLOY_ISO_URL":                 "http://iso",
+		"IRONIC_ENDPOINT":                "",
+		"IRONIC_INSPECTOR_ENDPOINT":      "",
+		"IRONIC_ENDPOINT_NAMES":          "dc1",
+		"IRONIC_ENDPOINT_DC1":            "http://ironic.dc1.test/v1/",
+		"IRONIC_INSPECTOR_ENDPOINT_DC1":  "http://ironic-inspector.dc1.test/v1/",
+		"IRONIC_ENDPOINT_NAMESPACES_DC1": "rack-1",
+	})
+	defer env.TearDown()
+
+	factory := ironicProvisionerFactory{log: logf.Log}
+	assert.NoError(t, factory.init())
+	assert.Nil(t, factory.defaultEndpoint)
+	assert.Len(t, factory.namedEndpoints, 1)
+	assert.Equal(t, "http://ironic.dc1.test/v1/", factory.namedEndpoints[0].clientIronic.Endpoint)
+	assert.Equal(t, "http://ironic-inspector.dc1.test/v1/", factory.namedEndpoints[0].clientInspector.Endpoint)
+
+	ironicAuth, inspectorAuth, err := clients.LoadAuth("dc1")
+	assert.NoError(t, err)
+	assert.Equal(t, clients.AuthConfig{Type: clients.HTTPBasicAuth, Username: "dc1-username", Password: "dc1-password"}, ironicAuth)
+	assert.Equal(t, clients.NoAuth, inspectorAuth.Type)
+
+	// The default endpoint is required when it is partially set
+	env.replace("IRONIC_ENDPOINT", "http://ironic.test/v1/")
+	factory = ironicProvisionerFactory{log: logf.Log}
+	assert.Regexp(t, "No IRONIC_INSPECTOR_ENDPOINT variable set", factory.init())
+}
@@ -0,0 +1,213 @@
+package ironic
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/baremetal/v1/nodes"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner/ironic/clients"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner/ironic/testserver"
+)
+
+func TestGetFirmwareSettings(t *testing.T) {
+	nodeUUID := "158c5d6d-5e2d-4d4c-b3ab-9b2c5a5e5c36"
+	lowerBound := 1
+	upperBound := 20
+	readOnly := true
+	resetRequired := true
+
+	cases := []struct {
+		name          string
+		ironic        *testserver.IronicMock
+		provisionerID string
+		includeSchema bool
+
+		expectedSettings metal3v1alpha1.SettingsMap
+		expectedSchema   map[string]metal3v1alpha1.SettingSchema
+		expectedError    error
+	}{
+		{
+			name: "settings",
+			ironic: testserver.NewIronic(t).BIOSSettings(nodeUUID, []nodes.BIOSSetting{
+				{Name: "L2Cache", Value: "10x256 KB"},
+				{Name: "NumCores", Value: "10"},
+				{Name: "ProcVirtualization", Value: "Enabled"},
+			}),
+			provisionerID: nodeUUID,
+
+			expectedSettings: metal3v1alpha1.SettingsMap{
+				"L2Cache":            "10x256 KB",
+				"NumCores":           "10",
+				"ProcVirtualization": "Enabled",
+			},
+		},
+		{
+			name: "settings-with-schema",
+			ironic: testserver.NewIronic(t).BIOSSettings(nodeUUID, []nodes.BIOSSetting{
+				{Name: "NumCores", Value: "10", AttributeType: "Integer",
+					LowerBound: &lowerBound, UpperBound: &upperBound, ReadOnly: &readOnly},
+				{Name: "ProcVirtualization", Value: "Enabled", AttributeType: "Enumeration",
+					AllowableValues: []string{"Enabled", "Disabled"}, ResetRequired: &resetRequired},
+			}),
+			provisionerID: nodeUUID,
+			includeSchema: true,
+
+			expectedSettings: metal3v1alpha1.SettingsMap{
+				"NumCores":           "10",
+				"ProcVirtualization": "Enabled",
+			},
+			expectedSchema: map[string]metal3v1alpha1.SettingSchema{
+				"NumCores": {AttributeType: "Integer",
+					LowerBound: &lowerBound, UpperBound: &upperBound, ReadOnly: &readOnly},
+				"ProcVirtualization": {AttributeType: "Enumeration",
+					AllowableValues: []string{"Enabled", "Disabled"}, ResetRequired: &resetRequired},
+			},
+		},
+		{
+			name: "settings-without-registry-support",
+			ironic: testserver.NewIronic(t).BIOSSettingsWithoutRegistry(nodeUUID, []nodes.BIOSSetting{
+				{Name: "ProcVirtualization", Value: "Enabled"},
+			}, "1.69"),
+			provisionerID: nodeUUID,
+			includeSchema: true,
+
+			expectedSettings: metal3v1alpha1.SettingsMap{
+				"ProcVirtualization": "Enabled",
+			},
+		},
+		{
+			name:          "no-settings",
+			ironic:        testserver.NewIronic(t).BIOSSettings(nodeUUID, []nodes.BIOSSetting{}),
+			provisionerID: nodeUUID,
+
+			expectedSettings: metal3v1alpha1.SettingsMap{},
+		},
+		{
+			name:          "node-not-found",
+			ironic:        testserver.NewIronic(t).BIOSSettingsError(nodeUUID, http.StatusNotFound),
+			provisionerID: nodeUUID,
+
+			expectedError: provisioner.ErrNeedsRegistration,
+		},
+		{
+			name:   "not-registered",
+			ironic: testserver.NewIronic(t),
+
+			expectedError: provisioner.ErrNeedsRegistration,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.ironic.Start()
+			defer tc.ironic.Stop()
+
+			inspector := testserver.NewInspector(t).Start()
+			defer inspector.Stop()
+
+			host := makeHost()
+			host.Status.Provisioning.ID = tc.provisionerID
+
+			auth := clients.AuthConfig{Type: clients.NoAuth}
+
+			prov, err := newProvisionerWithSettings(host, bmc.Credentials{}, nullEventPublisher,
+				tc.ironic.Endpoint(), auth, inspector.Endpoint(), auth,
+			)
+			if err != nil {
+				t.Fatalf("could not create provisioner: %s", err)
+			}
+
+			settings, schema, err := prov.GetFirmwareSettings(tc.includeSchema)
+
+			assert.Equal(t, tc.expectedSettings, settings)
+			assert.Equal(t, tc.expectedSchema, schema)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestMergeFirmwareSettings(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings []map[string]string
+		target   metal3v1alpha1.DesiredSettingsMap
+		actual   metal3v1alpha1.SettingsMap
+
+		expected []map[string]string
+	}{
+		{
+			name: "no-target",
+			settings: []map[string]string{
+				{"name": "ProcVirtualization", "value": "Enabled"},
+			},
+			actual: metal3v1alpha1.SettingsMap{"ProcVirtualization": "Disabled"},
+
+			expected: []map[string]string{
+				{"name": "ProcVirtualization", "value": "Enabled"},
+			},
+		},
+		{
+			name: "only-changed-settings",
+			target: metal3v1alpha1.DesiredSettingsMap{
+				"NetworkBootRetryCount": intstr.FromInt(20),
+				"SRIOV":                 intstr.FromString("Enabled"),
+				"ProcVirtualization":    intstr.FromString("Enabled"),
+			},
+			actual: metal3v1alpha1.SettingsMap{
+				"NetworkBootRetryCount": "10",
+				"SRIOV":                 "Enabled",
+				"ProcVirtualization":    "Disabled",
+			},
+
+			expected: []map[string]string{
+				{"name": "NetworkBootRetryCount", "value": "20"},
+				{"name": "ProcVirtualization", "value": "Enabled"},
+			},
+		},
+		{
+			name: "unknown-setting",
+			target: metal3v1alpha1.DesiredSettingsMap{
+				"SRIOV":              intstr.FromString("Enabled"),
+				"ProcVirtualization": intstr.FromString("Enabled"),
+			},
+			actual: metal3v1alpha1.SettingsMap{
+				"ProcVirtualization": "Disabled",
+			},
+
+			expected: []map[string]string{
+				{"name": "ProcVirtualization", "value": "Enabled"},
+			},
+		},
+		{
+			name: "target-overrides-firmware-config",
+			settings: []map[string]string{
+				{"name": "ProcVirtualization", "value": "Enabled"},
+				{"name": "LogicalProc", "value": "Enabled"},
+			},
+			target: metal3v1alpha1.DesiredSettingsMap{
+				"ProcVirtualization": intstr.FromString("Disabled"),
+			},
+			actual: metal3v1alpha1.SettingsMap{
+				"ProcVirtualization": "Enabled",
+			},
+
+			expected: []map[string]string{
+				{"name": "ProcVirtualization", "value": "Disabled"},
+				{"name": "LogicalProc", "value": "Enabled"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			settings := mergeFirmwareSettings(tc.settings, tc.target, tc.actual)
+			assert.Equal(t, tc.expected, settings)
+		})
+	}
+}
@@ -18,7 +18,7 @@ func GetHardwareDetails(data *introspection.Data) *metal3v1alpha1.HardwareDetail
 	details.SystemVendor = getSystemVendorDetails(data.Inventory.SystemVendor)
 	details.RAMMebibytes = data.MemoryMB
 	details.NIC = getNICDetails(data.Inventory.Interfaces, data.AllInterfaces, data.Extra.Network)
-	details.Storage = getStorageDetails(data.Inventory.Disks)
+	details.Storage = getStorageDetails(data.Inventory.Disks, data.Extra.Disk)
 	details.CPU = getCPUDetails(&data.Inventory.CPU)
 	details.Hostname = data.Inventory.Hostname
 	return details
@@ -70,12 +70,13 @@ func getNICDetails(ifdata []introspection.InterfaceType,
 				Name: intf.Name,
 				Model: strings.TrimLeft(fmt.Sprintf("%s %s",
 					intf.Vendor, intf.Product), " "),
-				MAC:       intf.MACAddress,
-				IP:        intf.IPV4Address,
-				VLANs:     vlans,
-				VLANID:    vlanid,
-				SpeedGbps: getNICSpeedGbps(extradata[intf.Name]),
-				PXE:       baseIntf.PXE,
+				MAC:             intf.MACAddress,
+				IP:              intf.IPV4Address,
+				VLANs:           vlans,
+				VLANID:          vlanid,
+				SpeedGbps:       getNICSpeedGbps(extradata[intf.Name]),
+				PXE:             baseIntf.PXE,
+				FirmwareVersion: getExtraString(extradata[intf.Name], "firmware"),
 			})
 		}
 		if intf.IPV6Address != "" {
@@ -83,12 +84,13 @@ func getNICDetails(ifdata []introspection.InterfaceType,
 				Name: intf.Name,
 				Model: strings.TrimLeft(fmt.Sprintf("%s %s",
 					intf.Vendor, intf.Product), " "),
-				MAC:       intf.MACAddress,
-				IP:        intf.IPV6Address,
-				VLANs:     vlans,
-				VLANID:    vlanid,
-				SpeedGbps: getNICSpeedGbps(extradata[intf.Name]),
-				PXE:       baseIntf.PXE,
+				MAC:             intf.MACAddress,
+				IP:              intf.IPV6Address,
+				VLANs:           vlans,
+				VLANID:          vlanid,
+				SpeedGbps:       getNICSpeedGbps(extradata[intf.Name]),
+				PXE:             baseIntf.PXE,
+				FirmwareVersion: getExtraString(extradata[intf.Name], "firmware"),
 			})
 		}
 	}
@@ -107,7 +109,17 @@ func getDiskType(diskdata introspection.RootDiskType) metal3v1alpha1.DiskType {
 	return metal3v1alpha1.SSD
 }
 
-func getStorageDetails(diskdata []introspection.RootDiskType) []metal3v1alpha1.Storage {
+// getExtraString returns a string value of the extra hardware data, or
+// an empty string when it is missing or of another type
+func getExtraString(extradata introspection.ExtraHardwareData, key string) string {
+	value, _ := extradata[key].(string)
+	return strings.TrimSpace(value)
+}
+
+// getStorageDetails converts the disks of the inventory, with their
+// firmware revision from the extra hardware data where disks are named
+// without the /dev/ prefix.
+func getStorageDetails(diskdata []introspection.RootDiskType, extradata introspection.ExtraHardwareDataSection) []metal3v1alpha1.Storage {
 	storage := make([]metal3v1alpha1.Storage, len(diskdata))
 	for i, disk := range diskdata {
 		storage[i] = metal3v1alpha1.Storage{
@@ -122,6 +134,7 @@ func getStorageDetails(diskdata []introspection.RootDiskType) []metal3v1alpha1.S
 			WWNVendorExtension: disk.WwnVendorExtension,
 			WWNWithExtension:   disk.WwnWithExtension,
 			HCTL:               disk.Hctl,
+			FirmwareVersion:    getExtraString(extradata[strings.TrimPrefix(disk.Name, "/dev/")], "rev"),
 		}
 	}
 	return storage
@@ -151,6 +164,10 @@ func getCPUDetails(cpudata *introspection.CPUType) metal3v1alpha1.CPU {
 	return cpu
 }
 
+// raidControllerPrefix is the prefix of the entries of the firmware
+// section of the extra hardware data describing RAID controllers
+const raidControllerPrefix = "raid"
+
 func getFirmwareDetails(firmwaredata introspection.ExtraHardwareDataSection) metal3v1alpha1.Firmware {
 
 	// handle bios optionally
@@ -166,7 +183,34 @@ func getFirmwareDetails(firmwaredata introspection.ExtraHardwareDataSection) met
 	}
 
 	return metal3v1alpha1.Firmware{
-		BIOS: bios,
+		BIOS:            bios,
+		BMCVersion:      getExtraString(firmwaredata["bmc"], "version"),
+		RAIDControllers: getRAIDControllerDetails(firmwaredata),
 	}
 
 }
+
+// getRAIDControllerDetails returns the RAID controllers found in the
+// firmware section of the extra hardware data, sorted by name
+func getRAIDControllerDetails(firmwaredata introspection.ExtraHardwareDataSection) []metal3v1alpha1.RAIDController {
+	names := []string{}
+	for name := range firmwaredata {
+		if strings.HasPrefix(name, raidControllerPrefix) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+
+	controllers := make([]metal3v1alpha1.RAIDController, len(names))
+	for i, name := range names {
+		controllers[i] = metal3v1alpha1.RAIDController{
+			Name:            name,
+			Model:           getExtraString(firmwaredata[name], "model"),
+			FirmwareVersion: getExtraString(firmwaredata[name], "version"),
+		}
+	}
+	return controllers
+}
@@ -183,7 +183,8 @@ func TestGetNICDetails(t *testing.T) {
 		},
 		introspection.ExtraHardwareDataSection{
 			"eth1": introspection.ExtraHardwareData{
-				"speed": "1Gbps",
+				"speed":    "1Gbps",
+				"firmware": "14.27.1016 (MT_0000000012)",
 			},
 		})
 
@@ -204,10 +205,11 @@ func TestGetNICDetails(t *testing.T) {
 		t.Errorf("Unexpected NIC data")
 	}
 	if (!reflect.DeepEqual(nics[1], metal3v1alpha1.NIC{
-		Name:      "eth1",
-		MAC:       "66:77:88:99:aa:bb",
-		IP:        "2001:db8::1",
-		SpeedGbps: 1,
+		Name:            "eth1",
+		MAC:             "66:77:88:99:aa:bb",
+		IP:              "2001:db8::1",
+		SpeedGbps:       1,
+		FirmwareVersion: "14.27.1016 (MT_0000000012)",
 	})) {
 		t.Errorf("Unexpected NIC data")
 	}
@@ -296,11 +298,60 @@ func TestGetFirmwareDetails(t *testing.T) {
 		},
 	})
 
+	// Read the BMC and RAID controllers
+	firmware = getFirmwareDetails(introspection.ExtraHardwareDataSection{
+		"bmc": {
+			"version": "4.40.10.00",
+		},
+		"raid1": {
+			"model":   "PERC H330",
+			"version": "25.5.9.0001",
+		},
+		"raid0": {
+			"model":   "PERC H740P",
+			"version": "51.14.0-3900",
+		},
+	})
+
+	if (!reflect.DeepEqual(firmware, metal3v1alpha1.Firmware{
+		BMCVersion: "4.40.10.00",
+		RAIDControllers: []metal3v1alpha1.RAIDController{
+			{Name: "raid0", Model: "PERC H740P", FirmwareVersion: "51.14.0-3900"},
+			{Name: "raid1", Model: "PERC H330", FirmwareVersion: "25.5.9.0001"},
+		},
+	})) {
+		t.Errorf("Unexpected firmware data: %v", firmware)
+	}
+
 	// Finally, ensure we can handle completely empty firmware data
 	firmware = getFirmwareDetails(introspection.ExtraHardwareDataSection{})
 
-	if (firmware != metal3v1alpha1.Firmware{}) {
+	if (!reflect.DeepEqual(firmware, metal3v1alpha1.Firmware{})) {
 		t.Errorf("Expected firmware data to be empty but got: %s", firmware)
 	}
 
 }
+
+func TestGetStorageDetails(t *testing.T) {
+	storage := getStorageDetails(
+		[]introspection.RootDiskType{
+			{Name: "/dev/sda", Model: "MZ7KH480", Size: 480103981056},
+			{Name: "/dev/nvme0n1", Model: "Dell Express Flash", Size: 1600321314816},
+		},
+		introspection.ExtraHardwareDataSection{
+			"sda": {
+				"rev":    "HXM7904Q",
+				"vendor": "ATA",
+			},
+		})
+
+	if len(storage) != 2 {
+		t.Fatalf("Expected 2 disks, got %d", len(storage))
+	}
+	if storage[0].FirmwareVersion != "HXM7904Q" {
+		t.Errorf("Expected the firmware revision of sda, got %q", storage[0].FirmwareVersion)
+	}
+	if storage[1].FirmwareVersion != "" {
+		t.Errorf("Expected no firmware revision for nvme0n1, got %q", storage[1].FirmwareVersion)
+	}
+}
@@ -2,6 +2,8 @@ package ironic
 
 import (
 	"fmt"
+	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	"github.com/metal3-io/baremetal-operator/pkg/bmc"
 	"github.com/metal3-io/baremetal-operator/pkg/provisioner"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner/ironic/clients"
 	"github.com/metal3-io/baremetal-operator/pkg/provisioner/ironic/devicehints"
 	"github.com/metal3-io/baremetal-operator/pkg/provisioner/ironic/hardwaredetails"
 )
@@ -65,6 +68,7 @@ type ironicConfig struct {
 	deployRamdiskURL string
 	deployISOURL     string
 	maxBusyHosts     int
+	bmcCABundleDir   string
 }
 
 // Provisioner implements the provisioning.Provisioner interface
@@ -80,6 +84,8 @@ type ironicProvisioner struct {
 	bmcAddress string
 	// whether to disable SSL certificate verification
 	disableCertVerification bool
+	// the CA certificates to verify the BMC with
+	bmcCABundle []byte
 	// credentials to log in to the BMC
 	bmcCreds bmc.Credentials
 	// the MAC address of the PXE boot interface
@@ -246,6 +252,12 @@ func (p *ironicProvisioner) findExistingHost(bootMACAddress string) (ironicNode
 		}
 	}
 
+	// Hosts booted from virtual media may not have a MAC address,
+	// listing the ports without one would return all of them
+	if bootMACAddress == "" {
+		return nil, nil
+	}
+
 	// Try to load the node by port address
 	p.log.Info("looking for existing node by MAC", "MAC", bootMACAddress)
 	allPorts, err := p.listAllPorts(bootMACAddress)
@@ -284,6 +296,20 @@ func (p *ironicProvisioner) findExistingHost(bootMACAddress string) (ironicNode
 	return nil, nil
 }
 
+// ramdiskNetworkData returns the network configuration passed to the
+// deploy ramdisk of hosts booted from virtual media, so that it can
+// reach Ironic without DHCP on a provisioning network.
+func ramdiskNetworkData(bmcAccess bmc.AccessDetails, networkDataRaw string) (map[string]interface{}, error) {
+	if networkDataRaw == "" || !strings.HasSuffix(bmcAccess.BootInterface(), "virtual-media") {
+		return nil, nil
+	}
+	var networkData map[string]interface{}
+	if err := yaml.Unmarshal([]byte(networkDataRaw), &networkData); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal network_data.json from secret")
+	}
+	return networkData, nil
+}
+
 func (p *ironicProvisioner) createPXEEnabledNodePort(uuid, macAddress string) error {
 	p.log.Info("creating PXE enabled ironic port for node", "NodeUUID", uuid, "MAC", macAddress)
 
@@ -341,7 +367,22 @@ func (p *ironicProvisioner) ValidateManagementAccess(data provisioner.Management
 		return
 	}
 
-	driverInfo := bmcAccess.DriverInfo(p.bmcCreds)
+	bmcCreds := p.bmcCreds
+	if len(p.bmcCABundle) != 0 {
+		bmcCreds.CABundlePath, err = p.writeBMCCABundle()
+		if err != nil {
+			result, err = transientError(err)
+			return
+		}
+	}
+
+	networkData, err := ramdiskNetworkData(bmcAccess, data.NetworkData)
+	if err != nil {
+		result, err = operationFailed(err.Error())
+		return
+	}
+
+	driverInfo := bmcAccess.DriverInfo(bmcCreds)
 	// FIXME(dhellmann): We need to get our IP on the
 	// provisioning network from somewhere.
 	if p.config.deployKernelURL != "" && p.config.deployRamdiskURL != "" {
@@ -379,6 +420,7 @@ func (p *ironicProvisioner) ValidateManagementAccess(data provisioner.Management
 				Properties: map[string]interface{}{
 					"capabilities": bootModeCapabilities[data.BootMode],
 				},
+				NetworkData: networkData,
 			}).Extract()
 		// FIXME(dhellmann): Handle 409 and 503? errors here.
 		if err != nil {
@@ -438,11 +480,15 @@ func (p *ironicProvisioner) ValidateManagementAccess(data provisioner.Management
 		}
 
 		// Look for the case where we previously enrolled this node
-		// and now the credentials have changed.
-		if credentialsChanged {
+		// and now the credentials or the CA bundle have changed.
+		if credentialsChanged || verifyCAChanged(driverInfo, ironicNode.DriverInfo) {
 			updater.SetTopLevelOpt("driver_info", driverInfo, nil)
 		}
 
+		if networkData != nil {
+			updater.SetTopLevelOpt("network_data", networkData, ironicNode.NetworkData)
+		}
+
 		// We don't return here because we also have to set the
 		// target provision state to manageable, which happens
 		// below.
@@ -991,6 +1037,7 @@ func (p *ironicProvisioner) buildManualCleaningSteps(bmcAccess bmc.AccessDetails
 	if err != nil {
 		return nil, err
 	}
+	settings = mergeFirmwareSettings(settings, data.TargetFirmwareSettings, data.ActualFirmwareSettings)
 	if len(settings) != 0 {
 		cleanSteps = append(
 			cleanSteps,
@@ -1004,11 +1051,77 @@ func (p *ironicProvisioner) buildManualCleaningSteps(bmcAccess bmc.AccessDetails
 		)
 	}
 
+	// Build firmware update clean steps
+	if len(data.FirmwareUpdates) != 0 {
+		if !bmcAccess.SupportsFirmwareUpdate() {
+			return nil, fmt.Errorf("firmware updates are defined, but the node's driver %s does not support updating firmware", bmcAccess.Driver())
+		}
+		cleanSteps = append(cleanSteps, buildFirmwareUpdateCleanStep(data.FirmwareUpdates))
+	}
+
 	// TODO: Add manual cleaning steps for host configuration
 
 	return
 }
 
+// buildFirmwareUpdateCleanStep returns the clean step applying the
+// firmware images in the order they are listed.
+func buildFirmwareUpdateCleanStep(updates []metal3v1alpha1.FirmwareUpdate) nodes.CleanStep {
+	images := make([]map[string]interface{}, 0, len(updates))
+	for _, update := range updates {
+		image := map[string]interface{}{
+			"url": update.URL,
+		}
+		if update.Checksum != "" {
+			image["checksum"] = update.Checksum
+		}
+		images = append(images, image)
+	}
+	return nodes.CleanStep{
+		Interface: "management",
+		Step:      "update_firmware",
+		Args: map[string]interface{}{
+			"firmware_images": images,
+		},
+	}
+}
+
+// mergeFirmwareSettings adds the target settings that differ from the
+// actual settings of the host to the list of BIOS settings to apply. A
+// setting given explicitly in the target overrides the value derived
+// from the firmware config.
+func mergeFirmwareSettings(settings []map[string]string, target metal3v1alpha1.DesiredSettingsMap, actual metal3v1alpha1.SettingsMap) []map[string]string {
+	names := make([]string, 0, len(target))
+	for name, value := range target {
+		// Settings the firmware does not report cannot be applied
+		if current, ok := actual[name]; !ok || current == value.String() {
+			continue
+		}
+		names = append(names, name)
+	}
+	// Sort the names so the clean steps are stable between reconciles
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := target[name]
+		found := false
+		for _, setting := range settings {
+			if setting["name"] == name {
+				setting["value"] = value.String()
+				found = true
+				break
+			}
+		}
+		if !found {
+			settings = append(settings, map[string]string{
+				"name":  name,
+				"value": value.String(),
+			})
+		}
+	}
+	return settings
+}
+
 func (p *ironicProvisioner) startManualCleaning(bmcAccess bmc.AccessDetails, ironicNode *nodes.Node, data provisioner.PrepareData) (success bool, result provisioner.Result, err error) {
 	if bmcAccess.RAIDInterface() != "no-raid" {
 		// Set raid configuration
@@ -1669,6 +1782,72 @@ func (p *ironicProvisioner) HasCapacity() (result bool, err error) {
 	return len(hosts) < p.config.maxBusyHosts, nil
 }
 
+// GetFirmwareSettings gets the BIOS settings cached by Ironic for the
+// node, along with the details from the BIOS attribute registry when
+// includeSchema is true.
+func (p *ironicProvisioner) GetFirmwareSettings(includeSchema bool) (settings metal3v1alpha1.SettingsMap, schema map[string]metal3v1alpha1.SettingSchema, err error) {
+	if p.nodeID == "" {
+		return nil, nil, provisioner.ErrNeedsRegistration
+	}
+
+	client := p.client
+	if includeSchema {
+		// Only this call needs the microversion of the registry
+		registryClient := *p.client
+		registryClient.Microversion = clients.BIOSRegistryMicroversion
+		client = &registryClient
+	}
+	opts := nodes.ListBIOSSettingsOpts{Detail: includeSchema}
+	biosSettings, err := nodes.ListBIOSSettings(client, p.nodeID, opts).Extract()
+	if includeSchema && isNotAcceptable(err) {
+		p.log.Info("Ironic does not support the BIOS registry, reading the BIOS settings without their schema",
+			"microversion", clients.BIOSRegistryMicroversion)
+		includeSchema = false
+		biosSettings, err = nodes.ListBIOSSettings(p.client, p.nodeID, nodes.ListBIOSSettingsOpts{}).Extract()
+	}
+	switch err.(type) {
+	case nil:
+	case gophercloud.ErrDefault404:
+		return nil, nil, provisioner.ErrNeedsRegistration
+	default:
+		return nil, nil, errors.Wrap(err,
+			fmt.Sprintf("could not get BIOS settings for node %s", p.nodeID))
+	}
+
+	settings = make(metal3v1alpha1.SettingsMap, len(biosSettings))
+	if includeSchema {
+		schema = make(map[string]metal3v1alpha1.SettingSchema, len(biosSettings))
+	}
+	for _, setting := range biosSettings {
+		settings[setting.Name] = setting.Value
+		if includeSchema {
+			schema[setting.Name] = metal3v1alpha1.SettingSchema{
+				AttributeType:   setting.AttributeType,
+				AllowableValues: setting.AllowableValues,
+				LowerBound:      setting.LowerBound,
+				UpperBound:      setting.UpperBound,
+				MinLength:       setting.MinLength,
+				MaxLength:       setting.MaxLength,
+				ReadOnly:        setting.ReadOnly,
+				ResetRequired:   setting.ResetRequired,
+				Unique:          setting.Unique,
+			}
+		}
+	}
+	p.debugLog.Info("retrieved BIOS settings", "count", len(settings))
+	return settings, schema, nil
+}
+
+// isNotAcceptable returns true when Ironic rejected a request because
+// it does not support the requested microversion.
+func isNotAcceptable(err error) bool {
+	var codeErr gophercloud.ErrUnexpectedResponseCode
+	if errors.As(err, &codeErr) {
+		return codeErr.Actual == http.StatusNotAcceptable
+	}
+	return false
+}
+
 func (p *ironicProvisioner) loadBusyHosts() (hosts map[string]struct{}, err error) {
 
 	hosts = make(map[string]struct{})
@@ -32,6 +32,7 @@ func newTestProvisionerFactory() ironicProvisionerFactory {
 			deployISOURL:     "http://deploy.test/ipa.iso",
 			maxBusyHosts:     20,
 		},
+		defaultEndpoint: &ironicEndpoint{},
 	}
 }
 
@@ -52,8 +53,10 @@ func newProvisionerWithSettings(host metal3v1alpha1.BareMetalHost, bmcCreds bmc.
 	}
 
 	factory := newTestProvisionerFactory()
-	factory.clientIronic = clientIronic
-	factory.clientInspector = clientInspector
+	factory.defaultEndpoint = &ironicEndpoint{
+		clientIronic:    clientIronic,
+		clientInspector: clientInspector,
+	}
 	return factory.ironicProvisioner(hostData, publisher)
 }
 
@@ -23,12 +23,14 @@ func (r *RAIDTestBMC) NeedsMAC() bool                                        { r
 func (r *RAIDTestBMC) Driver() string                                        { return "raid-test" }
 func (r *RAIDTestBMC) DisableCertificateVerification() bool                  { return false }
 func (r *RAIDTestBMC) DriverInfo(bmc.Credentials) (i map[string]interface{}) { return }
+func (r *RAIDTestBMC) ValidateCredentials(bmc.Credentials) error             { return nil }
 func (r *RAIDTestBMC) BootInterface() string                                 { return "" }
 func (r *RAIDTestBMC) ManagementInterface() string                           { return "" }
 func (r *RAIDTestBMC) PowerInterface() string                                { return "" }
 func (r *RAIDTestBMC) RAIDInterface() string                                 { return "" }
 func (r *RAIDTestBMC) VendorInterface() string                               { return "" }
 func (r *RAIDTestBMC) SupportsSecureBoot() bool                              { return false }
+func (r *RAIDTestBMC) SupportsFirmwareUpdate() bool                          { return false }
 func (r *RAIDTestBMC) BuildBIOSSettings(fwConf *metal3v1alpha1.FirmwareConfig) ([]map[string]string, error) {
 	return nil, nil
 }
@@ -44,9 +46,11 @@ func TestPrepare(t *testing.T) {
 		ironic               *testserver.IronicMock
 		unprepared           bool
 		existRaidConfig      bool
+		firmwareConfig       *metal3v1alpha1.FirmwareConfig
 		expectedStarted      bool
 		expectedDirty        bool
 		expectedError        bool
+		expectedErrorMessage string
 		expectedRequestAfter int
 	}{
 		{
@@ -72,6 +76,19 @@ func TestPrepare(t *testing.T) {
 			expectedRequestAfter: 10,
 			expectedDirty:        true,
 		},
+		{
+			name: "manageable state(unsupported firmware settings)",
+			ironic: testserver.NewIronic(t).WithDefaultResponses().Node(nodes.Node{
+				ProvisionState: string(nodes.Manageable),
+				UUID:           nodeUUID,
+			}),
+			unprepared: true,
+			firmwareConfig: &metal3v1alpha1.FirmwareConfig{
+				CStateLimit: metal3v1alpha1.CStateLimitC1E,
+			},
+			expectedStarted:      false,
+			expectedErrorMessage: "firmware setting cStateLimit is not supported by irmc",
+		},
 		{
 			name: "available state(haven't clean steps)",
 			ironic: testserver.NewIronic(t).WithDefaultResponses().Node(nodes.Node{
@@ -195,6 +212,11 @@ func TestPrepare(t *testing.T) {
 				}
 			}
 
+			if tc.firmwareConfig != nil {
+				host.Spec.BMC.Address = "irmc://test.bmc/"
+				prepData.FirmwareConfig = tc.firmwareConfig
+			}
+
 			publisher := func(reason, message string) {}
 			auth := clients.AuthConfig{Type: clients.NoAuth}
 			prov, err := newProvisionerWithSettings(host, bmc.Credentials{}, publisher,
@@ -209,6 +231,7 @@ func TestPrepare(t *testing.T) {
 			assert.Equal(t, tc.expectedStarted, started)
 			assert.Equal(t, tc.expectedDirty, result.Dirty)
 			assert.Equal(t, time.Second*time.Duration(tc.expectedRequestAfter), result.RequeueAfter)
+			assert.Equal(t, tc.expectedErrorMessage, result.ErrorMessage)
 			if !tc.expectedError {
 				assert.NoError(t, err)
 			} else {
@@ -217,3 +240,52 @@ func TestPrepare(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildManualCleaningStepsFirmwareUpdates(t *testing.T) {
+	updates := []metal3v1alpha1.FirmwareUpdate{
+		{
+			Component: metal3v1alpha1.FirmwareComponentBIOS,
+			URL:       "http://images.example.com/bios-2.11.0.exe",
+			Checksum:  "d1b1d2ab6e4a5c4f0f1a8e7f0c7c5a6b1e2f3d4c",
+			Version:   "2.11.0",
+		},
+		{
+			Component: metal3v1alpha1.FirmwareComponentBMC,
+			URL:       "http://images.example.com/bmc-4.40.10.00.exe",
+			Version:   "4.40.10.00",
+		},
+	}
+	data := provisioner.PrepareData{FirmwareUpdates: updates}
+	p := &ironicProvisioner{}
+
+	redfish, err := bmc.NewAccessDetails("redfish://192.168.122.1/redfish/v1/Systems/1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps, err := p.buildManualCleaningSteps(redfish, data)
+	assert.NoError(t, err)
+	assert.Equal(t, []nodes.CleanStep{
+		{
+			Interface: "management",
+			Step:      "update_firmware",
+			Args: map[string]interface{}{
+				"firmware_images": []map[string]interface{}{
+					{
+						"url":      "http://images.example.com/bios-2.11.0.exe",
+						"checksum": "d1b1d2ab6e4a5c4f0f1a8e7f0c7c5a6b1e2f3d4c",
+					},
+					{
+						"url": "http://images.example.com/bmc-4.40.10.00.exe",
+					},
+				},
+			},
+		},
+	}, steps)
+
+	ipmi, err := bmc.NewAccessDetails("ipmi://192.168.122.1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.buildManualCleaningSteps(ipmi, data)
+	assert.Error(t, err)
+}
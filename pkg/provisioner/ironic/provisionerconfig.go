@@ -0,0 +1,250 @@
+package ironic
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	logz "sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner/ironic/clients"
+)
+
+// ErrNotConfigured is returned when creating a provisioner before a
+// valid configuration has been loaded
+var ErrNotConfigured = errors.New("the Ironic provisioner is not configured")
+
+// ConfigurableProvisionerFactory is a provisioner factory configured
+// by a ProvisionerConfig resource instead of the environment. The
+// configuration can be replaced while the operator runs, and only the
+// provisioners created afterwards use the new one.
+type ConfigurableProvisionerFactory struct {
+	log logr.Logger
+
+	lock        sync.RWMutex
+	factory     *ironicProvisionerFactory
+	fingerprint string
+}
+
+// NewConfigurableProvisionerFactory returns a provisioner factory
+// that fails to create provisioners until a configuration is loaded.
+func NewConfigurableProvisionerFactory() *ConfigurableProvisionerFactory {
+	return &ConfigurableProvisionerFactory{
+		log: logz.New().WithName("provisioner").WithName("ironic"),
+	}
+}
+
+// NewProvisioner returns a new Ironic Provisioner using the last
+// configuration loaded.
+func (f *ConfigurableProvisionerFactory) NewProvisioner(hostData provisioner.HostData, publisher provisioner.EventPublisher) (provisioner.Provisioner, error) {
+	f.lock.RLock()
+	factory := f.factory
+	f.lock.RUnlock()
+
+	if factory == nil {
+		return nil, ErrNotConfigured
+	}
+	return factory.ironicProvisioner(hostData, publisher)
+}
+
+// Load validates the configuration and uses it for the provisioners
+// created afterwards, and returns true when it changed. The previous
+// configuration is kept when the new one is not valid. The clients of
+// the endpoints are only created again when the configuration or the
+// credentials and certificate files it uses changed, so Load can be
+// called periodically to pick up rotated certificates.
+func (f *ConfigurableProvisionerFactory) Load(spec *metal3v1alpha1.ProvisionerConfigSpec) (changed bool, err error) {
+	config, err := configFromSpec(spec)
+	if err != nil {
+		return false, err
+	}
+
+	endpoints, settings, err := endpointsFromSpec(spec)
+	if err != nil {
+		return false, err
+	}
+
+	fingerprint, err := configFingerprint(spec, settings)
+	if err != nil {
+		return false, err
+	}
+
+	f.lock.RLock()
+	unchanged := f.factory != nil && f.fingerprint == fingerprint
+	f.lock.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	factory := &ironicProvisionerFactory{
+		log:    f.log,
+		config: config,
+	}
+	for i, endpoint := range endpoints {
+		if err := factory.connectEndpoint(endpoint, settings[i]); err != nil {
+			return false, fmt.Errorf("could not connect to the Ironic endpoint %q: %w", endpoint.name, err)
+		}
+		if endpoint.name == "" {
+			factory.defaultEndpoint = endpoint
+		} else {
+			factory.namedEndpoints = append(factory.namedEndpoints, endpoint)
+		}
+	}
+
+	f.lock.Lock()
+	f.factory = factory
+	f.fingerprint = fingerprint
+	f.lock.Unlock()
+	return true, nil
+}
+
+func configFromSpec(spec *metal3v1alpha1.ProvisionerConfigSpec) (ironicConfig, error) {
+	c := ironicConfig{
+		deployKernelURL:  spec.DeployKernelURL,
+		deployRamdiskURL: spec.DeployRamdiskURL,
+		deployISOURL:     spec.DeployISOURL,
+		maxBusyHosts:     defaultMaxBusyHosts,
+	}
+	if c.deployISOURL == "" &&
+		(c.deployKernelURL == "" || c.deployRamdiskURL == "") {
+		return c, errors.New("either deployKernelURL and deployRamdiskURL or deployISOURL must be set")
+	}
+	if (c.deployKernelURL == "" && c.deployRamdiskURL != "") ||
+		(c.deployKernelURL != "" && c.deployRamdiskURL == "") {
+		return c, errors.New("deployKernelURL and deployRamdiskURL can only be set together")
+	}
+
+	if spec.ProvisioningLimit != nil {
+		if *spec.ProvisioningLimit < 1 {
+			return c, fmt.Errorf("invalid provisioningLimit %d", *spec.ProvisioningLimit)
+		}
+		c.maxBusyHosts = *spec.ProvisioningLimit
+	}
+
+	// The shared directory is mounted in the pod of the operator
+	loadBMCCABundleDirFromEnv(&c)
+	return c, nil
+}
+
+// endpointsFromSpec returns the endpoints of the configuration, along
+// with the settings used to create their clients
+func endpointsFromSpec(spec *metal3v1alpha1.ProvisionerConfigSpec) (endpoints []*ironicEndpoint, settings []endpointSettings, err error) {
+	if len(spec.Endpoints) == 0 {
+		return nil, nil, errors.New("no Ironic endpoint is configured")
+	}
+
+	seen := map[string]bool{}
+	for i := range spec.Endpoints {
+		endpointConfig := &spec.Endpoints[i]
+		name := endpointConfig.Name
+		if seen[name] {
+			if name == "" {
+				return nil, nil, errors.New("several Ironic endpoints have no name")
+			}
+			return nil, nil, fmt.Errorf("duplicate Ironic endpoint name %q", name)
+		}
+		seen[name] = true
+
+		endpoint, err := endpointFromSpec(endpointConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		endpointSetting := endpointSettings{
+			ironicURL:    endpointConfig.IronicURL,
+			inspectorURL: endpointConfig.InspectorURL,
+			tls: clients.TLSConfig{
+				TrustedCAFile:         certFile(name, endpointConfig.TLS.CACertFile, "ca/tls.crt"),
+				ClientCertificateFile: certFile(name, endpointConfig.TLS.ClientCertFile, "client/tls.crt"),
+				ClientPrivateKeyFile:  certFile(name, endpointConfig.TLS.ClientPrivateKeyFile, "client/tls.key"),
+				InsecureSkipVerify:    endpointConfig.TLS.Insecure,
+				SkipClientSANVerify:   endpointConfig.TLS.SkipClientSANVerify,
+			},
+		}
+		endpointSetting.ironicAuth, endpointSetting.inspectorAuth, err = clients.LoadAuth(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not load the credentials of the Ironic endpoint %q: %w", name, err)
+		}
+
+		endpoints = append(endpoints, endpoint)
+		settings = append(settings, endpointSetting)
+	}
+	return endpoints, settings, nil
+}
+
+func endpointFromSpec(endpointConfig *metal3v1alpha1.IronicEndpointConfig) (*ironicEndpoint, error) {
+	name := endpointConfig.Name
+	if name != "" {
+		if errs := validation.IsDNS1123Label(name); len(errs) != 0 {
+			return nil, fmt.Errorf("invalid Ironic endpoint name %q: %v", name, errs)
+		}
+	}
+	if endpointConfig.IronicURL == "" || endpointConfig.InspectorURL == "" {
+		return nil, fmt.Errorf("the Ironic endpoint %q must have an ironicURL and an inspectorURL", name)
+	}
+
+	endpoint := &ironicEndpoint{
+		name:       name,
+		namespaces: endpointConfig.Namespaces,
+	}
+	if endpointConfig.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(endpointConfig.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector of the Ironic endpoint %q: %w", name, err)
+		}
+		endpoint.selector = selector
+	}
+	switch {
+	case name == "" && (len(endpoint.namespaces) != 0 || endpoint.selector != nil):
+		return nil, errors.New("the Ironic endpoint without a name cannot have namespaces or a selector")
+	case name != "" && len(endpoint.namespaces) == 0 && endpoint.selector == nil:
+		return nil, fmt.Errorf("the Ironic endpoint %q must have namespaces or a selector", name)
+	}
+
+	if endpointConfig.ProvisioningLimit != nil {
+		if *endpointConfig.ProvisioningLimit < 1 {
+			return nil, fmt.Errorf("invalid provisioningLimit %d of the Ironic endpoint %q",
+				*endpointConfig.ProvisioningLimit, name)
+		}
+		endpoint.maxBusyHosts = *endpointConfig.ProvisioningLimit
+	}
+	return endpoint, nil
+}
+
+// configFingerprint returns a digest of the configuration, including
+// the credentials and the content of the certificate files it uses.
+func configFingerprint(spec *metal3v1alpha1.ProvisionerConfigSpec, settings []endpointSettings) (string, error) {
+	hash := sha256.New()
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	hash.Write(data)
+
+	for _, endpointSetting := range settings {
+		fmt.Fprintf(hash, "%v%v", endpointSetting.ironicAuth, endpointSetting.inspectorAuth)
+		for _, file := range []string{
+			endpointSetting.tls.TrustedCAFile,
+			endpointSetting.tls.ClientCertificateFile,
+			endpointSetting.tls.ClientPrivateKeyFile,
+		} {
+			content, err := ioutil.ReadFile(filepath.Clean(file))
+			if err != nil && !os.IsNotExist(err) {
+				return "", err
+			}
+			hash.Write(content)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,197 @@
+package ironic
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner"
+)
+
+func newProvisionerConfigSpec() *metal3v1alpha1.ProvisionerConfigSpec {
+	return &metal3v1alpha1.ProvisionerConfigSpec{
+		DeployISOURL: "http://deploy.test/ipa.iso",
+		Endpoints: []metal3v1alpha1.IronicEndpointConfig{
+			{
+				IronicURL:    "http://ironic.test/v1/",
+				InspectorURL: "http://ironic-inspector.test/v1/",
+			},
+			{
+				Name:         "dc1",
+				IronicURL:    "http://ironic.dc1.test/v1/",
+				InspectorURL: "http://ironic-inspector.dc1.test/v1/",
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"datacenter": "dc1"},
+				},
+			},
+		},
+	}
+}
+
+func TestConfigurableProvisionerFactory(t *testing.T) {
+	certsDir, err := ioutil.TempDir("", "metal3-certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(certsDir)
+	caFile := filepath.Join(certsDir, "ca.crt")
+
+	env := setUpEnv(map[string]string{"METAL3_AUTH_ROOT_DIR": certsDir})
+	defer env.TearDown()
+
+	factory := NewConfigurableProvisionerFactory()
+	host := makeHost()
+	hostData := provisioner.BuildHostData(host, bmc.Credentials{})
+	_, err = factory.NewProvisioner(hostData, nullEventPublisher)
+	assert.Equal(t, ErrNotConfigured, err)
+
+	spec := newProvisionerConfigSpec()
+	spec.Endpoints[0].TLS.CACertFile = caFile
+	changed, err := factory.Load(spec)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	prov, err := factory.NewProvisioner(hostData, nullEventPublisher)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://ironic.test/v1/", prov.(*ironicProvisioner).client.Endpoint)
+	assert.Equal(t, 20, prov.(*ironicProvisioner).config.maxBusyHosts)
+
+	// Nothing changes when the same configuration is loaded again
+	changed, err = factory.Load(spec)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	// The clients are created again when the CA certificate is rotated
+	assert.NoError(t, ioutil.WriteFile(caFile, []byte("new certificate"), 0600))
+	changed, err = factory.Load(spec)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.NoError(t, os.Remove(caFile))
+
+	// The new settings are used by the provisioners created afterwards
+	limit := 5
+	spec.ProvisioningLimit = &limit
+	spec.DeployISOURL = "http://deploy.test/new-ipa.iso"
+	spec.Endpoints[1].IronicURL = "http://ironic2.dc1.test/v1/"
+	changed, err = factory.Load(spec)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	host.Labels = map[string]string{"datacenter": "dc1"}
+	prov, err = factory.NewProvisioner(provisioner.BuildHostData(host, bmc.Credentials{}), nullEventPublisher)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://ironic2.dc1.test/v1/", prov.(*ironicProvisioner).client.Endpoint)
+	assert.Equal(t, 5, prov.(*ironicProvisioner).config.maxBusyHosts)
+	assert.Equal(t, "http://deploy.test/new-ipa.iso", prov.(*ironicProvisioner).config.deployISOURL)
+
+	// The previous configuration is kept when the new one is invalid
+	spec.Endpoints[1].Selector = nil
+	changed, err = factory.Load(spec)
+	assert.Regexp(t, "the Ironic endpoint \"dc1\" must have namespaces or a selector", err)
+	assert.False(t, changed)
+	prov, err = factory.NewProvisioner(provisioner.BuildHostData(host, bmc.Credentials{}), nullEventPublisher)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://ironic2.dc1.test/v1/", prov.(*ironicProvisioner).client.Endpoint)
+}
+
+func TestProvisionerConfigValidation(t *testing.T) {
+	zero := 0
+	cases := []struct {
+		name          string
+		update        func(*metal3v1alpha1.ProvisionerConfigSpec)
+		expectedError string
+	}{
+		{
+			name:   "valid",
+			update: func(spec *metal3v1alpha1.ProvisionerConfigSpec) {},
+		},
+		{
+			name: "only named endpoints",
+			update: func(spec *metal3v1alpha1.ProvisionerConfigSpec) {
+				spec.Endpoints = spec.Endpoints[1:]
+			},
+		},
+		{
+			name: "no deploy image",
+			update: func(spec *metal3v1alpha1.ProvisionerConfigSpec) {
+				spec.DeployISOURL = ""
+			},
+			expectedError: "either deployKernelURL and deployRamdiskURL or deployISOURL must be set",
+		},
+		{
+			name: "kernel without ramdisk",
+			update: func(spec *metal3v1alpha1.ProvisionerConfigSpec) {
+				spec.DeployKernelURL = "http://deploy.test/ipa.kernel"
+			},
+			expectedError: "deployKernelURL and deployRamdiskURL can only be set together",
+		},
+		{
+			name: "invalid limit",
+			update: func(spec *metal3v1alpha1.ProvisionerConfigSpec) {
+				spec.Endpoints[1].ProvisioningLimit = &zero
+			},
+			expectedError: "invalid provisioningLimit 0 of the Ironic endpoint \"dc1\"",
+		},
+		{
+			name: "no endpoint",
+			update: func(spec *metal3v1alpha1.ProvisionerConfigSpec) {
+				spec.Endpoints = nil
+			},
+			expectedError: "no Ironic endpoint is configured",
+		},
+		{
+			name: "duplicate name",
+			update: func(spec *metal3v1alpha1.ProvisionerConfigSpec) {
+				spec.Endpoints = append(spec.Endpoints, spec.Endpoints[1])
+			},
+			expectedError: "duplicate Ironic endpoint name \"dc1\"",
+		},
+		{
+			name: "several default endpoints",
+			update: func(spec *metal3v1alpha1.ProvisionerConfigSpec) {
+				spec.Endpoints = append(spec.Endpoints, spec.Endpoints[0])
+			},
+			expectedError: "several Ironic endpoints have no name",
+		},
+		{
+			name: "default endpoint with namespaces",
+			update: func(spec *metal3v1alpha1.ProvisionerConfigSpec) {
+				spec.Endpoints[0].Namespaces = []string{"rack-1"}
+			},
+			expectedError: "the Ironic endpoint without a name cannot have namespaces or a selector",
+		},
+		{
+			name: "invalid selector",
+			update: func(spec *metal3v1alpha1.ProvisionerConfigSpec) {
+				spec.Endpoints[1].Selector.MatchLabels["datacenter"] = "dc 1"
+			},
+			expectedError: "invalid selector of the Ironic endpoint \"dc1\"",
+		},
+		{
+			name: "missing inspector",
+			update: func(spec *metal3v1alpha1.ProvisionerConfigSpec) {
+				spec.Endpoints[1].InspectorURL = ""
+			},
+			expectedError: "the Ironic endpoint \"dc1\" must have an ironicURL and an inspectorURL",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := newProvisionerConfigSpec()
+			tc.update(spec)
+			_, err := NewConfigurableProvisionerFactory().Load(spec)
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
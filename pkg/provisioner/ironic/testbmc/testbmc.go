@@ -59,10 +59,16 @@ func (a *testAccessDetails) DriverInfo(bmcCreds bmc.Credentials) map[string]inte
 
 	if a.disableCertificateVerification {
 		result["test_verify_ca"] = false
+	} else if bmcCreds.CABundlePath != "" {
+		result["test_verify_ca"] = bmcCreds.CABundlePath
 	}
 	return result
 }
 
+func (a *testAccessDetails) ValidateCredentials(bmcCreds bmc.Credentials) error {
+	return bmcCreds.Validate()
+}
+
 func (a *testAccessDetails) BootInterface() string {
 	return "ipxe"
 }
@@ -87,6 +93,10 @@ func (a *testAccessDetails) SupportsSecureBoot() bool {
 	return false
 }
 
+func (a *testAccessDetails) SupportsFirmwareUpdate() bool {
+	return true
+}
+
 func (a *testAccessDetails) BuildBIOSSettings(firmwareConfig *metal3v1alpha1.FirmwareConfig) (settings []map[string]string, err error) {
 	return nil, nil
 }
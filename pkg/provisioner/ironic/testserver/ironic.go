@@ -235,6 +235,53 @@ func (m *IronicMock) WithNodeValidate(nodeUUID string) *IronicMock {
 	return m
 }
 
+// BIOSSettings configures the server with a valid response for
+// [GET] /v1/nodes/<node uuid>/bios
+func (m *IronicMock) BIOSSettings(nodeUUID string, settings []nodes.BIOSSetting) *IronicMock {
+	resp := map[string][]nodes.BIOSSetting{
+		"bios": settings,
+	}
+	m.ResponseJSON(m.buildURL("/v1/nodes/"+nodeUUID+"/bios", http.MethodGet), resp)
+	return m
+}
+
+// BIOSSettingsWithoutRegistry configures the server with a valid
+// response for [GET] /v1/nodes/<node uuid>/bios, rejecting the
+// requests for a microversion above maxMicroversion as Ironic does.
+func (m *IronicMock) BIOSSettingsWithoutRegistry(nodeUUID string, settings []nodes.BIOSSetting, maxMicroversion string) *IronicMock {
+	content, err := json.Marshal(map[string][]nodes.BIOSSetting{
+		"bios": settings,
+	})
+	if err != nil {
+		m.t.Error(err)
+	}
+	m.Handler("/v1/nodes/"+nodeUUID+"/bios", func(w http.ResponseWriter, r *http.Request) {
+		if version := r.Header.Get("X-OpenStack-Ironic-API-Version"); microversionAbove(version, maxMicroversion) {
+			m.logRequest(r, fmt.Sprintf("%d", http.StatusNotAcceptable))
+			http.Error(w, fmt.Sprintf("Version %s was requested but the maximum version is %s", version, maxMicroversion),
+				http.StatusNotAcceptable)
+			return
+		}
+		m.sendData(w, r, http.StatusOK, string(content))
+	})
+	return m
+}
+
+// microversionAbove returns true when the version is above the maximum
+func microversionAbove(version, maximum string) bool {
+	var major, minor, maxMajor, maxMinor int
+	fmt.Sscanf(version, "%d.%d", &major, &minor)
+	fmt.Sscanf(maximum, "%d.%d", &maxMajor, &maxMinor)
+	return major > maxMajor || (major == maxMajor && minor > maxMinor)
+}
+
+// BIOSSettingsError configures the server to return the specified
+// error code for /v1/nodes/<node uuid>/bios
+func (m *IronicMock) BIOSSettingsError(nodeUUID string, errorCode int) *IronicMock {
+	m.ErrorResponse(fmt.Sprintf("/v1/nodes/%s/bios", nodeUUID), errorCode)
+	return m
+}
+
 // Port configures the server with a valid response for
 //    [GET] /v1/nodes/<node uuid>/ports
 //    [GET] /v1/ports
@@ -1,7 +1,9 @@
 package ironic
 
 import (
+	"io/ioutil"
 	"net/http"
+	"path/filepath"
 	"testing"
 
 	"github.com/gophercloud/gophercloud/openstack/baremetal/v1/nodes"
@@ -758,3 +760,229 @@ func TestValidateManagementAccessMalformedBMCAddress(t *testing.T) {
 	}
 	assert.Equal(t, "failed to parse BMC address information: failed to parse BMC address information: parse \"<ipmi://192.168.122.1:6233>\": first path segment in URL cannot contain colon", result.ErrorMessage)
 }
+
+func TestValidateManagementAccessCABundle(t *testing.T) {
+	host := makeHost()
+	host.Spec.BootMACAddress = ""
+	host.Status.Provisioning.ID = "" // so we don't lookup by uuid
+
+	var createdNode *nodes.Node
+
+	createCallback := func(node nodes.Node) {
+		createdNode = &node
+	}
+
+	ironic := testserver.NewIronic(t).Ready().CreateNodes(createCallback).NoNode(host.Namespace + nameSeparator + host.Name).NoNode(host.Name)
+	ironic.AddDefaultResponse("/v1/nodes/node-0", "PATCH", http.StatusOK, "{}")
+	ironic.Start()
+	defer ironic.Stop()
+
+	auth := clients.AuthConfig{Type: clients.NoAuth}
+	prov, err := newProvisionerWithSettings(host, bmc.Credentials{}, nullEventPublisher,
+		ironic.Endpoint(), auth, testserver.NewInspector(t).Endpoint(), auth,
+	)
+	if err != nil {
+		t.Fatalf("could not create provisioner: %s", err)
+	}
+	prov.config.bmcCABundleDir = t.TempDir()
+	prov.bmcCABundle = []byte("-----BEGIN CERTIFICATE-----\n")
+
+	result, _, err := prov.ValidateManagementAccess(provisioner.ManagementAccessData{}, false, false)
+	if err != nil {
+		t.Fatalf("error from ValidateManagementAccess: %s", err)
+	}
+	assert.Equal(t, "", result.ErrorMessage)
+
+	path, ok := createdNode.DriverInfo["test_verify_ca"].(string)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, prov.config.bmcCABundleDir, filepath.Dir(path))
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, prov.bmcCABundle, content)
+}
+
+func TestValidateManagementAccessCABundleChanged(t *testing.T) {
+	host := makeHost()
+	host.Spec.BootMACAddress = ""
+	host.Status.Provisioning.ID = "" // so we don't lookup by uuid
+
+	ironic := testserver.NewIronic(t).
+		Node(
+			nodes.Node{
+				Name: host.Namespace + nameSeparator + host.Name,
+				UUID: "uuid",
+				DriverInfo: map[string]interface{}{
+					"test_address":   "test.bmc",
+					"test_verify_ca": "/shared/bmc-ca/old.pem",
+				},
+			}).
+		NodeUpdate(
+			nodes.Node{
+				Name: host.Namespace + nameSeparator + host.Name,
+				UUID: "uuid",
+			})
+	ironic.Start()
+	defer ironic.Stop()
+
+	auth := clients.AuthConfig{Type: clients.NoAuth}
+	prov, err := newProvisionerWithSettings(host, bmc.Credentials{}, nullEventPublisher,
+		ironic.Endpoint(), auth, testserver.NewInspector(t).Endpoint(), auth,
+	)
+	if err != nil {
+		t.Fatalf("could not create provisioner: %s", err)
+	}
+	prov.config.bmcCABundleDir = t.TempDir()
+	prov.bmcCABundle = []byte("-----BEGIN CERTIFICATE-----\n")
+
+	// The credentials did not change, but the CA bundle did
+	result, _, err := prov.ValidateManagementAccess(provisioner.ManagementAccessData{}, false, false)
+	if err != nil {
+		t.Fatalf("error from ValidateManagementAccess: %s", err)
+	}
+	assert.Equal(t, "", result.ErrorMessage)
+
+	updates := ironic.GetLastNodeUpdateRequestFor("uuid")
+	assert.Equal(t, "/driver_info", updates[0].Path)
+	newValues := updates[0].Value.(map[string]interface{})
+	assert.NotEqual(t, "/shared/bmc-ca/old.pem", newValues["test_verify_ca"])
+}
+
+const testNetworkData = `
+links:
+- id: eth0
+  type: phy
+  ethernet_mac_address: 52:54:00:aa:bb:01
+networks:
+- id: network0
+  type: ipv4
+  link: eth0
+  ip_address: 192.0.2.10
+  netmask: 255.255.255.0
+  routes:
+  - network: 0.0.0.0
+    netmask: 0.0.0.0
+    gateway: 192.0.2.1
+`
+
+func TestValidateManagementAccessVirtualMediaNetworkData(t *testing.T) {
+	for _, tc := range []struct {
+		Scenario        string
+		address         string
+		networkData     string
+		expectedLinks   int
+		expectedFailure string
+	}{
+		{
+			Scenario:      "virtual media",
+			address:       "redfish-virtualmedia://192.168.122.1/redfish/v1/Systems/1",
+			networkData:   testNetworkData,
+			expectedLinks: 1,
+		},
+		{
+			Scenario:      "idrac virtual media",
+			address:       "idrac-virtualmedia://192.168.122.1/redfish/v1/Systems/System.Embedded.1",
+			networkData:   testNetworkData,
+			expectedLinks: 1,
+		},
+		{
+			Scenario:    "network boot",
+			address:     "idrac://192.168.122.1",
+			networkData: testNetworkData,
+		},
+		{
+			Scenario: "no network data",
+			address:  "redfish-virtualmedia://192.168.122.1/redfish/v1/Systems/1",
+		},
+		{
+			Scenario:        "invalid network data",
+			address:         "redfish-virtualmedia://192.168.122.1/redfish/v1/Systems/1",
+			networkData:     "links: [",
+			expectedFailure: "failed to unmarshal network_data.json",
+		},
+	} {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			// Virtual media hosts do not need a MAC address
+			host := makeHost()
+			host.Spec.BMC.Address = tc.address
+			host.Spec.BootMACAddress = ""
+			host.Status.Provisioning.ID = "" // so we don't lookup by uuid
+
+			var createdNode *nodes.Node
+			createCallback := func(node nodes.Node) {
+				createdNode = &node
+			}
+
+			ironic := testserver.NewIronic(t).Ready().CreateNodes(createCallback).NoNode(host.Namespace + nameSeparator + host.Name).NoNode(host.Name)
+			ironic.AddDefaultResponse("/v1/nodes/node-0", "PATCH", http.StatusOK, "{}")
+			ironic.Start()
+			defer ironic.Stop()
+
+			auth := clients.AuthConfig{Type: clients.NoAuth}
+			prov, err := newProvisionerWithSettings(host, bmc.Credentials{Username: "admin", Password: "pw"}, nullEventPublisher,
+				ironic.Endpoint(), auth, testserver.NewInspector(t).Endpoint(), auth,
+			)
+			if err != nil {
+				t.Fatalf("could not create provisioner: %s", err)
+			}
+
+			result, _, err := prov.ValidateManagementAccess(provisioner.ManagementAccessData{NetworkData: tc.networkData}, false, false)
+			if err != nil {
+				t.Fatalf("error from ValidateManagementAccess: %s", err)
+			}
+			if tc.expectedFailure != "" {
+				assert.Contains(t, result.ErrorMessage, tc.expectedFailure)
+				assert.Nil(t, createdNode)
+				return
+			}
+			assert.Equal(t, "", result.ErrorMessage)
+			if tc.expectedLinks == 0 {
+				assert.Nil(t, createdNode.NetworkData)
+				return
+			}
+			assert.Len(t, createdNode.NetworkData["links"], tc.expectedLinks)
+			assert.Equal(t, "192.0.2.10", createdNode.NetworkData["networks"].([]interface{})[0].(map[string]interface{})["ip_address"])
+		})
+	}
+}
+
+func TestValidateManagementAccessVirtualMediaNetworkDataChanged(t *testing.T) {
+	host := makeHost()
+	host.Spec.BMC.Address = "redfish-virtualmedia://192.168.122.1/redfish/v1/Systems/1"
+	host.Spec.BootMACAddress = ""
+
+	ironic := testserver.NewIronic(t).Ready().Node(nodes.Node{
+		Name:        host.Namespace + nameSeparator + host.Name,
+		UUID:        host.Status.Provisioning.ID,
+		NetworkData: map[string]interface{}{"links": []interface{}{}},
+	}).NodeUpdate(nodes.Node{
+		UUID: host.Status.Provisioning.ID,
+	})
+	ironic.Start()
+	defer ironic.Stop()
+
+	auth := clients.AuthConfig{Type: clients.NoAuth}
+	prov, err := newProvisionerWithSettings(host, bmc.Credentials{Username: "admin", Password: "pw"}, nullEventPublisher,
+		ironic.Endpoint(), auth, testserver.NewInspector(t).Endpoint(), auth,
+	)
+	if err != nil {
+		t.Fatalf("could not create provisioner: %s", err)
+	}
+
+	result, _, err := prov.ValidateManagementAccess(provisioner.ManagementAccessData{NetworkData: testNetworkData}, false, false)
+	if err != nil {
+		t.Fatalf("error from ValidateManagementAccess: %s", err)
+	}
+	assert.Equal(t, "", result.ErrorMessage)
+
+	updates := ironic.GetLastNodeUpdateRequestFor(host.Status.Provisioning.ID)
+	found := false
+	for _, update := range updates {
+		if update.Path == "/network_data" {
+			found = true
+			assert.Len(t, update.Value.(map[string]interface{})["links"], 1)
+		}
+	}
+	assert.True(t, found, "network_data not updated")
+}
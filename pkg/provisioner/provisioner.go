@@ -24,6 +24,7 @@ type HostData struct {
 	BMCAddress                     string
 	BMCCredentials                 bmc.Credentials
 	DisableCertificateVerification bool
+	BMCCABundle                    []byte
 	BootMACAddress                 string
 	ProvisionerID                  string
 }
@@ -65,6 +66,10 @@ type ManagementAccessData struct {
 	State                 metal3v1alpha1.ProvisioningState
 	CurrentImage          *metal3v1alpha1.Image
 	HasCustomDeploy       bool
+	// NetworkData is the network configuration of the host, in the
+	// OpenStack network_data.json format, used by the deploy ramdisk
+	// of hosts booted from virtual media
+	NetworkData string
 }
 
 type AdoptData struct {
@@ -79,6 +84,14 @@ type PrepareData struct {
 	RAIDConfig      *metal3v1alpha1.RAIDConfig
 	RootDeviceHints *metal3v1alpha1.RootDeviceHints
 	FirmwareConfig  *metal3v1alpha1.FirmwareConfig
+	// TargetFirmwareSettings are the settings requested through the
+	// HostFirmwareSettings resource for the host.
+	TargetFirmwareSettings metal3v1alpha1.DesiredSettingsMap
+	// ActualFirmwareSettings are the settings last read from the
+	// host, used to only apply the target settings that differ.
+	ActualFirmwareSettings metal3v1alpha1.SettingsMap
+	// FirmwareUpdates are the firmware images to apply to the host.
+	FirmwareUpdates []metal3v1alpha1.FirmwareUpdate
 }
 
 type ProvisionData struct {
@@ -157,6 +170,12 @@ type Provisioner interface {
 
 	// HasCapacity checks if the backend has a free (de)provisioning slot for the current host
 	HasCapacity() (result bool, err error)
+
+	// GetFirmwareSettings gets the current BIOS settings of the host
+	// as a map of setting name to value. When includeSchema is true it
+	// also returns the description of each setting from the BIOS
+	// attribute registry.
+	GetFirmwareSettings(includeSchema bool) (settings metal3v1alpha1.SettingsMap, schema map[string]metal3v1alpha1.SettingSchema, err error)
 }
 
 // Result holds the response from a call in the Provsioner API.
@@ -0,0 +1,98 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+)
+
+// BareMetalHostDefaulterPath is the path the BareMetalHost mutating
+// webhook is served on
+const BareMetalHostDefaulterPath = "/mutate-metal3-io-v1alpha1-baremetalhost"
+
+// +kubebuilder:webhook:path=/mutate-metal3-io-v1alpha1-baremetalhost,mutating=true,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups=metal3.io,resources=baremetalhosts,verbs=create,versions=v1alpha1,name=defaults.baremetalhost.metal3.io
+
+// BareMetalHostDefaulter writes the values the controller would
+// otherwise assume onto the spec of new BareMetalHost resources, so the
+// stored object shows the effective settings. Existing hosts are left
+// alone, as rewriting their BMC address would register them again.
+type BareMetalHostDefaulter struct {
+	Log     logr.Logger
+	decoder *admission.Decoder
+}
+
+// InjectDecoder injects the decoder into the defaulter
+func (d *BareMetalHostDefaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+// Handle sets the defaults of the BareMetalHost created by the
+// admission request
+func (d *BareMetalHostDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+
+	host := &metal3v1alpha1.BareMetalHost{}
+	if err := d.decoder.Decode(req, host); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if !host.DeletionTimestamp.IsZero() {
+		return admission.Allowed("")
+	}
+
+	defaultHost(host)
+
+	marshaled, err := json.Marshal(host)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// defaultHost fills in the defaults and normalizes the spec of the
+// host. Values that cannot be normalized are left alone for the
+// validating webhook to report.
+func defaultHost(host *metal3v1alpha1.BareMetalHost) {
+	if host.Spec.BootMode == "" {
+		host.Spec.BootMode = metal3v1alpha1.DefaultBootMode
+	}
+
+	if host.Spec.AutomatedCleaningMode == "" {
+		host.Spec.AutomatedCleaningMode = metal3v1alpha1.CleaningModeMetadata
+	}
+
+	if host.Spec.BMC.Address != "" {
+		if address, err := bmc.NormalizeAddress(host.Spec.BMC.Address); err == nil {
+			host.Spec.BMC.Address = address
+		}
+	}
+
+	host.Spec.BootMACAddress = strings.ToLower(host.Spec.BootMACAddress)
+}
@@ -0,0 +1,156 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+func TestDefaultHost(t *testing.T) {
+	testCases := []struct {
+		Scenario string
+		Spec     metal3v1alpha1.BareMetalHostSpec
+		Expected metal3v1alpha1.BareMetalHostSpec
+	}{
+		{
+			Scenario: "empty",
+			Expected: metal3v1alpha1.BareMetalHostSpec{
+				BootMode:              metal3v1alpha1.DefaultBootMode,
+				AutomatedCleaningMode: metal3v1alpha1.CleaningModeMetadata,
+			},
+		},
+		{
+			Scenario: "explicit values",
+			Spec: metal3v1alpha1.BareMetalHostSpec{
+				BMC: metal3v1alpha1.BMCDetails{
+					Address: "redfish://192.168.122.1/redfish/v1/Systems/1",
+				},
+				BootMACAddress:        "00:11:22:33:44:55",
+				BootMode:              metal3v1alpha1.Legacy,
+				AutomatedCleaningMode: metal3v1alpha1.CleaningModeDisabled,
+			},
+			Expected: metal3v1alpha1.BareMetalHostSpec{
+				BMC: metal3v1alpha1.BMCDetails{
+					Address: "redfish://192.168.122.1:443/redfish/v1/Systems/1",
+				},
+				BootMACAddress:        "00:11:22:33:44:55",
+				BootMode:              metal3v1alpha1.Legacy,
+				AutomatedCleaningMode: metal3v1alpha1.CleaningModeDisabled,
+			},
+		},
+		{
+			Scenario: "normalized",
+			Spec: metal3v1alpha1.BareMetalHostSpec{
+				BMC: metal3v1alpha1.BMCDetails{
+					Address: "192.168.122.1:6233",
+				},
+				BootMACAddress: "00:11:22:33:AA:BB",
+			},
+			Expected: metal3v1alpha1.BareMetalHostSpec{
+				BMC: metal3v1alpha1.BMCDetails{
+					Address: "ipmi://192.168.122.1:6233",
+				},
+				BootMACAddress:        "00:11:22:33:aa:bb",
+				BootMode:              metal3v1alpha1.DefaultBootMode,
+				AutomatedCleaningMode: metal3v1alpha1.CleaningModeMetadata,
+			},
+		},
+		{
+			Scenario: "unparseable address",
+			Spec: metal3v1alpha1.BareMetalHostSpec{
+				BMC: metal3v1alpha1.BMCDetails{
+					Address: "[fe80::fc33:62ff:fe83:8a76]:6233:2",
+				},
+			},
+			Expected: metal3v1alpha1.BareMetalHostSpec{
+				BMC: metal3v1alpha1.BMCDetails{
+					Address: "[fe80::fc33:62ff:fe83:8a76]:6233:2",
+				},
+				BootMode:              metal3v1alpha1.DefaultBootMode,
+				AutomatedCleaningMode: metal3v1alpha1.CleaningModeMetadata,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			host := newHost("host", tc.Spec)
+			defaultHost(host)
+			assert.Equal(t, tc.Expected, host.Spec)
+		})
+	}
+}
+
+func TestBareMetalHostDefaulterHandle(t *testing.T) {
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &BareMetalHostDefaulter{
+		Log: ctrl.Log.WithName("webhooks").WithName("BareMetalHost"),
+	}
+	assert.NoError(t, d.InjectDecoder(decoder))
+
+	host := newHost("host", metal3v1alpha1.BareMetalHostSpec{
+		BMC: metal3v1alpha1.BMCDetails{
+			Address: "192.168.122.1",
+		},
+		BootMACAddress: "00:11:22:33:AA:BB",
+	})
+	resp := d.Handle(context.TODO(), newAdmissionRequest(t, admissionv1.Create, host, nil))
+	assert.True(t, resp.Allowed, resp.Result)
+
+	patched := map[string]interface{}{}
+	for _, patch := range resp.Patches {
+		patched[patch.Path] = patch.Value
+	}
+	assert.Equal(t, map[string]interface{}{
+		"/spec/bmc/address":           "ipmi://192.168.122.1:623",
+		"/spec/bootMACAddress":        "00:11:22:33:aa:bb",
+		"/spec/bootMode":              string(metal3v1alpha1.DefaultBootMode),
+		"/spec/automatedCleaningMode": string(metal3v1alpha1.CleaningModeMetadata),
+	}, patched)
+
+	deleted := host.DeepCopy()
+	now := metav1.Now()
+	deleted.DeletionTimestamp = &now
+	resp = d.Handle(context.TODO(), newAdmissionRequest(t, admissionv1.Create, deleted, nil))
+	assert.True(t, resp.Allowed, resp.Result)
+	assert.Empty(t, resp.Patches)
+}
+
+// TestBareMetalHostDefaulterUpdate ensures that the spec of existing
+// hosts is not rewritten when they are updated.
+func TestBareMetalHostDefaulterUpdate(t *testing.T) {
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &BareMetalHostDefaulter{
+		Log: ctrl.Log.WithName("webhooks").WithName("BareMetalHost"),
+	}
+	assert.NoError(t, d.InjectDecoder(decoder))
+
+	old := newHost("host", metal3v1alpha1.BareMetalHostSpec{
+		BMC: metal3v1alpha1.BMCDetails{
+			Address: "192.168.122.1",
+		},
+		BootMACAddress: "00:11:22:33:AA:BB",
+	})
+	host := old.DeepCopy()
+	host.Spec.Online = true
+	resp := d.Handle(context.TODO(), newAdmissionRequest(t, admissionv1.Update, host, old))
+	assert.True(t, resp.Allowed, resp.Result)
+	assert.Empty(t, resp.Patches)
+}
@@ -0,0 +1,152 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/metal3-io/baremetal-operator/pkg/bmc"
+	"github.com/metal3-io/baremetal-operator/pkg/hardware"
+	"github.com/metal3-io/baremetal-operator/pkg/provisioner/ironic"
+)
+
+// BareMetalHostValidatorPath is the path the BareMetalHost validating
+// webhook is served on
+const BareMetalHostValidatorPath = "/validate-metal3-io-v1alpha1-baremetalhost"
+
+// +kubebuilder:webhook:path=/validate-metal3-io-v1alpha1-baremetalhost,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups=metal3.io,resources=baremetalhosts,verbs=create;update,versions=v1alpha1,name=baremetalhost.metal3.io
+
+// BareMetalHostValidator rejects BareMetalHost resources that the
+// controller would fail to register or provision.
+type BareMetalHostValidator struct {
+	Client  client.Client
+	Log     logr.Logger
+	decoder *admission.Decoder
+}
+
+// InjectDecoder injects the decoder into the validator
+func (v *BareMetalHostValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle validates the BareMetalHost in the admission request
+func (v *BareMetalHostValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	host := &metal3v1alpha1.BareMetalHost{}
+	if err := v.decoder.Decode(req, host); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Update {
+		oldHost := &metal3v1alpha1.BareMetalHost{}
+		if err := v.decoder.DecodeRaw(req.OldObject, oldHost); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		// Do not get in the way of hosts being deleted, nor of the
+		// controller updating the metadata of hosts created before
+		// the validation existed.
+		if !host.DeletionTimestamp.IsZero() || reflect.DeepEqual(host.Spec, oldHost.Spec) {
+			return admission.Allowed("")
+		}
+	}
+
+	existing := &metal3v1alpha1.BareMetalHostList{}
+	if host.Spec.BootMACAddress != "" {
+		if err := v.Client.List(ctx, existing, client.InNamespace(host.Namespace)); err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+	}
+
+	profiles := &metal3v1alpha1.HardwareProfileList{}
+	if host.Spec.HardwareProfile != "" {
+		if err := v.Client.List(ctx, profiles, client.InNamespace(host.Namespace)); err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+	}
+
+	if errs := validateHost(host, existing.Items, profiles.Items); len(errs) != 0 {
+		v.Log.Info("rejecting host", "host", req.Namespace+"/"+req.Name, "errors", errs.ToAggregate().Error())
+		return admission.Denied(errs.ToAggregate().Error())
+	}
+	return admission.Allowed("")
+}
+
+// validateHost checks the spec of the host, using the other hosts of
+// the namespace to detect conflicts and the HardwareProfile resources
+// of the namespace to check the hardware profile.
+func validateHost(host *metal3v1alpha1.BareMetalHost, existing []metal3v1alpha1.BareMetalHost, profiles []metal3v1alpha1.HardwareProfile) (errs field.ErrorList) {
+	specPath := field.NewPath("spec")
+
+	var accessDetails bmc.AccessDetails
+	if host.Spec.BMC.Address != "" {
+		var err error
+		accessDetails, err = bmc.NewAccessDetails(host.Spec.BMC.Address, host.Spec.BMC.DisableCertificateVerification)
+		if err != nil {
+			errs = append(errs, field.Invalid(specPath.Child("bmc", "address"), host.Spec.BMC.Address, err.Error()))
+		}
+	}
+
+	if host.Spec.HardwareProfile != "" && !hardwareProfileExists(host.Spec.HardwareProfile, profiles) {
+		errs = append(errs, field.NotFound(specPath.Child("hardwareProfile"), host.Spec.HardwareProfile))
+	}
+
+	if _, err := ironic.BuildTargetRAIDCfg(host.Spec.RAID); err != nil {
+		errs = append(errs, field.Invalid(specPath.Child("raid"), host.Spec.RAID, err.Error()))
+	}
+
+	if host.Spec.BootMode == metal3v1alpha1.UEFISecureBoot && accessDetails != nil && !accessDetails.SupportsSecureBoot() {
+		errs = append(errs, field.Invalid(specPath.Child("bootMode"), host.Spec.BootMode,
+			fmt.Sprintf("BMC driver %s does not support secure boot", accessDetails.Type())))
+	}
+
+	if host.Spec.BootMACAddress != "" {
+		for _, other := range existing {
+			if other.Name == host.Name || other.Namespace != host.Namespace {
+				continue
+			}
+			if strings.EqualFold(other.Spec.BootMACAddress, host.Spec.BootMACAddress) {
+				errs = append(errs, field.Duplicate(specPath.Child("bootMACAddress"),
+					fmt.Sprintf("%s is already used by host %s", host.Spec.BootMACAddress, other.Name)))
+			}
+		}
+	}
+
+	return errs
+}
+
+// hardwareProfileExists checks whether the profile is either one of
+// the HardwareProfile resources or a built-in profile.
+func hardwareProfileExists(name string, profiles []metal3v1alpha1.HardwareProfile) bool {
+	for _, profile := range profiles {
+		if profile.Name == name {
+			return true
+		}
+	}
+	_, err := hardware.GetProfile(name)
+	return err == nil
+}
@@ -0,0 +1,268 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+func init() {
+	// Register our package types with the global scheme
+	metal3v1alpha1.AddToScheme(scheme.Scheme)
+}
+
+func newHost(name string, spec metal3v1alpha1.BareMetalHostSpec) *metal3v1alpha1.BareMetalHost {
+	return &metal3v1alpha1.BareMetalHost{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "BareMetalHost",
+			APIVersion: "metal3.io/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test-namespace",
+		},
+		Spec: spec,
+	}
+}
+
+func newAdmissionRequest(t *testing.T, op admissionv1.Operation, obj, oldObj runtime.Object) admission.Request {
+	req := admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: op,
+		},
+	}
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Object = runtime.RawExtension{Raw: raw}
+	if oldObj != nil {
+		raw, err = json.Marshal(oldObj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.OldObject = runtime.RawExtension{Raw: raw}
+	}
+	return req
+}
+
+func TestValidateHost(t *testing.T) {
+	sizeGibibytes := 100
+	existing := []metal3v1alpha1.BareMetalHost{
+		*newHost("other", metal3v1alpha1.BareMetalHostSpec{
+			BootMACAddress: "00:11:22:33:aa:bb",
+		}),
+	}
+	profiles := []metal3v1alpha1.HardwareProfile{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "r640", Namespace: "test-namespace"},
+		},
+	}
+
+	testCases := []struct {
+		Scenario string
+		Spec     metal3v1alpha1.BareMetalHostSpec
+		Errors   []string
+	}{
+		{
+			Scenario: "empty",
+		},
+		{
+			Scenario: "valid",
+			Spec: metal3v1alpha1.BareMetalHostSpec{
+				BMC: metal3v1alpha1.BMCDetails{
+					Address: "redfish://192.168.122.1/redfish/v1/Systems/1",
+				},
+				BootMACAddress:  "00:11:22:33:44:66",
+				BootMode:        metal3v1alpha1.UEFISecureBoot,
+				HardwareProfile: "libvirt",
+				RAID: &metal3v1alpha1.RAIDConfig{
+					HardwareRAIDVolumes: []metal3v1alpha1.HardwareRAIDVolume{
+						{Level: "1", SizeGibibytes: &sizeGibibytes},
+					},
+				},
+			},
+		},
+		{
+			Scenario: "unknown BMC type",
+			Spec: metal3v1alpha1.BareMetalHostSpec{
+				BMC: metal3v1alpha1.BMCDetails{
+					Address: "foo://192.168.122.1",
+				},
+			},
+			Errors: []string{"spec.bmc.address"},
+		},
+		{
+			Scenario: "unparseable BMC address",
+			Spec: metal3v1alpha1.BareMetalHostSpec{
+				BMC: metal3v1alpha1.BMCDetails{
+					Address: "[fe80::fc33:62ff:fe83:8a76]:6233:2",
+				},
+			},
+			Errors: []string{"spec.bmc.address"},
+		},
+		{
+			Scenario: "unknown hardware profile",
+			Spec: metal3v1alpha1.BareMetalHostSpec{
+				HardwareProfile: "no-such-profile",
+			},
+			Errors: []string{"spec.hardwareProfile"},
+		},
+		{
+			Scenario: "hardware profile resource",
+			Spec: metal3v1alpha1.BareMetalHostSpec{
+				HardwareProfile: "r640",
+			},
+		},
+		{
+			Scenario: "invalid RAID level",
+			Spec: metal3v1alpha1.BareMetalHostSpec{
+				RAID: &metal3v1alpha1.RAIDConfig{
+					SoftwareRAIDVolumes: []metal3v1alpha1.SoftwareRAIDVolume{
+						{Level: "5"},
+					},
+				},
+			},
+			Errors: []string{"spec.raid"},
+		},
+		{
+			Scenario: "secure boot not supported",
+			Spec: metal3v1alpha1.BareMetalHostSpec{
+				BMC: metal3v1alpha1.BMCDetails{
+					Address: "ipmi://192.168.122.1:6233",
+				},
+				BootMode: metal3v1alpha1.UEFISecureBoot,
+			},
+			Errors: []string{"spec.bootMode"},
+		},
+		{
+			Scenario: "duplicate boot MAC",
+			Spec: metal3v1alpha1.BareMetalHostSpec{
+				BootMACAddress: "00:11:22:33:aa:bb",
+			},
+			Errors: []string{"spec.bootMACAddress"},
+		},
+		{
+			Scenario: "duplicate boot MAC different case",
+			Spec: metal3v1alpha1.BareMetalHostSpec{
+				BootMACAddress: "00:11:22:33:AA:BB",
+			},
+			Errors: []string{"spec.bootMACAddress"},
+		},
+		{
+			Scenario: "multiple errors",
+			Spec: metal3v1alpha1.BareMetalHostSpec{
+				BMC: metal3v1alpha1.BMCDetails{
+					Address: "foo://192.168.122.1",
+				},
+				HardwareProfile: "no-such-profile",
+			},
+			Errors: []string{"spec.bmc.address", "spec.hardwareProfile"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			host := newHost("host", tc.Spec)
+			errs := validateHost(host, existing, profiles)
+			fields := []string{}
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			assert.ElementsMatch(t, tc.Errors, fields)
+		})
+	}
+}
+
+func TestBareMetalHostValidatorHandle(t *testing.T) {
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	existing := newHost("existing", metal3v1alpha1.BareMetalHostSpec{
+		BootMACAddress: "00:11:22:33:44:55",
+	})
+	duplicate := newHost("duplicate", metal3v1alpha1.BareMetalHostSpec{
+		BootMACAddress: "00:11:22:33:44:55",
+	})
+	deleted := duplicate.DeepCopy()
+	now := metav1.Now()
+	deleted.DeletionTimestamp = &now
+	relabeled := duplicate.DeepCopy()
+	relabeled.Labels = map[string]string{"foo": "bar"}
+	valid := newHost("valid", metal3v1alpha1.BareMetalHostSpec{
+		BootMACAddress: "00:11:22:33:44:66",
+	})
+
+	testCases := []struct {
+		Scenario  string
+		Operation admissionv1.Operation
+		Host      *metal3v1alpha1.BareMetalHost
+		OldHost   *metal3v1alpha1.BareMetalHost
+		Allowed   bool
+	}{
+		{
+			Scenario:  "create valid",
+			Operation: admissionv1.Create,
+			Host:      valid,
+			Allowed:   true,
+		},
+		{
+			Scenario:  "create duplicate",
+			Operation: admissionv1.Create,
+			Host:      duplicate,
+			Allowed:   false,
+		},
+		{
+			Scenario:  "update existing invalid host metadata",
+			Operation: admissionv1.Update,
+			Host:      relabeled,
+			OldHost:   duplicate,
+			Allowed:   true,
+		},
+		{
+			Scenario:  "update to duplicate",
+			Operation: admissionv1.Update,
+			Host:      duplicate,
+			OldHost:   valid,
+			Allowed:   false,
+		},
+		{
+			Scenario:  "update deleted host",
+			Operation: admissionv1.Update,
+			Host:      deleted,
+			OldHost:   valid,
+			Allowed:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			v := &BareMetalHostValidator{
+				Client: fakeclient.NewFakeClient(existing),
+				Log:    ctrl.Log.WithName("webhooks").WithName("BareMetalHost"),
+			}
+			assert.NoError(t, v.InjectDecoder(decoder))
+
+			var oldObj runtime.Object
+			if tc.OldHost != nil {
+				oldObj = tc.OldHost
+			}
+			resp := v.Handle(context.TODO(), newAdmissionRequest(t, tc.Operation, tc.Host, oldObj))
+			assert.Equal(t, tc.Allowed, resp.Allowed, resp.Result)
+		})
+	}
+}
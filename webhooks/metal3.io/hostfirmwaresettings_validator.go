@@ -0,0 +1,152 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+// HostFirmwareSettingsValidatorPath is the path the HostFirmwareSettings
+// validating webhook is served on
+const HostFirmwareSettingsValidatorPath = "/validate-metal3-io-v1alpha1-hostfirmwaresettings"
+
+// +kubebuilder:webhook:path=/validate-metal3-io-v1alpha1-hostfirmwaresettings,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups=metal3.io,resources=hostfirmwaresettings,verbs=create;update,versions=v1alpha1,name=hostfirmwaresettings.metal3.io
+
+// HostFirmwareSettingsValidator rejects settings that do not match the
+// FirmwareSchema of the host.
+type HostFirmwareSettingsValidator struct {
+	Client  client.Client
+	Log     logr.Logger
+	decoder *admission.Decoder
+}
+
+// InjectDecoder injects the decoder into the validator
+func (v *HostFirmwareSettingsValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle validates the HostFirmwareSettings in the admission request
+func (v *HostFirmwareSettingsValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	hfs := &metal3v1alpha1.HostFirmwareSettings{}
+	if err := v.decoder.Decode(req, hfs); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Update {
+		oldHFS := &metal3v1alpha1.HostFirmwareSettings{}
+		if err := v.decoder.DecodeRaw(req.OldObject, oldHFS); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if reflect.DeepEqual(hfs.Spec, oldHFS.Spec) {
+			return admission.Allowed("")
+		}
+		// The status is not part of the request when the spec is
+		// updated, use the reference recorded by the controller.
+		if hfs.Status.FirmwareSchema == nil {
+			hfs.Status.FirmwareSchema = oldHFS.Status.FirmwareSchema
+		}
+	}
+
+	ref := hfs.Status.FirmwareSchema
+	if ref == nil || len(hfs.Spec.Settings) == 0 {
+		// Nothing to check the settings against until the host has
+		// been inspected.
+		return admission.Allowed("")
+	}
+
+	schema := &metal3v1alpha1.FirmwareSchema{}
+	err := v.Client.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, schema)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return admission.Allowed(fmt.Sprintf("firmware schema %s/%s not found, settings not validated",
+				ref.Namespace, ref.Name))
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if errs := validateFirmwareSettings(hfs.Spec.Settings, schema); len(errs) != 0 {
+		v.Log.Info("rejecting firmware settings", "settings", req.Namespace+"/"+req.Name,
+			"errors", errs.ToAggregate().Error())
+		return admission.Denied(errs.ToAggregate().Error())
+	}
+	return admission.Allowed("")
+}
+
+// validateFirmwareSettings checks each of the settings against the
+// schema.
+func validateFirmwareSettings(settings metal3v1alpha1.DesiredSettingsMap, schema *metal3v1alpha1.FirmwareSchema) (errs field.ErrorList) {
+	settingsPath := field.NewPath("spec", "settings")
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := settings[name]
+		path := settingsPath.Key(name)
+
+		settingSchema, ok := schema.Spec.Schema[name]
+		switch {
+		case !ok:
+			errs = append(errs, field.NotFound(path, name))
+		case settingSchema.ReadOnly != nil && *settingSchema.ReadOnly:
+			errs = append(errs, field.Forbidden(path, "setting is read only"))
+		case settingSchema.AttributeType == "Password":
+			errs = append(errs, field.Forbidden(path, "password settings cannot be set"))
+		case !schema.CheckSettingIsValid(name, value, schema.Spec.Schema):
+			errs = append(errs, field.Invalid(path, value.String(), describeSetting(settingSchema)))
+		}
+	}
+	return errs
+}
+
+// describeSetting explains the values accepted by a setting.
+func describeSetting(schema metal3v1alpha1.SettingSchema) string {
+	switch schema.AttributeType {
+	case "Enumeration":
+		return fmt.Sprintf("must be one of %s", strings.Join(schema.AllowableValues, ", "))
+	case "Integer":
+		if schema.LowerBound != nil && schema.UpperBound != nil {
+			return fmt.Sprintf("must be an integer between %d and %d", *schema.LowerBound, *schema.UpperBound)
+		}
+	case "String":
+		if schema.MinLength != nil && schema.MaxLength != nil {
+			return fmt.Sprintf("must be between %d and %d characters long", *schema.MinLength, *schema.MaxLength)
+		}
+	case "Boolean":
+		return "must be true or false"
+	}
+	return fmt.Sprintf("not a valid value for a setting of type %q", schema.AttributeType)
+}
@@ -0,0 +1,236 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+func newFirmwareSchema() *metal3v1alpha1.FirmwareSchema {
+	lowerBound := 0
+	upperBound := 20
+	minLength := 0
+	maxLength := 16
+	readOnly := true
+
+	return &metal3v1alpha1.FirmwareSchema{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "schema-01234567",
+			Namespace: "test-namespace",
+		},
+		Spec: metal3v1alpha1.FirmwareSchemaSpec{
+			Schema: map[string]metal3v1alpha1.SettingSchema{
+				"AdminPassword": {
+					AttributeType: "Password",
+				},
+				"AssetTag": {
+					AttributeType: "String",
+					MinLength:     &minLength,
+					MaxLength:     &maxLength,
+				},
+				"NetworkBootRetryCount": {
+					AttributeType: "Integer",
+					LowerBound:    &lowerBound,
+					UpperBound:    &upperBound,
+				},
+				"ProcVirtualization": {
+					AttributeType:   "Enumeration",
+					AllowableValues: []string{"Enabled", "Disabled"},
+				},
+				"SerialNumber": {
+					AttributeType: "String",
+					ReadOnly:      &readOnly,
+				},
+			},
+		},
+	}
+}
+
+func newHostFirmwareSettings(settings metal3v1alpha1.DesiredSettingsMap, schema *metal3v1alpha1.FirmwareSchema) *metal3v1alpha1.HostFirmwareSettings {
+	hfs := &metal3v1alpha1.HostFirmwareSettings{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HostFirmwareSettings",
+			APIVersion: "metal3.io/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "host",
+			Namespace: "test-namespace",
+		},
+		Spec: metal3v1alpha1.HostFirmwareSettingsSpec{
+			Settings: settings,
+		},
+	}
+	if schema != nil {
+		hfs.Status.FirmwareSchema = &metal3v1alpha1.SchemaReference{
+			Namespace: schema.Namespace,
+			Name:      schema.Name,
+		}
+	}
+	return hfs
+}
+
+func TestValidateFirmwareSettings(t *testing.T) {
+	testCases := []struct {
+		Scenario string
+		Settings metal3v1alpha1.DesiredSettingsMap
+		Errors   map[string]string
+	}{
+		{
+			Scenario: "empty",
+		},
+		{
+			Scenario: "valid",
+			Settings: metal3v1alpha1.DesiredSettingsMap{
+				"AssetTag":              intstr.FromString("rack-1"),
+				"NetworkBootRetryCount": intstr.FromInt(10),
+				"ProcVirtualization":    intstr.FromString("Disabled"),
+			},
+		},
+		{
+			Scenario: "unknown setting",
+			Settings: metal3v1alpha1.DesiredSettingsMap{
+				"SRIOV": intstr.FromString("Enabled"),
+			},
+			Errors: map[string]string{
+				"spec.settings[SRIOV]": "Not found",
+			},
+		},
+		{
+			Scenario: "read only",
+			Settings: metal3v1alpha1.DesiredSettingsMap{
+				"SerialNumber": intstr.FromString("22654891"),
+			},
+			Errors: map[string]string{
+				"spec.settings[SerialNumber]": "setting is read only",
+			},
+		},
+		{
+			Scenario: "password",
+			Settings: metal3v1alpha1.DesiredSettingsMap{
+				"AdminPassword": intstr.FromString("secret"),
+			},
+			Errors: map[string]string{
+				"spec.settings[AdminPassword]": "password settings cannot be set",
+			},
+		},
+		{
+			Scenario: "invalid values",
+			Settings: metal3v1alpha1.DesiredSettingsMap{
+				"AssetTag":              intstr.FromString("a-very-long-asset-tag"),
+				"NetworkBootRetryCount": intstr.FromInt(30),
+				"ProcVirtualization":    intstr.FromString("Off"),
+			},
+			Errors: map[string]string{
+				"spec.settings[AssetTag]":              "must be between 0 and 16 characters long",
+				"spec.settings[NetworkBootRetryCount]": "must be an integer between 0 and 20",
+				"spec.settings[ProcVirtualization]":    "must be one of Enabled, Disabled",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			errs := validateFirmwareSettings(tc.Settings, newFirmwareSchema())
+			assert.Len(t, errs, len(tc.Errors))
+			for _, err := range errs {
+				if assert.Contains(t, tc.Errors, err.Field) {
+					assert.Contains(t, err.Error(), tc.Errors[err.Field])
+				}
+			}
+		})
+	}
+}
+
+func TestHostFirmwareSettingsValidatorHandle(t *testing.T) {
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := newFirmwareSchema()
+	missingSchema := newFirmwareSchema()
+	missingSchema.Name = "schema-89abcdef"
+
+	valid := metal3v1alpha1.DesiredSettingsMap{
+		"ProcVirtualization": intstr.FromString("Disabled"),
+	}
+	invalid := metal3v1alpha1.DesiredSettingsMap{
+		"ProcVirtualization": intstr.FromString("Off"),
+	}
+
+	testCases := []struct {
+		Scenario  string
+		Operation admissionv1.Operation
+		HFS       *metal3v1alpha1.HostFirmwareSettings
+		OldHFS    *metal3v1alpha1.HostFirmwareSettings
+		Allowed   bool
+	}{
+		{
+			Scenario:  "create without schema",
+			Operation: admissionv1.Create,
+			HFS:       newHostFirmwareSettings(invalid, nil),
+			Allowed:   true,
+		},
+		{
+			Scenario:  "create valid",
+			Operation: admissionv1.Create,
+			HFS:       newHostFirmwareSettings(valid, schema),
+			Allowed:   true,
+		},
+		{
+			Scenario:  "create invalid",
+			Operation: admissionv1.Create,
+			HFS:       newHostFirmwareSettings(invalid, schema),
+			Allowed:   false,
+		},
+		{
+			Scenario:  "update to invalid",
+			Operation: admissionv1.Update,
+			HFS:       newHostFirmwareSettings(invalid, nil),
+			OldHFS:    newHostFirmwareSettings(valid, schema),
+			Allowed:   false,
+		},
+		{
+			Scenario:  "update status of invalid",
+			Operation: admissionv1.Update,
+			HFS:       newHostFirmwareSettings(invalid, schema),
+			OldHFS:    newHostFirmwareSettings(invalid, nil),
+			Allowed:   true,
+		},
+		{
+			Scenario:  "schema not found",
+			Operation: admissionv1.Create,
+			HFS:       newHostFirmwareSettings(invalid, missingSchema),
+			Allowed:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			v := &HostFirmwareSettingsValidator{
+				Client: fakeclient.NewFakeClient(schema),
+				Log:    ctrl.Log.WithName("webhooks").WithName("HostFirmwareSettings"),
+			}
+			assert.NoError(t, v.InjectDecoder(decoder))
+
+			var oldObj runtime.Object
+			if tc.OldHFS != nil {
+				oldObj = tc.OldHFS
+			}
+			resp := v.Handle(context.TODO(), newAdmissionRequest(t, tc.Operation, tc.HFS, oldObj))
+			assert.Equal(t, tc.Allowed, resp.Allowed, resp.Result)
+		})
+	}
+}